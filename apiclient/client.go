@@ -0,0 +1,181 @@
+// Package apiclient - общий HTTP-клиент API сервера агрегатора.
+// Описание маршрутов: GET /api/openapi.json на сервере.
+// Используется researcher-ами и генератором данных вместо собственных postRequest.
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 2 * time.Second
+)
+
+// Client - клиент REST API сервера
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// New создаёт клиента. baseURL - адрес сервера без /api, например http://server:8080
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+	}
+}
+
+// WithRetries задаёт число попыток и базовую паузу между ними.
+// Повторяются только сетевые ошибки, 429 и 5xx.
+func (c *Client) WithRetries(maxRetries int, delay time.Duration) *Client {
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
+	c.maxRetries = maxRetries
+	c.retryDelay = delay
+	return c
+}
+
+// FieldError - ошибка валидации поля из ответа сервера
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error - ошибка, которую вернул сервер в формате {code, message, field_errors, request_id}
+type Error struct {
+	StatusCode  int          `json:"-"`
+	Code        string       `json:"code"`
+	Message     string       `json:"message"`
+	FieldErrors []FieldError `json:"field_errors,omitempty"`
+	RequestID   string       `json:"request_id"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("server error %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	for _, fe := range e.FieldErrors {
+		msg += fmt.Sprintf("; %s: %s", fe.Field, fe.Message)
+	}
+	if e.RequestID != "" {
+		msg += " [request_id=" + e.RequestID + "]"
+	}
+	return msg
+}
+
+// StatusCode возвращает HTTP-статус ошибки сервера или 0, если ошибка не от сервера
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsConflict - сущность уже существует (409)
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+// IsNotFound - сущность не найдена (404)
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsRejected - пост отклонён фильтром контента (422 post_rejected)
+func IsRejected(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == "post_rejected"
+}
+
+// do выполняет запрос с ретраями и декодирует JSON-ответ в out (если out != nil)
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * c.retryDelay):
+			}
+		}
+
+		respBody, status, err := c.send(ctx, method, path, payload)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if status >= 400 {
+			apiErr := &Error{StatusCode: status}
+			if json.Unmarshal(respBody, apiErr) != nil || apiErr.Code == "" {
+				apiErr.Code = http.StatusText(status)
+				apiErr.Message = strings.TrimSpace(string(respBody))
+			}
+			if status == http.StatusTooManyRequests || status >= 500 {
+				lastErr = apiErr
+				continue
+			}
+			return apiErr
+		}
+
+		if out == nil || len(respBody) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode response from %s %s: %w", method, path, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%s %s failed after %d attempts: %w", method, path, c.maxRetries, lastErr)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, payload []byte) ([]byte, int, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return respBody, resp.StatusCode, nil
+}
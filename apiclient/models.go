@@ -0,0 +1,161 @@
+package apiclient
+
+// Модели тел запросов и ответов. Поля совпадают с колонками db/init.sql
+// и со схемой components/schemas в /api/openapi.json.
+
+type Source struct {
+	SourceID int    `json:"source_id,omitempty"`
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Topic    string `json:"topic,omitempty"`
+}
+
+type Channel struct {
+	ChannelID        int    `json:"channel_id,omitempty"`
+	Name             string `json:"name"`
+	Link             string `json:"link,omitempty"`
+	SubscribersCount int    `json:"subscribers_count"`
+	SourceID         int    `json:"source_id,omitempty"`
+	Topic            string `json:"topic,omitempty"`
+}
+
+// Author - автор. Поля профиля платформы необязательны: их заполняют ресерчеры.
+type Author struct {
+	AuthorID           int    `json:"author_id,omitempty"`
+	Name               string `json:"name"`
+	Platform           string `json:"platform,omitempty"`
+	ExternalID         string `json:"external_id,omitempty"`
+	ProfileURL         string `json:"profile_url,omitempty"`
+	PlatformReputation *int64 `json:"platform_reputation,omitempty"`
+}
+
+// AuthorProfile - ответ GET /api/v1/authors/{id}/profile
+type AuthorProfile struct {
+	Author
+	UpdatedAt  string `json:"updated_at,omitempty"`
+	Reputation struct {
+		Submissions     int     `json:"submissions"`
+		ApprovedPosts   int     `json:"approved_posts"`
+		RejectedPosts   int     `json:"rejected_posts"`
+		FilteredPosts   int     `json:"filtered_posts"`
+		EngagementRatio float64 `json:"engagement_ratio"`
+		FilterRate      float64 `json:"filter_rate"`
+		Score           float64 `json:"score"`
+	} `json:"reputation"`
+}
+
+// Post - пост. При создании текст передаётся в Content,
+// сервер сам сохраняет его в news_texts и связывает теги.
+type Post struct {
+	PostID        int      `json:"post_id,omitempty"`
+	Title         string   `json:"title"`
+	Content       string   `json:"content"`
+	AuthorID      int      `json:"author_id"`
+	ChannelID     int      `json:"channel_id"`
+	TextID        int      `json:"text_id,omitempty"`
+	CommentsCount int      `json:"comments_count"`
+	LikesCount    int      `json:"likes_count"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	// Language - код языка текста (ru, en, ...), определяет сервер
+	Language string `json:"language,omitempty"`
+	// MarkedAsAds - платформа пометила пост как рекламу (сигнал для фильтра контента)
+	MarkedAsAds bool `json:"marked_as_ads,omitempty"`
+	// ViewsCount - просмотры на платформе (только при создании)
+	ViewsCount int `json:"views_count,omitempty"`
+	// NSFW - платформа пометила пост как 18+ (сигнал для фильтра контента)
+	NSFW bool `json:"nsfw,omitempty"`
+	// Metadata - поля платформы без отдельных колонок: flair, upvote_ratio,
+	// spoiler, permalink, domain, awards (Reddit)
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Только в ответах GET /api/v1/posts
+	AuthorName  string `json:"author_name,omitempty"`
+	ChannelName string `json:"channel_name,omitempty"`
+
+	// Только в ответе на создание: статус модерации, решение фильтра контента,
+	// категории, назначенные классификатором, сюжет (группа дубликатов)
+	// и извлечённые ключевые слова
+	PostStatus  string               `json:"post_status,omitempty"`
+	Filter      *FilterDecision      `json:"filter,omitempty"`
+	Categories  []CategoryAssignment `json:"categories,omitempty"`
+	StoryID     int                  `json:"story_id,omitempty"`
+	DuplicateOf *int                 `json:"duplicate_of,omitempty"`
+	Keywords    []KeywordTerm        `json:"keywords,omitempty"`
+}
+
+// KeywordTerm - ключевая фраза или сущность: kind = keyword, person, organization, location
+type KeywordTerm struct {
+	Term  string  `json:"term"`
+	Kind  string  `json:"kind"`
+	Score float64 `json:"score"`
+}
+
+// CategoryAssignment - категория поста и способ её назначения (keywords, bayes, editor)
+type CategoryAssignment struct {
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Method     string  `json:"method"`
+}
+
+// FilterDecision - решение фильтра контента: accept, quarantine или reject
+type FilterDecision struct {
+	Decision string `json:"decision"`
+	Reasons  []struct {
+		RuleID  int    `json:"rule_id"`
+		Kind    string `json:"kind"`
+		Action  string `json:"action"`
+		Message string `json:"message"`
+	} `json:"reasons"`
+}
+
+// VKPost - тело POST /api/vk/posts: текст передаётся в поле text
+type VKPost struct {
+	Title         string   `json:"title,omitempty"`
+	Text          string   `json:"text"`
+	AuthorID      int      `json:"author_id,omitempty"`
+	ChannelID     int      `json:"channel_id"`
+	CommentsCount int      `json:"comments_count"`
+	LikesCount    int      `json:"likes_count"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+
+	// Сигнал для фильтра контента
+	MarkedAsAds bool `json:"marked_as_ads,omitempty"`
+}
+
+type Media struct {
+	MediaID      int    `json:"media_id,omitempty"`
+	PostID       int    `json:"post_id"`
+	MediaContent string `json:"media_content,omitempty"`
+	MediaType    string `json:"media_type,omitempty"`
+	// Размеры фото или видео, превью и подпись (заголовок ссылки, вопрос опроса)
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	Title        string `json:"title,omitempty"`
+}
+
+// Tag - тег. Normalized и ParentID заполняет сервер.
+type Tag struct {
+	TagID      int    `json:"tag_id,omitempty"`
+	Name       string `json:"name"`
+	Normalized string `json:"normalized,omitempty"`
+	ParentID   *int   `json:"parent_id,omitempty"`
+}
+
+type Comment struct {
+	CommentID       int    `json:"comment_id,omitempty"`
+	PostID          int    `json:"post_id"`
+	Nickname        string `json:"nickname"`
+	ParentCommentID *int   `json:"parent_comment_id,omitempty"`
+	Text            string `json:"text"`
+	LikesCount      int    `json:"likes_count"`
+	CreatedAt       string `json:"created_at,omitempty"`
+}
+
+// Health - ответ GET /health
+type Health struct {
+	Status string `json:"status"`
+	Time   string `json:"time"`
+}
@@ -0,0 +1,199 @@
+package apiclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Health проверяет доступность сервера
+func (c *Client) Health(ctx context.Context) (Health, error) {
+	var h Health
+	err := c.do(ctx, http.MethodGet, "/health", nil, &h)
+	return h, err
+}
+
+// Create создаёт ресурс через POST /api/v1/{resource}; ответ декодируется в out
+func (c *Client) Create(ctx context.Context, resource string, body, out interface{}) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/"+resource, body, out)
+}
+
+// List читает все записи ресурса через GET /api/v1/{resource}
+func (c *Client) List(ctx context.Context, resource string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, "/api/v1/"+resource, nil, out)
+}
+
+// Get читает одну запись через GET /api/v1/{resource}/{id}
+func (c *Client) Get(ctx context.Context, resource string, id int, out interface{}) error {
+	return c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/%s/%d", resource, id), nil, out)
+}
+
+// Update обновляет запись через PUT /api/v1/{resource}/{id}; передаются только заданные поля
+func (c *Client) Update(ctx context.Context, resource string, id int, body, out interface{}) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/api/v1/%s/%d", resource, id), body, out)
+}
+
+// ============ РЕСУРСЫ /api/v1 ============
+
+func (c *Client) CreateSource(ctx context.Context, s Source) (Source, error) {
+	var out Source
+	err := c.Create(ctx, "sources", s, &out)
+	return out, err
+}
+
+func (c *Client) ListSources(ctx context.Context) ([]Source, error) {
+	var out []Source
+	err := c.List(ctx, "sources", &out)
+	return out, err
+}
+
+func (c *Client) CreateChannel(ctx context.Context, ch Channel) (Channel, error) {
+	var out Channel
+	err := c.Create(ctx, "channels", ch, &out)
+	return out, err
+}
+
+func (c *Client) ListChannels(ctx context.Context) ([]Channel, error) {
+	var out []Channel
+	err := c.List(ctx, "channels", &out)
+	return out, err
+}
+
+func (c *Client) CreateAuthor(ctx context.Context, name string) (Author, error) {
+	return c.createAuthor(ctx, Author{Name: name})
+}
+
+func (c *Client) createAuthor(ctx context.Context, a Author) (Author, error) {
+	var out Author
+	err := c.Create(ctx, "authors", a, &out)
+	return out, err
+}
+
+// UpdateAuthor обновляет профиль автора (имя не меняется)
+func (c *Client) UpdateAuthor(ctx context.Context, a Author) error {
+	body := map[string]interface{}{}
+	if a.Platform != "" {
+		body["platform"] = a.Platform
+	}
+	if a.ExternalID != "" {
+		body["external_id"] = a.ExternalID
+	}
+	if a.ProfileURL != "" {
+		body["profile_url"] = a.ProfileURL
+	}
+	if a.PlatformReputation != nil {
+		body["platform_reputation"] = *a.PlatformReputation
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return c.Update(ctx, "authors", a.AuthorID, body, nil)
+}
+
+// GetAuthorProfile читает профиль автора с внутренней репутацией
+func (c *Client) GetAuthorProfile(ctx context.Context, id int) (AuthorProfile, error) {
+	var out AuthorProfile
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v1/authors/%d/profile", id), nil, &out)
+	return out, err
+}
+
+func (c *Client) ListAuthors(ctx context.Context) ([]Author, error) {
+	var out []Author
+	err := c.List(ctx, "authors", &out)
+	return out, err
+}
+
+// EnsureAuthor возвращает ID автора без профиля платформы, создавая его при необходимости
+func (c *Client) EnsureAuthor(ctx context.Context, name string) (int, error) {
+	return c.EnsureAuthorProfile(ctx, Author{Name: name})
+}
+
+// EnsureAuthorProfile возвращает ID автора, создавая его сразу с профилем платформы,
+// а у существующего автора обновляет профиль (репутация на платформе меняется со временем).
+// Автор с ExternalID ищется сервером по паре (Platform, ExternalID): одинаковые имена
+// на разных платформах - разные авторы; автор без ExternalID - по имени.
+func (c *Client) EnsureAuthorProfile(ctx context.Context, a Author) (int, error) {
+	var out Author
+	if err := c.do(ctx, http.MethodPost, "/api/v1/authors/ensure", a, &out); err != nil {
+		return 0, err
+	}
+	return out.AuthorID, nil
+}
+
+// CreatePost создаёт пост вместе с текстом и тегами
+func (c *Client) CreatePost(ctx context.Context, p Post) (Post, error) {
+	var out Post
+	err := c.Create(ctx, "posts", p, &out)
+	return out, err
+}
+
+func (c *Client) GetPost(ctx context.Context, id int) (Post, error) {
+	var out Post
+	err := c.Get(ctx, "posts", id, &out)
+	return out, err
+}
+
+// ListPosts возвращает посты с текстом, тегами, именами автора и канала (новые первыми)
+func (c *Client) ListPosts(ctx context.Context) ([]Post, error) {
+	var out []Post
+	err := c.List(ctx, "posts", &out)
+	return out, err
+}
+
+func (c *Client) CreateTag(ctx context.Context, name string) (Tag, error) {
+	var out Tag
+	err := c.Create(ctx, "tags", Tag{Name: name}, &out)
+	return out, err
+}
+
+// LinkPostTag привязывает существующий тег к посту; повторная привязка не ошибка
+func (c *Client) LinkPostTag(ctx context.Context, postID, tagID int) error {
+	path := fmt.Sprintf("/api/v1/posts/%d/tags", postID)
+	return c.do(ctx, http.MethodPost, path, map[string]int{"tag_id": tagID}, nil)
+}
+
+func (c *Client) CreateComment(ctx context.Context, cm Comment) (Comment, error) {
+	var out Comment
+	err := c.Create(ctx, "comments", cm, &out)
+	return out, err
+}
+
+func (c *Client) CreateMedia(ctx context.Context, m Media) (Media, error) {
+	var out Media
+	err := c.Create(ctx, "media", m, &out)
+	return out, err
+}
+
+// ============ VK ============
+// Маршруты /api/vk/* принимают поля в формате VK researcher-а
+// и создают посты без проверки дубликатов.
+
+func (c *Client) CreateVKSource(ctx context.Context, s Source) (Source, error) {
+	var out Source
+	err := c.do(ctx, http.MethodPost, "/api/vk/sources", s, &out)
+	return out, err
+}
+
+func (c *Client) CreateVKChannel(ctx context.Context, ch Channel) (Channel, error) {
+	var out Channel
+	err := c.do(ctx, http.MethodPost, "/api/vk/channels", ch, &out)
+	return out, err
+}
+
+func (c *Client) CreateVKPost(ctx context.Context, p VKPost) (Post, error) {
+	var out Post
+	err := c.do(ctx, http.MethodPost, "/api/vk/posts", p, &out)
+	return out, err
+}
+
+func (c *Client) CreateVKMedia(ctx context.Context, m Media) (Media, error) {
+	var out Media
+	err := c.do(ctx, http.MethodPost, "/api/vk/media", m, &out)
+	return out, err
+}
+
+func (c *Client) CreateVKComment(ctx context.Context, cm Comment) (Comment, error) {
+	var out Comment
+	err := c.do(ctx, http.MethodPost, "/api/vk/comments", cm, &out)
+	return out, err
+}
@@ -2,16 +2,13 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"apiclient"
+	"context"
 	"fmt"
-	"io"
 	"log"
 	"math/rand"
-	"net/http"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -53,6 +50,8 @@ var (
 	sourceIDs []int
 	tagIDs    []int
 	mediaContent string = "https://example.com/media/image.jpg" // исправлено: убрали неиспользуемую переменную
+	api       *apiclient.Client
+	ctx       = context.Background()
 )
 
 func init() {
@@ -71,6 +70,8 @@ func init() {
 		LogLevel:         getEnv("LOG_LEVEL", "info"),
 	}
 
+	api = apiclient.New(config.APIURL).WithRetries(3, time.Second)
+
 	stats.StartTime = time.Now()
 }
 
@@ -163,18 +164,16 @@ func createSources(count int) {
 		sourceCounter++
 		sourceName := fmt.Sprintf("Источник %d", sourceCounter)
 
-		data := map[string]interface{}{
-			"name":    sourceName,
-			"address": fmt.Sprintf("https://source%d.example.com", sourceCounter),
-			"topic":   topics[rand.Intn(len(topics))],
-		}
-
-		id, err := sendRequest("/api/sources", data, "source_id")
+		source, err := api.CreateSource(ctx, apiclient.Source{
+			Name:    sourceName,
+			Address: fmt.Sprintf("https://source%d.example.com", sourceCounter),
+			Topic:   topics[rand.Intn(len(topics))],
+		})
 		if err != nil {
 			logger.Printf("Ошибка создания источника: %v", err)
 			stats.Errors++
-		} else if id > 0 {
-			sourceIDs = append(sourceIDs, id)
+		} else if source.SourceID > 0 {
+			sourceIDs = append(sourceIDs, source.SourceID)
 			stats.SourcesCreated++
 		}
 		time.Sleep(100 * time.Millisecond)
@@ -188,16 +187,12 @@ func createAuthors(count int) {
 		authorCounter++
 		authorName := fmt.Sprintf("Автор %d", authorCounter)
 
-		data := map[string]interface{}{
-			"name": authorName,
-		}
-
-		id, err := sendRequest("/api/authors", data, "author_id")
+		author, err := api.CreateAuthor(ctx, authorName)
 		if err != nil {
 			logger.Printf("Ошибка создания автора: %v", err)
 			stats.Errors++
-		} else if id > 0 {
-			authorIDs = append(authorIDs, id)
+		} else if author.AuthorID > 0 {
+			authorIDs = append(authorIDs, author.AuthorID)
 			stats.AuthorsCreated++
 		}
 		time.Sleep(100 * time.Millisecond)
@@ -218,20 +213,18 @@ func createChannels(count int) {
 		subscribers := rand.Intn(100000) + 1000
 		topic := topics[rand.Intn(len(topics))]
 
-		data := map[string]interface{}{
-			"name":               channelName,
-			"link":               fmt.Sprintf("https://channel-%d.example.com", i+1),
-			"subscribers_count":  subscribers,
-			"source_id":          sourceIDs[rand.Intn(len(sourceIDs))],
-			"topic":              topic,
-		}
-
-		id, err := sendRequest("/api/channels", data, "channel_id")
+		channel, err := api.CreateChannel(ctx, apiclient.Channel{
+			Name:             channelName,
+			Link:             fmt.Sprintf("https://channel-%d.example.com", i+1),
+			SubscribersCount: subscribers,
+			SourceID:         sourceIDs[rand.Intn(len(sourceIDs))],
+			Topic:            topic,
+		})
 		if err != nil {
 			logger.Printf("Ошибка создания канала: %v", err)
 			stats.Errors++
-		} else if id > 0 {
-			channelIDs = append(channelIDs, id)
+		} else if channel.ChannelID > 0 {
+			channelIDs = append(channelIDs, channel.ChannelID)
 			stats.ChannelsCreated++
 		}
 		time.Sleep(100 * time.Millisecond)
@@ -248,44 +241,25 @@ func createPosts(count int) {
 	for i := 0; i < count; i++ {
 		postCounter++
 
-		// Создаем текст поста
-		textData := map[string]interface{}{
-			"text": generatePostContent(),
-		}
-
-		textID, err := sendRequest("/api/news_texts", textData, "text_id")
-		if err != nil {
-			logger.Printf("Ошибка создания текста: %v", err)
-			stats.Errors++
-			continue
-		}
-
-		if textID == 0 {
-			logger.Printf("Не удалось получить text_id")
-			continue
-		}
-
-		// Создаем сам пост
-		postData := map[string]interface{}{
-			"title":          generatePostTitle(),
-			"author_id":      authorIDs[rand.Intn(len(authorIDs))],
-			"text_id":        textID,
-			"channel_id":     channelIDs[rand.Intn(len(channelIDs))],
-			"comments_count": rand.Intn(50),
-			"likes_count":    rand.Intn(200),
-			"created_at":     time.Now().Add(-time.Duration(rand.Intn(86400)) * time.Second).Format(time.RFC3339),
-		}
-
-		postID, err := sendRequest("/api/posts", postData, "post_id")
+		// Создаем пост (текст сервер сохраняет в news_texts сам)
+		post, err := api.CreatePost(ctx, apiclient.Post{
+			Title:         generatePostTitle(),
+			Content:       generatePostContent(),
+			AuthorID:      authorIDs[rand.Intn(len(authorIDs))],
+			ChannelID:     channelIDs[rand.Intn(len(channelIDs))],
+			CommentsCount: rand.Intn(50),
+			LikesCount:    rand.Intn(200),
+			CreatedAt:     time.Now().Add(-time.Duration(rand.Intn(86400)) * time.Second).Format(time.RFC3339),
+		})
 		if err != nil {
 			logger.Printf("Ошибка создания поста: %v", err)
 			stats.Errors++
-		} else if postID > 0 {
+		} else if post.PostID > 0 {
 			stats.PostsCreated++
 
 			// Добавляем теги к посту (если есть теги)
 			if len(tagIDs) > 0 {
-				addTagsToPost(postID)
+				addTagsToPost(post.PostID)
 			}
 		}
 		time.Sleep(100 * time.Millisecond)
@@ -303,15 +277,10 @@ func addTagsToPost(postID int) {
 	for i := 0; i < numTags && i < len(tagIDs); i++ {
 		tagID := tagIDs[rand.Intn(len(tagIDs))]
 
-		data := map[string]interface{}{
-			"post_id": postID,
-			"tag_id":  tagID,
-		}
-
-		_, err := sendRequest("/api/post_tags", data, "")
+		err := api.LinkPostTag(ctx, postID, tagID)
 		if err != nil {
 			// Игнорируем ошибку дублирования (тег уже добавлен)
-			if !strings.Contains(err.Error(), "duplicate") && !strings.Contains(err.Error(), "уже существует") {
+			if !apiclient.IsConflict(err) {
 				logger.Printf("Ошибка добавления тега к посту: %v", err)
 			}
 		}
@@ -326,19 +295,15 @@ func createTags(count int) {
 		tagCounter++
 		tagName := fmt.Sprintf("Тег %d", tagCounter)
 
-		data := map[string]interface{}{
-			"name": tagName,
-		}
-
-		id, err := sendRequest("/api/tags", data, "tag_id")
+		tag, err := api.CreateTag(ctx, tagName)
 		if err != nil {
 			// Тег может уже существовать, это нормально
-			if !strings.Contains(err.Error(), "duplicate") && !strings.Contains(err.Error(), "уже существует") {
+			if !apiclient.IsConflict(err) {
 				logger.Printf("Ошибка создания тега: %v", err)
 				stats.Errors++
 			}
-		} else if id > 0 {
-			tagIDs = append(tagIDs, id)
+		} else if tag.TagID > 0 {
+			tagIDs = append(tagIDs, tag.TagID)
 			stats.TagsCreated++
 		}
 		time.Sleep(50 * time.Millisecond)
@@ -355,20 +320,7 @@ func createComments(count int) {
 	}
 
 	for i := 0; i < count && i < len(posts); i++ {
-		post := posts[i].(map[string]interface{})
-		postID := 0
-		
-		// Извлекаем post_id из разных возможных форматов
-		if id, ok := post["post_id"].(float64); ok {
-			postID = int(id)
-		} else if id, ok := post["post_id"].(int); ok {
-			postID = id
-		} else if idStr, ok := post["post_id"].(string); ok {
-			if id, err := strconv.Atoi(idStr); err == nil {
-				postID = id
-			}
-		}
-		
+		postID := posts[i].PostID
 		if postID == 0 {
 			continue
 		}
@@ -384,15 +336,13 @@ func createComments(count int) {
 // Генерация комментариев для конкретного поста
 func createCommentsForPost(postID, count int, commentText string) {
 	for i := 0; i < count; i++ {
-		data := map[string]interface{}{
-			"post_id":     postID,
-			"nickname":    gofakeit.Username(),
-			"text":        commentText,
-			"likes_count": rand.Intn(50),
-			"created_at":  time.Now().Add(-time.Duration(rand.Intn(86400)) * time.Second).Format(time.RFC3339),
-		}
-
-		_, err := sendRequest("/api/comments", data, "comment_id")
+		_, err := api.CreateComment(ctx, apiclient.Comment{
+			PostID:     postID,
+			Nickname:   gofakeit.Username(),
+			Text:       commentText,
+			LikesCount: rand.Intn(50),
+			CreatedAt:  time.Now().Add(-time.Duration(rand.Intn(86400)) * time.Second).Format(time.RFC3339),
+		})
 		if err != nil {
 			logger.Printf("Ошибка создания комментария: %v", err)
 			stats.Errors++
@@ -415,16 +365,7 @@ func createMedia(count int) {
 	mediaTypes := []string{"image", "video", "audio"}
 
 	for i := 0; i < count && i < len(posts); i++ {
-		post := posts[i].(map[string]interface{})
-		postID := 0
-		
-		// Извлекаем post_id из разных возможных форматов
-		if id, ok := post["post_id"].(float64); ok {
-			postID = int(id)
-		} else if id, ok := post["post_id"].(int); ok {
-			postID = id
-		}
-		
+		postID := posts[i].PostID
 		if postID == 0 {
 			continue
 		}
@@ -434,13 +375,11 @@ func createMedia(count int) {
 		// Генерация уникального медиа
 		mediaCounter++
 
-		data := map[string]interface{}{
-			"post_id":       postID,
-			"media_content": generateMediaURL(mediaType),
-			"media_type":    mediaType,
-		}
-
-		_, err := sendRequest("/api/media", data, "media_id")
+		_, err := api.CreateMedia(ctx, apiclient.Media{
+			PostID:       postID,
+			MediaContent: generateMediaURL(mediaType),
+			MediaType:    mediaType,
+		})
 		if err != nil {
 			logger.Printf("Ошибка создания медиа: %v", err)
 			stats.Errors++
@@ -453,106 +392,16 @@ func createMedia(count int) {
 
 // ============ ВСПОМОГАТЕЛЬНЫЕ ФУНКЦИИ ============
 
-func sendRequest(endpoint string, data map[string]interface{}, idField string) (int, error) {
-	url := config.APIURL + endpoint
-
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return 0, fmt.Errorf("ошибка маршалинга JSON: %v", err)
-	}
-
-	// Отправляем запрос с ретраями
-	for retry := 0; retry < 3; retry++ {
-		if retry > 0 {
-			time.Sleep(time.Duration(retry) * time.Second)
-		}
-
-		resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-		if err != nil {
-			if retry == 2 {
-				return 0, fmt.Errorf("HTTP ошибка: %v", err)
-			}
-			continue
-		}
-		defer resp.Body.Close()
-
-		body, err := decodeResponse(resp)
-		if err != nil {
-			if retry == 2 {
-				return 0, err
-			}
-			continue
-		}
-
-		// Извлекаем ID
-		if idValue, ok := body[idField]; ok {
-			switch v := idValue.(type) {
-			case float64:
-				return int(v), nil
-			case int:
-				return v, nil
-			case string:
-				if id, err := strconv.Atoi(v); err == nil {
-					return id, nil
-				}
-			}
-		}
-
-		return 0, nil // Успешно, но без ID
-	}
-
-	return 0, fmt.Errorf("максимальное количество попыток")
-}
-
-func decodeResponse(resp *http.Response) (map[string]interface{}, error) {
-	// Читаем тело ответа
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения ответа: %v", err)
-	}
-
-	// Логируем ответ для отладки
-	if config.LogLevel == "debug" {
-		logger.Printf("Ответ от сервера [%d]: %s", resp.StatusCode, string(bodyBytes[:min(200, len(bodyBytes))]))
-	}
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("сервер вернул %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		// Пробуем разобрать как массив
-		var arrResult []interface{}
-		if err := json.Unmarshal(bodyBytes, &arrResult); err == nil {
-			return map[string]interface{}{"data": arrResult}, nil
-		}
-		return nil, fmt.Errorf("ошибка парсинга JSON: %v, тело: %s", err, string(bodyBytes))
-	}
-
-	return result, nil
-}
-
-func getRecentPosts(limit int) []interface{} {
-	url := fmt.Sprintf("%s/api/posts?limit=%d", config.APIURL, limit)
-	resp, err := http.Get(url)
+// getRecentPosts возвращает последние посты (сервер сортирует по created_at DESC)
+func getRecentPosts(limit int) []apiclient.Post {
+	posts, err := api.ListPosts(ctx)
 	if err != nil {
 		logger.Printf("Ошибка получения постов: %v", err)
 		return nil
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		logger.Printf("Не удалось получить посты: статус %d", resp.StatusCode)
-		return nil
+	if len(posts) > limit {
+		posts = posts[:limit]
 	}
-
-	var posts []interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&posts); err != nil {
-		logger.Printf("Ошибка парсинга постов: %v", err)
-		return nil
-	}
-
 	return posts
 }
 
@@ -599,65 +448,34 @@ func generateMediaURL(mediaType string) string {
 }
 
 func checkServerHealth() bool {
-	url := config.APIURL + "/health"
-	resp, err := http.Get(url)
-	if err != nil {
+	if _, err := api.Health(ctx); err != nil {
 		logger.Printf("Ошибка проверки здоровья сервера: %v", err)
 		return false
 	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == http.StatusOK
+	return true
 }
 
 func loadExistingData() {
 	logger.Printf("Загрузка существующих данных...")
 
 	// Загружаем авторов
-	resp, err := http.Get(config.APIURL + "/api/authors")
-	if err == nil {
-		defer resp.Body.Close()
-		if resp.StatusCode == http.StatusOK {
-			var authors []map[string]interface{}
-			if json.NewDecoder(resp.Body).Decode(&authors) == nil {
-				for _, author := range authors {
-					if id, ok := author["author_id"].(float64); ok {
-						authorIDs = append(authorIDs, int(id))
-					}
-				}
-			}
+	if authors, err := api.ListAuthors(ctx); err == nil {
+		for _, author := range authors {
+			authorIDs = append(authorIDs, author.AuthorID)
 		}
 	}
 
 	// Загружаем каналы
-	resp, err = http.Get(config.APIURL + "/api/channels")
-	if err == nil {
-		defer resp.Body.Close()
-		if resp.StatusCode == http.StatusOK {
-			var channels []map[string]interface{}
-			if json.NewDecoder(resp.Body).Decode(&channels) == nil {
-				for _, channel := range channels {
-					if id, ok := channel["channel_id"].(float64); ok {
-						channelIDs = append(channelIDs, int(id))
-					}
-				}
-			}
+	if channels, err := api.ListChannels(ctx); err == nil {
+		for _, channel := range channels {
+			channelIDs = append(channelIDs, channel.ChannelID)
 		}
 	}
 
 	// Загружаем источники
-	resp, err = http.Get(config.APIURL + "/api/sources")
-	if err == nil {
-		defer resp.Body.Close()
-		if resp.StatusCode == http.StatusOK {
-			var sources []map[string]interface{}
-			if json.NewDecoder(resp.Body).Decode(&sources) == nil {
-				for _, source := range sources {
-					if id, ok := source["source_id"].(float64); ok {
-						sourceIDs = append(sourceIDs, int(id))
-					}
-				}
-			}
+	if sources, err := api.ListSources(ctx); err == nil {
+		for _, source := range sources {
+			sourceIDs = append(sourceIDs, source.SourceID)
 		}
 	}
 
@@ -692,25 +510,15 @@ func showFinalStats() {
 	// Показываем общую статистику базы
 	logger.Printf("\n📈 ОБЩАЯ СТАТИСТИКА БАЗЫ:")
 
-	endpoints := []string{
-		"/api/sources", "/api/authors", "/api/channels",
-		"/api/posts", "/api/tags", "/api/comments", "/api/media",
+	tables := []string{
+		"sources", "authors", "channels",
+		"posts", "tags", "comments", "media",
 	}
 
-	for _, endpoint := range endpoints {
-		url := config.APIURL + endpoint
-		resp, err := http.Get(url)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			var data []interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&data); err == nil {
-				tableName := strings.TrimPrefix(endpoint, "/api/")
-				logger.Printf("   %s: %d записей", tableName, len(data))
-			}
+	for _, tableName := range tables {
+		var data []interface{}
+		if err := api.List(ctx, tableName, &data); err == nil {
+			logger.Printf("   %s: %d записей", tableName, len(data))
 		}
 	}
 }
@@ -739,11 +547,3 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
-
-// Вспомогательная функция для min (для Go версий до 1.21)
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
\ No newline at end of file
@@ -1,5 +1,10 @@
 module data-generator
 
-go 1.18
+go 1.21
 
-require github.com/brianvoe/gofakeit/v6 v6.28.0
+require (
+	apiclient v0.0.0-00010101000000-000000000000
+	github.com/brianvoe/gofakeit/v6 v6.28.0
+)
+
+replace apiclient => ../apiclient
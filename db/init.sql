@@ -1,4 +1,9 @@
 -- init.sql для PostgreSQL 
+--
+-- Скрипт повторяемый: базу, созданную прежней версией, он дополняет до текущей
+-- схемы (psql -f db/init.sql). Колонки, добавленные в существующие таблицы,
+-- продублированы в ALTER TABLE ... ADD COLUMN IF NOT EXISTS под таблицей:
+-- CREATE TABLE IF NOT EXISTS их в старую таблицу не добавит.
 
 -- Пользователи агрегатора
 CREATE TABLE IF NOT EXISTS users (
@@ -12,9 +17,31 @@ CREATE TABLE IF NOT EXISTS users (
 CREATE TABLE IF NOT EXISTS authors (
     author_id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
-    UNIQUE(name)
+    -- Профиль на платформе: vk, reddit и т.п.
+    platform VARCHAR(50),
+    external_id VARCHAR(255),
+    profile_url VARCHAR(500),
+    -- Репутация на платформе: карма Reddit, подписчики VK
+    platform_reputation BIGINT CHECK (platform_reputation >= 0),
+    updated_at TIMESTAMP DEFAULT NOW()
 );
 
+ALTER TABLE authors
+    ADD COLUMN IF NOT EXISTS platform VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS external_id VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS profile_url VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS platform_reputation BIGINT CHECK (platform_reputation >= 0),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
+
+-- Автор платформы определяется профилем (platform, external_id): одно имя на разных
+-- платформах - разные авторы. Имя уникально только у авторов без профиля.
+ALTER TABLE authors DROP CONSTRAINT IF EXISTS authors_name_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_authors_platform_external_id
+    ON authors(platform, external_id) WHERE external_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_authors_name
+    ON authors(name) WHERE external_id IS NULL;
+
 -- Тексты новостей
 CREATE TABLE IF NOT EXISTS news_texts (
     text_id SERIAL PRIMARY KEY,
@@ -48,23 +75,78 @@ CREATE TABLE IF NOT EXISTS posts (
     channel_id INT REFERENCES channels(channel_id) ON DELETE SET NULL,
     comments_count INT DEFAULT 0,
     likes_count INT DEFAULT 0,
-    created_at TIMESTAMP DEFAULT NOW()
+    created_at TIMESTAMP DEFAULT NOW(),
+    -- Модерация: в ленте и поиске только approved
+    post_status VARCHAR(20) NOT NULL DEFAULT 'approved'
+        CHECK (post_status IN ('pending', 'approved', 'rejected', 'quarantined')),
+    moderated_by VARCHAR(255),
+    moderated_at TIMESTAMP,
+    moderation_note VARCHAR(500),
+    -- Язык текста (ISO 639-1, 'und' - не определён), см. internal/langdetect
+    language VARCHAR(8),
+    -- Платформа пометила пост как 18+ (Reddit over_18)
+    nsfw BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Поля платформы без своих колонок: flair, upvote_ratio, spoiler, permalink, domain, awards
+    metadata JSONB NOT NULL DEFAULT '{}'
 );
 
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS post_status VARCHAR(20) NOT NULL DEFAULT 'approved'
+        CHECK (post_status IN ('pending', 'approved', 'rejected', 'quarantined')),
+    ADD COLUMN IF NOT EXISTS moderated_by VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS moderated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS moderation_note VARCHAR(500),
+    ADD COLUMN IF NOT EXISTS language VARCHAR(8),
+    ADD COLUMN IF NOT EXISTS nsfw BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
 -- Медиа (фото, видео и т.п.)
 CREATE TABLE IF NOT EXISTS media (
     media_id SERIAL PRIMARY KEY,
     post_id INT REFERENCES posts(post_id) ON DELETE CASCADE,
     media_content VARCHAR(1000),
-    media_type VARCHAR(50) DEFAULT 'image'
+    media_type VARCHAR(50) DEFAULT 'image',
+    -- Размеры фото или видео, если платформа их сообщает
+    width INT CHECK (width >= 0),
+    height INT CHECK (height >= 0),
+    -- Превью видео, ссылки или документа
+    thumbnail_url VARCHAR(1000),
+    -- Заголовок видео или ссылки, вопрос опроса, имя файла
+    title VARCHAR(500)
 );
 
+ALTER TABLE media
+    ADD COLUMN IF NOT EXISTS width INT CHECK (width >= 0),
+    ADD COLUMN IF NOT EXISTS height INT CHECK (height >= 0),
+    ADD COLUMN IF NOT EXISTS thumbnail_url VARCHAR(1000),
+    ADD COLUMN IF NOT EXISTS title VARCHAR(500);
+
 -- Теги
 CREATE TABLE IF NOT EXISTS tags (
     tag_id SERIAL PRIMARY KEY,
-    name VARCHAR(100) NOT NULL UNIQUE
+    name VARCHAR(100) NOT NULL UNIQUE,
+    -- Ключ нормализации (tagnorm.Key): варианты одного тега совпадают по нему
+    normalized VARCHAR(100),
+    -- Необязательная иерархия: "выборы" входят в "политику"
+    parent_id INT REFERENCES tags(tag_id) ON DELETE SET NULL
+);
+
+ALTER TABLE tags
+    ADD COLUMN IF NOT EXISTS normalized VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES tags(tag_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tags_normalized ON tags(normalized);
+CREATE INDEX IF NOT EXISTS idx_tags_parent_id ON tags(parent_id);
+
+-- Синонимы: ключ нормализации варианта ("new" для "news") -> канонический тег
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    alias VARCHAR(100) PRIMARY KEY,
+    tag_id INT NOT NULL REFERENCES tags(tag_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW()
 );
 
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag_id ON tag_aliases(tag_id);
+
 -- Связка постов и тегов
 CREATE TABLE IF NOT EXISTS post_tags (
     post_id INT REFERENCES posts(post_id) ON DELETE CASCADE,
@@ -72,6 +154,19 @@ CREATE TABLE IF NOT EXISTS post_tags (
     PRIMARY KEY (post_id, tag_id)
 );
 
+-- Ключевые фразы и именованные сущности поста (internal/keywords),
+-- дополняют теги для постов без хэштегов
+CREATE TABLE IF NOT EXISTS post_keywords (
+    post_id INT NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    term VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL
+        CHECK (kind IN ('keyword', 'person', 'organization', 'location')),
+    score REAL NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, kind, term)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_keywords_term ON post_keywords(term, kind);
+
 -- Комментарии
 CREATE TABLE IF NOT EXISTS comments (
     comment_id SERIAL PRIMARY KEY,
@@ -82,3 +177,139 @@ CREATE TABLE IF NOT EXISTS comments (
     created_at TIMESTAMP DEFAULT NOW(),
     likes_count INT DEFAULT 0
 );
+
+-- Индексы для построения дерева комментариев
+CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments(post_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_comment_id ON comments(parent_comment_id);
+
+-- Очередь модерации
+CREATE INDEX IF NOT EXISTS idx_posts_status ON posts(post_status, created_at);
+
+-- Фильтр ленты по языку (lang=)
+CREATE INDEX IF NOT EXISTS idx_posts_language ON posts(language, created_at);
+
+-- Репутация одного автора (функция author_reputation_stats): его посты и средние по их каналам
+CREATE INDEX IF NOT EXISTS idx_posts_author_id ON posts(author_id);
+CREATE INDEX IF NOT EXISTS idx_posts_channel_id ON posts(channel_id);
+
+-- Правила фильтрации контента (редактируются через /api/admin/filters)
+CREATE TABLE IF NOT EXISTS filter_rules (
+    rule_id SERIAL PRIMARY KEY,
+    kind VARCHAR(30) NOT NULL CHECK (kind IN ('keyword', 'regex', 'link_density', 'ad_marker', 'min_reputation', 'nsfw')),
+    topic VARCHAR(255),
+    pattern TEXT NOT NULL DEFAULT '',
+    threshold DOUBLE PRECISION,
+    action VARCHAR(20) NOT NULL DEFAULT 'quarantine' CHECK (action IN ('quarantine', 'reject')),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Список видов правил расширялся: в старой базе CHECK заменяется текущим
+ALTER TABLE filter_rules DROP CONSTRAINT IF EXISTS filter_rules_kind_check;
+ALTER TABLE filter_rules ADD CONSTRAINT filter_rules_kind_check
+    CHECK (kind IN ('keyword', 'regex', 'link_density', 'ad_marker', 'min_reputation', 'nsfw'));
+
+-- Решения фильтра по входящим постам (для отклонённых post_id пустой)
+CREATE TABLE IF NOT EXISTS post_filter_decisions (
+    decision_id SERIAL PRIMARY KEY,
+    post_id INT REFERENCES posts(post_id) ON DELETE SET NULL,
+    channel_id INT REFERENCES channels(channel_id) ON DELETE SET NULL,
+    author_id INT REFERENCES authors(author_id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('accept', 'quarantine', 'reject')),
+    reasons JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_filter_decisions_post_id ON post_filter_decisions(post_id);
+CREATE INDEX IF NOT EXISTS idx_post_filter_decisions_decision ON post_filter_decisions(decision, created_at);
+CREATE INDEX IF NOT EXISTS idx_post_filter_decisions_author_id ON post_filter_decisions(author_id);
+
+-- Базовые правила: явные рекламные метки отправляются в карантин, посты 18+ отклоняются.
+-- Каждое добавляется, если правил этого вида ещё нет: в базе с правилом одного
+-- вида появится и правило, добавленное позже.
+INSERT INTO filter_rules (kind, action, description)
+SELECT kind, action, description FROM (VALUES
+    ('ad_marker', 'quarantine', 'Рекламные метки и партнёрские ссылки'),
+    ('nsfw', 'reject', 'Посты 18+ по пометке платформы')
+) AS defaults (kind, action, description)
+WHERE NOT EXISTS (SELECT 1 FROM filter_rules fr WHERE fr.kind = defaults.kind);
+
+-- Категории постов и словари ключевых слов классификатора (редактируются через /api/admin/categories).
+-- "*" на конце ключевого слова - префикс: "полити*" совпадает с "политика", "политический".
+CREATE TABLE IF NOT EXISTS categories (
+    category_id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    keywords TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Категории поста: назначенные классификатором или редактором (editor - обучающая выборка)
+CREATE TABLE IF NOT EXISTS post_categories (
+    post_id INT REFERENCES posts(post_id) ON DELETE CASCADE,
+    category_id INT REFERENCES categories(category_id) ON DELETE CASCADE,
+    confidence REAL NOT NULL DEFAULT 1 CHECK (confidence BETWEEN 0 AND 1),
+    method VARCHAR(20) NOT NULL CHECK (method IN ('keywords', 'bayes', 'keywords+bayes', 'editor')),
+    assigned_by VARCHAR(255),
+    assigned_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (post_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_categories_category_id ON post_categories(category_id);
+CREATE INDEX IF NOT EXISTS idx_post_categories_editor ON post_categories(post_id) WHERE method = 'editor';
+
+-- Категории из ТЗ
+INSERT INTO categories (name, description, keywords) VALUES
+    ('Politics', 'Политика', ARRAY['полити*', 'выбор*', 'президент*', 'правительств*', 'парламент*', 'госдум*',
+        'депутат*', 'министр*', 'санкци*', 'закон*', 'партия', 'партии', 'оппозици*', 'дипломат*', 'саммит*',
+        'politic*', 'election*', 'president*', 'government*', 'parliament*', 'senat*', 'congress*', 'minister*',
+        'sanction*', 'diplomat*', 'vote', 'voting']),
+    ('Technology', 'Технологии', ARRAY['технолог*', 'программ*', 'смартфон*', 'компьютер*', 'процессор*',
+        'нейросет*', 'искусственный интеллект', 'ии', 'робот*', 'стартап*', 'гаджет*', 'интернет*', 'софт*',
+        'разработ*', 'алгоритм*', 'tech*', 'software', 'hardware', 'ai', 'gpu*', 'cpu*', 'smartphone*', 'iphone*',
+        'android', 'linux', 'startup*', 'programming', 'developer*', 'robot*', 'machine learning']),
+    ('Humor', 'Юмор', ARRAY['юмор*', 'шутк*', 'анекдот*', 'смешн*', 'прикол*', 'мем', 'мемы', 'мемас*', 'ржак*',
+        'угар*', 'комик*', 'humor', 'humour', 'funny', 'joke*', 'meme*', 'lol', 'comedy', 'comedian*']),
+    ('Games', 'Игры', ARRAY['игр*', 'геймер*', 'геймпле*', 'консол*', 'киберспорт*', 'стрим*', 'steam',
+        'playstation', 'xbox', 'nintendo', 'dlc', 'game*', 'gaming', 'gamer*', 'esport*', 'rpg', 'mmo*',
+        'speedrun*', 'twitch']),
+    ('History', 'История', ARRAY['истори*', 'историческ*', 'век', 'века', 'веке', 'древн*', 'средневеков*',
+        'импери*', 'войн*', 'археолог*', 'летопис*', 'царь', 'царя', 'династи*', 'революци*', 'ссср',
+        'history', 'historical', 'ancient', 'medieval', 'century', 'empire*', 'archaeolog*', 'dynasty',
+        'revolution*', 'ww2', 'wwii'])
+ON CONFLICT (name) DO NOTHING;
+
+-- Сюжеты: посты об одной новости, в том числе перепосты с других платформ
+CREATE TABLE IF NOT EXISTS stories (
+    story_id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    first_post_id INT REFERENCES posts(post_id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Отпечатки постов для поиска дубликатов: SHA-256 нормализованного текста,
+-- 64-битный SimHash и его 16-битные полосы для LSH-поиска близких отпечатков
+CREATE TABLE IF NOT EXISTS post_fingerprints (
+    post_id INT PRIMARY KEY REFERENCES posts(post_id) ON DELETE CASCADE,
+    content_hash CHAR(64) NOT NULL,
+    simhash BIGINT NOT NULL,
+    band0 INT NOT NULL,
+    band1 INT NOT NULL,
+    band2 INT NOT NULL,
+    band3 INT NOT NULL,
+    words INT NOT NULL DEFAULT 0,
+    story_id INT NOT NULL REFERENCES stories(story_id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_fingerprints_content_hash ON post_fingerprints(content_hash);
+CREATE INDEX IF NOT EXISTS idx_post_fingerprints_band0 ON post_fingerprints(band0);
+CREATE INDEX IF NOT EXISTS idx_post_fingerprints_band1 ON post_fingerprints(band1);
+CREATE INDEX IF NOT EXISTS idx_post_fingerprints_band2 ON post_fingerprints(band2);
+CREATE INDEX IF NOT EXISTS idx_post_fingerprints_band3 ON post_fingerprints(band3);
+CREATE INDEX IF NOT EXISTS idx_post_fingerprints_story_id ON post_fingerprints(story_id);
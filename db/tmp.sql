@@ -1,3 +1,33 @@
+-- Представления пересоздаются целиком: скрипт можно выполнить повторно
+-- на существующей базе после db/init.sql (psql -f db/tmp.sql).
+-- Представления публичные (/api/v1/analytics, /api/{table}): посты в них только
+-- одобренные (post_status = 'approved'), кроме author_reputation - входа фильтров.
+DROP VIEW IF EXISTS
+    story_stats,
+    author_reputation,
+    extended_post_analytics,
+    comprehensive_post_info,
+    media_with_context,
+    posts_with_tags_and_channels,
+    comments_with_post_info,
+    posts_with_authors_and_texts,
+    channels_with_sources,
+    posts_with_detailed_authors,
+    commenter_analysis,
+    tag_rank_by_channel,
+    cumulative_posts_analysis,
+    author_likes_trend,
+    posts_ranked_by_popularity,
+    post_discussion_stats,
+    user_comment_activity,
+    source_post_stats,
+    tag_popularity_detailed,
+    author_performance,
+    channel_activity_stats
+CASCADE;
+DROP FUNCTION IF EXISTS discussion_stats(INT);
+DROP FUNCTION IF EXISTS author_reputation_stats(INT);
+
 -- ==========================
 -- АГРЕГИРУЮЩИЕ ЗАПРОСЫ
 -- ==========================
@@ -11,7 +41,7 @@ SELECT
     COUNT(DISTINCT cm.comment_id) AS total_comments,
     COALESCE(AVG(p.likes_count), 0) AS avg_likes_per_post
 FROM channels c
-LEFT JOIN posts p ON c.channel_id = p.channel_id
+LEFT JOIN posts p ON c.channel_id = p.channel_id AND p.post_status = 'approved'
 LEFT JOIN comments cm ON p.post_id = cm.post_id
 GROUP BY c.channel_id, c.name;
 
@@ -25,7 +55,7 @@ SELECT
     SUM(p.comments_count) AS total_comments,
     COALESCE(AVG(p.likes_count), 0) AS avg_likes_per_post
 FROM authors a
-LEFT JOIN posts p ON a.author_id = p.author_id
+LEFT JOIN posts p ON a.author_id = p.author_id AND p.post_status = 'approved'
 GROUP BY a.author_id, a.name;
 
 -- 3. Популярность тегов с дополнительной статистикой
@@ -38,7 +68,7 @@ SELECT
     COALESCE(AVG(p.likes_count), 0) AS avg_likes
 FROM tags t
 LEFT JOIN post_tags pt ON t.tag_id = pt.tag_id
-LEFT JOIN posts p ON pt.post_id = p.post_id
+LEFT JOIN posts p ON pt.post_id = p.post_id AND p.post_status = 'approved'
 GROUP BY t.tag_id, t.name;
 
 -- 4. Статистика постов по источникам
@@ -52,7 +82,7 @@ SELECT
     SUM(p.comments_count) AS total_comments
 FROM sources s
 LEFT JOIN channels c ON s.source_id = c.source_id
-LEFT JOIN posts p ON c.channel_id = p.channel_id
+LEFT JOIN posts p ON c.channel_id = p.channel_id AND p.post_status = 'approved'
 GROUP BY s.source_id, s.name;
 
 -- 5. Активность пользователей по комментариям
@@ -66,6 +96,74 @@ SELECT
 FROM comments
 GROUP BY nickname;
 
+-- ==========================
+-- АКТИВНОСТЬ ОБСУЖДЕНИЙ
+-- ==========================
+
+-- 1. Статистика обсуждения одного поста: общие формулы представления
+--    post_discussion_stats и GET /api/posts/{id}/discussion-stats
+CREATE FUNCTION discussion_stats(p_post_id INT)
+RETURNS TABLE (
+    post_id INT,
+    post_created_at TIMESTAMP,
+    comments_total BIGINT,
+    unique_commenters BIGINT,
+    replies_total BIGINT,
+    max_depth INT,
+    reply_ratio NUMERIC,
+    comments_per_hour NUMERIC,
+    time_to_first_comment_seconds BIGINT,
+    first_comment_at TIMESTAMP,
+    last_comment_at TIMESTAMP
+)
+LANGUAGE sql STABLE AS $$
+    -- post_id стоит в якоре рекурсии: обходятся только комментарии этого поста
+    WITH RECURSIVE thread AS (
+        SELECT c.comment_id, 1 AS depth
+        FROM comments c
+        WHERE c.post_id = p_post_id AND c.parent_comment_id IS NULL
+        UNION ALL
+        SELECT c.comment_id, t.depth + 1
+        FROM comments c
+        JOIN thread t ON c.parent_comment_id = t.comment_id
+    ),
+    cs AS (
+        SELECT
+            COUNT(*) AS comments_total,
+            COUNT(DISTINCT c.nickname) AS unique_commenters,
+            COUNT(*) FILTER (WHERE c.parent_comment_id IS NOT NULL) AS replies_total,
+            MIN(c.created_at) AS first_comment_at,
+            MAX(c.created_at) AS last_comment_at
+        FROM comments c
+        WHERE c.post_id = p_post_id
+    )
+    SELECT
+        p.post_id,
+        p.created_at,
+        cs.comments_total,
+        cs.unique_commenters,
+        cs.replies_total,
+        COALESCE((SELECT MAX(t.depth) FROM thread t), 0),
+        COALESCE(ROUND(cs.replies_total::numeric / NULLIF(cs.comments_total, 0), 4), 0),
+        -- Окно обсуждения: от публикации до последнего комментария, не меньше часа
+        COALESCE(ROUND(cs.comments_total::numeric / GREATEST(
+            EXTRACT(EPOCH FROM (cs.last_comment_at - LEAST(p.created_at, cs.first_comment_at))) / 3600, 1
+        )::numeric, 4), 0),
+        GREATEST(EXTRACT(EPOCH FROM (cs.first_comment_at - p.created_at)), 0)::bigint,
+        cs.first_comment_at,
+        cs.last_comment_at
+    FROM posts p, cs
+    WHERE p.post_id = p_post_id
+$$;
+
+-- 2. Статистика обсуждения каждого одобренного поста (входные данные
+--    ранжирования posts_ranked_by_popularity)
+CREATE VIEW post_discussion_stats AS
+SELECT d.*
+FROM posts p
+CROSS JOIN LATERAL discussion_stats(p.post_id) d
+WHERE p.post_status = 'approved';
+
 -- ==========================
 -- ОКОННЫЕ ФУНКЦИИ
 -- ==========================
@@ -78,10 +176,20 @@ SELECT
     c.name AS channel_name,
     p.likes_count,
     p.comments_count,
+    d.unique_commenters,
+    d.max_depth,
+    d.comments_per_hour,
     RANK() OVER (PARTITION BY p.channel_id ORDER BY p.likes_count DESC) AS popularity_rank_in_channel,
-    PERCENT_RANK() OVER (PARTITION BY p.channel_id ORDER BY p.likes_count) AS popularity_percentile
+    PERCENT_RANK() OVER (PARTITION BY p.channel_id ORDER BY p.likes_count) AS popularity_percentile,
+    -- Живость обсуждения: разные участники, затем темп комментариев
+    RANK() OVER (
+        PARTITION BY p.channel_id
+        ORDER BY d.unique_commenters DESC, d.comments_per_hour DESC
+    ) AS discussion_rank_in_channel
 FROM posts p
-JOIN channels c ON p.channel_id = c.channel_id;
+JOIN channels c ON p.channel_id = c.channel_id
+JOIN post_discussion_stats d ON p.post_id = d.post_id
+WHERE p.post_status = 'approved';
 
 -- 2. Скользящее среднее лайков для авторов
 CREATE VIEW author_likes_trend AS
@@ -96,7 +204,8 @@ SELECT
         ORDER BY created_at 
         ROWS BETWEEN 2 PRECEDING AND CURRENT ROW
     ) AS moving_avg_likes
-FROM posts;
+FROM posts
+WHERE post_status = 'approved';
 
 -- 3. Кумулятивная статистика постов по датам
 CREATE VIEW cumulative_posts_analysis AS
@@ -112,7 +221,8 @@ SELECT
         PARTITION BY channel_id 
         ORDER BY created_at::DATE
     ) AS cumulative_likes
-FROM posts;
+FROM posts
+WHERE post_status = 'approved';
 
 -- 4. Ранжирование тегов по популярности в каждом канале
 CREATE VIEW tag_rank_by_channel AS
@@ -127,6 +237,7 @@ FROM channels c
 JOIN posts p ON c.channel_id = p.channel_id
 JOIN post_tags pt ON p.post_id = pt.post_id
 JOIN tags t ON pt.tag_id = t.tag_id
+WHERE p.post_status = 'approved'
 GROUP BY c.channel_id, c.name, t.tag_id, t.name;
 
 -- 5. Анализ комментаторской активности с оконными функциями
@@ -155,7 +266,8 @@ SELECT
     p.comments_count,
     a.name AS author_name
 FROM posts p
-JOIN authors a ON p.author_id = a.author_id;
+JOIN authors a ON p.author_id = a.author_id
+WHERE p.post_status = 'approved';
 
 -- 2. Каналы с информацией об источниках
 CREATE VIEW channels_with_sources AS
@@ -178,7 +290,8 @@ SELECT
     p.created_at
 FROM posts p
 JOIN authors a ON p.author_id = a.author_id
-JOIN news_texts nt ON p.text_id = nt.text_id;
+JOIN news_texts nt ON p.text_id = nt.text_id
+WHERE p.post_status = 'approved';
 
 -- 4. Комментарии с информацией о посте и авторе комментария
 CREATE VIEW comments_with_post_info AS
@@ -191,7 +304,8 @@ SELECT
     a.name AS post_author
 FROM comments c
 JOIN posts p ON c.post_id = p.post_id
-JOIN authors a ON p.author_id = a.author_id;
+JOIN authors a ON p.author_id = a.author_id
+WHERE p.post_status = 'approved';
 
 -- 5. Посты с тегами и каналами
 CREATE VIEW posts_with_tags_and_channels AS
@@ -204,7 +318,8 @@ SELECT
 FROM posts p
 JOIN post_tags pt ON p.post_id = pt.post_id
 JOIN tags t ON pt.tag_id = t.tag_id
-JOIN channels c ON p.channel_id = c.channel_id;
+JOIN channels c ON p.channel_id = c.channel_id
+WHERE p.post_status = 'approved';
 
 -- 6. Медиа с информацией о посте и канале
 CREATE VIEW media_with_context AS
@@ -212,11 +327,16 @@ SELECT
     m.media_id,
     m.media_content,
     m.media_type,
+    m.width,
+    m.height,
+    m.thumbnail_url,
+    m.title AS media_title,
     p.title AS post_title,
     c.name AS channel_name
 FROM media m
 JOIN posts p ON m.post_id = p.post_id
-JOIN channels c ON p.channel_id = c.channel_id;
+JOIN channels c ON p.channel_id = c.channel_id
+WHERE p.post_status = 'approved';
 
 -- 7. Полная информация о постах (автор, канал, теги, текст)
 CREATE VIEW comprehensive_post_info AS
@@ -234,7 +354,8 @@ FROM posts p
 JOIN authors a ON p.author_id = a.author_id
 JOIN channels c ON p.channel_id = c.channel_id
 JOIN sources s ON c.source_id = s.source_id
-JOIN news_texts nt ON p.text_id = nt.text_id;
+JOIN news_texts nt ON p.text_id = nt.text_id
+WHERE p.post_status = 'approved';
 
 -- 8. Расширенная аналитика постов (все связи)
 CREATE VIEW extended_post_analytics AS
@@ -258,6 +379,109 @@ JOIN news_texts nt ON p.text_id = nt.text_id
 LEFT JOIN post_tags pt ON p.post_id = pt.post_id
 LEFT JOIN tags t ON pt.tag_id = t.tag_id
 LEFT JOIN media m ON p.post_id = m.post_id
+WHERE p.post_status = 'approved'
 GROUP BY 
     p.post_id, p.title, a.name, c.name, s.name, s.topic, 
-    p.likes_count, p.comments_count, nt.text;
\ No newline at end of file
+    p.likes_count, p.comments_count, nt.text;
+
+-- ==========================
+-- РЕПУТАЦИЯ АВТОРОВ
+-- ==========================
+
+-- 1. Внутренняя репутация одного автора: общие формулы представления
+--    author_reputation, фильтров контента и GET /api/authors/{id}/profile.
+--    engagement_ratio - средняя вовлечённость одобренных постов (лайки + 2 * комментарии)
+--    относительно среднего по каналу; filter_rate - доля постов, задержанных фильтром
+--    или отклонённых модератором. Оценка 0..100, 50 - нейтральная; при малом числе
+--    постов она сдвигается к 50. Для несуществующего автора строк нет.
+CREATE FUNCTION author_reputation_stats(p_author_id INT)
+RETURNS TABLE (
+    author_id INT,
+    submissions BIGINT,
+    approved_posts BIGINT,
+    rejected_posts BIGINT,
+    filtered_posts BIGINT,
+    engagement_ratio NUMERIC,
+    filter_rate NUMERIC,
+    reputation_score NUMERIC
+)
+LANGUAGE sql STABLE AS $$
+    WITH author_posts AS (
+        SELECT p.channel_id, p.likes_count, p.comments_count, p.post_status
+        FROM posts p
+        WHERE p.author_id = p_author_id
+    ),
+    channel_avg AS (
+        SELECT p.channel_id, AVG(p.likes_count + 2 * p.comments_count) AS avg_engagement
+        FROM posts p
+        WHERE p.post_status = 'approved' AND p.channel_id IN (SELECT ap.channel_id FROM author_posts ap)
+        GROUP BY p.channel_id
+    ),
+    pf AS (
+        SELECT
+            COUNT(*) AS posts_total,
+            COUNT(*) FILTER (WHERE ap.post_status = 'approved') AS approved_posts,
+            COUNT(*) FILTER (WHERE ap.post_status = 'rejected') AS rejected_posts,
+            AVG((ap.likes_count + 2 * ap.comments_count) / NULLIF(ca.avg_engagement, 0))
+                FILTER (WHERE ap.post_status = 'approved') AS engagement_ratio
+        FROM author_posts ap
+        LEFT JOIN channel_avg ca ON ap.channel_id = ca.channel_id
+    ),
+    f AS (
+        SELECT COUNT(*) AS decisions_total, COUNT(*) FILTER (WHERE d.decision <> 'accept') AS filtered_total
+        FROM post_filter_decisions d
+        WHERE d.author_id = p_author_id
+    ),
+    rates AS (
+        SELECT
+            pf.approved_posts,
+            pf.rejected_posts,
+            f.filtered_total AS filtered_posts,
+            GREATEST(pf.posts_total, f.decisions_total) AS submissions,
+            COALESCE(pf.engagement_ratio, 1) AS engagement_ratio,
+            COALESCE(LEAST((f.filtered_total + pf.rejected_posts)::numeric
+                / NULLIF(GREATEST(pf.posts_total, f.decisions_total), 0), 1), 0) AS filter_rate
+        FROM pf, f
+    )
+    SELECT
+        a.author_id,
+        r.submissions,
+        r.approved_posts,
+        r.rejected_posts,
+        r.filtered_posts,
+        ROUND(r.engagement_ratio, 4),
+        ROUND(r.filter_rate, 4),
+        ROUND(50 + (25 * LEAST(r.engagement_ratio, 2) + 50 * (1 - r.filter_rate) - 50)
+            * r.submissions / (r.submissions + 5.0), 2)
+    FROM authors a, rates r
+    WHERE a.author_id = p_author_id
+$$;
+
+-- 2. Внутренняя репутация всех авторов (входные данные фильтров и ранжирования:
+--    JOIN author_reputation USING (author_id))
+CREATE VIEW author_reputation AS
+SELECT r.*
+FROM authors a
+CROSS JOIN LATERAL author_reputation_stats(a.author_id) r;
+
+-- 3. Сюжеты: сколько постов и каналов пишут об одном событии, суммарная вовлечённость
+--    и время первого и последнего поста. Учитываются только одобренные посты.
+CREATE VIEW story_stats AS
+SELECT 
+    s.story_id,
+    s.title,
+    s.first_post_id,
+    COUNT(p.post_id) AS posts_count,
+    COUNT(DISTINCT p.channel_id) AS channels_count,
+    COUNT(DISTINCT c.source_id) AS sources_count,
+    COALESCE(SUM(p.likes_count), 0) AS likes_total,
+    COALESCE(SUM(p.comments_count), 0) AS comments_total,
+    COALESCE(SUM(p.likes_count + 2 * p.comments_count), 0) AS engagement,
+    MIN(p.created_at) AS first_seen_at,
+    MAX(p.created_at) AS last_seen_at,
+    s.updated_at
+FROM stories s
+JOIN post_fingerprints f ON s.story_id = f.story_id
+JOIN posts p ON f.post_id = p.post_id AND p.post_status = 'approved'
+LEFT JOIN channels c ON p.channel_id = c.channel_id
+GROUP BY s.story_id;
@@ -0,0 +1,301 @@
+// Package pikabu - разбор HTML-страниц Пикабу (у сайта нет открытого API)
+// и адаптер платформы для researcher.Runner.
+//
+// Разметка, на которую опираются селекторы, записана в researchers/pikabu/testdata.
+package pikabu
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"researcher/htmlq"
+)
+
+// BaseURL - адрес сайта; относительные ссылки страниц разрешаются от него
+const BaseURL = "https://pikabu.ru"
+
+// Community - сообщество со страницы /communities
+type Community struct {
+	Name        string // имя в ссылке: /community/<name>
+	Title       string
+	Link        string
+	Subscribers int
+}
+
+// Author - автор поста
+type Author struct {
+	ID   string
+	Name string
+	URL  string
+}
+
+// Media - вложение поста
+type Media struct {
+	Type    string // image, video
+	URL     string
+	Preview string
+}
+
+// Story - пост со страницы ленты (сообщество, тег) или страницы поста
+type Story struct {
+	ID            string
+	Title         string
+	Text          string
+	URL           string
+	Rating        int
+	CommentsCount int
+	Author        Author
+	CreatedAt     time.Time
+	Tags          []string
+	Media         []Media
+	Sponsored     bool
+}
+
+// Comment - комментарий с ответами
+type Comment struct {
+	ID        string
+	ParentID  string
+	Author    string
+	Text      string
+	Rating    int
+	CreatedAt time.Time
+	Deleted   bool
+	Replies   []Comment
+}
+
+// ParseCommunities - сообщества со страницы /communities в порядке популярности
+func ParseCommunities(doc *htmlq.Node) []Community {
+	var communities []Community
+	for _, el := range doc.Find("div.community") {
+		title := el.First("a.community__title")
+		link := absURL(title.Attr("href"))
+		name := el.Attr("data-link-name")
+		if name == "" {
+			name = strings.TrimPrefix(linkPath(link), "/community/")
+		}
+		if name == "" || title == nil {
+			continue
+		}
+		subscribers, ok := htmlq.ParseCount(el.Attr("data-subscribers"))
+		if !ok {
+			subscribers, _ = htmlq.ParseCount(el.First(".community__subscribers").Text())
+		}
+		communities = append(communities, Community{
+			Name:        name,
+			Title:       title.Text(),
+			Link:        link,
+			Subscribers: subscribers,
+		})
+	}
+	return communities
+}
+
+// ParseStories - посты страницы ленты или страницы поста
+func ParseStories(doc *htmlq.Node) []Story {
+	page := PageURL(doc)
+	var stories []Story
+	for _, el := range doc.Find("article.story") {
+		if s, ok := parseStory(el, page); ok {
+			stories = append(stories, s)
+		}
+	}
+	return stories
+}
+
+// PageURL - адрес страницы из <link rel="canonical"> или <meta property="og:url">
+func PageURL(doc *htmlq.Node) string {
+	return absURL(firstNonEmpty(doc.First(`link[rel=canonical]`).Attr("href"),
+		doc.First(`meta[property="og:url"]`).Attr("content")))
+}
+
+// parseStory - пост из article.story; page - адрес страницы, на которой он найден
+func parseStory(el *htmlq.Node, page string) (Story, bool) {
+	s := Story{ID: el.Attr("data-story-id")}
+	if s.ID == "" {
+		return s, false
+	}
+
+	titleLink := el.First(".story__title-link")
+	s.Title = titleLink.Text()
+	s.URL = absURL(titleLink.Attr("href"))
+	// На странице поста заголовок не ссылка: адрес поста - адрес самой страницы
+	// (/story/<slug>_<id>). Без него адрес остаётся пустым - угадать slug нельзя.
+	if s.URL == "" && strings.HasPrefix(linkPath(page), "/story/") && strings.HasSuffix(linkPath(page), "_"+s.ID) {
+		s.URL = page
+	}
+
+	var paragraphs []string
+	for _, block := range el.Find(".story__content-inner .story-block_type_text") {
+		if text := block.Paragraphs(); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	s.Text = strings.Join(paragraphs, "\n")
+
+	var ok bool
+	if s.Rating, ok = htmlq.ParseCount(el.Attr("data-rating")); !ok {
+		s.Rating, _ = htmlq.ParseCount(el.First(".story__rating-count").Text())
+	}
+	if s.CommentsCount, ok = htmlq.ParseCount(el.Attr("data-comments")); !ok {
+		s.CommentsCount, _ = htmlq.ParseCount(el.First(".story__comments-link-count").Text())
+	}
+
+	user := el.First(".story__user")
+	s.Author = Author{ID: el.Attr("data-author-id"), Name: el.Attr("data-author-name")}
+	if s.Author.Name == "" {
+		s.Author.Name = user.Attr("data-name")
+	}
+	if s.Author.Name == "" {
+		s.Author.Name = user.First(".user__nick").Text()
+	}
+	if s.Author.Name != "" {
+		s.Author.URL = BaseURL + "/@" + s.Author.Name
+	}
+
+	s.CreatedAt = parseTime(el.First(".story__datetime").Attr("datetime"), el.Attr("data-timestamp"))
+
+	seen := map[string]bool{}
+	for _, tag := range el.Find(".story__tags .tags__tag") {
+		name := tag.Attr("data-tag")
+		if name == "" {
+			name = tag.Text()
+		}
+		if name != "" && !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			s.Tags = append(s.Tags, name)
+		}
+	}
+
+	s.Media = parseMedia(el)
+	s.Sponsored = el.HasClass("story_sponsored") || el.First(".story__sponsor") != nil
+	return s, true
+}
+
+// parseMedia - картинки (в самом большом размере) и видео из блоков поста
+func parseMedia(el *htmlq.Node) []Media {
+	var media []Media
+	for _, block := range el.Find(".story__content-inner .story-block") {
+		switch {
+		case block.HasClass("story-block_type_image"):
+			img := block.First("img")
+			src := firstNonEmpty(img.Attr("data-large-image"), block.First("a.story-image__link").Attr("href"),
+				img.Attr("data-src"), img.Attr("src"))
+			if src != "" && !strings.HasPrefix(src, "data:") {
+				media = append(media, Media{Type: "image", URL: absURL(src), Preview: absURL(img.Attr("data-src"))})
+			}
+		case block.HasClass("story-block_type_video"):
+			player := block.First(".player")
+			src := firstNonEmpty(player.Attr("data-source"), player.Attr("data-webm"))
+			if src != "" {
+				media = append(media, Media{Type: "video", URL: absURL(src), Preview: absURL(player.Attr("data-preview"))})
+			}
+		}
+	}
+	return media
+}
+
+// ParseComments - дерево комментариев страницы поста. Удалённые комментарии
+// без ответов пропускаются, с ответами - остаются без автора и текста.
+func ParseComments(doc *htmlq.Node) []Comment {
+	return collectComments(doc.First("#comments"))
+}
+
+// collectComments - комментарии верхнего уровня внутри n (вложенные - в Replies)
+func collectComments(n *htmlq.Node) []Comment {
+	var comments []Comment
+	for _, el := range n.Elements() {
+		if !el.HasClass("comment") || el.Attr("data-id") == "" {
+			comments = append(comments, collectComments(el)...)
+			continue
+		}
+		c := parseComment(el)
+		if c.Deleted && len(c.Replies) == 0 {
+			continue
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+func parseComment(el *htmlq.Node) Comment {
+	meta := parseMeta(el.Attr("data-meta"))
+	c := Comment{ID: el.Attr("data-id"), Deleted: meta["de"] == "1"}
+	if pid := meta["pid"]; pid != "" && pid != "0" {
+		c.ParentID = pid
+	}
+
+	// Дочерние комментарии лежат в .comment__children, поэтому свои поля ищем в .comment__body
+	body := el.First(".comment__body")
+	if !c.Deleted {
+		user := body.First(".comment__user")
+		c.Author = firstNonEmpty(user.Attr("data-name"), user.First(".user__nick").Text())
+		c.Text = body.First(".comment__content").Paragraphs()
+	}
+	var ok bool
+	if c.Rating, ok = htmlq.ParseCount(meta["r"]); !ok {
+		c.Rating, _ = htmlq.ParseCount(body.First(".comment__rating-count").Text())
+	}
+	c.CreatedAt = parseTime(firstNonEmpty(meta["d"], body.First(".comment__datetime").Attr("datetime")), "")
+
+	for _, child := range el.Elements() {
+		if child.HasClass("comment__children") {
+			c.Replies = append(c.Replies, collectComments(child)...)
+		}
+	}
+	return c
+}
+
+// parseMeta разбирает data-meta комментария: "pid=0;aid=901;r=25;d=2024-05-01T13:00:00+03:00"
+func parseMeta(meta string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range strings.Split(meta, ";") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return values
+}
+
+// parseTime - время из атрибута datetime (RFC 3339) или unix-времени
+func parseTime(datetime, unix string) time.Time {
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(datetime)); err == nil {
+		return t
+	}
+	if sec, err := strconv.ParseInt(strings.TrimSpace(unix), 10, 64); err == nil && sec > 0 {
+		return time.Unix(sec, 0)
+	}
+	return time.Time{}
+}
+
+// absURL - абсолютная ссылка от BaseURL
+func absURL(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+	base, _ := url.Parse(BaseURL + "/")
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func linkPath(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,243 @@
+package pikabu
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"researcher"
+	"researcher/htmlq"
+)
+
+// testdata - записанные страницы сайта
+const testdata = "../../testdata"
+
+func parseFixture(t *testing.T, name string) *htmlq.Node {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(testdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return htmlq.ParseString(htmlq.Decode(raw, ""))
+}
+
+func TestParseCommunities(t *testing.T) {
+	got := ParseCommunities(parseFixture(t, "communities.html"))
+	want := []Community{
+		{Name: "science", Title: "Наука | Научпоп", Link: "https://pikabu.ru/community/science", Subscribers: 152340},
+		// Число подписчиков из текста счётчика, если нет data-subscribers
+		{Name: "politics", Title: "Политика", Link: "https://pikabu.ru/community/politics", Subscribers: 98500},
+		{Name: "it", Title: "IT-юмор и новости", Link: "https://pikabu.ru/community/it", Subscribers: 1200000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("communities = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseStories(t *testing.T) {
+	telescope := Story{
+		ID:            "11111",
+		Title:         "Новый телескоп снял туманность Ориона в инфракрасном диапазоне",
+		Text:          "Астрономы опубликовали первые снимки туманности Ориона, полученные новым телескопом.\nНа изображениях видны протопланетные диски и облака пыли.\nПодробности — в статье журнала.",
+		URL:           "https://pikabu.ru/story/novyy_teleskop_snyal_tumannost_11111",
+		Rating:        154,
+		CommentsCount: 4,
+		Author:        Author{ID: "777", Name: "astro_fan", URL: "https://pikabu.ru/@astro_fan"},
+		CreatedAt:     time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		Tags:          []string{"Наука", "Космос", "Телескоп"},
+		Media: []Media{{
+			Type:    "image",
+			URL:     "https://cs14.pikabu.ru/post_img/big/2024/05/01/8/1714554000111.jpg",
+			Preview: "https://cs14.pikabu.ru/post_img/2024/05/01/8/1714554000111.webp",
+		}},
+	}
+	// На странице поста свежее рейтинг, адрес берётся из canonical
+	telescopePage := telescope
+	telescopePage.Rating = 161
+
+	tests := []struct {
+		fixture string
+		want    []Story
+	}{
+		{"community_science.html", []Story{
+			telescope,
+			{
+				ID:            "11112",
+				Title:         "Как работает CRISPR: объяснение на пальцах",
+				Text:          "Короткое видео о том, как редактируют геном.\nСмотреть со звуком.",
+				URL:           "https://pikabu.ru/story/kak_rabotaet_crispr_11112",
+				Rating:        -3,
+				CommentsCount: 0,
+				Author:        Author{ID: "778", Name: "biolog", URL: "https://pikabu.ru/@biolog"},
+				CreatedAt:     time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+				// Тег без data-tag - из текста ссылки
+				Tags: []string{"Биология", "Генетика"},
+				Media: []Media{{
+					Type:    "video",
+					URL:     "https://www.youtube.com/watch?v=2pp17E4E-O8",
+					Preview: "https://i.ytimg.com/vi/2pp17E4E-O8/hqdefault.jpg",
+				}},
+			},
+			{
+				ID:        "11113",
+				Title:     "Курс астрономии со скидкой 50%",
+				Text:      "Запишитесь на онлайн-курс по астрономии до конца недели.",
+				URL:       "https://pikabu.ru/story/kurs_astronomii_so_skidkoy_11113",
+				Rating:    12,
+				Author:    Author{ID: "1", Name: "pikabu.sponsor", URL: "https://pikabu.ru/@pikabu.sponsor"},
+				CreatedAt: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+				Media: []Media{{
+					Type:    "video",
+					URL:     "https://cs13.pikabu.ru/video/2024/05/01/1714546800_promo.mp4",
+					Preview: "https://cs13.pikabu.ru/video/2024/05/01/1714546800_promo.jpg",
+				}},
+				Sponsored: true,
+			},
+		}},
+		{"story_novyy_teleskop_snyal_tumannost_11111.html", []Story{telescopePage}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got := ParseStories(parseFixture(t, tt.fixture))
+			if len(got) != len(tt.want) {
+				t.Fatalf("stories = %d, want %d", len(got), len(tt.want))
+			}
+			for i := range tt.want {
+				g, w := got[i], tt.want[i]
+				if !g.CreatedAt.Equal(w.CreatedAt) {
+					t.Errorf("%s: created at %v, want %v", w.ID, g.CreatedAt, w.CreatedAt)
+				}
+				g.CreatedAt, w.CreatedAt = time.Time{}, time.Time{}
+				if !reflect.DeepEqual(g, w) {
+					t.Errorf("story %s:\n got %+v\nwant %+v", w.ID, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestStoryURLWithoutLink(t *testing.T) {
+	const article = `<article class="story" data-story-id="42"><h1 class="story__title"><span class="story__title-link">Заголовок</span></h1></article>`
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"canonical", `<link rel="canonical" href="https://pikabu.ru/story/zagolovok_42">`, "https://pikabu.ru/story/zagolovok_42"},
+		{"og:url", `<meta property="og:url" content="/story/zagolovok_42">`, "https://pikabu.ru/story/zagolovok_42"},
+		// Страница другого поста или ленты - адрес неизвестен
+		{"other story", `<link rel="canonical" href="https://pikabu.ru/story/drugoy_142">`, ""},
+		{"feed", `<link rel="canonical" href="https://pikabu.ru/community/science">`, ""},
+		{"no canonical", ``, ""},
+	}
+	for _, tt := range tests {
+		stories := ParseStories(htmlq.ParseString("<html><head>" + tt.head + "</head><body>" + article + "</body></html>"))
+		if len(stories) != 1 || stories[0].URL != tt.want {
+			t.Errorf("%s: stories = %+v, want URL %q", tt.name, stories, tt.want)
+		}
+	}
+}
+
+// commentShape - поля комментария, проверяемые в дереве
+type commentShape struct {
+	ID, ParentID, Author, Text string
+	Rating                     int
+	Deleted                    bool
+	Replies                    []commentShape
+}
+
+func shapes(comments []Comment) []commentShape {
+	var out []commentShape
+	for _, c := range comments {
+		out = append(out, commentShape{c.ID, c.ParentID, c.Author, c.Text, c.Rating, c.Deleted, shapes(c.Replies)})
+	}
+	return out
+}
+
+func TestParseComments(t *testing.T) {
+	comments := ParseComments(parseFixture(t, "story_novyy_teleskop_snyal_tumannost_11111.html"))
+
+	// Удалённый комментарий 502 без ответов пропускается
+	want := []commentShape{{
+		ID: "500", Author: "reader1", Rating: 25,
+		Text: "Потрясающие снимки!\nИнтересно, когда опубликуют данные спектрометра?",
+		Replies: []commentShape{{
+			ID: "501", ParentID: "500", Author: "astro_fan", Rating: 10, Text: "Обещают в течение месяца.",
+			Replies: []commentShape{{ID: "503", ParentID: "501", Author: "reader1", Rating: 3, Text: "Спасибо!"}},
+		}},
+	}}
+	if got := shapes(comments); !reflect.DeepEqual(got, want) {
+		t.Errorf("comments =\n%+v\nwant\n%+v", got, want)
+	}
+	if want := time.Date(2024, 5, 1, 10, 20, 0, 0, time.UTC); !comments[0].Replies[0].CreatedAt.Equal(want) {
+		t.Errorf("501 created at %v, want %v", comments[0].Replies[0].CreatedAt, want)
+	}
+}
+
+func TestParseCommentsKeepsDeletedWithReplies(t *testing.T) {
+	doc := htmlq.ParseString(`<section id="comments">
+		<div class="comment" data-id="1" data-meta="pid=0;r=-5;de=1">
+			<div class="comment__body"><div class="comment__content">Комментарий удалён.</div></div>
+			<div class="comment__children">
+				<div class="comment" data-id="2" data-meta="pid=1;r=2;de=0">
+					<div class="comment__body"><div class="comment__user" data-name="u"></div><div class="comment__content"><p>ответ</p></div></div>
+				</div>
+			</div>
+		</div></section>`)
+	want := []commentShape{{
+		ID: "1", Rating: -5, Deleted: true,
+		Replies: []commentShape{{ID: "2", ParentID: "1", Author: "u", Text: "ответ", Rating: 2}},
+	}}
+	if got := shapes(ParseComments(doc)); !reflect.DeepEqual(got, want) {
+		t.Errorf("comments = %+v, want %+v", got, want)
+	}
+}
+
+func TestPlatformOverFixtures(t *testing.T) {
+	ctx := context.Background()
+	p := NewPlatform(htmlq.DirFetcher{Dir: testdata})
+
+	channels, err := p.ListChannels(ctx, researcher.Config{ChannelLimit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 2 || channels[0].ExternalID != "community/science" || channels[1].Subscribers != 98500 {
+		t.Fatalf("channels = %+v", channels)
+	}
+
+	// Второй страницы ленты нет - листание останавливается на первой
+	posts, err := p.FetchPosts(ctx, channels[0], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 3 || posts[0].Author.ExternalID != "777" || !posts[2].MarkedAsAds || posts[1].Likes != -3 {
+		t.Fatalf("posts = %+v", posts)
+	}
+
+	comments, err := p.FetchComments(ctx, channels[0], posts[0], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// limit считает комментарии вместе с ответами
+	if len(comments) != 1 || len(comments[0].Replies) != 1 || len(comments[0].Replies[0].Replies) != 0 {
+		t.Errorf("comments = %+v", comments)
+	}
+
+	media, err := p.FetchMedia(ctx, channels[0], posts[1], 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(media) != 1 || media[0].Type != "video" || media[0].Thumbnail != "https://i.ytimg.com/vi/2pp17E4E-O8/hqdefault.jpg" {
+		t.Errorf("media = %+v", media)
+	}
+
+	// Пост без адреса: страницу комментариев не найти
+	noURL := posts[0]
+	noURL.URL = ""
+	if comments, err := p.FetchComments(ctx, channels[0], noURL, 10); err != nil || comments != nil {
+		t.Errorf("comments without url = %+v, %v", comments, err)
+	}
+}
@@ -0,0 +1,194 @@
+package pikabu
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"apiclient"
+	"researcher"
+	"researcher/htmlq"
+)
+
+// maxPages - страниц ленты канала за один обход
+const maxPages = 10
+
+// Platform - адаптер Пикабу для researcher.Runner. Каналы - сообщества
+// (/community/<name>) и теги (/tag/<name>).
+type Platform struct {
+	fetcher htmlq.Fetcher
+}
+
+// NewPlatform создаёт адаптер; fetcher - сайт или каталог фикстур
+func NewPlatform(fetcher htmlq.Fetcher) *Platform {
+	return &Platform{fetcher: fetcher}
+}
+
+func (p *Platform) Name() string { return "Pikabu" }
+
+func (p *Platform) Source() apiclient.Source {
+	return apiclient.Source{Name: "Pikabu", Address: "pikabu.ru", Topic: "social"}
+}
+
+// ListChannels - каналы из preferred_channels ("science", "community/science",
+// "tag/Новости") или самые популярные сообщества
+func (p *Platform) ListChannels(ctx context.Context, conf researcher.Config) ([]researcher.Channel, error) {
+	if len(conf.PreferredChannels) > 0 {
+		channels := make([]researcher.Channel, 0, len(conf.PreferredChannels))
+		for _, name := range conf.PreferredChannels {
+			channels = append(channels, preferredChannel(name))
+		}
+		return channels, nil
+	}
+
+	doc, err := p.fetcher.Fetch(ctx, BaseURL+"/communities")
+	if err != nil {
+		return nil, err
+	}
+	communities := ParseCommunities(doc)
+	if conf.ChannelLimit > 0 && len(communities) > conf.ChannelLimit {
+		communities = communities[:conf.ChannelLimit]
+	}
+
+	channels := make([]researcher.Channel, 0, len(communities))
+	for _, c := range communities {
+		channels = append(channels, researcher.Channel{
+			ExternalID:  "community/" + c.Name,
+			Name:        c.Title,
+			Link:        c.Link,
+			Subscribers: c.Subscribers,
+			Topic:       "general",
+		})
+	}
+	return channels, nil
+}
+
+func preferredChannel(name string) researcher.Channel {
+	kind, slug, ok := strings.Cut(strings.Trim(name, "/"), "/")
+	if !ok || (kind != "community" && kind != "tag") {
+		kind, slug = "community", strings.Trim(name, "/")
+	}
+	return researcher.Channel{
+		ExternalID: kind + "/" + slug,
+		Name:       slug,
+		Link:       BaseURL + "/" + kind + "/" + url.PathEscape(slug),
+		Topic:      "general",
+	}
+}
+
+// FetchPosts читает ленту канала по страницам (?page=N), пока не наберёт limit постов
+func (p *Platform) FetchPosts(ctx context.Context, ch researcher.Channel, limit int) ([]researcher.Post, error) {
+	var posts []researcher.Post
+	seen := map[string]bool{}
+	for page := 1; page <= maxPages && len(posts) < limit; page++ {
+		pageURL := ch.Link
+		if page > 1 {
+			pageURL = fmt.Sprintf("%s?page=%d", ch.Link, page)
+		}
+		doc, err := p.fetcher.Fetch(ctx, pageURL)
+		if err != nil {
+			if page > 1 && htmlq.IsNotFound(err) {
+				break
+			}
+			if len(posts) > 0 {
+				fmt.Printf("WARNING: %s: %v\n", pageURL, err)
+				break
+			}
+			return nil, err
+		}
+
+		added := 0
+		for _, s := range ParseStories(doc) {
+			if seen[s.ID] || len(posts) >= limit {
+				continue
+			}
+			seen[s.ID] = true
+			posts = append(posts, storyPost(s))
+			added++
+		}
+		if added == 0 {
+			break
+		}
+	}
+	return posts, nil
+}
+
+func storyPost(s Story) researcher.Post {
+	var author *apiclient.Author
+	if s.Author.Name != "" {
+		author = &apiclient.Author{
+			Name:       s.Author.Name,
+			Platform:   "pikabu",
+			ExternalID: s.Author.ID,
+			ProfileURL: s.Author.URL,
+		}
+	}
+	return researcher.Post{
+		ExternalID:  s.ID,
+		Title:       s.Title,
+		Text:        s.Text,
+		URL:         s.URL,
+		Author:      author,
+		CreatedAt:   s.CreatedAt,
+		Likes:       s.Rating,
+		Comments:    s.CommentsCount,
+		Tags:        s.Tags,
+		MarkedAsAds: s.Sponsored,
+		Raw:         s,
+	}
+}
+
+// FetchComments читает страницу поста; в ленте комментариев нет
+func (p *Platform) FetchComments(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Comment, error) {
+	if post.Comments == 0 || post.URL == "" {
+		return nil, nil
+	}
+	doc, err := p.fetcher.Fetch(ctx, post.URL)
+	if err != nil {
+		return nil, err
+	}
+	remaining := limit
+	return convertComments(ParseComments(doc), &remaining), nil
+}
+
+// convertComments переводит дерево в общий формат, не больше *remaining комментариев
+func convertComments(comments []Comment, remaining *int) []researcher.Comment {
+	var converted []researcher.Comment
+	for _, c := range comments {
+		if *remaining <= 0 {
+			break
+		}
+		*remaining--
+		text := c.Text
+		switch {
+		case c.Deleted:
+			text = "[deleted]"
+		case text == "":
+			// Комментарий только с картинкой
+			text = "[media]"
+		}
+		converted = append(converted, researcher.Comment{
+			ExternalID: c.ID,
+			Author:     c.Author,
+			Text:       text,
+			Likes:      c.Rating,
+			CreatedAt:  c.CreatedAt,
+			Replies:    convertComments(c.Replies, remaining),
+		})
+	}
+	return converted
+}
+
+// FetchMedia - вложения поста из ленты, без дополнительных запросов
+func (p *Platform) FetchMedia(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Media, error) {
+	s := post.Raw.(Story)
+	var media []researcher.Media
+	for _, m := range s.Media {
+		if len(media) >= limit {
+			break
+		}
+		media = append(media, researcher.Media{Type: m.Type, URL: m.URL, Thumbnail: m.Preview})
+	}
+	return media, nil
+}
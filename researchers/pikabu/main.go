@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"apiclient"
+	"researcher"
+	"researcher-pikabu/internal/pikabu"
+	"researcher/htmlq"
+)
+
+const (
+	serverURL      = "http://server:8080"
+	configFileName = "/usr/local/etc/pikabu-researcher/test_conf.xml"
+	stateFileName  = "/var/lib/pikabu-researcher/state.json"
+	userAgent      = "NewsAggregatorResearcher/0.1 (+https://github.com/Vi1689/News-Aggregator)"
+	// requestInterval - пауза между запросами страниц сайта
+	requestInterval = 2 * time.Second
+)
+
+func main() {
+	parseFile := flag.String("parse", "", "разобрать сохранённую страницу и вывести посты и комментарии в JSON")
+	fixtures := flag.String("fixtures", os.Getenv("PIKABU_FIXTURES"), "читать страницы из каталога фикстур вместо сайта")
+	flag.Parse()
+
+	if *parseFile != "" {
+		if err := printParsed(*parseFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Небольшая задержка для запуска сервера
+	time.Sleep(5 * time.Second)
+
+	var fetcher htmlq.Fetcher = htmlq.NewHTTPFetcher(userAgent, researcher.NewLimiter(requestInterval))
+	if *fixtures != "" {
+		fmt.Printf("Reading pages from fixtures in %s\n", *fixtures)
+		fetcher = htmlq.DirFetcher{Dir: *fixtures}
+	}
+
+	state, err := researcher.OpenState(stateFileName)
+	if err != nil {
+		fmt.Printf("Failed to open state: %v\n", err)
+		os.Exit(1)
+	}
+
+	runner := &researcher.Runner{
+		Platform:   pikabu.NewPlatform(fetcher),
+		Ingest:     researcher.NewIngest(apiclient.New(serverURL)),
+		State:      state,
+		ConfigPath: configFileName,
+	}
+	if err := runner.Run(context.Background()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// printParsed выводит то, что researcher извлекает из страницы: для проверки
+// селекторов на записанных страницах без сети и сервера
+func printParsed(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	doc, err := htmlq.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	out := struct {
+		Communities []pikabu.Community `json:"communities,omitempty"`
+		Stories     []pikabu.Story     `json:"stories,omitempty"`
+		Comments    []pikabu.Comment   `json:"comments,omitempty"`
+	}{
+		Communities: pikabu.ParseCommunities(doc),
+		Stories:     pikabu.ParseStories(doc),
+		Comments:    pikabu.ParseComments(doc),
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
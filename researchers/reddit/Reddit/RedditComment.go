@@ -27,6 +27,8 @@ type Comment struct {
 	AuthorName string        `json:"author"`
 	Thread     CommentThread `json:"thread"`
 	CreatedUTC float64       `json:"created_utc"`
+	// Score - сумма голосов за и против; Reddit скрывает её в первые часы (0)
+	Score int `json:"score"`
 }
 
 // -----------------------------
@@ -78,6 +80,7 @@ type redditCommentData struct {
 	Author     string          `json:"author"`
 	Replies    json.RawMessage `json:"replies"` // can be "" or listing
 	CreatedUTC float64         `json:"created_utc"`
+	Score      int             `json:"score"`
 }
 
 type redditMoreData struct {
@@ -138,6 +141,7 @@ func ParseCommentsFromBody(body []byte) ([]Comment, map[string][]string, error)
 					Text:       data.Body,
 					AuthorName: data.Author,
 					CreatedUTC: data.CreatedUTC,
+					Score:      data.Score,
 					Thread:     CommentThread{Count: 0, Items: nil}, // will fill later
 				}
 				flat = append(flat, c)
@@ -409,6 +413,7 @@ func FetchComments(accessToken, nameSubreddit, postID string, maxCountComment in
 					Text:       d.Body,
 					AuthorName: d.Author,
 					CreatedUTC: d.CreatedUTC,
+					Score:      d.Score,
 					Thread:     CommentThread{Count: 0, Items: nil},
 				}
 				// store in map if not exists
@@ -439,6 +444,7 @@ func FetchComments(accessToken, nameSubreddit, postID string, maxCountComment in
 										Text:       rd.Body,
 										AuthorName: rd.Author,
 										CreatedUTC: rd.CreatedUTC,
+										Score:      rd.Score,
 										Thread:     CommentThread{Count: 0, Items: nil},
 									}
 									if _, exists := commentsMap[cc.ID]; !exists {
@@ -545,6 +551,7 @@ func buildCommentTree(commentsMap map[string]*Comment, childrenMap map[string][]
 			Text:       cptr.Text,
 			AuthorName: cptr.AuthorName,
 			CreatedUTC: cptr.CreatedUTC,
+			Score:      cptr.Score,
 			Thread:     CommentThread{Count: count, Items: items},
 		}
 		topLevel = append(topLevel, node)
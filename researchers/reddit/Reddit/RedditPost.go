@@ -15,14 +15,52 @@ import (
 // Структура поста (по требованиям)
 // -------------------------------
 type Post struct {
-	Title      string  `json:"title"`
-	Text       string  `json:"selftext"`
-	AuthorName string  `json:"author"`
-	Comments   int     `json:"num_comments"`
-	Votes      int     `json:"score"`
-	Date       float64 `json:"created_utc"`
-	URL        string  `json:"url"`
-	ID         string  `json:"id"`
+	Title      string `json:"title"`
+	Text       string `json:"selftext"`
+	AuthorName string `json:"author"`
+	// AuthorFullname - ID аккаунта автора (t2_...), у удалённых аккаунтов пустой
+	AuthorFullname string  `json:"author_fullname"`
+	Comments       int     `json:"num_comments"`
+	Votes          int     `json:"score"`
+	Date           float64 `json:"created_utc"`
+	// URL - обсуждение на Reddit; исходный url ссылочного поста - в Link
+	URL string `json:"-"`
+	ID  string `json:"id"`
+
+	Link        string  `json:"url"`
+	Permalink   string  `json:"permalink"`
+	Domain      string  `json:"domain"`
+	Flair       string  `json:"link_flair_text"`
+	UpvoteRatio float64 `json:"upvote_ratio"`
+	NSFW        bool    `json:"over_18"`
+	Spoiler     bool    `json:"spoiler"`
+	Awards      int     `json:"total_awards_received"`
+}
+
+// Metadata - поля поста для сервера (posts.metadata); пустые опускаются
+func (p Post) Metadata() map[string]interface{} {
+	meta := map[string]interface{}{
+		"permalink": p.URL, // полный адрес обсуждения
+		"awards":    p.Awards,
+	}
+	// upvote_ratio 0 - Reddit не прислал долю (у постов без голосов она 1)
+	if p.UpvoteRatio > 0 {
+		meta["upvote_ratio"] = p.UpvoteRatio
+	}
+	if p.Flair != "" {
+		meta["flair"] = p.Flair
+	}
+	if p.Spoiler {
+		meta["spoiler"] = true
+	}
+	if p.Domain != "" {
+		meta["domain"] = p.Domain
+	}
+	// У текстовых постов url совпадает с обсуждением
+	if p.Link != "" && p.Link != p.URL {
+		meta["link"] = p.Link
+	}
+	return meta
 }
 
 // ---------------------------------------------------
@@ -58,7 +96,11 @@ func ParsePostsFromBody(body []byte, nameSubreddit string) ([]Post, error) {
 	}
 
 	for i := range posts {
-		posts[i].URL = "https://www.reddit.com/r/" + nameSubreddit + "/comments/" + posts[i].ID
+		if posts[i].Permalink != "" {
+			posts[i].URL = "https://www.reddit.com" + posts[i].Permalink
+		} else {
+			posts[i].URL = "https://www.reddit.com/r/" + nameSubreddit + "/comments/" + posts[i].ID
+		}
 	}
 
 	return posts, nil
@@ -117,22 +159,49 @@ func combineRawResponses(raws [][]byte) ([]byte, error) {
 // - dateBy *time.Time (nil / zero означает не задано)
 // - maxCountPost int (<=0 означает "без ограничений")
 //
+// Читает выборку /new; см. FetchListing
+// ---------------------------------------------------
+func FetchPosts(
+	accessToken string,
+	nameSubreddit string,
+	dateFrom *time.Time,
+	dateBy *time.Time,
+	maxCountPost int,
+) ([]byte, []Post, error) {
+	listing := DefaultListing
+	if dateFrom != nil {
+		listing.From = *dateFrom
+	}
+	if dateBy != nil {
+		listing.To = *dateBy
+	}
+	return FetchListing(accessToken, nameSubreddit, listing, nil, maxCountPost)
+}
+
+// ---------------------------------------------------
+// FetchListing - посты выборки listing (hot, new, top, rising) в диапазоне дат
+//
+// seen (может быть nil) - пост уже получен в прошлых обходах. Такие посты не
+// возвращаются и не считаются в maxCountPost; листание заканчивается на
+// странице, где все посты уже получены. Отдельные полученные посты его не
+// останавливают: между ними могут быть посты, которые не удалось сохранить.
+//
 // Возвращает:
 // - []byte : объединённый JSON (массив) всех сырых ответов от Reddit
 // - []Post : отфильтрованные посты (по created_utc согласно логике)
 // - error  : nil в нормальном случае; при превышении 30 попыток при 429 возвращает найденное и nil
 // ---------------------------------------------------
-func FetchPosts(
+func FetchListing(
 	accessToken string,
 	nameSubreddit string,
-	dateFrom *time.Time,
-	dateBy *time.Time,
+	listing Listing,
+	seen func(Post) bool,
 	maxCountPost int,
 ) ([]byte, []Post, error) {
 
 	// Подготовка: лимит на запрос (Reddit ограничивает 100)
 	var reqLimit int
-	if maxCountPost < 100 {
+	if maxCountPost > 0 && maxCountPost < 100 {
 		reqLimit = maxCountPost
 	} else {
 		reqLimit = 100
@@ -150,28 +219,6 @@ func FetchPosts(
 	// Счётчик суммарных retry-ожиданий (для поведения "более 30 попыток")
 	totalRetries := 0
 
-	// Вспомогательная функция проверки, попадает ли пост в диапазон
-	inRange := func(p Post) bool {
-		created := time.Unix(int64(p.Date), 0)
-		// оба не заданы — всё в диапазоне
-		if (dateFrom == nil || dateFrom.IsZero()) && (dateBy == nil || dateBy.IsZero()) {
-			return true
-		}
-		// только dateFrom задан
-		if dateFrom != nil && !dateFrom.IsZero() && (dateBy == nil || dateBy.IsZero()) {
-			return !created.Before(*dateFrom) // created >= dateFrom
-		}
-		// только dateBy задан
-		if dateBy != nil && !dateBy.IsZero() && (dateFrom == nil || dateFrom.IsZero()) {
-			return !created.After(*dateBy) // created <= dateBy
-		}
-		// оба заданы
-		if dateFrom != nil && !dateFrom.IsZero() && dateBy != nil && !dateBy.IsZero() {
-			return (!created.Before(*dateFrom)) && (!created.After(*dateBy)) // dateFrom <= created <= dateBy
-		}
-		return true
-	}
-
 	// Если maxCountPost <= 0 интерпретируем как "без ограничений"
 	unlimited := maxCountPost <= 0
 
@@ -182,13 +229,8 @@ func FetchPosts(
 
 	// Основной цикл: запрашиваем страницы, пока не получили достаточно или пока Reddit даёт данные
 	for {
-		// Формируем URL
-		// Используем /new с сортировкой по времени (новые сначала)
-		url := fmt.Sprintf("https://oauth.reddit.com/r/%s/new?limit=%d", nameSubreddit, reqLimit)
-		if afterFullname != "" {
-			// Параметр after ожидает fullname вида t3_<id>
-			url = url + "&after=" + afterFullname
-		}
+		// Формируем URL; параметр after ожидает fullname вида t3_<id>
+		url := listing.URL(nameSubreddit, reqLimit, afterFullname)
 
 		// Выполнение запроса с retry-политикой при 429 / временных ошибках
 		var resp *http.Response
@@ -277,8 +319,13 @@ func FetchPosts(
 		}
 
 		// Фильтрация и добавление постов из этой страницы
+		allSeen := true
 		for _, p := range postsPage {
-			if inRange(p) {
+			if seen != nil && seen(p) {
+				continue
+			}
+			allSeen = false
+			if listing.InRange(time.Unix(int64(p.Date), 0)) {
 				filteredPosts = append(filteredPosts, p)
 				// проверка лимита
 				if !unlimited && len(filteredPosts) >= maxCountPost {
@@ -293,8 +340,16 @@ func FetchPosts(
 		if len(postsPage) == 0 {
 			break
 		}
+		// Дальше только уже полученные посты
+		if seen != nil && allSeen {
+			break
+		}
 		// берем ID последнего поста в странице (самого старого в этой выборке)
 		lastPost := postsPage[len(postsPage)-1]
+		// В new дальше только посты старше начала диапазона
+		if listing.Chronological() && !listing.From.IsZero() && time.Unix(int64(lastPost.Date), 0).Before(listing.From) {
+			break
+		}
 		if lastPost.ID == "" {
 			// нет id — останавливаемся
 			break
@@ -0,0 +1,65 @@
+package Reddit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"researcher-reddit/token"
+)
+
+// User - профиль автора Reddit (/user/{name}/about)
+type User struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	TotalKarma   int64  `json:"total_karma"`
+	LinkKarma    int64  `json:"link_karma"`
+	CommentKarma int64  `json:"comment_karma"`
+}
+
+// Karma - карма пользователя; у старых аккаунтов total_karma может не приходить
+func (u User) Karma() int64 {
+	if u.TotalKarma > 0 {
+		return u.TotalKarma
+	}
+	return u.LinkKarma + u.CommentKarma
+}
+
+// FetchUser читает профиль автора; удалённые и заблокированные аккаунты возвращают ошибку
+func FetchUser(accessToken, name string) (User, error) {
+	reqURL := fmt.Sprintf("https://oauth.reddit.com/user/%s/about", url.PathEscape(name))
+
+	resp, err := MakeRedditRequestSubreddit(reqURL, accessToken)
+	if err != nil {
+		return User{}, fmt.Errorf("ошибка при запросе: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+
+		newTok, err := token.GetAccessToken()
+		if err != nil {
+			return User{}, fmt.Errorf("ошибка обновления токена: %w", err)
+		}
+		resp, err = MakeRedditRequestSubreddit(reqURL, newTok)
+		if err != nil {
+			return User{}, fmt.Errorf("ошибка при повторном запросе: %w", err)
+		}
+		defer resp.Body.Close()
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return User{}, fmt.Errorf("reddit user %s: status %d", name, resp.StatusCode)
+	}
+
+	var about struct {
+		Data User `json:"data"`
+	}
+	if err := json.Unmarshal(body, &about); err != nil {
+		return User{}, fmt.Errorf("ошибка парсинга профиля %s: %w", name, err)
+	}
+	return about.Data, nil
+}
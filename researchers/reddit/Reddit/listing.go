@@ -0,0 +1,144 @@
+package Reddit
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Listing - выборка постов сабреддита: сортировка, окно для top и диапазон дат
+type Listing struct {
+	// Sort - hot, new, top или rising
+	Sort string
+	// Time - окно top: hour, day, week, month, year, all; пустое - day
+	Time string
+	// From, To - диапазон дат создания постов; нулевая граница не задана
+	From, To time.Time
+}
+
+// DefaultListing - выборка каналов без настроек: новые посты, обход до уже отправленных
+var DefaultListing = Listing{Sort: "new"}
+
+var listingTimes = map[string]bool{"hour": true, "day": true, "week": true, "month": true, "year": true, "all": true}
+
+// ParseChannel разбирает элемент preferred_channels: имя сабреддита и,
+// через "/", его выборку с параметрами в стиле адресов Reddit:
+//
+//	worldnews
+//	worldnews/hot
+//	worldnews/top?t=week
+//	news/new?from=2025-01-01&to=2025-01-31
+//
+// from и to - даты (2006-01-02, to включительно) или время в RFC 3339.
+func ParseChannel(spec string) (string, Listing, error) {
+	spec = strings.Trim(strings.TrimSpace(spec), "/")
+	spec = strings.TrimPrefix(spec, "r/")
+	name, rest, _ := strings.Cut(spec, "/")
+	if name == "" {
+		return "", Listing{}, fmt.Errorf("empty subreddit in %q", spec)
+	}
+	listing := DefaultListing
+	if rest == "" {
+		return name, listing, nil
+	}
+
+	sort, rawQuery, _ := strings.Cut(rest, "?")
+	switch sort {
+	case "hot", "new", "top", "rising":
+		listing.Sort = sort
+	default:
+		return "", Listing{}, fmt.Errorf("%s: unknown listing %q (hot, new, top, rising)", name, sort)
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", Listing{}, fmt.Errorf("%s: %w", name, err)
+	}
+	if t := query.Get("t"); t != "" {
+		if listing.Sort != "top" || !listingTimes[t] {
+			return "", Listing{}, fmt.Errorf("%s: t=%s is only valid for top (hour, day, week, month, year, all)", name, t)
+		}
+		listing.Time = t
+	}
+	if listing.From, err = parseListingDate(query.Get("from"), false); err != nil {
+		return "", Listing{}, fmt.Errorf("%s: from: %w", name, err)
+	}
+	if listing.To, err = parseListingDate(query.Get("to"), true); err != nil {
+		return "", Listing{}, fmt.Errorf("%s: to: %w", name, err)
+	}
+	if !listing.From.IsZero() && !listing.To.IsZero() && listing.To.Before(listing.From) {
+		return "", Listing{}, fmt.Errorf("%s: to is before from", name)
+	}
+	return name, listing, nil
+}
+
+// parseListingDate - дата или время RFC 3339; дата в to означает конец дня
+func parseListingDate(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
+}
+
+// Chronological - посты идут от новых к старым (new): дальше первого уже
+// отправленного поста и первого поста раньше From листать незачем
+func (l Listing) Chronological() bool {
+	return l.Sort == "new"
+}
+
+// URL - адрес страницы выборки в OAuth API; after - fullname последнего поста прошлой страницы
+func (l Listing) URL(subreddit string, limit int, after string) string {
+	params := url.Values{}
+	params.Set("limit", fmt.Sprint(limit))
+	if l.Sort == "top" {
+		params.Set("t", l.window())
+	}
+	if after != "" {
+		params.Set("after", after)
+	}
+	return fmt.Sprintf("https://oauth.reddit.com/r/%s/%s?%s", subreddit, l.Sort, params.Encode())
+}
+
+func (l Listing) window() string {
+	if l.Time == "" {
+		return "day"
+	}
+	return l.Time
+}
+
+// InRange - пост создан в диапазоне From..To
+func (l Listing) InRange(created time.Time) bool {
+	if !l.From.IsZero() && created.Before(l.From) {
+		return false
+	}
+	return l.To.IsZero() || !created.After(l.To)
+}
+
+// String - выборка в формате preferred_channels без имени сабреддита
+func (l Listing) String() string {
+	s := l.Sort
+	params := url.Values{}
+	if l.Sort == "top" {
+		params.Set("t", l.window())
+	}
+	if !l.From.IsZero() {
+		params.Set("from", l.From.Format(time.RFC3339))
+	}
+	if !l.To.IsZero() {
+		params.Set("to", l.To.Format(time.RFC3339))
+	}
+	if len(params) > 0 {
+		s += "?" + params.Encode()
+	}
+	return s
+}
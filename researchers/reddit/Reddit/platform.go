@@ -0,0 +1,206 @@
+package Reddit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"apiclient"
+	"researcher"
+	"researcher-reddit/token"
+)
+
+// Platform - адаптер Reddit для researcher.Runner
+type Platform struct {
+	accessToken string
+	// state - то же состояние, что у Runner: по нему обход останавливается на уже
+	// отправленных постах
+	state *researcher.State
+}
+
+// NewPlatform создаёт адаптер; токен получается при каждом обходе (ListChannels)
+func NewPlatform(state *researcher.State) *Platform {
+	return &Platform{state: state}
+}
+
+// subscription - канал: сабреддит и его выборка из preferred_channels
+type subscription struct {
+	Subreddit
+	Listing Listing
+}
+
+func (p *Platform) Name() string { return "Reddit" }
+
+func (p *Platform) Source() apiclient.Source {
+	return apiclient.Source{Name: "Reddit", Address: "reddit.com", Topic: "social"}
+}
+
+func (p *Platform) ListChannels(ctx context.Context, conf researcher.Config) ([]researcher.Channel, error) {
+	tok, err := token.GetAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения токена: %w", err)
+	}
+	p.accessToken = tok
+
+	var subs []subscription
+	if len(conf.PreferredChannels) == 0 {
+		var popular []Subreddit
+		if _, popular, err = GetTopPopularGroups(tok, conf.ChannelLimit); err != nil {
+			return nil, err
+		}
+		for _, sr := range popular {
+			subs = append(subs, subscription{Subreddit: sr, Listing: DefaultListing})
+		}
+	} else if subs, err = p.preferred(tok, conf.PreferredChannels); err != nil {
+		return nil, err
+	}
+
+	channels := make([]researcher.Channel, 0, len(subs))
+	for _, sub := range subs {
+		channels = append(channels, researcher.Channel{
+			ExternalID:  sub.DisplayName,
+			Name:        sub.DisplayName,
+			Link:        fmt.Sprintf("https://reddit.com%s", sub.URL),
+			Subscribers: sub.Subscribers,
+			Topic:       sub.Title,
+			Raw:         sub,
+		})
+	}
+	return channels, nil
+}
+
+// preferred - сабреддиты из preferred_channels с их выборками (см. ParseChannel).
+// Один сабреддит с разными выборками - разные каналы с общим списком отправленных постов.
+func (p *Platform) preferred(tok string, specs []string) ([]subscription, error) {
+	var names []string
+	listings := map[string][]Listing{}
+	for _, spec := range specs {
+		name, listing, err := ParseChannel(spec)
+		if err != nil {
+			fmt.Printf("WARNING: preferred_channels: %v\n", err)
+			continue
+		}
+		key := strings.ToLower(name)
+		if _, ok := listings[key]; !ok {
+			names = append(names, name)
+		}
+		listings[key] = append(listings[key], listing)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("preferred_channels: no valid subreddits")
+	}
+
+	_, found, err := FetchSubreddits(tok, names)
+	if err != nil {
+		return nil, err
+	}
+	var subs []subscription
+	for _, sr := range found {
+		for _, listing := range listings[strings.ToLower(sr.DisplayName)] {
+			subs = append(subs, subscription{Subreddit: sr, Listing: listing})
+		}
+	}
+	return subs, nil
+}
+
+// FetchPosts - посты выборки канала, ещё не отправленные в прошлых обходах
+func (p *Platform) FetchPosts(ctx context.Context, ch researcher.Channel, limit int) ([]researcher.Post, error) {
+	sub := ch.Raw.(subscription)
+	listing := sub.Listing
+
+	// Уже полученными считаются только посты, отмеченные Runner-ом после
+	// отправки: пост, который не удалось сохранить, придёт в следующем обходе
+	seen := func(post Post) bool {
+		_, ok := p.state.PostID(ch.ExternalID, post.ID)
+		return ok
+	}
+
+	fmt.Printf("r/%s: %s\n", ch.Name, listing)
+	_, redditPosts, err := FetchListing(p.accessToken, ch.Name, listing, seen, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]researcher.Post, 0, len(redditPosts))
+	for _, post := range redditPosts {
+		var author *apiclient.Author
+		if post.AuthorName != "" && post.AuthorName != "[deleted]" {
+			author = &apiclient.Author{
+				Name:       post.AuthorName,
+				Platform:   "reddit",
+				ExternalID: post.AuthorFullname,
+				ProfileURL: "https://www.reddit.com/user/" + post.AuthorName,
+			}
+		}
+		posts = append(posts, researcher.Post{
+			ExternalID: post.ID,
+			Title:      post.Title,
+			Text:       post.Text,
+			URL:        post.URL,
+			Author:     author,
+			CreatedAt:  time.Unix(int64(post.Date), 0),
+			Likes:      post.Votes,
+			Comments:   post.Comments,
+			NSFW:       post.NSFW,
+			Metadata:   post.Metadata(),
+			Raw:        post,
+		})
+	}
+	return posts, nil
+}
+
+func (p *Platform) FetchComments(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Comment, error) {
+	_, comments, err := FetchComments(p.accessToken, ch.Name, post.ExternalID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return convertComments(comments), nil
+}
+
+// convertComments переводит дерево комментариев (Thread.Items) в общий формат
+func convertComments(comments []Comment) []researcher.Comment {
+	if len(comments) == 0 {
+		return nil
+	}
+	converted := make([]researcher.Comment, 0, len(comments))
+	for _, c := range comments {
+		converted = append(converted, researcher.Comment{
+			ExternalID: c.ID,
+			Author:     c.AuthorName,
+			Text:       c.Text,
+			Likes:      c.Score,
+			CreatedAt:  time.Unix(int64(c.CreatedUTC), 0),
+			Replies:    convertComments(c.Thread.Items),
+		})
+	}
+	return converted
+}
+
+func (p *Platform) FetchMedia(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Media, error) {
+	_, redditMedia, err := FetchPostMedia(p.accessToken, ch.Name, post.ExternalID, limit)
+	if err != nil {
+		return nil, err
+	}
+	media := make([]researcher.Media, 0, len(redditMedia))
+	for _, m := range redditMedia {
+		media = append(media, researcher.Media{Type: m.Type, URL: m.URL})
+	}
+	return media, nil
+}
+
+// EnrichAuthor дополняет автора профилем Reddit: ID аккаунта и карма.
+// Удалённые и заблокированные аккаунты возвращают ошибку.
+func (p *Platform) EnrichAuthor(ctx context.Context, author *apiclient.Author) error {
+	user, err := FetchUser(p.accessToken, author.Name)
+	if err != nil {
+		return err
+	}
+	karma := user.Karma()
+	if karma < 0 {
+		karma = 0
+	}
+	author.ExternalID = "t2_" + user.ID
+	author.PlatformReputation = &karma
+	return nil
+}
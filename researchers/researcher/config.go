@@ -0,0 +1,103 @@
+package researcher
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config - настройки researcher-а из config/researchers.xml (раздел <source name="...">).
+// Описание параметров - в researchers/README.md.
+type Config struct {
+	ChannelLimit      int
+	PostLimit         int
+	CommentLimit      int
+	MediaLimit        int
+	PreferredChannels []string
+	ResearchPeriod    time.Duration
+}
+
+type configXML struct {
+	XMLName xml.Name    `xml:"config"`
+	Source  []sourceXML `xml:"source"`
+}
+
+type sourceXML struct {
+	Name              string `xml:"name,attr"`
+	ChannelLimit      string `xml:"channel_limit"`
+	PostLimit         string `xml:"post_limit"`
+	CommentLimit      string `xml:"comment_limit"`
+	MediaLimit        string `xml:"media_limit"`
+	PreferredChannels string `xml:"preferred_channels"`
+	ResearchPeriod    string `xml:"research_period"`
+}
+
+// LoadConfig читает файл конфигурации и возвращает раздел источника name
+func LoadConfig(path, name string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return ParseConfig(data, name)
+}
+
+// ParseConfig разбирает раздел <source name="name"> и проверяет значения
+func ParseConfig(data []byte, name string) (Config, error) {
+	var parsed configXML
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return Config{}, err
+	}
+
+	for _, src := range parsed.Source {
+		if src.Name != name {
+			continue
+		}
+		var conf Config
+		var period int
+		fields := []struct {
+			name  string
+			value string
+			dst   *int
+		}{
+			{"channel_limit", src.ChannelLimit, &conf.ChannelLimit},
+			{"post_limit", src.PostLimit, &conf.PostLimit},
+			{"comment_limit", src.CommentLimit, &conf.CommentLimit},
+			{"media_limit", src.MediaLimit, &conf.MediaLimit},
+			{"research_period", src.ResearchPeriod, &period},
+		}
+		for _, f := range fields {
+			n, err := strconv.Atoi(strings.TrimSpace(f.value))
+			if err != nil {
+				return Config{}, fmt.Errorf("config: %s: %w", f.name, err)
+			}
+			if n < 0 {
+				return Config{}, fmt.Errorf("config: %s must be >= 0", f.name)
+			}
+			*f.dst = n
+		}
+		conf.ResearchPeriod = time.Duration(period) * time.Second
+		conf.PreferredChannels = splitList(src.PreferredChannels)
+		return conf, nil
+	}
+	return Config{}, fmt.Errorf("config: there is no %s config", name)
+}
+
+// String - настройки одной строкой для лога
+func (c Config) String() string {
+	return fmt.Sprintf("channel_limit=%d post_limit=%d comment_limit=%d media_limit=%d preferred_channels=%v research_period=%v",
+		c.ChannelLimit, c.PostLimit, c.CommentLimit, c.MediaLimit, c.PreferredChannels, c.ResearchPeriod)
+}
+
+// splitList - список через запятую без пустых элементов
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,7 @@
+module researcher
+
+go 1.25.1
+
+require apiclient v0.0.0-00010101000000-000000000000
+
+replace apiclient => ../../apiclient
@@ -0,0 +1,51 @@
+package htmlq
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseCount разбирает счётчики со страниц сайтов: "154", "-3", "152 340",
+// "98,5K", "1.2M", "1,2 млн"; false - в начале строки нет числа
+func ParseCount(s string) (int, bool) {
+	// Пробелы-разделители разрядов внутри числа убираем, остальные нужны для суффикса
+	runes := []rune(strings.ToLower(strings.TrimSpace(s)))
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsSpace(r) && i > 0 && i+1 < len(runes) && unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1]) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	s = b.String()
+
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == ',' || s[end] == '.' || end == 0 && (s[end] == '-' || s[end] == '+')) {
+		end++
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(s[:end], ",", "."), 64)
+	if err != nil {
+		return 0, false
+	}
+	multiplier := 1.0
+	rest := strings.TrimLeftFunc(s[end:], unicode.IsSpace)
+	for _, suffix := range []struct {
+		text  string
+		value float64
+	}{{"млн", 1e6}, {"тыс", 1e3}, {"k", 1e3}, {"к", 1e3}, {"m", 1e6}, {"м", 1e6}} {
+		// "152 340 подписчиков" - не "к": после суффикса не должно быть букв
+		if strings.HasPrefix(rest, suffix.text) && !startsWithLetter(rest[len(suffix.text):]) {
+			multiplier = suffix.value
+			break
+		}
+	}
+	return int(f * multiplier), true
+}
+
+func startsWithLetter(s string) bool {
+	for _, r := range s {
+		return unicode.IsLetter(r)
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package htmlq
+
+import "testing"
+
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   int
+		wantOK bool
+	}{
+		{"154", 154, true},
+		{"-3", -3, true},
+		{"+12", 12, true},
+		{" 152 340 ", 152340, true},
+		{"152 340 подписчиков", 152340, true},
+		{"98,5K", 98500, true},
+		{"98.5k подписчиков", 98500, true},
+		{"1.2M", 1200000, true},
+		{"1,2 млн подписчиков", 1200000, true},
+		{"12 тыс.", 12000, true},
+		{"3к", 3000, true},
+		// Слово после числа - не суффикс
+		{"5 комментариев", 5, true},
+		{"7 монет", 7, true},
+		{"", 0, false},
+		{"нет", 0, false},
+		{"-", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseCount(tt.in)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseCount(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package htmlq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"researcher"
+)
+
+// Fetcher - источник HTML-страниц: сайт (HTTPFetcher) или записанные
+// страницы-фикстуры (DirFetcher) для работы без сети
+type Fetcher interface {
+	Fetch(ctx context.Context, pageURL string) (*Node, error)
+}
+
+// StatusError - сайт ответил не 200
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GET %s: status %d", e.URL, e.Code)
+}
+
+// IsNotFound - страницы нет: 404 сайта или отсутствующая фикстура
+func IsNotFound(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code == http.StatusNotFound
+	}
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+// HTTPFetcher загружает страницы сайта; страницы в windows-1251 перекодируются в UTF-8
+type HTTPFetcher struct {
+	Client    *http.Client
+	UserAgent string
+	// Limiter - общий ограничитель запросов к сайту; nil - без ограничения
+	Limiter *researcher.Limiter
+	// Attempts - попыток на страницу при сетевых ошибках, 429 и 5xx
+	Attempts int
+}
+
+// NewHTTPFetcher создаёт загрузчик с таймаутом 30 секунд и тремя попытками
+func NewHTTPFetcher(userAgent string, limiter *researcher.Limiter) *HTTPFetcher {
+	return &HTTPFetcher{
+		Client:    &http.Client{Timeout: 30 * time.Second},
+		UserAgent: userAgent,
+		Limiter:   limiter,
+		Attempts:  3,
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, pageURL string) (*Node, error) {
+	var doc *Node
+	err := researcher.Retry(ctx, f.Attempts, 5*time.Second, func() error {
+		if err := f.Limiter.Wait(ctx); err != nil {
+			return researcher.Permanent(err)
+		}
+		body, err := f.get(ctx, pageURL)
+		if err != nil {
+			return err
+		}
+		doc = ParseString(body)
+		return nil
+	})
+	return doc, err
+}
+
+func (f *HTTPFetcher) get(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", researcher.Permanent(err)
+	}
+	req.Header.Set("User-Agent", f.UserAgent)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &StatusError{URL: pageURL, Code: resp.StatusCode}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			f.Limiter.Slow(10 * time.Second)
+			return "", statusErr
+		}
+		return "", researcher.Permanent(statusErr)
+	}
+	return Decode(raw, resp.Header.Get("Content-Type")), nil
+}
+
+var metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset=["']?([\w-]+)`)
+
+// Decode переводит страницу в UTF-8 по charset из Content-Type или <meta>.
+// Поддерживается windows-1251 (старые русскоязычные сайты), остальное считается UTF-8.
+func Decode(raw []byte, contentType string) string {
+	charset := ""
+	if i := strings.Index(strings.ToLower(contentType), "charset="); i >= 0 {
+		charset = strings.Trim(contentType[i+len("charset="):], `"' `)
+	} else if m := metaCharset.FindSubmatch(raw[:min(len(raw), 2048)]); m != nil {
+		charset = string(m[1])
+	}
+	switch strings.ToLower(charset) {
+	case "windows-1251", "cp1251", "cp-1251":
+		return decodeWindows1251(raw)
+	}
+	return string(bytes.ToValidUTF8(raw, []byte("�")))
+}
+
+// DirFetcher читает страницы из каталога фикстур: имя файла - FixtureName(url)
+type DirFetcher struct {
+	Dir string
+}
+
+func (f DirFetcher) Fetch(ctx context.Context, pageURL string) (*Node, error) {
+	raw, err := os.ReadFile(filepath.Join(f.Dir, FixtureName(pageURL)))
+	if err != nil {
+		return nil, err
+	}
+	return ParseString(Decode(raw, "")), nil
+}
+
+var nonName = regexp.MustCompile(`[^\p{L}\p{N}@]+`)
+
+// FixtureName - имя файла фикстуры для страницы: путь и запрос URL, где
+// все разделители заменены на "_" (https://pikabu.ru/community/science?page=2 ->
+// community_science_page_2.html); главная страница - index.html
+func FixtureName(pageURL string) string {
+	name := pageURL
+	if u, err := url.Parse(pageURL); err == nil {
+		name = u.Path
+		if u.RawQuery != "" {
+			name += "?" + u.RawQuery
+		}
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+	}
+	name = strings.Trim(nonName.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = "index"
+	}
+	return name + ".html"
+}
+
+// decodeWindows1251 - таблица верхней половины windows-1251 (0x80-0xFF)
+func decodeWindows1251(raw []byte) string {
+	var b strings.Builder
+	b.Grow(len(raw) * 2)
+	for _, c := range raw {
+		if c < 0x80 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteRune(cp1251[c-0x80])
+	}
+	return b.String()
+}
+
+var cp1251 = [128]rune{
+	'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+	'ђ', '‘', '’', '“', '”', '•', '–', '—', '�', '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+	'\u00a0', 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', '\u00ad', '®', 'Ї',
+	'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+	'А', 'Б', 'В', 'Г', 'Д', 'Е', 'Ж', 'З', 'И', 'Й', 'К', 'Л', 'М', 'Н', 'О', 'П',
+	'Р', 'С', 'Т', 'У', 'Ф', 'Х', 'Ц', 'Ч', 'Ш', 'Щ', 'Ъ', 'Ы', 'Ь', 'Э', 'Ю', 'Я',
+	'а', 'б', 'в', 'г', 'д', 'е', 'ж', 'з', 'и', 'й', 'к', 'л', 'м', 'н', 'о', 'п',
+	'р', 'с', 'т', 'у', 'ф', 'х', 'ц', 'ч', 'ш', 'щ', 'ъ', 'ы', 'ь', 'э', 'ю', 'я',
+}
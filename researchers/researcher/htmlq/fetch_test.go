@@ -0,0 +1,122 @@
+package htmlq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFixtureName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://pikabu.ru/community/science", "community_science.html"},
+		{"https://pikabu.ru/community/science?page=2", "community_science_page_2.html"},
+		{"https://pikabu.ru/tag/%D0%9D%D0%BE%D0%B2%D0%BE%D1%81%D1%82%D0%B8", "tag_Новости.html"},
+		{"https://pikabu.ru/@astro_fan", "@astro_fan.html"},
+		{"https://pikabu.ru/", "index.html"},
+		{"https://t.me/s/tginfo?before=5001", "s_tginfo_before_5001.html"},
+	}
+	for _, tt := range tests {
+		if got := FixtureName(tt.url); got != tt.want {
+			t.Errorf("FixtureName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	// "Привет" в windows-1251
+	cp1251 := []byte{0xCF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2}
+	tests := []struct {
+		name        string
+		raw         []byte
+		contentType string
+		want        string
+	}{
+		{"utf-8", []byte("Привет"), "text/html; charset=utf-8", "Привет"},
+		{"header charset", cp1251, "text/html; charset=windows-1251", "Привет"},
+		{"meta charset", append([]byte(`<meta charset="windows-1251">`), cp1251...), "", `<meta charset="windows-1251">Привет`},
+		{"http-equiv", append([]byte(`<meta http-equiv="Content-Type" content="text/html; charset=cp1251">`), cp1251...), "",
+			`<meta http-equiv="Content-Type" content="text/html; charset=cp1251">Привет`},
+		// Без charset страница считается UTF-8, битые байты заменяются
+		{"invalid utf-8", []byte{'a', 0xFF, 'b'}, "", "a�b"},
+	}
+	for _, tt := range tests {
+		if got := Decode(tt.raw, tt.contentType); got != tt.want {
+			t.Errorf("%s: Decode = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDirFetcher(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "community_science.html"), []byte(`<h1>Наука</h1>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := DirFetcher{Dir: dir}
+
+	doc, err := f.Fetch(context.Background(), "https://pikabu.ru/community/science")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.First("h1").Text(); got != "Наука" {
+		t.Errorf("h1 = %q", got)
+	}
+	// Отсутствующая фикстура - как 404 сайта
+	if _, err := f.Fetch(context.Background(), "https://pikabu.ru/community/science?page=2"); !IsNotFound(err) {
+		t.Errorf("missing fixture err = %v, want not found", err)
+	}
+}
+
+func TestHTTPFetcher(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/page":
+			if r.Header.Get("User-Agent") != "test-agent" {
+				t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+			}
+			w.Header().Set("Content-Type", "text/html; charset=windows-1251")
+			_, _ = w.Write([]byte{'<', 'p', '>', 0xC4, 0xE0, '<', '/', 'p', '>'}) // "Да"
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := NewHTTPFetcher("test-agent", nil)
+	ctx := context.Background()
+
+	doc, err := f.Fetch(ctx, srv.URL+"/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.First("p").Text(); got != "Да" {
+		t.Errorf("p = %q, want Да", got)
+	}
+
+	// 404 не повторяется
+	requests.Store(0)
+	_, err = f.Fetch(ctx, srv.URL+"/missing")
+	var statusErr *StatusError
+	if !IsNotFound(err) || !errors.As(err, &statusErr) || requests.Load() != 1 {
+		t.Errorf("404: err = %v after %d requests", err, requests.Load())
+	}
+
+	// 5xx возвращается как StatusError; паузу перед повтором (5 секунд) проверка не ждёт
+	f.Attempts = 1
+	requests.Store(0)
+	_, err = f.Fetch(ctx, srv.URL+"/down")
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusServiceUnavailable || IsNotFound(err) {
+		t.Errorf("503: err = %v", err)
+	}
+}
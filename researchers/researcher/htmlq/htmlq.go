@@ -0,0 +1,275 @@
+// Package htmlq - разбор HTML без внешних зависимостей и поиск элементов простыми
+// CSS-селекторами. Нужен researcher-ам платформ без API, которые читают страницы сайта.
+//
+// Разбор терпим к ошибкам разметки: незакрытые теги закрываются концом родителя,
+// лишние закрывающие теги пропускаются.
+package htmlq
+
+import (
+	"html"
+	"io"
+	"strings"
+)
+
+// NodeType - тип узла дерева
+type NodeType int
+
+const (
+	DocumentNode NodeType = iota
+	ElementNode
+	TextNode
+)
+
+// Node - узел дерева документа
+type Node struct {
+	Type NodeType
+	// Tag - имя тега в нижнем регистре (ElementNode)
+	Tag   string
+	Attrs map[string]string
+	// Data - текст узла без HTML-сущностей (TextNode)
+	Data     string
+	Parent   *Node
+	Children []*Node
+}
+
+// Элементы без содержимого и закрывающего тега
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
+
+// Элементы, содержимое которых не разбирается как разметка
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// Элементы, которые закрываются следующим таким же (<li>a<li>b)
+var autoCloseSame = map[string]bool{
+	"p": true, "li": true, "option": true, "tr": true, "td": true, "th": true, "dt": true, "dd": true,
+}
+
+// Блочные элементы, закрывающие незакрытый <p>
+var closesParagraph = map[string]bool{
+	"div": true, "p": true, "ul": true, "ol": true, "table": true, "section": true,
+	"article": true, "header": true, "footer": true, "blockquote": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "figure": true,
+}
+
+// Parse читает и разбирает HTML-документ
+func Parse(r io.Reader) (*Node, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseString(string(data)), nil
+}
+
+// ParseString разбирает HTML-документ из строки
+func ParseString(s string) *Node {
+	p := &parser{s: s, doc: &Node{Type: DocumentNode}}
+	p.stack = []*Node{p.doc}
+	p.run()
+	return p.doc
+}
+
+type parser struct {
+	s     string
+	pos   int
+	doc   *Node
+	stack []*Node
+}
+
+func (p *parser) top() *Node { return p.stack[len(p.stack)-1] }
+
+func (p *parser) append(n *Node) {
+	parent := p.top()
+	n.Parent = parent
+	parent.Children = append(parent.Children, n)
+}
+
+func (p *parser) text(raw string, unescape bool) {
+	if raw == "" {
+		return
+	}
+	if unescape {
+		raw = html.UnescapeString(raw)
+	}
+	p.append(&Node{Type: TextNode, Data: raw})
+}
+
+func (p *parser) run() {
+	for p.pos < len(p.s) {
+		lt := strings.IndexByte(p.s[p.pos:], '<')
+		if lt < 0 {
+			p.text(p.s[p.pos:], true)
+			return
+		}
+		p.text(p.s[p.pos:p.pos+lt], true)
+		p.pos += lt
+		rest := p.s[p.pos:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				return
+			}
+			p.pos += 4 + end + 3
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			p.skipPast('>')
+		case strings.HasPrefix(rest, "</"):
+			p.pos += 2
+			name := strings.ToLower(p.readName())
+			p.skipPast('>')
+			p.close(name)
+		case len(rest) > 1 && isLetter(rest[1]):
+			p.pos++
+			p.openTag()
+		default:
+			p.text("<", false)
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) openTag() {
+	name := strings.ToLower(p.readName())
+	el := &Node{Type: ElementNode, Tag: name, Attrs: map[string]string{}}
+	selfClosing := p.readAttrs(el)
+
+	if closesParagraph[name] && p.top().Tag == "p" {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	if autoCloseSame[name] && p.top().Tag == name {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	p.append(el)
+	if voidElements[name] || selfClosing {
+		return
+	}
+
+	if rawTextElements[name] {
+		end := indexFold(p.s[p.pos:], "</"+name)
+		if end < 0 {
+			end = len(p.s) - p.pos
+		}
+		content := p.s[p.pos : p.pos+end]
+		p.pos += end
+		p.stack = append(p.stack, el)
+		// Текст <title> и <textarea> может содержать сущности, скрипты и стили - нет
+		p.text(content, name == "title" || name == "textarea")
+		p.stack = p.stack[:len(p.stack)-1]
+		if p.pos < len(p.s) {
+			p.skipPast('>')
+		}
+		return
+	}
+	p.stack = append(p.stack, el)
+}
+
+// readAttrs читает атрибуты до конца тега; true - тег самозакрывающийся (<br/>)
+func (p *parser) readAttrs(el *Node) bool {
+	for p.pos < len(p.s) {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return false
+		}
+		switch c := p.s[p.pos]; {
+		case c == '>':
+			p.pos++
+			return false
+		case c == '/':
+			p.pos++
+			if p.pos < len(p.s) && p.s[p.pos] == '>' {
+				p.pos++
+				return true
+			}
+			continue
+		}
+
+		start := p.pos
+		for p.pos < len(p.s) && !isSpace(p.s[p.pos]) && !strings.ContainsRune("=/>", rune(p.s[p.pos])) {
+			p.pos++
+		}
+		name := strings.ToLower(p.s[start:p.pos])
+		if name == "" {
+			p.pos++
+			continue
+		}
+		p.skipSpace()
+		value := ""
+		if p.pos < len(p.s) && p.s[p.pos] == '=' {
+			p.pos++
+			p.skipSpace()
+			value = p.readValue()
+		}
+		if _, dup := el.Attrs[name]; !dup {
+			el.Attrs[name] = html.UnescapeString(value)
+		}
+	}
+	return false
+}
+
+func (p *parser) readValue() string {
+	if p.pos >= len(p.s) {
+		return ""
+	}
+	if q := p.s[p.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(p.s[p.pos+1:], q)
+		if end < 0 {
+			v := p.s[p.pos+1:]
+			p.pos = len(p.s)
+			return v
+		}
+		v := p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return v
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !isSpace(p.s[p.pos]) && p.s[p.pos] != '>' {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// close закрывает ближайший открытый элемент name вместе с вложенными незакрытыми
+func (p *parser) close(name string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].Tag == name {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func (p *parser) readName() string {
+	start := p.pos
+	for p.pos < len(p.s) && (isLetter(p.s[p.pos]) || isDigit(p.s[p.pos]) || p.s[p.pos] == '-' || p.s[p.pos] == ':') {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && isSpace(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) skipPast(c byte) {
+	end := strings.IndexByte(p.s[p.pos:], c)
+	if end < 0 {
+		p.pos = len(p.s)
+		return
+	}
+	p.pos += end + 1
+}
+
+func indexFold(s, substr string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isSpace(c byte) bool  { return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' }
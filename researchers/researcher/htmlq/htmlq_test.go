@@ -0,0 +1,85 @@
+package htmlq
+
+import (
+	"reflect"
+	"testing"
+)
+
+// tags - имена элементов дерева в порядке документа
+func tags(n *Node) []string {
+	var out []string
+	for _, el := range n.Find("*") {
+		out = append(out, el.Tag)
+	}
+	return out
+}
+
+func TestParseTolerant(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []string
+	}{
+		{"nested", `<div><p><b>x</b></p></div>`, []string{"div", "p", "b"}},
+		{"void elements", `<p>a<br>b<img src=x>c</p>`, []string{"p", "br", "img"}},
+		{"self closing", `<div><span/>x</div>`, []string{"div", "span"}},
+		// <li> и <p> закрываются следующими такими же
+		{"auto close li", `<ul><li>a<li>b</ul>`, []string{"ul", "li", "li"}},
+		{"block closes p", `<p>a<div>b</div>`, []string{"p", "div"}},
+		{"unclosed", `<div><span>x</div><i>y</i>`, []string{"div", "span", "i"}},
+		{"stray close", `</span><b>x</b></div>`, []string{"b"}},
+		{"comment", `<!-- <b>no</b> --><i>x</i>`, []string{"i"}},
+		{"doctype", `<!DOCTYPE html><HTML><Body></body></html>`, []string{"html", "body"}},
+	}
+	for _, tt := range tests {
+		if got := tags(ParseString(tt.html)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: tags = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseNesting(t *testing.T) {
+	doc := ParseString(`<ul><li>a<li>b</ul>`)
+	ul := doc.First("ul")
+	if items := ul.Elements(); len(items) != 2 || items[0].Text() != "a" || items[1].Text() != "b" {
+		t.Errorf("ul children = %+v", items)
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	el := ParseString(`<a HREF="/x?a=1&amp;b=2" data-x='q"q' checked title=plain class="one  two">t</a>`).First("a")
+	want := map[string]string{
+		"href":    "/x?a=1&b=2",
+		"data-x":  `q"q`,
+		"checked": "",
+		"title":   "plain",
+		"class":   "one  two",
+	}
+	if !reflect.DeepEqual(el.Attrs, want) {
+		t.Errorf("attrs = %v, want %v", el.Attrs, want)
+	}
+	if !el.HasAttr("checked") || el.HasAttr("missing") || !el.HasClass("two") || el.HasClass("one two") {
+		t.Error("HasAttr or HasClass mismatch")
+	}
+}
+
+func TestParseRawText(t *testing.T) {
+	doc := ParseString(`<title>A &amp; B</title><script>if (a < b && "</div>") {}</script><p>x</p>`)
+	if got := doc.First("title").Text(); got != "A & B" {
+		t.Errorf("title = %q", got)
+	}
+	// Разметка внутри <script> не разбирается
+	if script := doc.First("script"); len(script.Children) != 1 || script.Children[0].Data != `if (a < b && "</div>") {}` {
+		t.Errorf("script = %+v", script.Children)
+	}
+	if doc.First("p").Text() != "x" {
+		t.Error("element after script lost")
+	}
+}
+
+func TestParseEntities(t *testing.T) {
+	doc := ParseString(`<p>Tom &amp; Jerry &lt;3 &#x2014; &laquo;ok&raquo; 1 < 2</p>`)
+	if got := doc.First("p").Text(); got != "Tom & Jerry <3 — «ok» 1 < 2" {
+		t.Errorf("text = %q", got)
+	}
+}
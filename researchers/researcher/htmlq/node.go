@@ -0,0 +1,286 @@
+package htmlq
+
+import (
+	"strings"
+)
+
+// Attr - значение атрибута или "", если его нет
+func (n *Node) Attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	return n.Attrs[name]
+}
+
+// HasAttr - есть ли у элемента атрибут (в том числе пустой)
+func (n *Node) HasAttr(name string) bool {
+	if n == nil {
+		return false
+	}
+	_, ok := n.Attrs[name]
+	return ok
+}
+
+// HasClass - есть ли у элемента класс
+func (n *Node) HasClass(class string) bool {
+	if n == nil {
+		return false
+	}
+	for _, c := range strings.Fields(n.Attrs["class"]) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Elements - дочерние элементы (без текстовых узлов)
+func (n *Node) Elements() []*Node {
+	if n == nil {
+		return nil
+	}
+	var elements []*Node
+	for _, c := range n.Children {
+		if c.Type == ElementNode {
+			elements = append(elements, c)
+		}
+	}
+	return elements
+}
+
+// Text - текст элемента и его потомков; пробельные символы схлопываются в один пробел
+func (n *Node) Text() string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	n.walkText(&b, false)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Paragraphs - текст элемента с сохранением абзацев: блочные элементы и <br>
+// разделяют строки, пустые строки отбрасываются
+func (n *Node) Paragraphs() string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	n.walkText(&b, true)
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (n *Node) walkText(b *strings.Builder, keepLines bool) {
+	sep := byte(' ')
+	if keepLines {
+		sep = '\n'
+	}
+	switch n.Type {
+	case TextNode:
+		b.WriteString(n.Data)
+		return
+	case ElementNode:
+		if n.Tag == "script" || n.Tag == "style" {
+			return
+		}
+		if n.Tag == "br" {
+			b.WriteByte(sep)
+			return
+		}
+	}
+	block := n.Type == ElementNode && (closesParagraph[n.Tag] || autoCloseSame[n.Tag])
+	if block {
+		b.WriteByte(sep)
+	}
+	for _, c := range n.Children {
+		c.walkText(b, keepLines)
+	}
+	if block {
+		b.WriteByte(sep)
+	}
+}
+
+// Find - все потомки, подходящие под селектор, в порядке документа.
+// Селектор: tag, .class, #id, [attr], [attr=value], [attr^=value], [attr*=value],
+// их сочетания (a.title[href]), потомки через пробел (div.story a) и списки через запятую.
+func (n *Node) Find(selector string) []*Node {
+	if n == nil {
+		return nil
+	}
+	groups := parseSelector(selector)
+	var found []*Node
+	var walk func(*Node)
+	walk = func(cur *Node) {
+		for _, c := range cur.Children {
+			if c.Type != ElementNode {
+				continue
+			}
+			for _, g := range groups {
+				if g.match(c, n) {
+					found = append(found, c)
+					break
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// First - первый потомок, подходящий под селектор, или nil
+func (n *Node) First(selector string) *Node {
+	if found := n.Find(selector); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// Closest - сам элемент или ближайший предок, подходящий под селектор, или nil
+func (n *Node) Closest(selector string) *Node {
+	groups := parseSelector(selector)
+	for cur := n; cur != nil && cur.Type == ElementNode; cur = cur.Parent {
+		for _, g := range groups {
+			if g.match(cur, nil) {
+				return cur
+			}
+		}
+	}
+	return nil
+}
+
+// chain - цепочка простых селекторов через пробел (потомки)
+type chain []compound
+
+type compound struct {
+	tag   string
+	id    string
+	class []string
+	attrs []attrCond
+}
+
+type attrCond struct {
+	name  string
+	op    byte // 0 - есть атрибут, '=' - равен, '^' - начинается с, '*' - содержит
+	value string
+}
+
+// match проверяет n и предков до root (не включая root; nil - до корня документа)
+func (ch chain) match(n, root *Node) bool {
+	if len(ch) == 0 || !ch[len(ch)-1].match(n) {
+		return false
+	}
+	cur := n.Parent
+	for i := len(ch) - 2; i >= 0; i-- {
+		for cur != nil && cur != root && !ch[i].match(cur) {
+			cur = cur.Parent
+		}
+		if cur == nil || cur == root {
+			return false
+		}
+		cur = cur.Parent
+	}
+	return true
+}
+
+func (c compound) match(n *Node) bool {
+	if n.Type != ElementNode {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && c.tag != n.Tag {
+		return false
+	}
+	if c.id != "" && n.Attrs["id"] != c.id {
+		return false
+	}
+	for _, class := range c.class {
+		if !n.HasClass(class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		v, ok := n.Attrs[a.name]
+		if !ok {
+			return false
+		}
+		switch a.op {
+		case '=':
+			ok = v == a.value
+		case '^':
+			ok = strings.HasPrefix(v, a.value)
+		case '*':
+			ok = strings.Contains(v, a.value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func parseSelector(selector string) []chain {
+	var groups []chain
+	for _, group := range strings.Split(selector, ",") {
+		var ch chain
+		for _, part := range strings.Fields(group) {
+			ch = append(ch, parseCompound(part))
+		}
+		if len(ch) > 0 {
+			groups = append(groups, ch)
+		}
+	}
+	return groups
+}
+
+func parseCompound(s string) compound {
+	var c compound
+	i := 0
+	readIdent := func() string {
+		start := i
+		for i < len(s) && !strings.ContainsRune(".#[", rune(s[i])) {
+			i++
+		}
+		return s[start:i]
+	}
+	c.tag = strings.ToLower(readIdent())
+	for i < len(s) {
+		switch s[i] {
+		case '.':
+			i++
+			c.class = append(c.class, readIdent())
+		case '#':
+			i++
+			c.id = readIdent()
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				end = len(s) - i
+			}
+			c.attrs = append(c.attrs, parseAttrCond(s[i+1:i+end]))
+			i += end + 1
+		default:
+			i++
+		}
+	}
+	return c
+}
+
+func parseAttrCond(s string) attrCond {
+	eq := strings.IndexByte(s, '=')
+	if eq < 0 {
+		return attrCond{name: strings.ToLower(s)}
+	}
+	cond := attrCond{op: '=', value: strings.Trim(s[eq+1:], `"'`)}
+	name := s[:eq]
+	if n := len(name); n > 0 && (name[n-1] == '^' || name[n-1] == '*') {
+		cond.op = name[n-1]
+		name = name[:n-1]
+	}
+	cond.name = strings.ToLower(name)
+	return cond
+}
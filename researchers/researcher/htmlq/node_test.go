@@ -0,0 +1,110 @@
+package htmlq
+
+import (
+	"reflect"
+	"testing"
+)
+
+const page = `<html><body>
+<div id="feed" class="feed">
+  <article class="story story_hot" data-id="1">
+    <h2 class="story__title"><a class="title" href="/story/1">Первый</a></h2>
+    <div class="story__text"><p>Абзац <b>один</b></p><p>Абзац два<br>строка</p></div>
+  </article>
+  <article class="story" data-id="2">
+    <h2 class="story__title"><a class="title" href="https://example.com/2">Второй</a></h2>
+    <script>var x = "<p>не текст</p>";</script>
+  </article>
+</div>
+<a class="title" href="/outside">Вне ленты</a>
+</body></html>`
+
+func attrs(nodes []*Node, name string) []string {
+	var out []string
+	for _, n := range nodes {
+		out = append(out, n.Attr(name))
+	}
+	return out
+}
+
+func TestFind(t *testing.T) {
+	doc := ParseString(page)
+	tests := []struct {
+		selector string
+		attr     string
+		want     []string
+	}{
+		{"article", "data-id", []string{"1", "2"}},
+		{".story_hot", "data-id", []string{"1"}},
+		{"article.story.story_hot", "data-id", []string{"1"}},
+		{"#feed", "class", []string{"feed"}},
+		{"[data-id=2]", "data-id", []string{"2"}},
+		{`a[href^="/"]`, "href", []string{"/story/1", "/outside"}},
+		{"a[href*=example]", "href", []string{"https://example.com/2"}},
+		{"a[href]", "href", []string{"/story/1", "https://example.com/2", "/outside"}},
+		// Потомки через пробел: ссылка вне ленты не подходит
+		{"#feed a.title", "href", []string{"/story/1", "https://example.com/2"}},
+		{"div article h2 a", "href", []string{"/story/1", "https://example.com/2"}},
+		// Списки через запятую - в порядке документа
+		{"#feed, a[href=/outside]", "id", []string{"feed", ""}},
+		{"section", "id", nil},
+	}
+	for _, tt := range tests {
+		if got := attrs(doc.Find(tt.selector), tt.attr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Find(%q) = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestFindWithinNode(t *testing.T) {
+	doc := ParseString(page)
+	second := doc.Find("article")[1]
+	// Предки выше узла поиска в селекторе не учитываются
+	if got := second.Find("#feed a"); len(got) != 0 {
+		t.Errorf("search escaped the node: %v", attrs(got, "href"))
+	}
+	if got := second.First("a.title").Attr("href"); got != "https://example.com/2" {
+		t.Errorf("First = %q", got)
+	}
+}
+
+func TestNilNode(t *testing.T) {
+	var n *Node
+	if n.Attr("x") != "" || n.HasClass("x") || n.HasAttr("x") || n.Text() != "" || n.Paragraphs() != "" ||
+		n.Find("a") != nil || n.First("a") != nil || n.Elements() != nil {
+		t.Error("nil node is not empty")
+	}
+	// Цепочка First по отсутствующим элементам не паникует
+	if got := ParseString(page).First(".missing").First("a").Attr("href"); got != "" {
+		t.Errorf("missing chain = %q", got)
+	}
+}
+
+func TestClosest(t *testing.T) {
+	doc := ParseString(page)
+	link := doc.First("b")
+	if got := link.Closest("article").Attr("data-id"); got != "1" {
+		t.Errorf("Closest(article) = %q", got)
+	}
+	if got := doc.First("article").Closest("article"); got != doc.First("article") {
+		t.Error("Closest does not match the element itself")
+	}
+	if link.Closest("section") != nil {
+		t.Error("Closest found a missing ancestor")
+	}
+}
+
+func TestTextAndParagraphs(t *testing.T) {
+	doc := ParseString(page)
+	text := doc.First(".story__text")
+	if got := text.Text(); got != "Абзац один Абзац два строка" {
+		t.Errorf("Text = %q", got)
+	}
+	if got := text.Paragraphs(); got != "Абзац один\nАбзац два\nстрока" {
+		t.Errorf("Paragraphs = %q", got)
+	}
+	// Скрипты в текст не попадают
+	if got := doc.Find("article")[1].Text(); got != "Второй" {
+		t.Errorf("Text with script = %q", got)
+	}
+}
@@ -0,0 +1,287 @@
+package researcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"apiclient"
+)
+
+const (
+	// maxTitleLen - длина заголовка поста на сервере (posts.title VARCHAR(255))
+	maxTitleLen = 255
+	// generatedTitleLen - длина заголовка из начала текста для постов без заголовка
+	generatedTitleLen = 100
+	// maxMediaTitleLen - длина подписи вложения на сервере (media.title VARCHAR(500))
+	maxMediaTitleLen = 500
+	// AuthorProfileTTL - профиль автора обновляется не чаще
+	AuthorProfileTTL = 24 * time.Hour
+	timestampLayout  = "2006-01-02 15:04:05"
+)
+
+// Ingest - отправка данных researcher-а на сервер через apiclient.
+// Источник и каналы не дублируются: существующие находятся по адресу и ссылке.
+type Ingest struct {
+	api *apiclient.Client
+	// Log - необязательный журнал запросов к серверу
+	Log func(endpoint string, data interface{}, err error)
+
+	mu       sync.Mutex
+	sources  map[string]int
+	channels map[string]int
+	authors  map[string]cachedAuthor
+}
+
+type cachedAuthor struct {
+	id        int
+	refreshed time.Time
+}
+
+// NewIngest создаёт клиент отправки поверх api
+func NewIngest(api *apiclient.Client) *Ingest {
+	return &Ingest{api: api, sources: map[string]int{}, authors: map[string]cachedAuthor{}}
+}
+
+func (in *Ingest) log(endpoint string, data interface{}, err error) {
+	if in.Log != nil {
+		in.Log(endpoint, data, err)
+	}
+}
+
+// Source возвращает ID источника с тем же именем и адресом или создаёт его
+func (in *Ingest) Source(ctx context.Context, s apiclient.Source) (int, error) {
+	key := s.Name + "|" + s.Address
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if id, ok := in.sources[key]; ok {
+		return id, nil
+	}
+
+	sources, err := in.api.ListSources(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, existing := range sources {
+		if existing.Name == s.Name && existing.Address == s.Address {
+			in.sources[key] = existing.SourceID
+			return existing.SourceID, nil
+		}
+	}
+	created, err := in.api.CreateSource(ctx, s)
+	in.log("/sources", s, err)
+	if err != nil {
+		return 0, err
+	}
+	in.sources[key] = created.SourceID
+	return created.SourceID, nil
+}
+
+// Channel возвращает ID канала источника с той же ссылкой или создаёт его
+func (in *Ingest) Channel(ctx context.Context, sourceID int, ch Channel) (int, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if in.channels == nil {
+		channels, err := in.api.ListChannels(ctx)
+		if err != nil {
+			return 0, err
+		}
+		in.channels = map[string]int{}
+		for _, c := range channels {
+			if c.Link != "" {
+				in.channels[channelKey(c.SourceID, c.Link)] = c.ChannelID
+			}
+		}
+	}
+	key := channelKey(sourceID, ch.Link)
+	if id, ok := in.channels[key]; ok && ch.Link != "" {
+		return id, nil
+	}
+
+	data := apiclient.Channel{
+		Name:             ch.Name,
+		Link:             ch.Link,
+		SubscribersCount: ch.Subscribers,
+		SourceID:         sourceID,
+		Topic:            ch.Topic,
+	}
+	created, err := in.api.CreateChannel(ctx, data)
+	in.log("/channels", data, err)
+	if err != nil {
+		return 0, err
+	}
+	in.channels[key] = created.ChannelID
+	return created.ChannelID, nil
+}
+
+func channelKey(sourceID int, link string) string {
+	return fmt.Sprintf("%d|%s", sourceID, link)
+}
+
+// Author возвращает ID автора, создавая его с профилем платформы. Профиль
+// запрашивается у enrich (может быть nil) не чаще раза в AuthorProfileTTL.
+// Если профиль недоступен (аккаунт удалён), автор создаётся без него.
+func (in *Ingest) Author(ctx context.Context, a apiclient.Author, enrich AuthorEnricher) (int, error) {
+	key := a.Platform + "/" + a.Name
+	if a.ExternalID != "" {
+		key = a.Platform + "#" + a.ExternalID
+	}
+	in.mu.Lock()
+	cached, ok := in.authors[key]
+	in.mu.Unlock()
+	if ok && time.Since(cached.refreshed) < AuthorProfileTTL {
+		return cached.id, nil
+	}
+
+	if enrich != nil {
+		if err := enrich.EnrichAuthor(ctx, &a); err != nil {
+			fmt.Printf("WARNING: profile of %s unavailable: %v\n", a.Name, err)
+		}
+	}
+	id, err := in.api.EnsureAuthorProfile(ctx, a)
+	in.log("/authors", a, err)
+	if err != nil {
+		return 0, err
+	}
+
+	in.mu.Lock()
+	in.authors[key] = cachedAuthor{id: id, refreshed: time.Now()}
+	in.mu.Unlock()
+	return id, nil
+}
+
+// Post отправляет пост; текст и теги сервер сохраняет сам. Ошибки сервера
+// возвращаются как есть: apiclient.IsRejected - отклонён фильтром,
+// apiclient.IsConflict - точная копия уже сохранённого поста.
+func (in *Ingest) Post(ctx context.Context, channelID, authorID int, p Post) (int, error) {
+	// У ссылочных постов нет текста - сохраняем ссылку
+	content := p.Text
+	if content == "" {
+		content = p.URL
+	}
+	if content == "" {
+		content = p.Title
+	}
+
+	title := p.Title
+	if title == "" {
+		title = truncate(p.Text, generatedTitleLen)
+	}
+	if title == "" {
+		title = fmt.Sprintf("Post %s", p.ExternalID)
+	}
+
+	createdAt := p.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	data := apiclient.Post{
+		Title:         truncate(title, maxTitleLen),
+		Content:       content,
+		AuthorID:      authorID,
+		ChannelID:     channelID,
+		CommentsCount: nonNegative(p.Comments),
+		LikesCount:    nonNegative(p.Likes),
+		CreatedAt:     createdAt.UTC().Format(timestampLayout),
+		Tags:          p.Tags,
+		MarkedAsAds:   p.MarkedAsAds,
+		ViewsCount:    nonNegative(p.Views),
+		NSFW:          p.NSFW,
+		Metadata:      p.Metadata,
+	}
+	created, err := in.api.CreatePost(ctx, data)
+	in.log("/posts", data, err)
+	if err != nil {
+		return 0, err
+	}
+	return created.PostID, nil
+}
+
+// Comments отправляет дерево комментариев и возвращает число сохранённых.
+// Комментарий без текста (например, только со стикером) сервер не примет: он
+// пропускается, а его ответы, как и ответы на комментарий, который не удалось
+// сохранить, прикрепляются к его родителю.
+func (in *Ingest) Comments(ctx context.Context, postID int, comments []Comment) int {
+	return in.comments(ctx, postID, nil, comments)
+}
+
+func (in *Ingest) comments(ctx context.Context, postID int, parentID *int, comments []Comment) int {
+	saved := 0
+	for _, c := range comments {
+		if strings.TrimSpace(c.Text) == "" {
+			saved += in.comments(ctx, postID, parentID, c.Replies)
+			continue
+		}
+		nickname := c.Author
+		if nickname == "" {
+			nickname = "[deleted]"
+		}
+		createdAt := c.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		data := apiclient.Comment{
+			PostID:          postID,
+			Nickname:        nickname,
+			ParentCommentID: parentID,
+			Text:            c.Text,
+			LikesCount:      nonNegative(c.Likes),
+			CreatedAt:       createdAt.UTC().Format(timestampLayout),
+		}
+		created, err := in.api.CreateComment(ctx, data)
+		in.log("/comments", data, err)
+		if err != nil {
+			fmt.Printf("ERROR: failed to add comment %s: %v\n", c.ExternalID, err)
+			saved += in.comments(ctx, postID, parentID, c.Replies)
+			continue
+		}
+		saved++
+		commentID := created.CommentID
+		saved += in.comments(ctx, postID, &commentID, c.Replies)
+	}
+	return saved
+}
+
+// Media отправляет вложения поста и возвращает число сохранённых
+func (in *Ingest) Media(ctx context.Context, postID int, media []Media) int {
+	saved := 0
+	for _, m := range media {
+		data := apiclient.Media{
+			PostID:       postID,
+			MediaContent: m.URL,
+			MediaType:    m.Type,
+			Width:        nonNegative(m.Width),
+			Height:       nonNegative(m.Height),
+			ThumbnailURL: m.Thumbnail,
+			Title:        truncate(m.Title, maxMediaTitleLen),
+		}
+		_, err := in.api.CreateMedia(ctx, data)
+		in.log("/media", data, err)
+		if err != nil {
+			fmt.Printf("ERROR: failed to add media %s: %v\n", m.URL, err)
+			continue
+		}
+		saved++
+	}
+	return saved
+}
+
+// truncate обрезает строку до n символов, добавляя "..."
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
+
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
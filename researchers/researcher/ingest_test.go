@@ -0,0 +1,332 @@
+package researcher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"apiclient"
+)
+
+// fakeServer - сервер агрегатора в памяти: запоминает созданные объекты и
+// выдаёт им последовательные ID
+type fakeServer struct {
+	mu       sync.Mutex
+	requests []string
+	sources  []apiclient.Source
+	channels []apiclient.Channel
+	authors  []apiclient.Author
+	posts    []apiclient.Post
+	comments []apiclient.Comment
+	media    []apiclient.Media
+	// rejectComment - текст комментария, который сервер не принимает
+	rejectComment string
+	// failPost - заголовок поста, на который сервер отвечает 503
+	failPost string
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	decode := func(v interface{}) {
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	var out interface{}
+	switch r.Method + " " + r.URL.Path {
+	case "GET /api/v1/sources":
+		out = s.sources
+	case "POST /api/v1/sources":
+		var src apiclient.Source
+		decode(&src)
+		src.SourceID = 100 + len(s.sources)
+		s.sources = append(s.sources, src)
+		out = src
+	case "GET /api/v1/channels":
+		out = s.channels
+	case "POST /api/v1/channels":
+		var ch apiclient.Channel
+		decode(&ch)
+		ch.ChannelID = 200 + len(s.channels)
+		s.channels = append(s.channels, ch)
+		out = ch
+	case "POST /api/v1/authors/ensure":
+		var a apiclient.Author
+		decode(&a)
+		a.AuthorID = 300 + len(s.authors)
+		s.authors = append(s.authors, a)
+		out = a
+	case "POST /api/v1/posts":
+		var p apiclient.Post
+		decode(&p)
+		if p.Title == s.failPost {
+			http.Error(w, `{"code":"unavailable","message":"try later"}`, http.StatusServiceUnavailable)
+			return
+		}
+		p.PostID = 400 + len(s.posts)
+		s.posts = append(s.posts, p)
+		out = p
+	case "POST /api/v1/comments":
+		var c apiclient.Comment
+		decode(&c)
+		if c.Text == s.rejectComment {
+			http.Error(w, `{"code":"validation_error","message":"rejected"}`, http.StatusBadRequest)
+			return
+		}
+		c.CommentID = 500 + len(s.comments)
+		s.comments = append(s.comments, c)
+		out = c
+	case "POST /api/v1/media":
+		var m apiclient.Media
+		decode(&m)
+		if m.MediaContent == "" {
+			http.Error(w, `{"code":"validation_error","message":"media_content required"}`, http.StatusBadRequest)
+			return
+		}
+		m.MediaID = 600 + len(s.media)
+		s.media = append(s.media, m)
+		out = m
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// count - число запросов "METHOD /path"
+func (s *fakeServer) count(request string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.requests {
+		if r == request {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestIngest(t *testing.T) (*Ingest, *fakeServer) {
+	t.Helper()
+	fake := &fakeServer{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return NewIngest(apiclient.New(srv.URL).WithRetries(1, 0)), fake
+}
+
+func TestIngestSourceFindsExisting(t *testing.T) {
+	in, fake := newTestIngest(t)
+	fake.sources = []apiclient.Source{{SourceID: 7, Name: "Reddit", Address: "reddit.com"}}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		id, err := in.Source(ctx, apiclient.Source{Name: "Reddit", Address: "reddit.com"})
+		if err != nil || id != 7 {
+			t.Fatalf("source = %d, %v, want 7", id, err)
+		}
+	}
+	// Второй вызов берёт ID из кеша
+	if n := fake.count("GET /api/v1/sources"); n != 1 {
+		t.Errorf("source list requests = %d, want 1", n)
+	}
+
+	// Тот же адрес под другим именем - другой источник
+	id, err := in.Source(ctx, apiclient.Source{Name: "RSS", Address: "reddit.com"})
+	if err != nil || id != 101 {
+		t.Errorf("new source = %d, %v, want 101", id, err)
+	}
+}
+
+func TestIngestChannelByLink(t *testing.T) {
+	in, fake := newTestIngest(t)
+	fake.channels = []apiclient.Channel{{ChannelID: 9, SourceID: 1, Link: "https://vk.com/habr"}}
+	ctx := context.Background()
+
+	id, err := in.Channel(ctx, 1, Channel{Name: "Хабр", Link: "https://vk.com/habr"})
+	if err != nil || id != 9 {
+		t.Fatalf("existing channel = %d, %v, want 9", id, err)
+	}
+	// Та же ссылка в другом источнике - новый канал, повторный вызов - из кеша
+	for i := 0; i < 2; i++ {
+		id, err = in.Channel(ctx, 2, Channel{Name: "Хабр", Link: "https://vk.com/habr", Subscribers: 10})
+		if err != nil || id != 201 {
+			t.Fatalf("new channel = %d, %v, want 201", id, err)
+		}
+	}
+	if n := fake.count("POST /api/v1/channels"); n != 1 {
+		t.Errorf("channel creations = %d, want 1", n)
+	}
+	if got := fake.channels[1]; got.SourceID != 2 || got.SubscribersCount != 10 {
+		t.Errorf("created channel = %+v", got)
+	}
+}
+
+// enricherFunc - AuthorEnricher из функции
+type enricherFunc func(a *apiclient.Author) error
+
+func (f enricherFunc) EnrichAuthor(_ context.Context, a *apiclient.Author) error { return f(a) }
+
+func TestIngestAuthorCache(t *testing.T) {
+	in, fake := newTestIngest(t)
+	ctx := context.Background()
+	enriched := 0
+	enrich := enricherFunc(func(a *apiclient.Author) error {
+		enriched++
+		rep := int64(42)
+		a.PlatformReputation = &rep
+		return nil
+	})
+
+	first, err := in.Author(ctx, apiclient.Author{Name: "alice", Platform: "reddit", ExternalID: "t2_1"}, enrich)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Переименованный аккаунт - тот же автор: ключ кеша - платформа и внешний ID
+	renamed, err := in.Author(ctx, apiclient.Author{Name: "alice2", Platform: "reddit", ExternalID: "t2_1"}, enrich)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed != first || enriched != 1 || fake.count("POST /api/v1/authors/ensure") != 1 {
+		t.Errorf("renamed author = %d (first %d), enriched %d times", renamed, first, enriched)
+	}
+	if rep := fake.authors[0].PlatformReputation; rep == nil || *rep != 42 {
+		t.Errorf("sent author = %+v, want reputation from enricher", fake.authors[0])
+	}
+
+	// То же имя на другой платформе - другой автор
+	other, err := in.Author(ctx, apiclient.Author{Name: "alice", Platform: "vk", ExternalID: "1"}, nil)
+	if err != nil || other == first {
+		t.Errorf("vk author = %d, %v, want a new author", other, err)
+	}
+
+	// Профиль устарел - он запрашивается снова
+	in.authors["reddit#t2_1"] = cachedAuthor{id: first, refreshed: time.Now().Add(-AuthorProfileTTL - time.Minute)}
+	if _, err := in.Author(ctx, apiclient.Author{Name: "alice", Platform: "reddit", ExternalID: "t2_1"}, enrich); err != nil {
+		t.Fatal(err)
+	}
+	if enriched != 2 {
+		t.Errorf("stale profile enriched %d times, want 2", enriched)
+	}
+}
+
+func TestIngestPost(t *testing.T) {
+	in, fake := newTestIngest(t)
+	ctx := context.Background()
+	created := time.Date(2025, 3, 10, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	tests := []struct {
+		name        string
+		post        Post
+		wantTitle   string
+		wantContent string
+	}{
+		{"title and text", Post{ExternalID: "1", Title: "Заголовок", Text: "Текст"}, "Заголовок", "Текст"},
+		// Ссылочный пост без текста сохраняется со ссылкой
+		{"link post", Post{ExternalID: "2", Title: "Ссылка", URL: "https://example.com/a"}, "Ссылка", "https://example.com/a"},
+		{"no title", Post{ExternalID: "3", Text: strings.Repeat("я", 150)}, strings.Repeat("я", 97) + "...", strings.Repeat("я", 150)},
+		{"only id", Post{ExternalID: "4", URL: "https://example.com/b"}, "Post 4", "https://example.com/b"},
+		{"long title", Post{ExternalID: "5", Title: strings.Repeat("ё", 300), Text: "x"}, strings.Repeat("ё", 252) + "...", "x"},
+	}
+	for i, tt := range tests {
+		tt.post.CreatedAt = created
+		tt.post.Likes = -3
+		id, err := in.Post(ctx, 20, 30, tt.post)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if id != 400+i {
+			t.Errorf("%s: id = %d, want %d", tt.name, id, 400+i)
+		}
+		got := fake.posts[i]
+		if got.Title != tt.wantTitle || got.Content != tt.wantContent {
+			t.Errorf("%s: title %q, content %q, want %q, %q", tt.name, got.Title, got.Content, tt.wantTitle, tt.wantContent)
+		}
+		// Время уходит в UTC, отрицательные счётчики - как 0
+		if got.CreatedAt != "2025-03-10 09:30:00" || got.LikesCount != 0 || got.ChannelID != 20 || got.AuthorID != 30 {
+			t.Errorf("%s: post = %+v", tt.name, got)
+		}
+	}
+}
+
+func TestIngestComments(t *testing.T) {
+	in, fake := newTestIngest(t)
+	fake.rejectComment = "отклонён"
+
+	tree := []Comment{
+		{ExternalID: "1", Author: "a", Text: "корень", Replies: []Comment{
+			{ExternalID: "2", Text: "ответ без автора"},
+			// Стикер без текста пропускается, его ответ цепляется к корню
+			{ExternalID: "3", Author: "b", Replies: []Comment{
+				{ExternalID: "4", Author: "c", Text: "ответ на стикер"},
+			}},
+		}},
+		// Непринятый комментарий: его ответ становится корневым
+		{ExternalID: "5", Author: "d", Text: "отклонён", Replies: []Comment{
+			{ExternalID: "6", Author: "e", Text: "ответ на отклонённый", Likes: -1},
+		}},
+	}
+	if saved := in.Comments(context.Background(), 77, tree); saved != 4 {
+		t.Errorf("saved = %d, want 4", saved)
+	}
+
+	type sent struct {
+		text     string
+		nickname string
+		parent   int
+	}
+	var got []sent
+	for _, c := range fake.comments {
+		s := sent{text: c.Text, nickname: c.Nickname}
+		if c.ParentCommentID != nil {
+			s.parent = *c.ParentCommentID
+		}
+		if c.PostID != 77 || c.LikesCount < 0 {
+			t.Errorf("comment = %+v", c)
+		}
+		got = append(got, s)
+	}
+	want := []sent{
+		{"корень", "a", 0},
+		{"ответ без автора", "[deleted]", 500},
+		{"ответ на стикер", "c", 500},
+		{"ответ на отклонённый", "e", 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("comments = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("comment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestIngestMedia(t *testing.T) {
+	in, fake := newTestIngest(t)
+	media := []Media{
+		{Type: "photo", URL: "https://example.com/1.jpg", Width: 800, Height: -1},
+		// Сервер не примет вложение без адреса - оно не считается
+		{Type: "link"},
+		{Type: "video", URL: "https://example.com/2.mp4", Thumbnail: "https://example.com/2.jpg", Title: strings.Repeat("ж", 600)},
+	}
+	if saved := in.Media(context.Background(), 5, media); saved != 2 {
+		t.Errorf("saved = %d, want 2", saved)
+	}
+	if len(fake.media) != 2 {
+		t.Fatalf("media = %+v", fake.media)
+	}
+	if m := fake.media[0]; m.PostID != 5 || m.Width != 800 || m.Height != 0 {
+		t.Errorf("photo = %+v", m)
+	}
+	if m := fake.media[1]; m.ThumbnailURL != "https://example.com/2.jpg" || len([]rune(m.Title)) != maxMediaTitleLen {
+		t.Errorf("video thumbnail = %q, title of %d runes", m.ThumbnailURL, len([]rune(m.Title)))
+	}
+}
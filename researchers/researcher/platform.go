@@ -0,0 +1,102 @@
+// Package researcher - общая часть researcher-ов: чтение конфигурации, планировщик
+// обхода каналов, ограничение частоты запросов, отправка данных на сервер и
+// хранилище состояния. Researcher конкретной платформы реализует только Platform.
+package researcher
+
+import (
+	"context"
+	"time"
+
+	"apiclient"
+)
+
+// Platform - адаптер платформы (VK, Reddit, ...). Методы получают данные платформы
+// и приводят их к общим типам; отправкой на сервер занимается Runner.
+type Platform interface {
+	// Name - имя раздела <source name="..."> в config/researchers.xml
+	Name() string
+	// Source - источник, под которым посты попадают на сервер
+	Source() apiclient.Source
+	// ListChannels - каналы для обхода: conf.PreferredChannels или популярные, не больше conf.ChannelLimit
+	ListChannels(ctx context.Context, conf Config) ([]Channel, error)
+	// FetchPosts - последние посты канала, не больше limit
+	FetchPosts(ctx context.Context, ch Channel, limit int) ([]Post, error)
+	// FetchComments - дерево комментариев поста, не больше limit вместе с ответами
+	FetchComments(ctx context.Context, ch Channel, post Post, limit int) ([]Comment, error)
+	// FetchMedia - вложения поста, не больше limit
+	FetchMedia(ctx context.Context, ch Channel, post Post, limit int) ([]Media, error)
+}
+
+// AuthorEnricher - необязательное расширение Platform: дополняет автора профилем
+// платформы (ID аккаунта, репутация). Вызывается не чаще раза в AuthorProfileTTL на автора.
+type AuthorEnricher interface {
+	EnrichAuthor(ctx context.Context, author *apiclient.Author) error
+}
+
+// ChannelCommitter - необязательное расширение Platform: сохраняет состояние канала
+// из ListChannels (валидаторы условного GET ленты), когда все посты канала
+// отправлены на сервер или отброшены им. После ошибки отправки состояние не
+// сохраняется, и следующий обход снова получит те же посты.
+type ChannelCommitter interface {
+	CommitChannel(ch Channel)
+}
+
+// Channel - канал платформы (группа VK, сабреддит)
+type Channel struct {
+	ExternalID  string
+	Name        string
+	Link        string
+	Subscribers int
+	Topic       string
+	// Author - автор постов канала, у которых нет своего (группа VK)
+	Author *apiclient.Author
+	// Source - свой источник канала вместо Platform.Source (сайт RSS-ленты); nil - общий
+	Source *apiclient.Source
+	// Raw - данные платформы для следующих вызовов адаптера
+	Raw interface{}
+}
+
+// Post - пост платформы
+type Post struct {
+	ExternalID string
+	Title      string
+	Text       string
+	URL        string
+	Author     *apiclient.Author
+	CreatedAt  time.Time
+	Likes      int
+	Comments   int
+	// Views - просмотры, если платформа их показывает (Telegram); 0 - неизвестно
+	Views       int
+	Tags        []string
+	MarkedAsAds bool
+	// NSFW - платформа пометила пост как 18+ (сигнал для фильтра контента)
+	NSFW bool
+	// Metadata - поля платформы без общего аналога (флейр, доля голосов "за",
+	// награды); сервер хранит их как есть и учитывает в рейтинге
+	Metadata map[string]interface{}
+	// Raw - данные платформы для FetchComments и FetchMedia (например, вложения)
+	Raw interface{}
+}
+
+// Comment - комментарий с ответами
+type Comment struct {
+	ExternalID string
+	Author     string
+	Text       string
+	Likes      int
+	CreatedAt  time.Time
+	Replies    []Comment
+}
+
+// Media - вложение поста
+type Media struct {
+	Type string
+	URL  string
+	// Width, Height - размеры картинки или видео; 0 - неизвестны
+	Width, Height int
+	// Thumbnail - превью видео, ссылки или документа
+	Thumbnail string
+	// Title - заголовок видео или ссылки, вопрос опроса, имя файла
+	Title string
+}
@@ -0,0 +1,159 @@
+package researcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Limiter - не больше одного запроса к платформе за interval. Один Limiter
+// делится между всеми запросами адаптера, в том числе из разных горутин.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewLimiter создаёт ограничитель; interval <= 0 - без ограничения
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{interval: interval}
+}
+
+// Wait ждёт своей очереди или отмены ctx. У nil Limiter не ждёт.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil || l.interval <= 0 {
+		return ctx.Err()
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	return Sleep(ctx, time.Until(at))
+}
+
+// Slow увеличивает паузу перед следующим запросом: платформа попросила
+// подождать (flood control, 429)
+func (l *Limiter) Slow(d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if until := time.Now().Add(d); l.next.Before(until) {
+		l.next = until
+	}
+	l.mu.Unlock()
+}
+
+// Bucket - ограничитель "ведро токенов": в среднем не больше rate запросов в
+// секунду и до burst запросов подряд. Подходит платформам с лимитом вида
+// "N запросов в секунду" (VK API), где Limiter с паузой 1/N зря растягивал бы серии.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	// paused - до этого момента запросы не выдаются (Slow)
+	paused time.Time
+}
+
+// NewBucket создаёт полное ведро; rate <= 0 - без ограничения
+func NewBucket(rate float64, burst int) *Bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait забирает токен, дожидаясь его появления, или возвращает ошибку отмены ctx.
+// У nil Bucket не ждёт.
+func (b *Bucket) Wait(ctx context.Context) error {
+	if b == nil || b.rate <= 0 {
+		return ctx.Err()
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	// Токен резервируется сразу: при нехватке следующий ждущий встаёт в очередь за ним
+	b.tokens--
+	at := now
+	if b.tokens < 0 {
+		at = now.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+	if at.Before(b.paused) {
+		at = b.paused
+	}
+	b.mu.Unlock()
+
+	return Sleep(ctx, time.Until(at))
+}
+
+// Slow приостанавливает выдачу токенов на d: платформа сообщила о превышении лимита
+func (b *Bucket) Slow(d time.Duration) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	if until := time.Now().Add(d); b.paused.Before(until) {
+		b.paused = until
+	}
+	b.mu.Unlock()
+}
+
+// Sleep - пауза, прерываемая отменой ctx
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку, после которой Retry не повторяет попытку
+// (неверный запрос, закрытая стена, удалённый аккаунт)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// Retry вызывает fn до attempts раз; перед n-й повторной попыткой ждёт n*delay.
+// Возвращает последнюю ошибку (без обёртки Permanent).
+func Retry(ctx context.Context, attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := Sleep(ctx, time.Duration(attempt)*delay); sleepErr != nil {
+				return err
+			}
+		}
+		err = fn()
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
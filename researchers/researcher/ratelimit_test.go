@@ -0,0 +1,170 @@
+package researcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterSpacesRequests(t *testing.T) {
+	l := NewLimiter(50 * time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Первый запрос сразу, каждый следующий - через interval
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least 150ms", elapsed)
+	}
+}
+
+func TestLimiterSlow(t *testing.T) {
+	l := NewLimiter(time.Millisecond)
+	l.Slow(100 * time.Millisecond)
+
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("request after Slow waited %v, want about 100ms", elapsed)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	var nilLimiter *Limiter
+	for _, l := range []*Limiter{nilLimiter, NewLimiter(0)} {
+		start := time.Now()
+		for i := 0; i < 100; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("disabled limiter waited %v", elapsed)
+		}
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := NewLimiter(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestBucketBurstThenRate(t *testing.T) {
+	b := NewBucket(20, 3)
+	ctx := context.Background()
+
+	// Полное ведро отдаёт burst токенов сразу
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Millisecond {
+		t.Errorf("burst took %v", elapsed)
+	}
+
+	// Дальше - rate токенов в секунду: 4 токена за 200ms
+	start = time.Now()
+	for i := 0; i < 4; i++ {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("4 tokens after burst took %v, want at least 200ms", elapsed)
+	}
+}
+
+func TestBucketSlow(t *testing.T) {
+	b := NewBucket(1000, 10)
+	b.Slow(100 * time.Millisecond)
+
+	// Пауза действует, даже когда токены в ведре есть
+	start := time.Now()
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("token after Slow waited %v, want about 100ms", elapsed)
+	}
+	// Более короткая пауза не сокращает уже назначенную
+	b.Slow(100 * time.Millisecond)
+	b.Slow(time.Millisecond)
+	start = time.Now()
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("shorter Slow cut the pause to %v", elapsed)
+	}
+}
+
+func TestBucketCancel(t *testing.T) {
+	b := NewBucket(0.001, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errGone := errors.New("gone")
+	tests := []struct {
+		name      string
+		results   []error
+		wantErr   error
+		wantCalls int
+	}{
+		{"first try", []error{nil}, nil, 1},
+		{"after failures", []error{errTemporary, errTemporary, nil}, nil, 3},
+		{"all attempts fail", []error{errTemporary, errTemporary, errTemporary}, errTemporary, 3},
+		{"permanent stops", []error{errTemporary, Permanent(errGone), nil}, errGone, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(context.Background(), 3, time.Millisecond, func() error {
+				err := tt.results[calls]
+				calls++
+				return err
+			})
+			if err != tt.wantErr || calls != tt.wantCalls {
+				t.Errorf("err = %v after %d calls, want %v after %d", err, calls, tt.wantErr, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errTemporary := errors.New("temporary")
+	calls := 0
+	err := Retry(ctx, 5, time.Hour, func() error {
+		calls++
+		cancel()
+		return errTemporary
+	})
+	if err != errTemporary || calls != 1 {
+		t.Errorf("err = %v after %d calls, want the last error after 1", err, calls)
+	}
+}
@@ -0,0 +1,232 @@
+package researcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"apiclient"
+)
+
+// Runner - планировщик обхода: раз в research_period перечитывает конфигурацию,
+// обходит каналы платформы и отправляет новые посты с комментариями и медиа.
+type Runner struct {
+	Platform Platform
+	Ingest   *Ingest
+	// State - уже отправленные посты; nil - состояние только в памяти
+	State *State
+	// Limiter - общий ограничитель запросов к платформе; nil - без ограничения
+	Limiter *Limiter
+	// ConfigPath - путь к config/researchers.xml
+	ConfigPath string
+	Logger     *log.Logger
+}
+
+type fatalError struct{ err error }
+
+func (e fatalError) Error() string { return e.err.Error() }
+func (e fatalError) Unwrap() error { return e.err }
+
+// Fatal помечает ошибку платформы, после которой обходить дальше бессмысленно
+// (нет действующего токена): Run возвращает её, а не ждёт следующего обхода
+func Fatal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fatalError{err}
+}
+
+// IsFatal - ошибка помечена Fatal
+func IsFatal(err error) bool {
+	var fatal fatalError
+	return errors.As(err, &fatal)
+}
+
+// Run регистрирует источник и обходит каналы до отмены ctx.
+// Ошибка конфигурации или Fatal останавливает researcher, остальные ошибки
+// платформы - только текущий обход.
+func (r *Runner) Run(ctx context.Context) error {
+	r.defaults()
+
+	var sourceID int
+	err := Retry(ctx, 5, 5*time.Second, func() error {
+		var err error
+		sourceID, err = r.Ingest.Source(ctx, r.Platform.Source())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("add %s source: %w", r.Platform.Name(), err)
+	}
+	r.Logger.Printf("sourceID = %d", sourceID)
+
+	for {
+		conf, err := LoadConfig(r.ConfigPath, r.Platform.Name())
+		if err != nil {
+			return err
+		}
+		r.Logger.Printf("new scan: %s", conf)
+
+		if err := r.Scan(ctx, sourceID, conf); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if IsFatal(err) {
+				_ = r.State.Save()
+				return err
+			}
+			r.Logger.Printf("scan failed: %v", err)
+		}
+		if err := r.State.Save(); err != nil {
+			r.Logger.Printf("save state: %v", err)
+		}
+
+		r.Logger.Printf("scan completed, waiting %v", conf.ResearchPeriod)
+		if err := Sleep(ctx, conf.ResearchPeriod); err != nil {
+			return err
+		}
+	}
+}
+
+// Scan - один обход каналов. Ошибка канала, кроме Fatal, не прерывает обход остальных.
+func (r *Runner) Scan(ctx context.Context, sourceID int, conf Config) error {
+	r.defaults()
+	if err := r.Limiter.Wait(ctx); err != nil {
+		return err
+	}
+	channels, err := r.Platform.ListChannels(ctx, conf)
+	if err != nil {
+		return fmt.Errorf("list channels: %w", err)
+	}
+	r.Logger.Printf("found %d channels", len(channels))
+
+	for i, ch := range channels {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		r.Logger.Printf("[%d/%d] channel %s (%d subscribers)", i+1, len(channels), ch.Name, ch.Subscribers)
+		sent, failed, err := r.scanChannel(ctx, sourceID, ch, conf)
+		if IsFatal(err) {
+			return fmt.Errorf("channel %s: %w", ch.Name, err)
+		}
+		if err != nil {
+			r.Logger.Printf("channel %s: %v", ch.Name, err)
+			continue
+		}
+		r.State.MarkScanned(ch.ExternalID)
+		if committer, ok := r.Platform.(ChannelCommitter); ok && failed == 0 {
+			committer.CommitChannel(ch)
+		}
+		r.Logger.Printf("channel %s: %d new posts, %d failed", ch.Name, sent, failed)
+	}
+	return nil
+}
+
+func (r *Runner) defaults() {
+	if r.Logger == nil {
+		r.Logger = log.New(os.Stdout, "["+r.Platform.Name()+"] ", log.LstdFlags)
+	}
+	if r.State == nil {
+		r.State, _ = OpenState("")
+	}
+}
+
+// scanChannel отправляет новые посты канала; failed - посты, которые не удалось
+// отправить из-за ошибки сервера или сети
+func (r *Runner) scanChannel(ctx context.Context, sourceID int, ch Channel, conf Config) (sent, failed int, err error) {
+	if ch.Source != nil {
+		id, err := r.Ingest.Source(ctx, *ch.Source)
+		if err != nil {
+			return 0, 0, fmt.Errorf("add source %s: %w", ch.Source.Address, err)
+		}
+		sourceID = id
+	}
+	channelID, err := r.Ingest.Channel(ctx, sourceID, ch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("add channel: %w", err)
+	}
+
+	if err := r.Limiter.Wait(ctx); err != nil {
+		return 0, 0, err
+	}
+	posts, err := r.Platform.FetchPosts(ctx, ch, conf.PostLimit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("fetch posts: %w", err)
+	}
+
+	for _, post := range posts {
+		if ctx.Err() != nil {
+			return sent, failed, ctx.Err()
+		}
+		if _, ok := r.State.PostID(ch.ExternalID, post.ExternalID); ok {
+			continue
+		}
+		ok, err := r.sendPost(ctx, channelID, ch, post, conf)
+		switch {
+		case err != nil:
+			failed++
+		case ok:
+			sent++
+		}
+	}
+	return sent, failed, nil
+}
+
+// sendPost отправляет пост с комментариями и медиа; false - пост не сохранён,
+// ошибка - не сохранён из-за сервера или сети, и его стоит отправить ещё раз
+func (r *Runner) sendPost(ctx context.Context, channelID int, ch Channel, post Post, conf Config) (bool, error) {
+	author := post.Author
+	if author == nil {
+		author = ch.Author
+	}
+	if author == nil {
+		r.Logger.Printf("SKIP: post %s has no author", post.ExternalID)
+		return false, nil
+	}
+	enricher, _ := r.Platform.(AuthorEnricher)
+	authorID, err := r.Ingest.Author(ctx, *author, enricher)
+	if err != nil {
+		r.Logger.Printf("ERROR: add author %s: %v", author.Name, err)
+		return false, err
+	}
+
+	postID, err := r.Ingest.Post(ctx, channelID, authorID, post)
+	switch {
+	case apiclient.IsRejected(err), apiclient.IsConflict(err):
+		// Повторно такой пост отправлять незачем
+		r.Logger.Printf("SKIP: post %s: %v", post.ExternalID, err)
+		r.State.MarkPost(ch.ExternalID, post.ExternalID, 0)
+		return false, nil
+	case err != nil:
+		r.Logger.Printf("ERROR: add post %s: %v", post.ExternalID, err)
+		return false, err
+	}
+	r.State.MarkPost(ch.ExternalID, post.ExternalID, postID)
+
+	if conf.CommentLimit > 0 {
+		if err := r.Limiter.Wait(ctx); err != nil {
+			return true, nil
+		}
+		comments, err := r.Platform.FetchComments(ctx, ch, post, conf.CommentLimit)
+		if err != nil {
+			r.Logger.Printf("ERROR: fetch comments of %s: %v", post.ExternalID, err)
+		} else if len(comments) > 0 {
+			r.Ingest.Comments(ctx, postID, comments)
+		}
+	}
+
+	if conf.MediaLimit > 0 {
+		if err := r.Limiter.Wait(ctx); err != nil {
+			return true, nil
+		}
+		media, err := r.Platform.FetchMedia(ctx, ch, post, conf.MediaLimit)
+		if err != nil {
+			r.Logger.Printf("ERROR: fetch media of %s: %v", post.ExternalID, err)
+		} else if len(media) > 0 {
+			r.Ingest.Media(ctx, postID, media)
+		}
+	}
+	return true, nil
+}
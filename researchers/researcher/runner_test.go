@@ -0,0 +1,79 @@
+package researcher
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"apiclient"
+)
+
+// testPlatform - один канал с заданными постами; запоминает CommitChannel
+type testPlatform struct {
+	posts     []Post
+	committed []string
+}
+
+func (p *testPlatform) Name() string { return "Test" }
+
+func (p *testPlatform) Source() apiclient.Source {
+	return apiclient.Source{Name: "Test", Address: "test"}
+}
+
+func (p *testPlatform) ListChannels(context.Context, Config) ([]Channel, error) {
+	return []Channel{{
+		ExternalID: "feed",
+		Name:       "Лента",
+		Link:       "https://example.com/feed",
+		Author:     &apiclient.Author{Name: "Лента", Platform: "test", ExternalID: "feed"},
+	}}, nil
+}
+
+func (p *testPlatform) FetchPosts(context.Context, Channel, int) ([]Post, error) {
+	return p.posts, nil
+}
+
+func (p *testPlatform) FetchComments(context.Context, Channel, Post, int) ([]Comment, error) {
+	return nil, nil
+}
+
+func (p *testPlatform) FetchMedia(context.Context, Channel, Post, int) ([]Media, error) {
+	return nil, nil
+}
+
+func (p *testPlatform) CommitChannel(ch Channel) {
+	p.committed = append(p.committed, ch.ExternalID)
+}
+
+func TestScanCommitsChannelAfterAllPostsSent(t *testing.T) {
+	in, fake := newTestIngest(t)
+	fake.failPost = "Второй"
+	platform := &testPlatform{posts: []Post{
+		{ExternalID: "1", Title: "Первый", Text: "текст"},
+		{ExternalID: "2", Title: "Второй", Text: "текст"},
+	}}
+	r := &Runner{Platform: platform, Ingest: in, Logger: log.New(io.Discard, "", 0)}
+	ctx := context.Background()
+	conf := Config{PostLimit: 10}
+
+	// Второй пост не отправлен: состояние канала не сохраняется
+	if err := r.Scan(ctx, 1, conf); err != nil {
+		t.Fatal(err)
+	}
+	if len(platform.committed) != 0 {
+		t.Errorf("committed after failed post: %v", platform.committed)
+	}
+	if _, ok := r.State.PostID("feed", "2"); ok {
+		t.Error("failed post marked as sent")
+	}
+
+	// Следующий обход отправляет только его и сохраняет состояние
+	fake.failPost = ""
+	if err := r.Scan(ctx, 1, conf); err != nil {
+		t.Fatal(err)
+	}
+	if len(platform.committed) != 1 || len(fake.posts) != 2 || fake.count("POST /api/v1/posts") != 3 {
+		t.Errorf("committed %v, posts %d, requests %d", platform.committed, len(fake.posts), fake.count("POST /api/v1/posts"))
+	}
+}
@@ -0,0 +1,148 @@
+package researcher
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StateRetention - сколько помнить отправленные посты: более старые платформы
+// уже не возвращают в ленте
+const StateRetention = 30 * 24 * time.Hour
+
+// State - что уже отправлено на сервер: посты каналов и курсоры инкрементального
+// обхода. Хранится в JSON-файле, чтобы перезапуск не отправлял посты повторно.
+type State struct {
+	mu   sync.Mutex
+	path string
+	data stateData
+}
+
+type stateData struct {
+	Channels map[string]*channelState `json:"channels"`
+}
+
+type channelState struct {
+	Cursor   string              `json:"cursor,omitempty"`
+	LastScan time.Time           `json:"last_scan"`
+	Posts    map[string]sentPost `json:"posts"`
+}
+
+type sentPost struct {
+	ID int       `json:"id"`
+	At time.Time `json:"at"`
+}
+
+// OpenState читает состояние из path; файла ещё нет - пустое состояние.
+// Пустой path - состояние только в памяти.
+func OpenState(path string) (*State, error) {
+	s := &State{path: path, data: stateData{Channels: map[string]*channelState{}}}
+	if path == "" {
+		return s, nil
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &s.data); err != nil {
+		return nil, err
+	}
+	if s.data.Channels == nil {
+		s.data.Channels = map[string]*channelState{}
+	}
+	return s, nil
+}
+
+func (s *State) channel(id string) *channelState {
+	ch := s.data.Channels[id]
+	if ch == nil {
+		ch = &channelState{Posts: map[string]sentPost{}}
+		s.data.Channels[id] = ch
+	}
+	if ch.Posts == nil {
+		ch.Posts = map[string]sentPost{}
+	}
+	return ch
+}
+
+// PostID - ID поста на сервере, если пост канала уже отправлен
+func (s *State) PostID(channelID, postID string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.channel(channelID).Posts[postID]
+	return p.ID, ok
+}
+
+// MarkPost запоминает отправленный пост; serverID 0 - пост отклонён сервером
+func (s *State) MarkPost(channelID, postID string, serverID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(channelID).Posts[postID] = sentPost{ID: serverID, At: time.Now()}
+}
+
+// Cursor - курсор инкрементального обхода канала (формат задаёт адаптер)
+func (s *State) Cursor(channelID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.channel(channelID).Cursor
+}
+
+// SetCursor сохраняет курсор канала
+func (s *State) SetCursor(channelID, cursor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(channelID).Cursor = cursor
+}
+
+// LastScan - время последнего обхода канала, нулевое - канал ещё не обходили
+func (s *State) LastScan(channelID string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.channel(channelID).LastScan
+}
+
+// MarkScanned отмечает завершённый обход канала
+func (s *State) MarkScanned(channelID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channel(channelID).LastScan = time.Now()
+}
+
+// Save удаляет посты старше StateRetention и записывает состояние в файл
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-StateRetention)
+	for _, ch := range s.data.Channels {
+		for id, p := range ch.Posts {
+			if p.At.Before(cutoff) {
+				delete(ch.Posts, id)
+			}
+		}
+	}
+	if s.path == "" {
+		return nil
+	}
+
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	// Запись через временный файл: прерванный researcher не оставит битый JSON
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,101 @@
+package researcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateSaveAndOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "state.json")
+	s, err := OpenState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.PostID("news", "1"); ok {
+		t.Fatal("empty state knows post 1")
+	}
+
+	s.MarkPost("news", "1", 101)
+	// 0 - пост отклонён сервером, но отправлять его снова не нужно
+	s.MarkPost("news", "2", 0)
+	s.SetCursor("news", "t3_abc")
+	s.MarkScanned("news")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+
+	reopened, err := OpenState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := reopened.PostID("news", "1"); !ok || id != 101 {
+		t.Errorf("post 1 = %d, %v, want 101, true", id, ok)
+	}
+	if id, ok := reopened.PostID("news", "2"); !ok || id != 0 {
+		t.Errorf("post 2 = %d, %v, want 0, true", id, ok)
+	}
+	if c := reopened.Cursor("news"); c != "t3_abc" {
+		t.Errorf("cursor = %q, want t3_abc", c)
+	}
+	if last := reopened.LastScan("news"); time.Since(last) > time.Minute {
+		t.Errorf("last scan = %v", last)
+	}
+	// Каналы не смешиваются
+	if _, ok := reopened.PostID("sport", "1"); ok || !reopened.LastScan("sport").IsZero() {
+		t.Error("channel sport inherited state of news")
+	}
+}
+
+func TestStateSaveDropsOldPosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := OpenState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.MarkPost("news", "old", 1)
+	s.MarkPost("news", "new", 2)
+	s.data.Channels["news"].Posts["old"] = sentPost{ID: 1, At: time.Now().Add(-StateRetention - time.Hour)}
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reopened.PostID("news", "old"); ok {
+		t.Error("post older than StateRetention kept")
+	}
+	if _, ok := reopened.PostID("news", "new"); !ok {
+		t.Error("recent post dropped")
+	}
+}
+
+func TestStateInMemory(t *testing.T) {
+	s, err := OpenState("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.MarkPost("news", "1", 5)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := s.PostID("news", "1"); !ok || id != 5 {
+		t.Errorf("post 1 = %d, %v", id, ok)
+	}
+}
+
+func TestOpenStateBrokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenState(path); err == nil {
+		t.Error("broken state file opened without error")
+	}
+}
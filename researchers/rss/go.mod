@@ -0,0 +1,13 @@
+module researcher-rss
+
+go 1.25.1
+
+require (
+	apiclient v0.0.0-00010101000000-000000000000
+	researcher v0.0.0-00010101000000-000000000000
+)
+
+replace (
+	apiclient => ../../apiclient
+	researcher => ../researcher
+)
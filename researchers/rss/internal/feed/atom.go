@@ -0,0 +1,103 @@
+package feed
+
+import (
+	"strconv"
+	"strings"
+)
+
+// atomFeed - лента Atom (RFC 4287)
+type atomFeed struct {
+	Title   atomText    `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string       `xml:"id"`
+	Title      atomText     `xml:"title"`
+	Links      []atomLink   `xml:"link"`
+	Published  string       `xml:"published"`
+	Updated    string       `xml:"updated"`
+	Authors    []atomPerson `xml:"author"`
+	Categories []struct {
+		Term  string `xml:"term,attr"`
+		Label string `xml:"label,attr"`
+	} `xml:"category"`
+	Summary atomText `xml:"summary"`
+	Content atomText `xml:"content"`
+	mediaElements
+}
+
+// atomText - текстовая конструкция: type="text", "html" (экранированный HTML)
+// или "xhtml" (разметка внутри <div>)
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+	return t.Text
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"`
+}
+
+// alternateLink - ссылка на страницу: rel="alternate" или без rel
+func alternateLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	var doc atomFeed
+	if err := newDecoder(data).Decode(&doc); err != nil {
+		return nil, err
+	}
+	feed := &Feed{Title: strings.TrimSpace(doc.Title.String()), Link: alternateLink(doc.Links)}
+	for _, e := range doc.Entries {
+		// Автор ленты - автор записей без своего
+		author := doc.Author
+		if len(e.Authors) > 0 {
+			author = e.Authors[0]
+		}
+		it := Item{
+			GUID:      e.ID,
+			Title:     e.Title.String(),
+			Link:      alternateLink(e.Links),
+			Text:      firstNonEmpty(e.Content.String(), e.Summary.String()),
+			Author:    strings.TrimSpace(author.Name),
+			AuthorURL: strings.TrimSpace(author.URI),
+			Published: parseDate(e.Published, e.Updated),
+		}
+		for _, c := range e.Categories {
+			it.Categories = append(it.Categories, firstNonEmpty(c.Label, c.Term))
+		}
+		for _, l := range e.Links {
+			if l.Rel == "enclosure" && l.Href != "" {
+				length, _ := strconv.ParseInt(l.Length, 10, 64)
+				it.Enclosures = append(it.Enclosures, Enclosure{URL: l.Href, Type: l.Type, Length: length})
+			}
+		}
+		it.Enclosures = append(it.Enclosures, e.enclosures()...)
+		feed.Items = append(feed.Items, it)
+	}
+	return feed, nil
+}
@@ -0,0 +1,201 @@
+// Package feed - разбор лент новостных сайтов (RSS 0.9x/1.0/2.0, Atom, JSON Feed),
+// загрузка с условным GET и адаптер платформы для researcher.Runner.
+//
+// Записанные ленты лежат в researchers/rss/testdata, feed_test.go разбирает их.
+package feed
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"researcher/htmlq"
+)
+
+// Feed - лента в общем для всех форматов виде
+type Feed struct {
+	Title string
+	// Link - адрес сайта ленты
+	Link  string
+	Items []Item
+}
+
+// Item - запись ленты
+type Item struct {
+	// GUID - постоянный ID записи: guid, id записи Atom/JSON Feed, иначе ссылка
+	GUID       string
+	Title      string
+	Link       string
+	Text       string
+	Author     string
+	AuthorURL  string
+	Categories []string
+	Published  time.Time
+	Enclosures []Enclosure
+}
+
+// Enclosure - вложение записи: enclosure RSS, media:content, ссылка rel="enclosure"
+// Atom, attachments JSON Feed или картинка из текста
+type Enclosure struct {
+	URL    string
+	Type   string // MIME-тип, если известен
+	Medium string // image, video, audio - если тип не указан
+	Length int64
+}
+
+// Parse определяет формат ленты и разбирает её
+func Parse(data []byte) (*Feed, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var feed *Feed
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		feed, err = parseJSONFeed(trimmed)
+	} else {
+		feed, err = parseXML(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range feed.Items {
+		feed.Items[i].normalize()
+	}
+	return feed, nil
+}
+
+func parseXML(data []byte) (*Feed, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+	switch root {
+	case "rss", "RDF":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	}
+	return nil, fmt.Errorf("unknown feed format: <%s>", root)
+}
+
+// newDecoder - нестрогий разбор XML: в лентах встречаются HTML-сущности (&nbsp;)
+// и кодировка windows-1251
+func newDecoder(data []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charsetReader
+	return d
+}
+
+func rootElement(data []byte) (string, error) {
+	d := newDecoder(data)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", fmt.Errorf("not a feed: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	raw, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(charset) {
+	case "windows-1251", "cp1251", "cp-1251":
+		return strings.NewReader(htmlq.Decode(raw, "charset=windows-1251")), nil
+	case "iso-8859-1", "latin1", "latin-1":
+		runes := make([]rune, len(raw))
+		for i, b := range raw {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+// normalize - текст без HTML, GUID для записей без него, картинки из текста
+// для записей без вложений
+func (it *Item) normalize() {
+	it.Title = htmlq.ParseString(it.Title).Text()
+	it.Link = strings.TrimSpace(it.Link)
+	doc := htmlq.ParseString(it.Text)
+	it.Text = doc.Paragraphs()
+	if len(it.Enclosures) == 0 {
+		for _, img := range doc.Find("img[src]") {
+			if src := img.Attr("src"); strings.HasPrefix(src, "http") {
+				it.Enclosures = append(it.Enclosures, Enclosure{URL: src, Medium: "image"})
+			}
+		}
+	}
+
+	it.GUID = strings.TrimSpace(it.GUID)
+	if it.GUID == "" {
+		it.GUID = it.Link
+	}
+	if it.GUID == "" {
+		sum := sha1.Sum([]byte(it.Title + "|" + it.Published.String()))
+		it.GUID = "sha1:" + hex.EncodeToString(sum[:])
+	}
+
+	var categories []string
+	seen := map[string]bool{}
+	for _, c := range it.Categories {
+		c = strings.TrimSpace(c)
+		if c != "" && !seen[strings.ToLower(c)] {
+			seen[strings.ToLower(c)] = true
+			categories = append(categories, c)
+		}
+	}
+	it.Categories = categories
+}
+
+// MediaType - тип вложения для сервера: image, video, audio или document
+func (e Enclosure) MediaType() string {
+	kind, _, _ := strings.Cut(e.Type, "/")
+	if kind == "" {
+		kind = e.Medium
+	}
+	switch kind {
+	case "image", "video", "audio":
+		return kind
+	}
+	return "document"
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate - дата RSS (RFC 822 со множеством отступлений) или RFC 3339
+func parseDate(values ...string) time.Time {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
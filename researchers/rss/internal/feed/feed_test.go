@@ -0,0 +1,166 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testdata - записанные ленты сайтов
+const testdata = "../../testdata"
+
+func TestParseFixtures(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	edt := time.FixedZone("EDT", -4*60*60)
+	tests := []struct {
+		fixture     string
+		title, link string
+		items       []Item
+	}{
+		// RSS 2.0 с повтором записи: повторы убирает FetchPosts, разбор оставляет все
+		{"lenta_ru_rss_news.xml", "Lenta.ru : Новости", "https://lenta.ru", []Item{
+			{
+				GUID:       "https://lenta.ru/news/2024/05/01/teleskop/",
+				Title:      "Новый телескоп снял туманность Ориона в инфракрасном диапазоне",
+				Link:       "https://lenta.ru/news/2024/05/01/teleskop/",
+				Text:       "Астрономы опубликовали первые снимки нового телескопа. На изображениях видны протозвёзды, скрытые облаками пыли.",
+				Author:     "Мария Соколова",
+				Categories: []string{"Наука и техника"},
+				Published:  time.Date(2024, 5, 1, 13, 5, 0, 0, msk),
+				Enclosures: []Enclosure{{URL: "https://icdn.lenta.ru/images/2024/05/01/13/teleskop_1280.jpg", Type: "image/jpeg", Length: 183560}},
+			},
+			{
+				GUID:  "https://lenta.ru/news/2024/05/01/ceny/",
+				Title: "Аналитики оценили рост цен на авиабилеты перед праздниками",
+				Link:  "https://lenta.ru/news/2024/05/01/ceny/",
+				Text:  "Стоимость перелётов по России выросла в среднем на 12 процентов по сравнению с прошлым годом.",
+				// Категории без учёта регистра не повторяются
+				Categories: []string{"Экономика"},
+				Published:  time.Date(2024, 5, 1, 11, 40, 0, 0, msk),
+			},
+			{
+				GUID:       "https://lenta.ru/news/2024/05/01/teleskop/",
+				Title:      "Новый телескоп снял туманность Ориона в инфракрасном диапазоне",
+				Link:       "https://lenta.ru/news/2024/05/01/teleskop/",
+				Text:       "Повтор записи в ленте.",
+				Author:     "Мария Соколова",
+				Categories: []string{"Наука и техника"},
+				Published:  time.Date(2024, 5, 1, 13, 5, 0, 0, msk),
+			},
+			{
+				GUID:       "https://lenta.ru/news/2024/05/01/match/",
+				Title:      "«Зенит» обыграл ЦСКА в полуфинале Кубка России",
+				Link:       "https://lenta.ru/news/2024/05/01/match/",
+				Text:       "Матч завершился со счётом 2:1.\nФинал пройдёт в июне.",
+				Categories: []string{"Спорт"},
+				Published:  time.Date(2024, 5, 1, 22, 15, 0, 0, msk),
+				Enclosures: []Enclosure{{URL: "https://icdn.lenta.ru/images/2024/05/01/22/match_1280.jpg", Type: "image/jpeg", Length: 201442}},
+			},
+		}},
+		// windows-1251, HTML-сущности, yandex:full-text и media:content
+		{"www_interfax_ru_rss_asp.xml", "Интерфакс", "https://www.interfax.ru/", []Item{
+			{
+				GUID:       "https://www.interfax.ru/russia/958211",
+				Title:      "Правительство утвердило программу развития региональных аэропортов",
+				Link:       "https://www.interfax.ru/russia/958211",
+				Text:       "Программа рассчитана до 2030 года и предусматривает реконструкцию 75 аэропортов. Финансирование распределят между федеральным и региональными бюджетами.",
+				Categories: []string{"В России"},
+				Published:  time.Date(2024, 5, 1, 12, 31, 0, 0, msk),
+				Enclosures: []Enclosure{{URL: "https://www.interfax.ru/ftproot/photos/photostory/2024/05/01/air_700.jpg", Medium: "image"}},
+			},
+			{
+				GUID:       "https://www.interfax.ru/business/958190",
+				Title:      "Курс евро на Мосбирже опустился ниже 99 рублей",
+				Link:       "https://www.interfax.ru/business/958190",
+				Text:       "Курс евро с расчетами «завтра» снизился на 45 копеек.",
+				Categories: []string{"Экономика"},
+				Published:  time.Date(2024, 5, 1, 11, 2, 0, 0, msk),
+			},
+		}},
+		// Atom: картинка из HTML-содержимого, XHTML-содержимое, ссылка rel="enclosure"
+		{"www_theverge_com_rss_index_xml.xml", "The Verge", "https://www.theverge.com", []Item{
+			{
+				GUID:       "https://www.theverge.com/2024/5/1/24146001/apple-ipad-event",
+				Title:      "Apple’s new iPad event is set for next week",
+				Link:       "https://www.theverge.com/2024/5/1/24146001/apple-ipad-event",
+				Text:       "Apple is expected to announce new iPad Pro and iPad Air models.\nThe event streams online on May 7th.",
+				Author:     "Jane Doe",
+				AuthorURL:  "https://www.theverge.com/authors/jane-doe",
+				Categories: []string{"Apple", "Tech"},
+				Published:  time.Date(2024, 5, 1, 10, 15, 0, 0, edt),
+				Enclosures: []Enclosure{{URL: "https://cdn.vox-cdn.com/thumbor/ipad_event.jpg", Medium: "image"}},
+			},
+			{
+				GUID:       "tag:theverge.com,2024:vergecast-0501",
+				Title:      "Podcast: the week in gadgets",
+				Link:       "https://www.theverge.com/2024/5/1/24146100/vergecast",
+				Text:       "This week we talk about foldable phones.",
+				Author:     "John Roe",
+				Categories: []string{"Podcasts"},
+				Published:  time.Date(2024, 5, 1, 12, 0, 0, 0, edt),
+				Enclosures: []Enclosure{{URL: "https://traffic.megaphone.fm/vergecast_0501.mp3", Type: "audio/mpeg", Length: 48213300}},
+			},
+		}},
+		// JSON Feed: автор ленты у записей без своего, числовой id
+		{"daringfireball_net_feeds_json.json", "Daring Fireball", "https://daringfireball.net/", []Item{
+			{
+				GUID:       "https://daringfireball.net/linked/2024/05/01/ipad-event",
+				Title:      "Apple Announces iPad Event",
+				Link:       "https://daringfireball.net/linked/2024/05/01/ipad-event",
+				Text:       "Scheduled for Tuesday 7 May.\nExpect new iPads.",
+				Author:     "John Gruber",
+				AuthorURL:  "https://twitter.com/gruber",
+				Categories: []string{"Apple", "iPad"},
+				Published:  time.Date(2024, 5, 1, 18, 5, 0, 0, time.UTC),
+			},
+			{
+				GUID:       "4712",
+				Title:      "The Talk Show: Episode 400",
+				Link:       "https://daringfireball.net/thetalkshow/2024/04/30/ep-400",
+				Text:       "Special guest discusses the state of the industry.",
+				Author:     "John Gruber",
+				AuthorURL:  "https://twitter.com/gruber",
+				Published:  time.Date(2024, 4, 30, 21, 0, 0, 0, time.UTC),
+				Enclosures: []Enclosure{{URL: "https://daringfireball.net/thetalkshow/ep400.mp3", Type: "audio/mpeg", Length: 98304000}},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join(testdata, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			feed, err := Parse(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feed.Title != tt.title || feed.Link != tt.link {
+				t.Errorf("feed = %q, %q, want %q, %q", feed.Title, feed.Link, tt.title, tt.link)
+			}
+			if len(feed.Items) != len(tt.items) {
+				t.Fatalf("items = %d, want %d", len(feed.Items), len(tt.items))
+			}
+			for i := range tt.items {
+				g, w := feed.Items[i], tt.items[i]
+				if !g.Published.Equal(w.Published) {
+					t.Errorf("%s: published %v, want %v", w.GUID, g.Published, w.Published)
+				}
+				g.Published, w.Published = time.Time{}, time.Time{}
+				if !reflect.DeepEqual(g, w) {
+					t.Errorf("item %d:\n got %+v\nwant %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	for _, data := range []string{`<html><body>not a feed</body></html>`, ``} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q): no error", data)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"researcher"
+	"researcher/htmlq"
+)
+
+// maxFeedSize - лента больше этого размера обрезается (и, скорее всего, не разберётся)
+const maxFeedSize = 10 << 20
+
+// Validators - заголовки прошлого ответа для условного GET (If-None-Match,
+// If-Modified-Since): если лента не изменилась, сервер ответит 304 без тела
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// Response - ответ на запрос ленты
+type Response struct {
+	Body       []byte
+	Validators Validators
+	// NotModified - лента не изменилась с прошлого запроса (304), Body пустой
+	NotModified bool
+}
+
+// Getter - источник лент: сайты (HTTPGetter) или записанные ленты (DirGetter)
+type Getter interface {
+	Get(ctx context.Context, feedURL string, v Validators) (Response, error)
+}
+
+// HTTPGetter загружает ленты с условным GET
+type HTTPGetter struct {
+	Client    *http.Client
+	UserAgent string
+	// Limiter - общий ограничитель запросов; nil - без ограничения
+	Limiter *researcher.Limiter
+	// Attempts - попыток на ленту при сетевых ошибках, 429 и 5xx
+	Attempts int
+}
+
+// NewHTTPGetter создаёт загрузчик с таймаутом 30 секунд и тремя попытками
+func NewHTTPGetter(userAgent string, limiter *researcher.Limiter) *HTTPGetter {
+	return &HTTPGetter{
+		Client:    &http.Client{Timeout: 30 * time.Second},
+		UserAgent: userAgent,
+		Limiter:   limiter,
+		Attempts:  3,
+	}
+}
+
+func (g *HTTPGetter) Get(ctx context.Context, feedURL string, v Validators) (Response, error) {
+	var resp Response
+	err := researcher.Retry(ctx, g.Attempts, 5*time.Second, func() error {
+		if err := g.Limiter.Wait(ctx); err != nil {
+			return researcher.Permanent(err)
+		}
+		var err error
+		resp, err = g.get(ctx, feedURL, v)
+		return err
+	})
+	return resp, err
+}
+
+func (g *HTTPGetter) get(ctx context.Context, feedURL string, v Validators) (Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return Response{}, researcher.Permanent(err)
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, */*;q=0.8")
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+
+	httpResp, err := g.Client.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer httpResp.Body.Close()
+
+	switch {
+	case httpResp.StatusCode == http.StatusNotModified:
+		// Сервер может прислать обновлённые валидаторы и в ответе 304
+		return Response{NotModified: true, Validators: merge(validators(httpResp.Header), v)}, nil
+	case httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= 500:
+		g.Limiter.Slow(10 * time.Second)
+		return Response{}, &htmlq.StatusError{URL: feedURL, Code: httpResp.StatusCode}
+	case httpResp.StatusCode != http.StatusOK:
+		return Response{}, researcher.Permanent(&htmlq.StatusError{URL: feedURL, Code: httpResp.StatusCode})
+	}
+
+	body, err := io.ReadAll(io.LimitReader(httpResp.Body, maxFeedSize))
+	if err != nil {
+		return Response{}, err
+	}
+	// Кодировку XML-ленты указывает её пролог, а не Content-Type: тело отдаём как есть
+	return Response{Body: body, Validators: validators(httpResp.Header)}, nil
+}
+
+func validators(h http.Header) Validators {
+	return Validators{ETag: h.Get("ETag"), LastModified: h.Get("Last-Modified")}
+}
+
+func merge(v, old Validators) Validators {
+	if v.ETag == "" {
+		v.ETag = old.ETag
+	}
+	if v.LastModified == "" {
+		v.LastModified = old.LastModified
+	}
+	return v
+}
+
+// DirGetter читает записанные ленты из каталога: имя файла - FixtureName(url)
+// с расширением .xml или .json. ETag - хеш файла, поэтому повторный обход
+// неизменённой ленты, как и на сайте, получает NotModified.
+type DirGetter struct {
+	Dir string
+}
+
+func (g DirGetter) Get(ctx context.Context, feedURL string, v Validators) (Response, error) {
+	name := FixtureName(feedURL)
+	var body []byte
+	var err error
+	for _, ext := range []string{".xml", ".json"} {
+		if body, err = os.ReadFile(filepath.Join(g.Dir, name+ext)); !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if err != nil {
+		return Response{}, err
+	}
+	sum := sha1.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	if v.ETag == etag {
+		return Response{NotModified: true, Validators: v}, nil
+	}
+	return Response{Body: body, Validators: Validators{ETag: etag}}, nil
+}
+
+// FixtureName - имя файла записанной ленты без расширения: хост, путь и запрос,
+// где разделители заменены на "_" (https://lenta.ru/rss/news -> lenta_ru_rss_news)
+func FixtureName(feedURL string) string {
+	name := strings.TrimSuffix(htmlq.FixtureName(feedURL), ".html")
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		host := strings.NewReplacer(".", "_", ":", "_").Replace(u.Hostname())
+		if name == "index" {
+			return host
+		}
+		return host + "_" + name
+	}
+	return name
+}
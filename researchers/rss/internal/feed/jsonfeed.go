@@ -0,0 +1,88 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonFeed - JSON Feed 1.0/1.1 (https://jsonfeed.org/version/1.1)
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url"`
+	Author      *jsonAuthor  `json:"author"`
+	Authors     []jsonAuthor `json:"authors"`
+	Items       []struct {
+		ID            json.RawMessage `json:"id"`
+		URL           string          `json:"url"`
+		ExternalURL   string          `json:"external_url"`
+		Title         string          `json:"title"`
+		ContentHTML   string          `json:"content_html"`
+		ContentText   string          `json:"content_text"`
+		Summary       string          `json:"summary"`
+		Image         string          `json:"image"`
+		DatePublished string          `json:"date_published"`
+		DateModified  string          `json:"date_modified"`
+		Author        *jsonAuthor     `json:"author"`
+		Authors       []jsonAuthor    `json:"authors"`
+		Tags          []string        `json:"tags"`
+		Attachments   []struct {
+			URL         string `json:"url"`
+			MimeType    string `json:"mime_type"`
+			SizeInBytes int64  `json:"size_in_bytes"`
+		} `json:"attachments"`
+	} `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// firstAuthor - authors (1.1) или author (1.0)
+func firstAuthor(authors []jsonAuthor, author *jsonAuthor) *jsonAuthor {
+	if len(authors) > 0 {
+		return &authors[0]
+	}
+	return author
+}
+
+func parseJSONFeed(data []byte) (*Feed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("json feed: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("json feed: unknown version %q", doc.Version)
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(doc.Title), Link: doc.HomePageURL}
+	feedAuthor := firstAuthor(doc.Authors, doc.Author)
+	for _, ji := range doc.Items {
+		it := Item{
+			// id - строка, но встречаются и числа
+			GUID:       strings.Trim(string(ji.ID), `"`),
+			Title:      ji.Title,
+			Link:       firstNonEmpty(ji.URL, ji.ExternalURL),
+			Text:       firstNonEmpty(ji.ContentHTML, ji.ContentText, ji.Summary),
+			Categories: ji.Tags,
+			Published:  parseDate(ji.DatePublished, ji.DateModified),
+		}
+		if a := firstAuthor(ji.Authors, ji.Author); a != nil {
+			it.Author, it.AuthorURL = a.Name, a.URL
+		} else if feedAuthor != nil {
+			it.Author, it.AuthorURL = feedAuthor.Name, feedAuthor.URL
+		}
+		for _, a := range ji.Attachments {
+			if a.URL != "" {
+				it.Enclosures = append(it.Enclosures, Enclosure{URL: a.URL, Type: a.MimeType, Length: a.SizeInBytes})
+			}
+		}
+		if ji.Image != "" {
+			it.Enclosures = append(it.Enclosures, Enclosure{URL: ji.Image, Medium: "image"})
+		}
+		feed.Items = append(feed.Items, it)
+	}
+	return feed, nil
+}
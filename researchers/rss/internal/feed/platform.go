@@ -0,0 +1,216 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"apiclient"
+	"researcher"
+)
+
+// defaultFeeds - ленты без preferred_channels: крупные новостные сайты
+var defaultFeeds = []string{
+	"https://lenta.ru/rss/news",
+	"https://tass.ru/rss/v2.xml",
+	"https://www.interfax.ru/rss.asp",
+	"https://meduza.io/rss/all",
+	"https://feeds.bbci.co.uk/russian/rss.xml",
+	"https://habr.com/ru/rss/news/",
+}
+
+// Platform - адаптер лент для researcher.Runner. Канал - лента, источник канала -
+// её сайт, поэтому записи разных изданий попадают на сервер под своими источниками.
+type Platform struct {
+	getter Getter
+	// state хранит валидаторы условного GET и заголовок каждой ленты
+	state *researcher.State
+}
+
+// NewPlatform создаёт адаптер; state - то же состояние, что у Runner
+func NewPlatform(getter Getter, state *researcher.State) *Platform {
+	return &Platform{getter: getter, state: state}
+}
+
+func (p *Platform) Name() string { return "RSS" }
+
+// Source - общий источник researcher-а; посты отправляются под источниками сайтов (Channel.Source)
+func (p *Platform) Source() apiclient.Source {
+	return apiclient.Source{Name: "RSS", Address: "rss", Topic: "news"}
+}
+
+// feedCursor - курсор канала в researcher.State
+type feedCursor struct {
+	Validators
+	Title string `json:"title,omitempty"`
+	Site  string `json:"site,omitempty"`
+}
+
+// ListChannels загружает ленты из preferred_channels (адреса лент) или встроенного
+// списка. Неизменившаяся с прошлого обхода лента (304) остаётся каналом без записей.
+func (p *Platform) ListChannels(ctx context.Context, conf researcher.Config) ([]researcher.Channel, error) {
+	feeds := conf.PreferredChannels
+	if len(feeds) == 0 {
+		feeds = defaultFeeds
+		if conf.ChannelLimit > 0 && len(feeds) > conf.ChannelLimit {
+			feeds = feeds[:conf.ChannelLimit]
+		}
+	}
+
+	var channels []researcher.Channel
+	var firstErr error
+	for _, feedURL := range feeds {
+		ch, err := p.channel(ctx, feedURL)
+		if err != nil {
+			fmt.Printf("WARNING: feed %s: %v\n", feedURL, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		channels = append(channels, ch)
+	}
+	if len(channels) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return channels, nil
+}
+
+func (p *Platform) channel(ctx context.Context, feedURL string) (researcher.Channel, error) {
+	var cursor feedCursor
+	if raw := p.state.Cursor(feedURL); raw != "" {
+		_ = json.Unmarshal([]byte(raw), &cursor)
+	}
+
+	resp, err := p.getter.Get(ctx, feedURL, cursor.Validators)
+	if err != nil {
+		return researcher.Channel{}, err
+	}
+	var items []Item
+	if !resp.NotModified {
+		feed, err := Parse(resp.Body)
+		if err != nil {
+			return researcher.Channel{}, err
+		}
+		cursor.Title = feed.Title
+		cursor.Site = feed.Link
+		items = feed.Items
+	}
+	// Валидаторы сохраняет CommitChannel, когда записи ленты отправлены:
+	// иначе после ошибки сервера следующий обход получил бы 304 без них
+	cursor.Validators = resp.Validators
+
+	site := siteHost(cursor.Site, feedURL)
+	title := cursor.Title
+	if title == "" {
+		title = site
+	}
+	return researcher.Channel{
+		ExternalID: feedURL,
+		Name:       title,
+		Link:       feedURL,
+		Topic:      "news",
+		Author: &apiclient.Author{
+			Name:       fmt.Sprintf("RSS: %s", title),
+			Platform:   "rss",
+			ExternalID: feedURL,
+			ProfileURL: firstNonEmpty(cursor.Site, feedURL),
+		},
+		Source: &apiclient.Source{Name: site, Address: site, Topic: "news"},
+		Raw:    feedChannel{items: items, cursor: cursor},
+	}, nil
+}
+
+// feedChannel - Channel.Raw ленты: записи и курсор, который ещё не сохранён
+type feedChannel struct {
+	items  []Item
+	cursor feedCursor
+}
+
+// CommitChannel сохраняет валидаторы и заголовок ленты после отправки её записей
+func (p *Platform) CommitChannel(ch researcher.Channel) {
+	if raw, err := json.Marshal(ch.Raw.(feedChannel).cursor); err == nil {
+		p.state.SetCursor(ch.ExternalID, string(raw))
+	}
+}
+
+// siteHost - домен сайта ленты без www: из ссылки на сайт или адреса самой ленты
+func siteHost(links ...string) string {
+	for _, link := range links {
+		if u, err := url.Parse(link); err == nil && u.Hostname() != "" {
+			return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		}
+	}
+	return ""
+}
+
+// FetchPosts - записи ленты от новых к старым, без повторов GUID
+func (p *Platform) FetchPosts(ctx context.Context, ch researcher.Channel, limit int) ([]researcher.Post, error) {
+	items := append([]Item(nil), ch.Raw.(feedChannel).items...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Published.After(items[j].Published) })
+
+	site := ch.Source.Address
+	var posts []researcher.Post
+	seen := map[string]bool{}
+	for _, it := range items {
+		if len(posts) >= limit {
+			break
+		}
+		if seen[it.GUID] {
+			continue
+		}
+		seen[it.GUID] = true
+		posts = append(posts, itemPost(it, site))
+	}
+	return posts, nil
+}
+
+func itemPost(it Item, site string) researcher.Post {
+	// Авторы разных изданий - разные люди: к имени добавляется сайт, иначе
+	// авторы с одинаковыми именами стали бы на сервере одним
+	var author *apiclient.Author
+	if it.Author != "" {
+		author = &apiclient.Author{
+			Name:       fmt.Sprintf("%s (%s)", it.Author, site),
+			Platform:   "rss",
+			ExternalID: site + "/" + it.Author,
+			ProfileURL: it.AuthorURL,
+		}
+	}
+	return researcher.Post{
+		ExternalID: it.GUID,
+		Title:      it.Title,
+		Text:       it.Text,
+		URL:        it.Link,
+		Author:     author,
+		CreatedAt:  it.Published,
+		Tags:       it.Categories,
+		Raw:        it,
+	}
+}
+
+// FetchComments - комментариев в лентах нет
+func (p *Platform) FetchComments(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Comment, error) {
+	return nil, nil
+}
+
+// FetchMedia - вложения записи, без дополнительных запросов
+func (p *Platform) FetchMedia(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Media, error) {
+	it := post.Raw.(Item)
+	var media []researcher.Media
+	seen := map[string]bool{}
+	for _, e := range it.Enclosures {
+		if len(media) >= limit {
+			break
+		}
+		if seen[e.URL] {
+			continue
+		}
+		seen[e.URL] = true
+		media = append(media, researcher.Media{Type: e.MediaType(), URL: e.URL})
+	}
+	return media, nil
+}
@@ -0,0 +1,53 @@
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"researcher"
+)
+
+func TestListChannelsSavesValidatorsOnCommit(t *testing.T) {
+	ctx := context.Background()
+	state, err := researcher.OpenState("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPlatform(DirGetter{Dir: testdata}, state)
+	conf := researcher.Config{PreferredChannels: []string{"https://lenta.ru/rss/news"}}
+
+	channels, err := p.ListChannels(ctx, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 1 || channels[0].Source.Address != "lenta.ru" || channels[0].Name != "Lenta.ru : Новости" {
+		t.Fatalf("channels = %+v", channels)
+	}
+	posts, err := p.FetchPosts(ctx, channels[0], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Повтор GUID пропускается, записи от новых к старым
+	if len(posts) != 3 || posts[0].ExternalID != "https://lenta.ru/news/2024/05/01/match/" {
+		t.Fatalf("posts = %+v", posts)
+	}
+
+	// Записи не отправлены: лента загружается заново целиком
+	if state.Cursor("https://lenta.ru/rss/news") != "" {
+		t.Error("validators saved before commit")
+	}
+	channels, _ = p.ListChannels(ctx, conf)
+	if posts, _ := p.FetchPosts(ctx, channels[0], 10); len(posts) != 3 {
+		t.Errorf("posts before commit = %d, want 3", len(posts))
+	}
+
+	// После CommitChannel лента не изменилась (304): канал без записей, заголовок из курсора
+	p.CommitChannel(channels[0])
+	channels, err = p.ListChannels(ctx, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts, _ := p.FetchPosts(ctx, channels[0], 10); len(posts) != 0 || channels[0].Name != "Lenta.ru : Новости" {
+		t.Errorf("not modified feed: name %q, posts %+v", channels[0].Name, posts)
+	}
+}
@@ -0,0 +1,131 @@
+package feed
+
+import (
+	"strconv"
+	"strings"
+)
+
+// rssDoc - RSS 2.0 и 0.9x (<rss><channel><item>) и RSS 1.0 (<rdf:RDF>, записи вне <channel>)
+type rssDoc struct {
+	Channel struct {
+		Title string `xml:"title"`
+		// Ссылок может быть несколько: <link> и <atom:link rel="self"/> без текста
+		Links []string  `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	// FullText - полный текст для Яндекс.Новостей, есть у многих русскоязычных изданий
+	FullText   string   `xml:"http://news.yandex.ru full-text"`
+	Author     string   `xml:"author"`
+	Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories []string `xml:"category"`
+	Subjects   []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	GUID       string   `xml:"guid"`
+	About      string   `xml:"about,attr"`
+	PubDate    string   `xml:"pubDate"`
+	Date       string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Enclosures []struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+	} `xml:"enclosure"`
+	mediaElements
+}
+
+// mediaElements - вложения Media RSS (media:content, media:group, media:thumbnail)
+type mediaElements struct {
+	MediaContent []mediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroups  []struct {
+		Content []mediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	} `xml:"http://search.yahoo.com/mrss/ group"`
+	MediaThumbnails []struct {
+		URL string `xml:"url,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+type mediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize string `xml:"fileSize,attr"`
+}
+
+func (m mediaElements) enclosures() []Enclosure {
+	var enclosures []Enclosure
+	contents := m.MediaContent
+	for _, g := range m.MediaGroups {
+		contents = append(contents, g.Content...)
+	}
+	for _, c := range contents {
+		if c.URL != "" {
+			size, _ := strconv.ParseInt(c.FileSize, 10, 64)
+			enclosures = append(enclosures, Enclosure{URL: c.URL, Type: c.Type, Medium: c.Medium, Length: size})
+		}
+	}
+	// Миниатюра нужна, только если других картинок нет
+	if len(enclosures) == 0 {
+		for _, t := range m.MediaThumbnails {
+			if t.URL != "" {
+				enclosures = append(enclosures, Enclosure{URL: t.URL, Medium: "image"})
+			}
+		}
+	}
+	return enclosures
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	var doc rssDoc
+	if err := newDecoder(data).Decode(&doc); err != nil {
+		return nil, err
+	}
+	feed := &Feed{Title: strings.TrimSpace(doc.Channel.Title), Link: firstNonEmpty(doc.Channel.Links...)}
+	for _, ri := range append(doc.Channel.Items, doc.Items...) {
+		it := Item{
+			GUID:       firstNonEmpty(ri.GUID, ri.About),
+			Title:      ri.Title,
+			Link:       firstNonEmpty(ri.Links...),
+			Text:       firstNonEmpty(ri.Content, ri.FullText, ri.Description),
+			Author:     firstNonEmpty(ri.Creator, rssAuthor(ri.Author)),
+			Categories: append(ri.Categories, ri.Subjects...),
+			Published:  parseDate(ri.PubDate, ri.Date),
+		}
+		for _, e := range ri.Enclosures {
+			if e.URL != "" {
+				length, _ := strconv.ParseInt(e.Length, 10, 64)
+				it.Enclosures = append(it.Enclosures, Enclosure{URL: e.URL, Type: e.Type, Length: length})
+			}
+		}
+		it.Enclosures = append(it.Enclosures, ri.enclosures()...)
+		feed.Items = append(feed.Items, it)
+	}
+	return feed, nil
+}
+
+// rssAuthor - имя из <author>: по спецификации это e-mail с именем в скобках
+// ("news@example.com (Иван Петров)"); голый e-mail за имя не считается
+func rssAuthor(author string) string {
+	author = strings.TrimSpace(author)
+	if open := strings.IndexByte(author, '('); open >= 0 && strings.HasSuffix(author, ")") {
+		return strings.TrimSpace(author[open+1 : len(author)-1])
+	}
+	if strings.Contains(author, "@") && !strings.Contains(author, " ") {
+		return ""
+	}
+	return author
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"apiclient"
+	"researcher"
+	"researcher-rss/internal/feed"
+)
+
+const (
+	serverURL      = "http://server:8080"
+	configFileName = "/usr/local/etc/rss-researcher/test_conf.xml"
+	stateFileName  = "/var/lib/rss-researcher/state.json"
+	userAgent      = "NewsAggregatorResearcher/0.1 (+https://github.com/Vi1689/News-Aggregator)"
+	// requestInterval - пауза между запросами лент
+	requestInterval = time.Second
+)
+
+func main() {
+	parseFile := flag.String("parse", "", "разобрать сохранённую ленту (RSS, Atom, JSON Feed) и вывести записи в JSON")
+	fixtures := flag.String("fixtures", os.Getenv("RSS_FIXTURES"), "читать ленты из каталога фикстур вместо сайтов")
+	flag.Parse()
+
+	if *parseFile != "" {
+		if err := printParsed(*parseFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Небольшая задержка для запуска сервера
+	time.Sleep(5 * time.Second)
+
+	var getter feed.Getter = feed.NewHTTPGetter(userAgent, researcher.NewLimiter(requestInterval))
+	if *fixtures != "" {
+		fmt.Printf("Reading feeds from fixtures in %s\n", *fixtures)
+		getter = feed.DirGetter{Dir: *fixtures}
+	}
+
+	state, err := researcher.OpenState(stateFileName)
+	if err != nil {
+		fmt.Printf("Failed to open state: %v\n", err)
+		os.Exit(1)
+	}
+
+	runner := &researcher.Runner{
+		Platform:   feed.NewPlatform(getter, state),
+		Ingest:     researcher.NewIngest(apiclient.New(serverURL)),
+		State:      state,
+		ConfigPath: configFileName,
+	}
+	if err := runner.Run(context.Background()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// printParsed выводит то, что researcher извлекает из ленты: для проверки
+// разбора на записанных лентах без сети и сервера
+func printParsed(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	parsed, err := feed.Parse(data)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(parsed)
+}
@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export - канал, выгруженный Telegram Desktop ("Экспорт истории канала", формат JSON)
+type Export struct {
+	Channel ChannelInfo
+	// Messages - сообщения от старых к новым, как в файле
+	Messages []Message
+}
+
+type exportFile struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	ID       int64           `json:"id"`
+	Messages []exportMessage `json:"messages"`
+}
+
+type exportMessage struct {
+	ID            int             `json:"id"`
+	Type          string          `json:"type"`
+	Date          string          `json:"date"`
+	DateUnixtime  string          `json:"date_unixtime"`
+	ForwardedFrom string          `json:"forwarded_from"`
+	Author        string          `json:"author"`
+	Text          json.RawMessage `json:"text"`
+	Photo         string          `json:"photo"`
+	File          string          `json:"file"`
+	Thumbnail     string          `json:"thumbnail"`
+	MediaType     string          `json:"media_type"`
+	Reactions     []struct {
+		Emoji string `json:"emoji"`
+		Count int    `json:"count"`
+	} `json:"reactions"`
+}
+
+// ParseExport разбирает result.json экспорта канала. Служебные сообщения
+// пропускаются; ссылки на сообщения строятся как t.me/c/<id>/<message>.
+func ParseExport(data []byte) (Export, error) {
+	var file exportFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Export{}, fmt.Errorf("telegram export: %w", err)
+	}
+	if file.ID == 0 {
+		return Export{}, fmt.Errorf("telegram export: no channel id")
+	}
+
+	export := Export{Channel: ChannelInfo{ID: file.ID, Title: file.Name}}
+	for _, em := range file.Messages {
+		if em.Type != "message" {
+			continue
+		}
+		m := Message{
+			ID:            em.ID,
+			URL:           fmt.Sprintf("%s/c/%d/%d", BaseURL, file.ID, em.ID),
+			Text:          exportText(em.Text),
+			Date:          exportDate(em.DateUnixtime, em.Date),
+			ForwardedFrom: em.ForwardedFrom,
+			Signature:     em.Author,
+		}
+		for _, r := range em.Reactions {
+			m.Reactions = append(m.Reactions, Reaction{Emoji: r.Emoji, Count: r.Count})
+		}
+		m.Media = exportMedia(em, m.URL)
+		export.Messages = append(export.Messages, m)
+	}
+	return export, nil
+}
+
+// exportText - текст сообщения: строка или массив из строк и сущностей
+// ({"type": "bold", "text": "..."})
+func exportText(raw json.RawMessage) string {
+	var plain string
+	if err := json.Unmarshal(raw, &plain); err == nil {
+		return strings.TrimSpace(plain)
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range parts {
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &plain); err == nil {
+			b.WriteString(plain)
+		} else if err := json.Unmarshal(part, &entity); err == nil {
+			b.WriteString(entity.Text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// exportDate - время из date_unixtime; у старых экспортов есть только местное время date
+func exportDate(unix, local string) time.Time {
+	if sec, err := strconv.ParseInt(unix, 10, 64); err == nil && sec > 0 {
+		return time.Unix(sec, 0)
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", local, time.Local); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// exportMedia - вложение сообщения. Пути в экспорте относительные (photos/...);
+// если файл не выгружен, вместо пути ссылка на сообщение.
+func exportMedia(em exportMessage, messageURL string) []Media {
+	path := func(p string) string {
+		if p == "" || strings.HasPrefix(p, "(") {
+			// "(File not included. Change data exporting settings to download.)"
+			return messageURL
+		}
+		return p
+	}
+	if em.Photo != "" {
+		return []Media{{Type: "photo", URL: path(em.Photo)}}
+	}
+	if em.File == "" {
+		return nil
+	}
+	preview := ""
+	if em.Thumbnail != "" && !strings.HasPrefix(em.Thumbnail, "(") {
+		preview = em.Thumbnail
+	}
+	switch em.MediaType {
+	case "sticker":
+		return nil
+	case "video_file", "animation", "video_message":
+		return []Media{{Type: "video", URL: path(em.File), Preview: preview}}
+	case "audio_file", "voice_message":
+		return []Media{{Type: "audio", URL: path(em.File)}}
+	}
+	return []Media{{Type: "document", URL: path(em.File)}}
+}
@@ -0,0 +1,255 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"apiclient"
+	"researcher"
+	"researcher/htmlq"
+)
+
+// maxPages - страниц превью канала за один обход (около 20 сообщений на страницу)
+const maxPages = 10
+
+// defaultChannels - каналы без preferred_channels: каталога популярных каналов
+// у Telegram нет
+var defaultChannels = []string{"durov", "telegram", "tginfo", "rian_ru", "tass_agency", "meduzalive", "bbcrussian"}
+
+// Platform - адаптер Telegram для researcher.Runner. Канал - публичный канал,
+// читаемый через веб-превью, или файл экспорта Telegram Desktop. Автор постов -
+// сам канал, как у групп VK.
+type Platform struct {
+	fetcher htmlq.Fetcher
+	// exportDir - каталог, от которого считаются относительные пути к экспортам
+	exportDir string
+}
+
+// NewPlatform создаёт адаптер; fetcher - сайт или каталог фикстур
+func NewPlatform(fetcher htmlq.Fetcher, exportDir string) *Platform {
+	return &Platform{fetcher: fetcher, exportDir: exportDir}
+}
+
+func (p *Platform) Name() string { return "Telegram" }
+
+func (p *Platform) Source() apiclient.Source {
+	return apiclient.Source{Name: "Telegram", Address: "t.me", Topic: "social"}
+}
+
+// channelData - содержимое канала, прочитанное в ListChannels: первая страница
+// превью или весь экспорт
+type channelData struct {
+	page   *Page
+	export *Export
+}
+
+// ListChannels - каналы из preferred_channels ("durov", "@durov", "https://t.me/durov"
+// или путь к result.json экспорта) или встроенный список новостных каналов.
+// Заголовок и число подписчиков берутся с первой страницы превью.
+func (p *Platform) ListChannels(ctx context.Context, conf researcher.Config) ([]researcher.Channel, error) {
+	names := conf.PreferredChannels
+	if len(names) == 0 {
+		names = defaultChannels
+		if conf.ChannelLimit > 0 && len(names) > conf.ChannelLimit {
+			names = names[:conf.ChannelLimit]
+		}
+	}
+
+	var channels []researcher.Channel
+	var firstErr error
+	for _, name := range names {
+		ch, err := p.channel(ctx, name)
+		if err != nil {
+			fmt.Printf("WARNING: telegram channel %s: %v\n", name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		channels = append(channels, ch)
+	}
+	if len(channels) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return channels, nil
+}
+
+func (p *Platform) channel(ctx context.Context, name string) (researcher.Channel, error) {
+	if strings.HasSuffix(name, ".json") {
+		path := name
+		if !filepath.IsAbs(path) && p.exportDir != "" {
+			path = filepath.Join(p.exportDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return researcher.Channel{}, err
+		}
+		export, err := ParseExport(data)
+		if err != nil {
+			return researcher.Channel{}, err
+		}
+		link := fmt.Sprintf("%s/c/%d", BaseURL, export.Channel.ID)
+		return newChannel("export/"+strconv.FormatInt(export.Channel.ID, 10), link, export.Channel,
+			channelData{export: &export}), nil
+	}
+
+	username := channelUsername(name)
+	doc, err := p.fetcher.Fetch(ctx, previewURL(username, 0))
+	if err != nil {
+		return researcher.Channel{}, err
+	}
+	page := ParsePreview(doc)
+	if page.Channel.Title == "" {
+		// Вместо превью t.me отдаёт страницу-заглушку: канала нет или он приватный
+		return researcher.Channel{}, fmt.Errorf("no public preview")
+	}
+	if page.Channel.Username == "" {
+		page.Channel.Username = username
+	}
+	return newChannel("@"+page.Channel.Username, BaseURL+"/"+page.Channel.Username, page.Channel,
+		channelData{page: &page}), nil
+}
+
+func newChannel(externalID, link string, info ChannelInfo, data channelData) researcher.Channel {
+	reputation := int64(info.Subscribers)
+	return researcher.Channel{
+		ExternalID:  externalID,
+		Name:        info.Title,
+		Link:        link,
+		Subscribers: info.Subscribers,
+		Topic:       "general",
+		Author: &apiclient.Author{
+			Name:               fmt.Sprintf("Telegram: %s", info.Title),
+			Platform:           "telegram",
+			ExternalID:         externalID,
+			ProfileURL:         link,
+			PlatformReputation: &reputation,
+		},
+		Raw: data,
+	}
+}
+
+// channelUsername - имя канала из "@name", "t.me/name" или "https://t.me/s/name"
+func channelUsername(name string) string {
+	name = strings.TrimSpace(name)
+	for _, prefix := range []string{"https://", "http://", "t.me/", "telegram.me/", "s/", "@"} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	name, _, _ = strings.Cut(name, "/")
+	return name
+}
+
+func previewURL(username string, before int) string {
+	if before > 0 {
+		return fmt.Sprintf("%s/s/%s?before=%d", BaseURL, username, before)
+	}
+	return BaseURL + "/s/" + username
+}
+
+// FetchPosts - последние сообщения канала, от новых к старым. Превью
+// листается назад по ?before=<id>, пока не наберётся limit сообщений.
+func (p *Platform) FetchPosts(ctx context.Context, ch researcher.Channel, limit int) ([]researcher.Post, error) {
+	data := ch.Raw.(channelData)
+	if data.export != nil {
+		return newestPosts(data.export.Messages, nil, limit), nil
+	}
+
+	page := data.page
+	var posts []researcher.Post
+	seen := map[int]bool{}
+	for n := 1; ; n++ {
+		posts = newestPosts(page.Messages, seen, limit-len(posts), posts...)
+		if len(posts) >= limit || page.Before == 0 || n >= maxPages {
+			break
+		}
+		doc, err := p.fetcher.Fetch(ctx, previewURL(page.Channel.Username, page.Before))
+		if err != nil {
+			if !htmlq.IsNotFound(err) {
+				fmt.Printf("WARNING: %s: %v\n", previewURL(page.Channel.Username, page.Before), err)
+			}
+			break
+		}
+		next := ParsePreview(doc)
+		next.Channel.Username = page.Channel.Username
+		if len(next.Messages) == 0 {
+			break
+		}
+		page = &next
+	}
+	return posts, nil
+}
+
+// newestPosts добавляет к posts до limit сообщений, начиная с последнего;
+// seen (может быть nil) отсекает сообщения, встречавшиеся на прошлых страницах
+func newestPosts(messages []Message, seen map[int]bool, limit int, posts ...researcher.Post) []researcher.Post {
+	for i := len(messages) - 1; i >= 0 && limit > 0; i-- {
+		m := messages[i]
+		if seen != nil {
+			if seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+		}
+		if m.Text == "" && len(m.Media) == 0 {
+			continue
+		}
+		posts = append(posts, messagePost(m))
+		limit--
+	}
+	return posts
+}
+
+var hashtag = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_]+)`)
+
+func messagePost(m Message) researcher.Post {
+	text := m.Text
+	if m.ForwardedFrom != "" {
+		text = strings.TrimSpace(fmt.Sprintf("Переслано из: %s\n%s", m.ForwardedFrom, text))
+	}
+
+	var tags []string
+	seen := map[string]bool{}
+	for _, match := range hashtag.FindAllStringSubmatch(m.Text, -1) {
+		if tag := strings.ToLower(match[1]); !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	// Автора нет: Runner возьмёт канал (ch.Author), подпись автора в канале -
+	// не аккаунт и профиля не имеет
+	return researcher.Post{
+		ExternalID: strconv.Itoa(m.ID),
+		Text:       text,
+		URL:        m.URL,
+		CreatedAt:  m.Date,
+		Likes:      m.ReactionsCount(),
+		Views:      m.Views,
+		Tags:       tags,
+		Raw:        m,
+	}
+}
+
+// FetchComments - обсуждения каналов находятся в отдельной группе, и ни превью,
+// ни экспорт канала их не содержат
+func (p *Platform) FetchComments(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Comment, error) {
+	return nil, nil
+}
+
+// FetchMedia - вложения сообщения, без дополнительных запросов
+func (p *Platform) FetchMedia(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Media, error) {
+	m := post.Raw.(Message)
+	var media []researcher.Media
+	for _, item := range m.Media {
+		if len(media) >= limit {
+			break
+		}
+		media = append(media, researcher.Media{Type: item.Type, URL: item.URL, Thumbnail: item.Preview})
+	}
+	return media, nil
+}
@@ -0,0 +1,206 @@
+// Package telegram - чтение публичных каналов Telegram без API-ключа: веб-превью
+// канала (t.me/s/<channel>) или экспорт канала из Telegram Desktop (result.json),
+// и адаптер платформы для researcher.Runner.
+//
+// Разметка превью и формат экспорта, на которые опирается разбор, записаны в
+// researchers/telegram/testdata.
+package telegram
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"researcher/htmlq"
+)
+
+// BaseURL - адрес веб-версии каналов
+const BaseURL = "https://t.me"
+
+// ChannelInfo - заголовок канала
+type ChannelInfo struct {
+	// Username - имя канала без @; у экспорта его нет
+	Username string
+	// ID - числовой ID канала из экспорта
+	ID          int64
+	Title       string
+	Description string
+	Subscribers int
+}
+
+// Reaction - реакция на сообщение и число поставивших её
+type Reaction struct {
+	Emoji string
+	Count int
+}
+
+// Media - вложение сообщения
+type Media struct {
+	Type    string // photo, video, audio, document, link
+	URL     string
+	Preview string
+}
+
+// Message - сообщение канала
+type Message struct {
+	ID   int
+	URL  string
+	Text string
+	Date time.Time
+	// Views - просмотры; в экспорте их нет
+	Views     int
+	Reactions []Reaction
+	// ForwardedFrom - канал или пользователь, из которого переслано сообщение
+	ForwardedFrom    string
+	ForwardedFromURL string
+	// Signature - подпись автора, если в канале включены подписи
+	Signature string
+	Media     []Media
+}
+
+// ReactionsCount - сумма всех реакций сообщения
+func (m Message) ReactionsCount() int {
+	total := 0
+	for _, r := range m.Reactions {
+		total += r.Count
+	}
+	return total
+}
+
+// Page - страница превью канала
+type Page struct {
+	Channel ChannelInfo
+	// Messages - сообщения в порядке страницы: от старых к новым
+	Messages []Message
+	// Before - ID для загрузки более старых сообщений (?before=<id>); 0 - их нет
+	Before int
+}
+
+// ParsePreview разбирает страницу t.me/s/<channel>. Служебные сообщения
+// (создание канала, смена названия) пропускаются.
+func ParsePreview(doc *htmlq.Node) Page {
+	page := Page{Channel: parseChannelInfo(doc)}
+	minID := 0
+	for _, el := range doc.Find(".tgme_widget_message[data-post]") {
+		if el.HasClass("service_message") {
+			continue
+		}
+		m, ok := parseMessage(el)
+		if !ok {
+			continue
+		}
+		if minID == 0 || m.ID < minID {
+			minID = m.ID
+		}
+		page.Messages = append(page.Messages, m)
+	}
+
+	if more := doc.First("a.tme_messages_more[data-before]"); more != nil {
+		page.Before, _ = strconv.Atoi(more.Attr("data-before"))
+	} else if minID > 1 && doc.First(".tme_messages_more") != nil {
+		page.Before = minID
+	}
+	return page
+}
+
+func parseChannelInfo(doc *htmlq.Node) ChannelInfo {
+	info := ChannelInfo{
+		Title:       doc.First(".tgme_channel_info_header_title").Text(),
+		Username:    strings.TrimPrefix(doc.First(".tgme_channel_info_header_username").Text(), "@"),
+		Description: doc.First(".tgme_channel_info_description").Paragraphs(),
+	}
+	for _, counter := range doc.Find(".tgme_channel_info_counter") {
+		kind := counter.First(".counter_type").Text()
+		if strings.HasPrefix(kind, "subscriber") || strings.HasPrefix(kind, "member") {
+			info.Subscribers, _ = htmlq.ParseCount(counter.First(".counter_value").Text())
+		}
+	}
+	return info
+}
+
+func parseMessage(el *htmlq.Node) (Message, bool) {
+	// data-post="channel/123"
+	post := el.Attr("data-post")
+	slash := strings.LastIndexByte(post, '/')
+	if slash < 0 {
+		return Message{}, false
+	}
+	id, err := strconv.Atoi(post[slash+1:])
+	if err != nil {
+		return Message{}, false
+	}
+	m := Message{ID: id, URL: BaseURL + "/" + post}
+
+	date := el.First(".tgme_widget_message_date")
+	if href := date.Attr("href"); href != "" {
+		m.URL = href
+	}
+	if t, err := time.Parse(time.RFC3339, date.First("time").Attr("datetime")); err == nil {
+		m.Date = t
+	}
+
+	// Цитата в ответе на сообщение тоже может содержать текст - берём только свой
+	for _, text := range el.Find(".tgme_widget_message_text") {
+		if text.Closest(".tgme_widget_message_reply, .tgme_widget_message_link_preview") == nil {
+			m.Text = text.Paragraphs()
+			break
+		}
+	}
+
+	m.Views, _ = htmlq.ParseCount(el.First(".tgme_widget_message_views").Text())
+	m.Signature = el.First(".tgme_widget_message_from_author").Text()
+
+	if from := el.First(".tgme_widget_message_forwarded_from_name"); from != nil {
+		m.ForwardedFrom = from.Text()
+		m.ForwardedFromURL = from.Attr("href")
+	}
+
+	for _, r := range el.Find(".tgme_reaction") {
+		emoji := r.First(".emoji").Text()
+		count, ok := htmlq.ParseCount(strings.TrimSpace(strings.TrimPrefix(r.Text(), emoji)))
+		if ok {
+			m.Reactions = append(m.Reactions, Reaction{Emoji: emoji, Count: count})
+		}
+	}
+
+	m.Media = parseMedia(el, m.URL)
+	return m, true
+}
+
+// parseMedia - фото (в альбоме их несколько), видео, документы и превью ссылок
+func parseMedia(el *htmlq.Node, messageURL string) []Media {
+	var media []Media
+	for _, photo := range el.Find(".tgme_widget_message_photo_wrap") {
+		if src := backgroundImage(photo.Attr("style")); src != "" {
+			media = append(media, Media{Type: "photo", URL: src})
+		}
+	}
+	for _, player := range el.Find(".tgme_widget_message_video_player") {
+		thumb := backgroundImage(player.First(".tgme_widget_message_video_thumb").Attr("style"))
+		// Большие видео превью не отдаёт - остаётся ссылка на сообщение
+		src := player.First("video").Attr("src")
+		if src == "" {
+			src = messageURL
+		}
+		media = append(media, Media{Type: "video", URL: src, Preview: thumb})
+	}
+	for range el.Find(".tgme_widget_message_document_wrap") {
+		media = append(media, Media{Type: "document", URL: messageURL})
+	}
+	if link := el.First("a.tgme_widget_message_link_preview"); link != nil && link.Attr("href") != "" {
+		preview := backgroundImage(link.First(".link_preview_image, .link_preview_right_image").Attr("style"))
+		media = append(media, Media{Type: "link", URL: link.Attr("href"), Preview: preview})
+	}
+	return media
+}
+
+var backgroundURL = regexp.MustCompile(`background-image:\s*url\(['"]?([^'")]+)['"]?\)`)
+
+// backgroundImage - адрес картинки из style="background-image:url('...')"
+func backgroundImage(style string) string {
+	if m := backgroundURL.FindStringSubmatch(style); m != nil {
+		return m[1]
+	}
+	return ""
+}
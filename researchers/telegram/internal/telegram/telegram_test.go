@@ -0,0 +1,246 @@
+package telegram
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"researcher"
+	"researcher/htmlq"
+)
+
+// testdata - записанные страницы превью и экспорт канала tginfo
+const testdata = "../../testdata"
+
+func parseFixture(t *testing.T, name string) Page {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(testdata, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ParsePreview(htmlq.ParseString(htmlq.Decode(raw, "")))
+}
+
+func messageIDs(messages []Message) []int {
+	ids := make([]int, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func mediaTypes(media []Media) []string {
+	types := make([]string, 0, len(media))
+	for _, m := range media {
+		types = append(types, m.Type)
+	}
+	return types
+}
+
+func TestParsePreview(t *testing.T) {
+	page := parseFixture(t, "s_tginfo.html")
+
+	want := ChannelInfo{
+		Username:    "tginfo",
+		Title:       "Telegram Info",
+		Description: "Новости о Telegram.\nОбратная связь: @tginfochat",
+		Subscribers: 412000,
+	}
+	if page.Channel != want {
+		t.Errorf("channel = %+v, want %+v", page.Channel, want)
+	}
+	// Служебное сообщение 5003 пропускается
+	if ids := messageIDs(page.Messages); !reflect.DeepEqual(ids, []int{5001, 5002, 5004}) {
+		t.Fatalf("message ids = %v, want [5001 5002 5004]", ids)
+	}
+	if page.Before != 5001 {
+		t.Errorf("before = %d, want 5001", page.Before)
+	}
+
+	update, forwarded, link := page.Messages[0], page.Messages[1], page.Messages[2]
+
+	if update.URL != "https://t.me/tginfo/5001" {
+		t.Errorf("5001 url = %q", update.URL)
+	}
+	if want := time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC); !update.Date.Equal(want) {
+		t.Errorf("5001 date = %v, want %v", update.Date, want)
+	}
+	if update.Views != 98400 {
+		t.Errorf("5001 views = %d, want 98400", update.Views)
+	}
+	if len(update.Reactions) != 3 || update.Reactions[0] != (Reaction{Emoji: "👍", Count: 1500}) {
+		t.Errorf("5001 reactions = %+v", update.Reactions)
+	}
+	if n := update.ReactionsCount(); n != 1832 {
+		t.Errorf("5001 reactions count = %d, want 1832", n)
+	}
+	if got := mediaTypes(update.Media); !reflect.DeepEqual(got, []string{"photo"}) ||
+		update.Media[0].URL != "https://cdn4.telesco.pe/file/tginfo_5001_a.jpg" {
+		t.Errorf("5001 media = %+v", update.Media)
+	}
+
+	if forwarded.Views != 120000 {
+		t.Errorf("5002 views = %d, want 120000", forwarded.Views)
+	}
+	if forwarded.ForwardedFrom != "Telegram News" || forwarded.ForwardedFromURL != "https://t.me/telegram" {
+		t.Errorf("5002 forwarded from = %q (%q)", forwarded.ForwardedFrom, forwarded.ForwardedFromURL)
+	}
+	wantVideo := Media{
+		Type:    "video",
+		URL:     "https://cdn4.telesco.pe/file/tginfo_5002.mp4",
+		Preview: "https://cdn4.telesco.pe/file/tginfo_5002_thumb.jpg",
+	}
+	if len(forwarded.Media) != 1 || forwarded.Media[0] != wantVideo {
+		t.Errorf("5002 media = %+v, want %+v", forwarded.Media, wantVideo)
+	}
+
+	if link.Views != 41700 || len(link.Reactions) != 0 {
+		t.Errorf("5004 views = %d, reactions = %+v", link.Views, link.Reactions)
+	}
+	if link.Signature != "Марина" {
+		t.Errorf("5004 signature = %q", link.Signature)
+	}
+	wantLink := Media{
+		Type:    "link",
+		URL:     "https://telegram.org/blog/pinned-stories",
+		Preview: "https://cdn4.telesco.pe/file/blog_pinned_stories.jpg",
+	}
+	if len(link.Media) != 1 || link.Media[0] != wantLink {
+		t.Errorf("5004 media = %+v, want %+v", link.Media, wantLink)
+	}
+}
+
+func TestParsePreviewOldestPage(t *testing.T) {
+	page := parseFixture(t, "s_tginfo_before_5001.html")
+
+	if ids := messageIDs(page.Messages); !reflect.DeepEqual(ids, []int{4999, 5000}) {
+		t.Fatalf("message ids = %v, want [4999 5000]", ids)
+	}
+	// Более старых сообщений нет
+	if page.Before != 0 {
+		t.Errorf("before = %d, want 0", page.Before)
+	}
+
+	album, doc := page.Messages[0], page.Messages[1]
+	if album.Views != 75200 || album.ReactionsCount() != 0 {
+		t.Errorf("4999 views = %d, reactions = %d", album.Views, album.ReactionsCount())
+	}
+	if got := mediaTypes(album.Media); !reflect.DeepEqual(got, []string{"photo", "photo"}) {
+		t.Errorf("4999 media = %v, want two photos", got)
+	}
+	if doc.Views != 52000 || doc.ReactionsCount() != 640 {
+		t.Errorf("5000 views = %d, reactions = %d", doc.Views, doc.ReactionsCount())
+	}
+	// У документа без ссылки на файл URL - само сообщение
+	if len(doc.Media) != 1 || doc.Media[0] != (Media{Type: "document", URL: "https://t.me/tginfo/5000"}) {
+		t.Errorf("5000 media = %+v", doc.Media)
+	}
+}
+
+func TestFetchPostsPaginatesByBefore(t *testing.T) {
+	ctx := context.Background()
+	p := NewPlatform(htmlq.DirFetcher{Dir: testdata}, "")
+
+	channels, err := p.ListChannels(ctx, researcher.Config{PreferredChannels: []string{"https://t.me/s/tginfo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 1 || channels[0].ExternalID != "@tginfo" || channels[0].Subscribers != 412000 {
+		t.Fatalf("channels = %+v", channels)
+	}
+
+	// Первая страница даёт три поста, остальные - страница ?before=5001
+	posts, err := p.FetchPosts(ctx, channels[0], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, post := range posts {
+		ids = append(ids, post.ExternalID)
+	}
+	if want := []string{"5004", "5002", "5001", "5000", "4999"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("post ids = %v, want %v", ids, want)
+	}
+
+	update := posts[2]
+	if update.Views != 98400 || update.Likes != 1832 {
+		t.Errorf("5001 views = %d, likes = %d", update.Views, update.Likes)
+	}
+	if want := []string{"обновление", "android"}; !reflect.DeepEqual(update.Tags, want) {
+		t.Errorf("5001 tags = %v, want %v", update.Tags, want)
+	}
+	if want := "Переслано из: Telegram News\nВстречайте новые анимированные реакции и темы чатов."; posts[1].Text != want {
+		t.Errorf("5002 text = %q, want %q", posts[1].Text, want)
+	}
+
+	media, err := p.FetchMedia(ctx, channels[0], posts[1], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(media) != 1 || media[0].URL != "https://cdn4.telesco.pe/file/tginfo_5002.mp4" ||
+		media[0].Thumbnail != "https://cdn4.telesco.pe/file/tginfo_5002_thumb.jpg" {
+		t.Errorf("5002 media = %+v", media)
+	}
+
+	// limit останавливает листание на первой странице
+	posts, err = p.FetchPosts(ctx, channels[0], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 2 || posts[0].ExternalID != "5004" || posts[1].ExternalID != "5002" {
+		t.Errorf("limited posts = %+v", posts)
+	}
+}
+
+func TestParseExport(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join(testdata, "exports", "tginfo_result.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	export, err := ParseExport(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if export.Channel.ID != 1001234567 || export.Channel.Title != "Telegram Info" {
+		t.Errorf("channel = %+v", export.Channel)
+	}
+	// Служебное сообщение пропускается
+	if ids := messageIDs(export.Messages); !reflect.DeepEqual(ids, []int{4990, 4991, 4992, 4993}) {
+		t.Fatalf("message ids = %v, want [4990 4991 4992 4993]", ids)
+	}
+
+	beta, video, changelog := export.Messages[0], export.Messages[1], export.Messages[2]
+	if beta.URL != "https://t.me/c/1001234567/4990" {
+		t.Errorf("4990 url = %q", beta.URL)
+	}
+	if want := time.Date(2024, 4, 29, 11, 0, 0, 0, time.UTC); !beta.Date.Equal(want) {
+		t.Errorf("4990 date = %v, want %v", beta.Date, want)
+	}
+	// В экспорте нет просмотров, реакции есть
+	if beta.Views != 0 || beta.ReactionsCount() != 875 {
+		t.Errorf("4990 views = %d, reactions = %d", beta.Views, beta.ReactionsCount())
+	}
+	if got := mediaTypes(beta.Media); !reflect.DeepEqual(got, []string{"photo"}) {
+		t.Errorf("4990 media = %v", got)
+	}
+
+	if video.ForwardedFrom != "Telegram News" {
+		t.Errorf("4991 forwarded from = %q", video.ForwardedFrom)
+	}
+	wantVideo := Media{
+		Type:    "video",
+		URL:     "video_files/intro@29-04-2024_15-30-00.mp4",
+		Preview: "video_files/intro@29-04-2024_15-30-00.mp4_thumb.jpg",
+	}
+	if len(video.Media) != 1 || video.Media[0] != wantVideo {
+		t.Errorf("4991 media = %+v, want %+v", video.Media, wantVideo)
+	}
+
+	if changelog.Signature != "Марина" || !reflect.DeepEqual(mediaTypes(changelog.Media), []string{"document"}) {
+		t.Errorf("4992 signature = %q, media = %+v", changelog.Signature, changelog.Media)
+	}
+}
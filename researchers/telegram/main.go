@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"apiclient"
+	"researcher"
+	"researcher-telegram/internal/telegram"
+	"researcher/htmlq"
+)
+
+const (
+	serverURL      = "http://server:8080"
+	configFileName = "/usr/local/etc/telegram-researcher/test_conf.xml"
+	stateFileName  = "/var/lib/telegram-researcher/state.json"
+	// exportDir - каталог с экспортами каналов (относительные пути в preferred_channels)
+	exportDir = "/usr/local/etc/telegram-researcher/exports"
+	userAgent = "NewsAggregatorResearcher/0.1 (+https://github.com/Vi1689/News-Aggregator)"
+	// requestInterval - пауза между запросами страниц t.me
+	requestInterval = 2 * time.Second
+)
+
+func main() {
+	parseFile := flag.String("parse", "", "разобрать сохранённую страницу превью или result.json экспорта и вывести сообщения в JSON")
+	fixtures := flag.String("fixtures", os.Getenv("TELEGRAM_FIXTURES"), "читать страницы из каталога фикстур вместо t.me")
+	exports := flag.String("exports", exportDir, "каталог с экспортами каналов Telegram Desktop")
+	flag.Parse()
+
+	if *parseFile != "" {
+		if err := printParsed(*parseFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Небольшая задержка для запуска сервера
+	time.Sleep(5 * time.Second)
+
+	var fetcher htmlq.Fetcher = htmlq.NewHTTPFetcher(userAgent, researcher.NewLimiter(requestInterval))
+	if *fixtures != "" {
+		fmt.Printf("Reading pages from fixtures in %s\n", *fixtures)
+		fetcher = htmlq.DirFetcher{Dir: *fixtures}
+	}
+
+	state, err := researcher.OpenState(stateFileName)
+	if err != nil {
+		fmt.Printf("Failed to open state: %v\n", err)
+		os.Exit(1)
+	}
+
+	runner := &researcher.Runner{
+		Platform:   telegram.NewPlatform(fetcher, *exports),
+		Ingest:     researcher.NewIngest(apiclient.New(serverURL)),
+		State:      state,
+		ConfigPath: configFileName,
+	}
+	if err := runner.Run(context.Background()); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// printParsed выводит то, что researcher извлекает из файла: для проверки
+// разбора на записанных страницах и экспортах без сети и сервера
+func printParsed(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var out interface{}
+	if strings.HasSuffix(path, ".json") {
+		if out, err = telegram.ParseExport(data); err != nil {
+			return err
+		}
+	} else {
+		out = telegram.ParsePreview(htmlq.ParseString(htmlq.Decode(data, "")))
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -1,6 +1,7 @@
 package sendRequests
 
 import (
+	"apiclient"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -114,14 +115,21 @@ func (rl *RequestLogger) LogRequest(endpoint string, data interface{}, success b
 
 	// Добавляем краткую информацию о данных
 	if endpoint == "/posts" {
-		if postData, ok := data.(map[string]interface{}); ok {
-			if title, ok := postData["title"].(string); ok {
-				titleShort := title
-				if len(titleShort) > 50 {
-					titleShort = titleShort[:50] + "..."
-				}
-				logEntry += fmt.Sprintf(" | Title: %s", titleShort)
+		title := ""
+		switch postData := data.(type) {
+		case apiclient.Post:
+			title = postData.Title
+		case apiclient.VKPost:
+			title = postData.Title
+		case map[string]interface{}:
+			title, _ = postData["title"].(string)
+		}
+		if title != "" {
+			titleShort := title
+			if len(titleShort) > 50 {
+				titleShort = titleShort[:50] + "..."
 			}
+			logEntry += fmt.Sprintf(" | Title: %s", titleShort)
 		}
 	}
 
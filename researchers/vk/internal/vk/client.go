@@ -0,0 +1,423 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"researcher"
+	"researcher-vk/internal/vkToken"
+)
+
+const (
+	apiURL     = "https://api.vk.com/method/"
+	apiVersion = "5.199"
+	// requestsPerSecond - документированный лимит VK API для ключа доступа
+	requestsPerSecond = 3
+	// maxExecuteCalls - максимум обращений к API внутри одного execute
+	maxExecuteCalls = 25
+	// attempts - попыток на запрос при превышении лимитов, сетевых ошибках и 5xx
+	attempts = 5
+	// baseBackoff, maxBackoff - пауза перед первой повторной попыткой и её предел
+	baseBackoff = time.Second
+	maxBackoff  = time.Minute
+)
+
+// Коды ошибок VK API
+const (
+	// errAuthFailed - токен недействителен: истёк, отозван или не того типа
+	errAuthFailed = 5
+	// errTooManyRequests - больше requestsPerSecond запросов в секунду
+	errTooManyRequests = 6
+	// errFloodControl - слишком много однотипных действий
+	errFloodControl = 9
+	// errInternal - внутренняя ошибка сервера VK
+	errInternal = 10
+	// errAppAuthFailed - метод недоступен с сервисным ключом (groups.search)
+	errAppAuthFailed = 28
+	// errRateLimit - исчерпана суточная квота метода
+	errRateLimit = 29
+	// errAccessToComments - у поста закрыты комментарии
+	errAccessToComments = 212
+)
+
+var (
+	// ErrTooManyRequests - ошибка 6; запрос повторяется с паузой
+	ErrTooManyRequests = errors.New("VK: too many requests per second")
+	// ErrFloodControl - ошибка 9; запрос повторяется с паузой
+	ErrFloodControl = errors.New("VK: flood control")
+	// ErrRateLimit - ошибка 29: квота метода исчерпана до следующих суток, метод
+	// не вызывается до полуночи по Москве
+	ErrRateLimit = errors.New("VK: method rate limit reached")
+	// ErrUserTokenRequired - ошибка 28: метод требует токена пользователя, с сервисным ключом не работает
+	ErrUserTokenRequired = errors.New("VK: method requires a user token")
+)
+
+// VKError - ошибка, которую VK API вернул в теле ответа
+type VKError struct {
+	Code    int    `json:"error_code"`
+	Message string `json:"error_msg"`
+	// Method - метод, вызванный внутри execute
+	Method string `json:"method,omitempty"`
+}
+
+func (e *VKError) Error() string {
+	if e.Method != "" {
+		return fmt.Sprintf("VK API error %d in %s: %s", e.Code, e.Method, e.Message)
+	}
+	return fmt.Sprintf("VK API error %d: %s", e.Code, e.Message)
+}
+
+// Is сопоставляет коды лимитов с ErrTooManyRequests, ErrFloodControl и ErrRateLimit,
+// код 28 - с ErrUserTokenRequired
+func (e *VKError) Is(target error) bool {
+	switch target {
+	case ErrTooManyRequests:
+		return e.Code == errTooManyRequests
+	case ErrFloodControl:
+		return e.Code == errFloodControl
+	case ErrRateLimit:
+		return e.Code == errRateLimit
+	case ErrUserTokenRequired:
+		return e.Code == errAppAuthFailed
+	}
+	return false
+}
+
+// IsAuthError - VK отклонил токен (ошибка 5)
+func IsAuthError(err error) bool {
+	var vkErr *VKError
+	return errors.As(err, &vkErr) && vkErr.Code == errAuthFailed
+}
+
+// retryable - после ошибки стоит повторить запрос
+func retryable(err error) bool {
+	var vkErr *VKError
+	if errors.As(err, &vkErr) {
+		return vkErr.Code == errTooManyRequests || vkErr.Code == errFloodControl || vkErr.Code == errInternal
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+	}
+	// Сетевые ошибки; отмена ctx прерывает повторы сама
+	return true
+}
+
+type statusError struct {
+	method string
+	code   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("VK API %s: HTTP %d", e.method, e.code)
+}
+
+// TokenSource - источник токена VK API (vkToken.Provider)
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	// Invalidate - VK отклонил токен failed; возвращает новый
+	Invalidate(ctx context.Context, failed string) (string, error)
+	// Refreshable - токен пользователя; false - сервисный ключ
+	Refreshable() bool
+}
+
+// Client - клиент VK API: все запросы researcher-а идут через него. Частоту
+// запросов ограничивает общее ведро токенов, превышение лимитов (ошибки 6 и 9)
+// приостанавливает все запросы и повторяется с экспоненциальной паузой.
+type Client struct {
+	tokens TokenSource
+	bucket *researcher.Bucket
+	http   *http.Client
+	// baseURL - адрес методов API (подменяется в проверках)
+	baseURL string
+	// now - текущее время (подменяется в проверках)
+	now func() time.Time
+
+	mu sync.Mutex
+	// exhausted - методы с исчерпанной суточной квотой и время её сброса
+	exhausted map[string]time.Time
+}
+
+// NewClient создаёт клиент с лимитом requestsPerSecond
+func NewClient(tokens TokenSource) *Client {
+	return &Client{
+		tokens:  tokens,
+		bucket:  researcher.NewBucket(requestsPerSecond, requestsPerSecond),
+		http:    &http.Client{Timeout: 30 * time.Second},
+		baseURL: apiURL,
+		now:     time.Now,
+	}
+}
+
+// ServiceKey - запросы идут с сервисным ключом: поиск групп недоступен
+func (c *Client) ServiceKey() bool {
+	return !c.tokens.Refreshable()
+}
+
+// Call вызывает метод API и разбирает поле response в out. После ошибки 5
+// запрос повторяется с новым токеном; если нового токена нет, ошибка помечается
+// researcher.Fatal: обходить дальше без токена бессмысленно. Ошибка 28 тоже
+// Fatal: метод не заработает, пока не сменится тип токена. После ошибки 29
+// метод не вызывается до сброса суточной квоты.
+func (c *Client) Call(ctx context.Context, method string, params url.Values, out interface{}) error {
+	if err := c.quotaLeft(method); err != nil {
+		return err
+	}
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return tokenError(err)
+	}
+	err = c.callWithRetry(ctx, token, method, params, out)
+	if errors.Is(err, ErrRateLimit) {
+		c.exhaust(method)
+	}
+	if errors.Is(err, ErrUserTokenRequired) {
+		return researcher.Fatal(err)
+	}
+	if !IsAuthError(err) {
+		return err
+	}
+	fmt.Printf("WARNING: VK rejected access token: %v\n", err)
+	if token, err = c.tokens.Invalidate(ctx, token); err != nil {
+		return tokenError(err)
+	}
+	err = c.callWithRetry(ctx, token, method, params, out)
+	if IsAuthError(err) {
+		return researcher.Fatal(fmt.Errorf("%w: new token rejected too: %v", vkToken.ErrAuthRequired, err))
+	}
+	return err
+}
+
+// quotaLeft - ошибка ErrRateLimit, если суточная квота метода ещё не сброшена
+func (c *Client) quotaLeft(method string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, ok := c.exhausted[method]
+	if !ok {
+		return nil
+	}
+	if !c.now().Before(until) {
+		delete(c.exhausted, method)
+		return nil
+	}
+	return fmt.Errorf("%w: %s until %s", ErrRateLimit, method, until.Format(time.RFC3339))
+}
+
+// exhaust запоминает исчерпанную квоту метода до её сброса
+func (c *Client) exhaust(method string) {
+	until := quotaReset(c.now())
+	fmt.Printf("WARNING: VK daily quota of %s is exhausted, no calls until %s\n", method, until.Format(time.RFC3339))
+	c.mu.Lock()
+	if c.exhausted == nil {
+		c.exhausted = map[string]time.Time{}
+	}
+	c.exhausted[method] = until
+	c.mu.Unlock()
+}
+
+// moscow - часовой пояс суточных квот VK
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+// quotaReset - ближайшая полночь по Москве: в неё VK обновляет суточные квоты
+func quotaReset(t time.Time) time.Time {
+	y, m, d := t.In(moscow).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, moscow)
+}
+
+func tokenError(err error) error {
+	if errors.Is(err, vkToken.ErrAuthRequired) {
+		return researcher.Fatal(err)
+	}
+	return err
+}
+
+func (c *Client) callWithRetry(ctx context.Context, token, method string, params url.Values, out interface{}) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := backoff(attempt)
+			fmt.Printf("WARNING: %s: %v, retry %d in %v\n", method, err, attempt, delay.Round(time.Millisecond))
+			if errors.Is(err, ErrTooManyRequests) || errors.Is(err, ErrFloodControl) {
+				// Лимит общий для всех запросов с этим ключом
+				c.bucket.Slow(delay)
+			}
+			if sleepErr := researcher.Sleep(ctx, delay); sleepErr != nil {
+				return err
+			}
+		}
+		if err = c.bucket.Wait(ctx); err != nil {
+			return err
+		}
+		err = c.call(ctx, token, method, params, out)
+		if err == nil || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// backoff - экспоненциальная пауза перед попыткой attempt со случайной
+// половиной: одновременные запросы после ошибки не повторяются разом
+func backoff(attempt int) time.Duration {
+	d := min(baseBackoff<<(attempt-1), maxBackoff)
+	return d/2 + rand.N(d/2+1)
+}
+
+// envelope - ответ VK API: response или error; execute_errors - ошибки вызовов внутри execute
+type envelope struct {
+	Response      json.RawMessage `json:"response"`
+	Error         *VKError        `json:"error"`
+	ExecuteErrors []VKError       `json:"execute_errors"`
+}
+
+func (c *Client) call(ctx context.Context, token, method string, params url.Values, out interface{}) error {
+	form := url.Values{}
+	for k, v := range params {
+		form[k] = v
+	}
+	form.Set("access_token", token)
+	form.Set("v", apiVersion)
+
+	// POST: код execute и списки параметров не упираются в длину URL
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+method, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("VK API %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("VK API %s: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{method: method, code: resp.StatusCode}
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return fmt.Errorf("VK API %s: %w", method, err)
+	}
+	if env.Error != nil {
+		return env.Error
+	}
+	if out == nil {
+		return nil
+	}
+	if raw, ok := out.(*envelope); ok {
+		*raw = env
+		return nil
+	}
+	if err := json.Unmarshal(env.Response, out); err != nil {
+		return fmt.Errorf("VK API %s response: %w", method, err)
+	}
+	return nil
+}
+
+// Request - вызов метода для Execute
+type Request struct {
+	Method string
+	Params map[string]interface{}
+}
+
+// Result - результат вызова из Execute: Response или Err
+type Result struct {
+	Response json.RawMessage
+	Err      error
+}
+
+// Execute выполняет вызовы через метод execute, по maxExecuteCalls за запрос:
+// серия из 25 вызовов тратит один запрос из лимита в секунду. Ошибка отдельного
+// вызова возвращается в его Result, ошибка всего запроса - вторым значением
+// вместе с результатами уже выполненных пачек. Вызовы методов с исчерпанной
+// квотой в execute не попадают и сразу получают ErrRateLimit.
+func (c *Client) Execute(ctx context.Context, reqs []Request) ([]Result, error) {
+	results := make([]Result, 0, len(reqs))
+	for start := 0; start < len(reqs); start += maxExecuteCalls {
+		batch := reqs[start:min(start+maxExecuteCalls, len(reqs))]
+		batchResults := make([]Result, len(batch))
+		var calls []Request
+		var indexes []int
+		for i, r := range batch {
+			if err := c.quotaLeft(r.Method); err != nil {
+				batchResults[i].Err = err
+				continue
+			}
+			calls = append(calls, r)
+			indexes = append(indexes, i)
+		}
+		if len(calls) > 0 {
+			if err := c.execute(ctx, calls, indexes, batchResults); err != nil {
+				return results, err
+			}
+		}
+		results = append(results, batchResults...)
+	}
+	return results, nil
+}
+
+// execute выполняет calls одним запросом и раскладывает ответы в results по indexes
+func (c *Client) execute(ctx context.Context, calls []Request, indexes []int, results []Result) error {
+	code, err := executeCode(calls)
+	if err != nil {
+		return err
+	}
+	var env envelope
+	if err := c.Call(ctx, "execute", url.Values{"code": {code}}, &env); err != nil {
+		return err
+	}
+	var responses []json.RawMessage
+	if err := json.Unmarshal(env.Response, &responses); err != nil {
+		return fmt.Errorf("VK API execute response: %w", err)
+	}
+	// Неудачный вызов возвращает false, его ошибка - очередная в execute_errors
+	failed := 0
+	for j, i := range indexes {
+		switch {
+		case j < len(responses) && string(responses[j]) != "false":
+			results[i].Response = responses[j]
+		case failed < len(env.ExecuteErrors):
+			vkErr := &env.ExecuteErrors[failed]
+			failed++
+			if errors.Is(vkErr, ErrRateLimit) && vkErr.Method != "" {
+				c.exhaust(vkErr.Method)
+			}
+			results[i].Err = vkErr
+		default:
+			results[i].Err = fmt.Errorf("VK API execute: %s failed", calls[j].Method)
+		}
+	}
+	return nil
+}
+
+// executeCode - VKScript, возвращающий массив ответов batch
+func executeCode(batch []Request) (string, error) {
+	calls := make([]string, 0, len(batch))
+	for _, r := range batch {
+		var params strings.Builder
+		enc := json.NewEncoder(&params)
+		enc.SetEscapeHTML(false)
+		p := r.Params
+		if p == nil {
+			p = map[string]interface{}{}
+		}
+		if err := enc.Encode(p); err != nil {
+			return "", fmt.Errorf("execute %s params: %w", r.Method, err)
+		}
+		calls = append(calls, fmt.Sprintf("API.%s(%s)", r.Method, strings.TrimSpace(params.String())))
+	}
+	return "return [" + strings.Join(calls, ",") + "];", nil
+}
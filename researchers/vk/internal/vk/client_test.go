@@ -0,0 +1,229 @@
+package vk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"researcher"
+	"researcher-vk/internal/vkToken"
+)
+
+// serviceKey - TokenSource с неизменным сервисным ключом
+type serviceKey string
+
+func (k serviceKey) Token(context.Context) (string, error) { return string(k), nil }
+
+func (k serviceKey) Invalidate(context.Context, string) (string, error) {
+	return "", vkToken.ErrAuthRequired
+}
+
+func (k serviceKey) Refreshable() bool { return false }
+
+// testClient - клиент, отправляющий запросы в handler
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c := NewClient(serviceKey("key"))
+	c.baseURL = srv.URL + "/"
+	return c
+}
+
+func vkError(code int) string {
+	return fmt.Sprintf(`{"error":{"error_code":%d,"error_msg":"test"}}`, code)
+}
+
+func TestBucketLimitsRequests(t *testing.T) {
+	var calls atomic.Int32
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"response":1}`)
+	})
+
+	// Первые requestsPerSecond запросов идут сразу, следующие - по одному в 1/3 секунды
+	start := time.Now()
+	for i := 0; i < 2*requestsPerSecond; i++ {
+		if err := c.Call(context.Background(), "users.get", nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("%d requests took %v, want at least 1s", 2*requestsPerSecond, elapsed)
+	}
+	if n := calls.Load(); n != 2*requestsPerSecond {
+		t.Errorf("calls = %d, want %d", n, 2*requestsPerSecond)
+	}
+}
+
+func TestCallRetriesLimitErrors(t *testing.T) {
+	for _, code := range []int{errTooManyRequests, errFloodControl} {
+		t.Run(fmt.Sprint(code), func(t *testing.T) {
+			var calls atomic.Int32
+			c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) == 1 {
+					fmt.Fprint(w, vkError(code))
+					return
+				}
+				fmt.Fprint(w, `{"response":{"count":7}}`)
+			})
+
+			var out struct{ Count int }
+			start := time.Now()
+			if err := c.Call(context.Background(), "wall.get", nil, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.Count != 7 || calls.Load() != 2 {
+				t.Errorf("count = %d after %d calls, want 7 after 2", out.Count, calls.Load())
+			}
+			// Первая повторная попытка - через половину baseBackoff или позже
+			if elapsed := time.Since(start); elapsed < baseBackoff/2 {
+				t.Errorf("retry after %v, want at least %v", elapsed, baseBackoff/2)
+			}
+		})
+	}
+}
+
+func TestCallBlocksMethodAfterRateLimit(t *testing.T) {
+	var wallCalls, groupCalls atomic.Int32
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/wall.get") {
+			wallCalls.Add(1)
+			fmt.Fprint(w, vkError(errRateLimit))
+			return
+		}
+		groupCalls.Add(1)
+		fmt.Fprint(w, `{"response":1}`)
+	})
+	now := time.Date(2025, 3, 10, 23, 30, 0, 0, time.UTC) // 02:30 по Москве
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := c.Call(ctx, "wall.get", nil, nil); !errors.Is(err, ErrRateLimit) {
+			t.Fatalf("call %d: err = %v, want ErrRateLimit", i, err)
+		}
+	}
+	// Ошибка 29 не повторяется, и до сброса квоты метод не вызывается
+	if n := wallCalls.Load(); n != 1 {
+		t.Errorf("wall.get calls = %d, want 1", n)
+	}
+	// Квота других методов не тронута
+	if err := c.Call(ctx, "groups.getById", nil, nil); err != nil || groupCalls.Load() != 1 {
+		t.Errorf("groups.getById: err = %v, calls = %d", err, groupCalls.Load())
+	}
+
+	// Квота сбрасывается в полночь по Москве
+	now = time.Date(2025, 3, 11, 20, 59, 0, 0, time.UTC)
+	if err := c.Call(ctx, "wall.get", nil, nil); !errors.Is(err, ErrRateLimit) || wallCalls.Load() != 1 {
+		t.Errorf("before reset: err = %v, calls = %d", err, wallCalls.Load())
+	}
+	now = time.Date(2025, 3, 11, 21, 0, 0, 0, time.UTC)
+	_ = c.Call(ctx, "wall.get", nil, nil)
+	if n := wallCalls.Load(); n != 2 {
+		t.Errorf("after reset: wall.get calls = %d, want 2", n)
+	}
+}
+
+func TestCallUserTokenRequiredIsFatal(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, vkError(errAppAuthFailed))
+	})
+	err := c.Call(context.Background(), "groups.search", nil, nil)
+	if !errors.Is(err, ErrUserTokenRequired) || !researcher.IsFatal(err) {
+		t.Errorf("err = %v, want fatal ErrUserTokenRequired", err)
+	}
+}
+
+func TestExecuteMapsFailedCalls(t *testing.T) {
+	var codes []string
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		codes = append(codes, r.PostForm.Get("code"))
+		fmt.Fprint(w, `{"response":[{"count":1},false,{"count":2},false],"execute_errors":[`+
+			`{"method":"wall.get","error_code":15,"error_msg":"Access denied"},`+
+			`{"method":"groups.search","error_code":29,"error_msg":"Rate limit reached"}]}`)
+	})
+	ctx := context.Background()
+
+	reqs := []Request{
+		{Method: "wall.get", Params: map[string]interface{}{"owner_id": -1}},
+		{Method: "wall.get", Params: map[string]interface{}{"owner_id": -2}},
+		{Method: "wall.get", Params: map[string]interface{}{"owner_id": -3}},
+		{Method: "groups.search", Params: map[string]interface{}{"q": "новости"}},
+	}
+	results, err := c.Execute(ctx, reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(reqs) {
+		t.Fatalf("results = %d, want %d", len(results), len(reqs))
+	}
+	if string(results[0].Response) != `{"count":1}` || string(results[2].Response) != `{"count":2}` {
+		t.Errorf("responses = %s, %s", results[0].Response, results[2].Response)
+	}
+	// false в ответе - очередная ошибка из execute_errors
+	var vkErr *VKError
+	if !errors.As(results[1].Err, &vkErr) || vkErr.Code != 15 || vkErr.Method != "wall.get" {
+		t.Errorf("results[1].Err = %v, want error 15 in wall.get", results[1].Err)
+	}
+	if !errors.Is(results[3].Err, ErrRateLimit) {
+		t.Errorf("results[3].Err = %v, want ErrRateLimit", results[3].Err)
+	}
+	if want := `return [API.wall.get({"owner_id":-1}),API.wall.get({"owner_id":-2}),` +
+		`API.wall.get({"owner_id":-3}),API.groups.search({"q":"новости"})];`; codes[0] != want {
+		t.Errorf("code = %s, want %s", codes[0], want)
+	}
+
+	// Метод с исчерпанной квотой больше не попадает в execute
+	results, err = c.Execute(ctx, []Request{{Method: "groups.search"}})
+	if err != nil || len(results) != 1 || !errors.Is(results[0].Err, ErrRateLimit) {
+		t.Errorf("results = %+v, err = %v", results, err)
+	}
+	if len(codes) != 1 {
+		t.Errorf("execute requests = %d, want 1", len(codes))
+	}
+}
+
+func TestQuotaReset(t *testing.T) {
+	tests := []struct {
+		now, want time.Time
+	}{
+		{time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 21, 0, 0, 0, time.UTC)},
+		// После 21:00 UTC в Москве уже следующие сутки
+		{time.Date(2025, 3, 10, 21, 0, 0, 0, time.UTC), time.Date(2025, 3, 11, 21, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 12, 31, 22, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 21, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := quotaReset(tt.now); !got.Equal(tt.want) {
+			t.Errorf("quotaReset(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+// Параметры уходят в теле POST вместе с ключом и версией API
+func TestCallSendsForm(t *testing.T) {
+	var form url.Values
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		form = r.PostForm
+		fmt.Fprint(w, `{"response":1}`)
+	})
+	if err := c.Call(context.Background(), "groups.getById", url.Values{"group_ids": {"habr,tass_agency"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("access_token") != "key" || form.Get("v") != apiVersion || form.Get("group_ids") != "habr,tass_agency" {
+		t.Errorf("form = %v", form)
+	}
+}
@@ -0,0 +1,228 @@
+package vk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"apiclient"
+	"researcher"
+)
+
+// Platform - адаптер VK для researcher.Runner. Частоту запросов ограничивает
+// Client, поэтому Runner запускается без Limiter.
+type Platform struct {
+	client *Client
+	// groupOffset - смещение в поиске популярных групп: каждый обход берёт следующие
+	groupOffset int
+}
+
+// NewPlatform создаёт адаптер поверх клиента VK API
+func NewPlatform(client *Client) *Platform {
+	return &Platform{client: client}
+}
+
+func (p *Platform) Name() string { return "Vkontakte" }
+
+func (p *Platform) Source() apiclient.Source {
+	return apiclient.Source{Name: "VK", Address: "vk.com", Topic: "social"}
+}
+
+// CheckConfig сообщает, можно ли обходить каналы conf с токеном клиента: поиск
+// популярных групп (пустой preferred_channels) требует токена пользователя
+func (p *Platform) CheckConfig(conf researcher.Config) error {
+	if len(conf.PreferredChannels) == 0 && p.client.ServiceKey() {
+		return fmt.Errorf("%w: groups.search is not available with a service key, "+
+			"list group screen names in preferred_channels or authorize a user", ErrUserTokenRequired)
+	}
+	return nil
+}
+
+// ListChannels - группы из preferred_channels или популярные новостные группы.
+// С сервисным ключом preferred_channels - короткие имена групп (groups.getById),
+// с токеном пользователя - названия для поиска.
+func (p *Platform) ListChannels(ctx context.Context, conf researcher.Config) ([]researcher.Channel, error) {
+	if err := p.CheckConfig(conf); err != nil {
+		return nil, researcher.Fatal(err)
+	}
+
+	var groups []VKGroup
+	var err error
+	switch {
+	case len(conf.PreferredChannels) == 0:
+		groups, err = p.popularGroups(ctx, conf.ChannelLimit)
+	case p.client.ServiceKey():
+		groups, err = p.client.GroupsByScreenNames(ctx, conf.PreferredChannels)
+	default:
+		groups, err = p.client.GroupsByFullNames(ctx, conf.PreferredChannels)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]researcher.Channel, 0, len(groups))
+	for _, group := range groups {
+		link := fmt.Sprintf("https://vk.com/%s", group.ScreenName)
+		// Автор постов группы - сама группа, репутация на платформе - число подписчиков
+		members := int64(group.MembersCount)
+		channels = append(channels, researcher.Channel{
+			ExternalID:  strconv.Itoa(group.ID),
+			Name:        group.Name,
+			Link:        link,
+			Subscribers: group.MembersCount,
+			Topic:       "general",
+			Author: &apiclient.Author{
+				Name:               fmt.Sprintf("VK Group: %s", group.Name),
+				Platform:           "vk",
+				ExternalID:         fmt.Sprintf("-%d", group.ID),
+				ProfileURL:         link,
+				PlatformReputation: &members,
+			},
+			Raw: group,
+		})
+	}
+	return channels, nil
+}
+
+// popularGroups - следующая страница популярных групп. После последней страницы
+// поиск начинается сначала; пустая страница в середине обхода сразу сменяется первой.
+func (p *Platform) popularGroups(ctx context.Context, limit int) ([]VKGroup, error) {
+	groups, last, err := p.client.TopPopularGroups(ctx, limit, p.groupOffset)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 && p.groupOffset > 0 {
+		p.groupOffset = 0
+		groups, last, err = p.client.TopPopularGroups(ctx, limit, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if last {
+		p.groupOffset = 0
+	} else {
+		p.groupOffset += limit
+	}
+	return groups, nil
+}
+
+func (p *Platform) FetchPosts(ctx context.Context, ch researcher.Channel, limit int) ([]researcher.Post, error) {
+	group := ch.Raw.(VKGroup)
+	vkPosts, err := p.client.GroupPosts(ctx, group.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]researcher.Post, 0, len(vkPosts))
+	for _, post := range vkPosts {
+		posts = append(posts, researcher.Post{
+			ExternalID:  strconv.Itoa(post.ID),
+			Text:        post.Text,
+			URL:         fmt.Sprintf("https://vk.com/wall-%d_%d", group.ID, post.ID),
+			CreatedAt:   time.Unix(post.Date, 0),
+			Likes:       post.Likes,
+			Comments:    post.Comments,
+			Views:       post.Views,
+			Tags:        post.Tags,
+			MarkedAsAds: post.MarkedAsAds == 1,
+			Raw:         post,
+		})
+	}
+	return posts, nil
+}
+
+// FetchComments - дерево комментариев поста: корневые комментарии и их ветки
+func (p *Platform) FetchComments(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Comment, error) {
+	if post.Comments == 0 {
+		return nil, nil
+	}
+	group := ch.Raw.(VKGroup)
+	vkPost := post.Raw.(VKPost)
+	roots, err := p.client.CommentsWithThreads(ctx, -group.ID, vkPost.ID, limit)
+	var vkErr *VKError
+	if errors.As(err, &vkErr) && vkErr.Code == errAccessToComments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]researcher.Comment, 0, len(roots))
+	for _, root := range roots {
+		c := convertComment(root)
+		c.Replies = threadTree(root.ID, root.Thread.Items)
+		comments = append(comments, c)
+	}
+	return comments, nil
+}
+
+// threadTree строит дерево ветки. VK отдаёт ветку плоским списком, где ответ
+// ссылается на комментарий через reply_to_comment; ответы корню и ответы на
+// комментарии, которых нет в списке (удалены или не загружены), цепляются к корню.
+func threadTree(rootID int, items []VKComment) []researcher.Comment {
+	known := map[int]bool{rootID: true}
+	for _, item := range items {
+		known[item.ID] = true
+	}
+	children := map[int][]VKComment{}
+	for _, item := range items {
+		parent := item.ParentID
+		// Ответить можно только на более ранний комментарий - это исключает циклы
+		if !known[parent] || parent >= item.ID {
+			parent = rootID
+		}
+		children[parent] = append(children[parent], item)
+	}
+
+	var build func(id int) []researcher.Comment
+	build = func(id int) []researcher.Comment {
+		var replies []researcher.Comment
+		for _, item := range children[id] {
+			c := convertComment(item)
+			c.Replies = build(item.ID)
+			replies = append(replies, c)
+		}
+		return replies
+	}
+	return build(rootID)
+}
+
+func convertComment(c VKComment) researcher.Comment {
+	text := c.Text
+	switch {
+	case c.Deleted:
+		text = "[deleted]"
+	case text == "":
+		// Комментарий только со стикером или картинкой
+		text = "[media]"
+	}
+	return researcher.Comment{
+		ExternalID: strconv.Itoa(c.ID),
+		Author:     c.AuthorName,
+		Text:       text,
+		Likes:      c.Likes.Count,
+		CreatedAt:  time.Unix(c.Date, 0),
+	}
+}
+
+// FetchMedia - вложения поста из ответа wall.get, без дополнительных запросов
+func (p *Platform) FetchMedia(ctx context.Context, ch researcher.Channel, post researcher.Post, limit int) ([]researcher.Media, error) {
+	vkPost := post.Raw.(VKPost)
+	var media []researcher.Media
+	for _, m := range vkPost.Media() {
+		if len(media) >= limit {
+			break
+		}
+		media = append(media, researcher.Media{
+			Type:      m.Type,
+			URL:       m.URL,
+			Width:     m.Width,
+			Height:    m.Height,
+			Thumbnail: m.Thumbnail,
+			Title:     m.Title,
+		})
+	}
+	return media, nil
+}
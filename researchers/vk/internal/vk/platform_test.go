@@ -0,0 +1,102 @@
+package vk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"researcher"
+)
+
+// userToken - TokenSource с токеном пользователя: поиск групп доступен
+type userToken struct{ serviceKey }
+
+func (userToken) Refreshable() bool { return true }
+
+// groupsSearch отвечает на groups.search страницами из total групп с id 1..total
+func groupsSearch(t *testing.T, total int, offsets *[]int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		offset, _ := strconv.Atoi(r.PostForm.Get("offset"))
+		count, _ := strconv.Atoi(r.PostForm.Get("count"))
+		*offsets = append(*offsets, offset)
+
+		resp := VKGroupsResponse{Count: total, Items: []VKGroup{}}
+		for id := offset + 1; id <= min(offset+count, total); id++ {
+			resp.Items = append(resp.Items, VKGroup{ID: id, Name: "group " + strconv.Itoa(id), MembersCount: 1000 - id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"response": resp})
+	}
+}
+
+func channelIDs(channels []researcher.Channel) []string {
+	ids := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		ids = append(ids, ch.ExternalID)
+	}
+	return ids
+}
+
+func TestListChannelsWrapsPopularGroups(t *testing.T) {
+	var offsets []int
+	c := testClient(t, groupsSearch(t, 5, &offsets))
+	c.tokens = userToken{}
+	p := NewPlatform(c)
+	conf := researcher.Config{ChannelLimit: 2}
+
+	want := [][]string{{"1", "2"}, {"3", "4"}, {"5"}, {"1", "2"}}
+	for i, w := range want {
+		channels, err := p.ListChannels(context.Background(), conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := channelIDs(channels); !reflect.DeepEqual(got, w) {
+			t.Errorf("scan %d: groups = %v, want %v", i, got, w)
+		}
+	}
+	// Короткая третья страница - последняя, четвёртый обход начинается сначала
+	if !reflect.DeepEqual(offsets, []int{0, 2, 4, 0}) {
+		t.Errorf("offsets = %v, want [0 2 4 0]", offsets)
+	}
+}
+
+func TestListChannelsRestartsOnEmptyPage(t *testing.T) {
+	var offsets []int
+	c := testClient(t, groupsSearch(t, 4, &offsets))
+	c.tokens = userToken{}
+	p := NewPlatform(c)
+	// Выдача сократилась между обходами: смещение указывает за её конец
+	p.groupOffset = 10
+
+	channels, err := p.ListChannels(context.Background(), researcher.Config{ChannelLimit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := channelIDs(channels); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("groups = %v, want [1 2]", got)
+	}
+	if !reflect.DeepEqual(offsets, []int{10, 0}) || p.groupOffset != 2 {
+		t.Errorf("offsets = %v, next offset = %d", offsets, p.groupOffset)
+	}
+}
+
+func TestListChannelsServiceKey(t *testing.T) {
+	var offsets []int
+	c := testClient(t, groupsSearch(t, 4, &offsets))
+	p := NewPlatform(c)
+
+	// Без preferred_channels нужен groups.search, а с сервисным ключом он недоступен
+	_, err := p.ListChannels(context.Background(), researcher.Config{ChannelLimit: 2})
+	if !errors.Is(err, ErrUserTokenRequired) || !researcher.IsFatal(err) {
+		t.Errorf("err = %v, want fatal ErrUserTokenRequired", err)
+	}
+	if len(offsets) != 0 {
+		t.Errorf("groups.search called %d times", len(offsets))
+	}
+}
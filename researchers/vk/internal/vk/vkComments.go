@@ -1,14 +1,19 @@
 package vk
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"strconv"
 )
 
+const (
+	// maxCommentsPerRequest - максимум count у wall.getComments
+	maxCommentsPerRequest = 100
+	// threadPreviewItems - ответов ветки в ответе на корневые комментарии (максимум VK)
+	threadPreviewItems = 10
+)
+
 // Структура для пользователя
 type VKUser struct {
 	ID        int    `json:"id"`
@@ -16,31 +21,37 @@ type VKUser struct {
 	LastName  string `json:"last_name"`
 }
 
-// Структура для вложенных комментариев (thread) — НОВАЯ
+// Структура для вложенных комментариев (thread)
 type VKThread struct {
 	Count int         `json:"count"` // Общее количество вложенных комментариев
-	Items []VKComment `json:"items"` // Массив вложенных комментариев
+	Items []VKComment `json:"items"` // Загруженные ответы ветки (плоский список)
 }
 
 // Структура для комментария (с ветвлениями, ID родительского и автором)
 type VKComment struct {
-	ID         int      `json:"id"`
-	FromID     int      `json:"from_id"`
-	Text       string   `json:"text"`
-	ParentID   int      `json:"reply_to_comment"` // ID родительского комментария
-	AuthorName string   `json:"-"`                // Имя автора (заполняется вручную, не из JSON)
-	Thread     VKThread `json:"thread"`           // Вложенные комментарии как объект (изменено с []VKComment)
+	ID         int    `json:"id"`
+	FromID     int    `json:"from_id"`
+	Date       int64  `json:"date"`
+	Text       string `json:"text"`
+	ParentID   int    `json:"reply_to_comment"` // ID комментария, на который ответили
+	Deleted    bool   `json:"deleted"`
+	AuthorName string `json:"-"` // Имя автора (заполняется вручную, не из JSON)
+	Likes      struct {
+		Count int `json:"count"`
+	} `json:"likes"`
+	Attachments []VKAttachment `json:"attachments,omitempty"`
+	Thread      VKThread       `json:"thread"` // Ответы на корневой комментарий
 }
 
 // Структура ответа от VK API для wall.getComments
 type VKCommentsResponse struct {
-	Response struct {
-		Count    int         `json:"count"`
-		Items    []VKComment `json:"items"`
-		CanPost  bool        `json:"can_post"`
-		Groups   []VKGroup   `json:"groups"`
-		Profiles []VKUser    `json:"profiles"`
-	} `json:"response"`
+	Count int `json:"count"`
+	// CurrentLevelCount - комментариев на запрошенном уровне (корневых или ответов ветки)
+	CurrentLevelCount int         `json:"current_level_count"`
+	Items             []VKComment `json:"items"`
+	CanPost           bool        `json:"can_post"`
+	Groups            []VKGroup   `json:"groups"`
+	Profiles          []VKUser    `json:"profiles"`
 }
 
 // Вспомогательная функция для получения имени автора по ID
@@ -64,69 +75,131 @@ func getAuthorName(fromID int, users map[int]VKUser, groups map[int]VKGroup) str
 func fillAuthorNames(comments []VKComment, users map[int]VKUser, groups map[int]VKGroup) {
 	for i := range comments {
 		comments[i].AuthorName = getAuthorName(comments[i].FromID, users, groups)
-		// Теперь проверяем Thread.Items (массив вложенных комментариев)
 		if len(comments[i].Thread.Items) > 0 {
 			fillAuthorNames(comments[i].Thread.Items, users, groups)
 		}
 	}
 }
 
-// Функция для получения заданного количества комментариев с ветвлениями
-func GetCommentsWithThreads(accessToken string, ownerID int, postID int, count int) ([]VKComment, error) {
-	// Базовый URL VK API для получения комментариев
-	baseURL := "https://api.vk.com/method/wall.getComments"
-
-	// Параметры запроса
-	params := url.Values{}
-	params.Set("access_token", accessToken)
-	params.Set("v", "5.131")                      // Версия API
-	params.Set("owner_id", strconv.Itoa(ownerID)) // ID владельца поста
-	params.Set("post_id", strconv.Itoa(postID))   // ID поста
-	params.Set("count", strconv.Itoa(count))      // Количество корневых комментариев (макс 100)
-	params.Set("thread_items_count", "10")        // Количество вложенных комментариев на уровень (макс 10)
-	params.Set("extended", "1")                   // Получить расширенную информацию (профили, группы)
-
-	// Формируем полный URL
-	fullURL := baseURL + "?" + params.Encode()
-	fmt.Printf("Запрос: %s\n", fullURL)
-
-	// Делаем HTTP GET запрос
-	resp, err := http.Get(fullURL)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка запроса к VK API: %w", err)
+// commentsFetcher - загрузка комментариев одного поста: профили авторов со всех
+// страниц собираются вместе, чтобы подписать комментарии в конце
+type commentsFetcher struct {
+	client  *Client
+	ownerID int
+	postID  int
+	users   map[int]VKUser
+	groups  map[int]VKGroup
+}
+
+// CommentsWithThreads возвращает корневые комментарии поста с ответами в
+// Thread.Items, всего не больше limit. Корневые комментарии загружаются страницами
+// по 100; если в ветке больше ответов, чем пришло вместе с корнем
+// (thread_items_count), остальные догружаются запросами с comment_id.
+func (c *Client) CommentsWithThreads(ctx context.Context, ownerID int, postID int, limit int) ([]VKComment, error) {
+	f := &commentsFetcher{
+		client:  c,
+		ownerID: ownerID,
+		postID:  postID,
+		users:   map[int]VKUser{},
+		groups:  map[int]VKGroup{},
 	}
-	defer resp.Body.Close()
 
-	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("VK API вернул ошибку: %s, тело: %s", resp.Status, string(body))
+	var roots []VKComment
+	remaining := limit
+	for offset := 0; remaining > 0; {
+		params := url.Values{}
+		params.Set("count", strconv.Itoa(min(maxCommentsPerRequest, remaining)))
+		params.Set("offset", strconv.Itoa(offset))
+		params.Set("thread_items_count", strconv.Itoa(threadPreviewItems))
+		page, err := f.request(ctx, params)
+		if err != nil {
+			if len(roots) > 0 {
+				fmt.Printf("WARNING: comments of post %d_%d after %d: %v\n", ownerID, postID, offset, err)
+				break
+			}
+			return nil, err
+		}
+		items := page.Items
+		if len(items) == 0 {
+			break
+		}
+
+		for _, root := range items {
+			if remaining <= 0 {
+				break
+			}
+			remaining--
+			if len(root.Thread.Items) > remaining {
+				root.Thread.Items = root.Thread.Items[:remaining]
+			}
+			remaining -= len(root.Thread.Items)
+			if root.Thread.Count > len(root.Thread.Items) && remaining > 0 {
+				more, err := f.thread(ctx, root.ID, len(root.Thread.Items), min(remaining, root.Thread.Count-len(root.Thread.Items)))
+				if err != nil {
+					fmt.Printf("WARNING: thread %d of post %d_%d: %v\n", root.ID, ownerID, postID, err)
+				}
+				root.Thread.Items = append(root.Thread.Items, more...)
+				remaining -= len(more)
+			}
+			roots = append(roots, root)
+		}
+
+		offset += len(items)
+		total := page.CurrentLevelCount
+		if total == 0 {
+			total = page.Count
+		}
+		if offset >= total {
+			break
+		}
 	}
 
-	// Читаем и парсим JSON
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	fillAuthorNames(roots, f.users, f.groups)
+	return roots, nil
+}
+
+// thread догружает ответы ветки commentID, начиная с offset, не больше limit
+func (f *commentsFetcher) thread(ctx context.Context, commentID, offset, limit int) ([]VKComment, error) {
+	var replies []VKComment
+	for len(replies) < limit {
+		params := url.Values{}
+		params.Set("comment_id", strconv.Itoa(commentID))
+		params.Set("count", strconv.Itoa(min(maxCommentsPerRequest, limit-len(replies))))
+		params.Set("offset", strconv.Itoa(offset))
+		page, err := f.request(ctx, params)
+		if err != nil {
+			return replies, err
+		}
+		if len(page.Items) == 0 {
+			break
+		}
+		replies = append(replies, page.Items...)
+		offset += len(page.Items)
 	}
+	if len(replies) > limit {
+		replies = replies[:limit]
+	}
+	return replies, nil
+}
 
-	var vkResp VKCommentsResponse
-	if err := json.Unmarshal(bodyBytes, &vkResp); err != nil {
+// request - один вызов wall.getComments; профили и группы авторов запоминаются
+func (f *commentsFetcher) request(ctx context.Context, params url.Values) (*VKCommentsResponse, error) {
+	params.Set("owner_id", strconv.Itoa(f.ownerID))
+	params.Set("post_id", strconv.Itoa(f.postID))
+	params.Set("need_likes", "1")
+	params.Set("sort", "asc")
+	params.Set("extended", "1")
+	params.Set("fields", "first_name,last_name,name")
+
+	var resp VKCommentsResponse
+	if err := f.client.Call(ctx, "wall.getComments", params, &resp); err != nil {
 		return nil, err
 	}
-
-	// Создаём мапы для быстрого поиска профилей и групп
-	users := make(map[int]VKUser)
-	for _, user := range vkResp.Response.Profiles {
-		users[user.ID] = user
+	for _, user := range resp.Profiles {
+		f.users[user.ID] = user
 	}
-	groups := make(map[int]VKGroup)
-	for _, group := range vkResp.Response.Groups {
-		groups[group.ID] = group
+	for _, group := range resp.Groups {
+		f.groups[group.ID] = group
 	}
-
-	// Заполняем AuthorName в комментариях и их ветвлениях
-	fillAuthorNames(vkResp.Response.Items, users, groups)
-
-	// Возвращаем комментарии
-	return vkResp.Response.Items, nil
+	return &resp, nil
 }
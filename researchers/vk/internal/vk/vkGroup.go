@@ -1,14 +1,19 @@
 package vk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
+)
+
+const (
+	// maxGroupsSearch - максимум count у groups.search
+	maxGroupsSearch = 1000
+	// maxGroupsByID - максимум group_ids у groups.getById
+	maxGroupsByID = 500
 )
 
 // Структура для группы
@@ -17,192 +22,139 @@ type VKGroup struct {
 	Name         string `json:"name"`
 	ScreenName   string `json:"screen_name"`
 	MembersCount int    `json:"members_count"`
+	IsClosed     int    `json:"is_closed"` // 0 - открытая, 1 - закрытая, 2 - частная
 }
 
-// Структура ответа
+// Структура ответа groups.search
 type VKGroupsResponse struct {
-	Response struct {
-		Count int       `json:"count"`
-		Items []VKGroup `json:"items"`
-	} `json:"response"`
+	Count int       `json:"count"`
+	Items []VKGroup `json:"items"`
 }
 
-// Поиск популярных групп
-func GetTopPopularGroups(accessToken string, count int, offset int) ([]VKGroup, error) {
-    // Ограничиваем количество
-    if count > 20 {
-        count = 20
-        fmt.Printf("Reducing group count to %d\n", count)
-    }
-    
-    baseURL := "https://api.vk.com/method/groups.search"
-    params := url.Values{}
-    params.Set("access_token", accessToken)
-    params.Set("v", "5.199")
-    params.Set("q", "новости")
-    params.Set("type", "group")
-    params.Set("sort", "6")
-    params.Set("count", strconv.Itoa(count))
-    params.Set("offset", strconv.Itoa(offset))
-    params.Set("fields", "members_count,name,screen_name")
-
-    fullURL := baseURL + "?" + params.Encode()
-    
-    // Добавляем задержку
-    time.Sleep(500 * time.Millisecond)
-    
-    resp, err := http.Get(fullURL)
-    if err != nil {
-        return nil, fmt.Errorf("HTTP request error: %w", err)
-    }
-    defer resp.Body.Close()
-
-    if resp.StatusCode != http.StatusOK {
-        body, _ := io.ReadAll(resp.Body)
-        return nil, fmt.Errorf("VK API error %d: %s", resp.StatusCode, string(body))
-    }
-
-    body, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return nil, fmt.Errorf("read response error: %w", err)
-    }
-
-    var vkResp VKGroupsResponse
-    if err := json.Unmarshal(body, &vkResp); err != nil {
-        return nil, fmt.Errorf("JSON parse error: %w", err)
-    }
-
-    fmt.Printf("Found %d groups, got %d items\n", vkResp.Response.Count, len(vkResp.Response.Items))
-    
-    // Фильтруем группы без названия
-    var filteredGroups []VKGroup
-    for _, group := range vkResp.Response.Items {
-        if group.Name != "" && group.MembersCount > 0 {
-            // Убедимся, что screen_name не пустой
-            if group.ScreenName == "" {
-                group.ScreenName = strconv.Itoa(group.ID)
-            }
-            filteredGroups = append(filteredGroups, group)
-        }
-    }
-
-    return filteredGroups, nil
-}
-
-// Получение групп по названиям
-func GetGroupsByFullNames(accessToken string, names string, n int) ([]VKGroup, error) {
-	if len(names) == 0 {
-		return []VKGroup{}, nil
+// TopPopularGroups - новостные группы по убыванию популярности, начиная с offset.
+// last - страница последняя: VK вернул меньше count групп или выдача закончилась.
+func (c *Client) TopPopularGroups(ctx context.Context, count int, offset int) (groups []VKGroup, last bool, err error) {
+	params := url.Values{}
+	params.Set("q", "новости")
+	params.Set("type", "group")
+	params.Set("sort", "6")
+	count = min(count, maxGroupsSearch)
+	params.Set("count", strconv.Itoa(count))
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("fields", "members_count,name,screen_name")
+
+	var resp VKGroupsResponse
+	if err := c.Call(ctx, "groups.search", params, &resp); err != nil {
+		return nil, false, err
 	}
-
-	nameList := strings.Split(names, ",")
-	for i := range nameList {
-		nameList[i] = strings.TrimSpace(nameList[i])
+	fmt.Printf("Found %d groups, got %d items\n", resp.Count, len(resp.Items))
+	last = len(resp.Items) < count || offset+len(resp.Items) >= resp.Count
+
+	// Фильтруем группы без названия
+	for _, group := range resp.Items {
+		if group.Name != "" && group.MembersCount > 0 {
+			if group.ScreenName == "" {
+				group.ScreenName = strconv.Itoa(group.ID)
+			}
+			groups = append(groups, group)
+		}
 	}
+	return groups, last, nil
+}
 
-	var allGroups []VKGroup
+// Структура ответа groups.getById
+type VKGroupsByIDResponse struct {
+	Groups []VKGroup `json:"groups"`
+}
 
-	for _, fullName := range nameList {
-		if len(fullName) == 0 {
-			continue
+// GroupsByScreenNames - открытые группы по коротким именам или id через
+// groups.getById; в отличие от groups.search работает с сервисным ключом
+func (c *Client) GroupsByScreenNames(ctx context.Context, names []string) ([]VKGroup, error) {
+	var ids []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			ids = append(ids, name)
 		}
+	}
 
-		// Задержка между запросами
-		time.Sleep(1 * time.Second)
-		
-		baseURL := "https://api.vk.com/method/groups.search"
+	var allGroups []VKGroup
+	for start := 0; start < len(ids); start += maxGroupsByID {
 		params := url.Values{}
-		params.Set("access_token", accessToken)
-		params.Set("v", "5.199")
-		params.Set("q", fullName)
-		params.Set("type", "group")
-		params.Set("count", "5")
+		params.Set("group_ids", strings.Join(ids[start:min(start+maxGroupsByID, len(ids))], ","))
 		params.Set("fields", "members_count,name,screen_name,is_closed")
 
-		fullURL := baseURL + "?" + params.Encode()
-		
-		// ДОБАВИМ ОТЛАДКУ
-		fmt.Printf("DEBUG: Searching group: %s\n", fullName)
-		
-		client := &http.Client{
-			Timeout: 30 * time.Second,
+		var resp VKGroupsByIDResponse
+		if err := c.Call(ctx, "groups.getById", params, &resp); err != nil {
+			return nil, err
 		}
-
-		resp, err := client.Get(fullURL)
-		if err != nil {
-			fmt.Printf("DEBUG: Request error for '%s': %v\n", fullName, err)
-			continue
+		for _, group := range resp.Groups {
+			if group.IsClosed != 0 {
+				fmt.Printf("WARNING: group '%s' is not open, skipped\n", group.ScreenName)
+				continue
+			}
+			allGroups = append(allGroups, group)
 		}
-		defer resp.Body.Close()
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("DEBUG: HTTP error %d for '%s'\n", resp.StatusCode, fullName)
-			continue
-		}
+	fmt.Printf("Found %d groups by screen names\n", len(allGroups))
+	return allGroups, nil
+}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Printf("DEBUG: Read error for '%s': %v\n", fullName, err)
+// GroupsByFullNames - первая открытая группа по каждому названию. Поиски по
+// всем названиям выполняются пачками через execute.
+func (c *Client) GroupsByFullNames(ctx context.Context, names []string) ([]VKGroup, error) {
+	var queries []string
+	var reqs []Request
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
 			continue
 		}
+		queries = append(queries, name)
+		reqs = append(reqs, Request{Method: "groups.search", Params: map[string]interface{}{
+			"q":      name,
+			"type":   "group",
+			"count":  5,
+			"fields": "members_count,name,screen_name,is_closed",
+		}})
+	}
+	if len(reqs) == 0 {
+		return []VKGroup{}, nil
+	}
 
-		// ДОБАВИМ ПРЕВЬЮ ОТВЕТА
-		fmt.Printf("DEBUG: Response for '%s': %s\n", fullName, string(body[:min(200, len(body))]))
-		
-		var vkResp struct {
-			Response struct {
-				Count int `json:"count"`
-				Items []struct {
-					ID           int    `json:"id"`
-					Name         string `json:"name"`
-					ScreenName   string `json:"screen_name"`
-					MembersCount int    `json:"members_count"`
-					IsClosed     int    `json:"is_closed"` // 0 - открытая, 1 - закрытая, 2 - частная
-				} `json:"items"`
-			} `json:"response"`
+	results, err := c.Execute(ctx, reqs)
+	if err != nil && len(results) == 0 {
+		return nil, err
+	}
+	if err != nil {
+		fmt.Printf("WARNING: group search stopped after %d names: %v\n", len(results), err)
+	}
+
+	var allGroups []VKGroup
+	for i, r := range results {
+		name := queries[i]
+		if r.Err != nil {
+			fmt.Printf("WARNING: search group '%s': %v\n", name, r.Err)
+			continue
 		}
-		
-		if err := json.Unmarshal(body, &vkResp); err != nil {
-			fmt.Printf("DEBUG: JSON parse error for '%s': %v\n", fullName, err)
+		var resp VKGroupsResponse
+		if err := json.Unmarshal(r.Response, &resp); err != nil {
+			fmt.Printf("WARNING: search group '%s': %v\n", name, err)
 			continue
 		}
-
-		fmt.Printf("DEBUG: Found %d groups for '%s'\n", vkResp.Response.Count, fullName)
-		
-		// Добавляем найденные группы
-		if len(vkResp.Response.Items) > 0 {
-			for _, item := range vkResp.Response.Items {
-				fmt.Printf("DEBUG: Group: ID=%d, Name='%s', ScreenName='%s', Members=%d, IsClosed=%d\n",
-					item.ID, item.Name, item.ScreenName, item.MembersCount, item.IsClosed)
-					
-				// Проверяем что группа открытая
-				if item.IsClosed == 0 {
-					group := VKGroup{
-						ID:           item.ID,
-						Name:         item.Name,
-						ScreenName:   item.ScreenName,
-						MembersCount: item.MembersCount,
-					}
-					if group.ScreenName == "" {
-						group.ScreenName = fullName
-					}
-					allGroups = append(allGroups, group)
-					fmt.Printf("DEBUG: Added OPEN group: %s (ID: %d)\n", group.Name, group.ID)
-					break // Берем первую открытую группу
-				} else {
-					fmt.Printf("DEBUG: Skipping CLOSED group: %s (IsClosed=%d)\n", item.Name, item.IsClosed)
-				}
+		// Берем первую открытую группу
+		for _, group := range resp.Items {
+			if group.IsClosed != 0 {
+				continue
+			}
+			if group.ScreenName == "" {
+				group.ScreenName = name
 			}
+			allGroups = append(allGroups, group)
+			break
 		}
 	}
 
 	fmt.Printf("Found %d groups by names\n", len(allGroups))
 	return allGroups, nil
 }
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
\ No newline at end of file
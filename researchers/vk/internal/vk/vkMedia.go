@@ -1,129 +1,192 @@
 package vk
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"strconv"
+	"strings"
 )
 
-// Структура для медиа (упрощённая)
+// Структура для медиа поста
 type VKMedia struct {
-	Type string `json:"type"` // "photo", "video", "audio" и т.д.
-	URL  string `json:"url"`  // URL медиа (если доступен)
+	Type      string // "photo", "video", "doc", "link", "poll", "audio"
+	URL       string // Ссылка на файл или страницу вложения
+	Width     int    // Размеры фото или видео; 0 - неизвестны
+	Height    int
+	Thumbnail string // Превью видео, ссылки или документа
+	Title     string // Заголовок видео или ссылки, вопрос опроса, имя файла
 }
 
-// Структура для фото (упрощённая)
+// Вложение поста или комментария: заполнено поле, соответствующее Type
+type VKAttachment struct {
+	Type  string   `json:"type"`
+	Photo *VKPhoto `json:"photo,omitempty"`
+	Video *VKVideo `json:"video,omitempty"`
+	Doc   *VKDoc   `json:"doc,omitempty"`
+	Link  *VKLink  `json:"link,omitempty"`
+	Poll  *VKPoll  `json:"poll,omitempty"`
+	Audio *VKAudio `json:"audio,omitempty"`
+}
+
+// Структура для фото
 type VKPhoto struct {
-	Sizes []VKPhotoSize `json:"sizes"`
+	ID      int           `json:"id"`
+	OwnerID int           `json:"owner_id"`
+	Text    string        `json:"text"`
+	Sizes   []VKPhotoSize `json:"sizes"`
 }
+
+// Копия фото одного размера; у превью документов адрес в src
 type VKPhotoSize struct {
-	Type string `json:"type"`
-	URL  string `json:"url"`
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Src    string `json:"src"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
 }
 
-// Структура для видео (упрощённая)
-type VKVideo struct {
-	Player string `json:"player"` // URL плеера
+func (s VKPhotoSize) link() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return s.Src
 }
 
-// Структура для аудио (упрощённая)
-type VKAudio struct {
-	URL string `json:"url"`
-}
+// sizeOrder - размеры VK от меньшего к большему: у старых фото width и height нулевые
+const sizeOrder = "smxopqryzw"
 
-// Функция для получения медиа из заданного количества постов
-func GetMediaFromPosts(accessToken string, ownerID int, count int) ([]VKMedia, error) {
-	// Базовый URL VK API для получения постов
-	baseURL := "https://api.vk.com/method/wall.get"
+// largestSize - копия с наибольшей площадью, при неизвестных размерах - по букве типа
+func largestSize(sizes []VKPhotoSize) (VKPhotoSize, bool) {
+	best, found := VKPhotoSize{}, false
+	for _, s := range sizes {
+		if s.link() == "" {
+			continue
+		}
+		switch {
+		case !found:
+		case s.Width*s.Height > best.Width*best.Height:
+		case s.Width*s.Height == best.Width*best.Height &&
+			strings.Index(sizeOrder, s.Type) > strings.Index(sizeOrder, best.Type):
+		default:
+			continue
+		}
+		best, found = s, true
+	}
+	return best, found
+}
 
-	// Параметры запроса
-	params := url.Values{}
-	params.Set("access_token", accessToken)
-	params.Set("v", "5.131")                      // Версия API
-	params.Set("owner_id", strconv.Itoa(ownerID)) // ID владельца стены (группа с минусом)
-	params.Set("count", strconv.Itoa(count))      // Количество постов (макс 100)
-	params.Set("extended", "1")                   // Расширенная информация (опционально)
+// Структура для видео. player приходит не всегда: без него ссылка ведёт на страницу видео
+type VKVideo struct {
+	ID          int           `json:"id"`
+	OwnerID     int           `json:"owner_id"`
+	Title       string        `json:"title"`
+	Player      string        `json:"player"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	Image       []VKPhotoSize `json:"image"`
+	FirstFrame  []VKPhotoSize `json:"first_frame"`
+	AccessKey   string        `json:"access_key"`
+	Description string        `json:"description"`
+}
 
-	// Формируем полный URL
-	fullURL := baseURL + "?" + params.Encode()
-	fmt.Printf("Запрос: %s\n", fullURL)
+// Структура для документа (файлы, gif)
+type VKDoc struct {
+	ID      int    `json:"id"`
+	OwnerID int    `json:"owner_id"`
+	Title   string `json:"title"`
+	Ext     string `json:"ext"`
+	URL     string `json:"url"`
+	Preview struct {
+		Photo struct {
+			Sizes []VKPhotoSize `json:"sizes"`
+		} `json:"photo"`
+	} `json:"preview"`
+}
 
-	// Делаем HTTP GET запрос
-	resp, err := http.Get(fullURL)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка запроса к VK API: %w", err)
-	}
-	defer resp.Body.Close()
+// Структура для ссылки со сниппетом
+type VKLink struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Caption     string   `json:"caption"`
+	Description string   `json:"description"`
+	Photo       *VKPhoto `json:"photo"`
+}
 
-	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("VK API вернул ошибку: %s, тело: %s", resp.Status, string(body))
-	}
+// Структура для опроса
+type VKPoll struct {
+	ID       int    `json:"id"`
+	OwnerID  int    `json:"owner_id"`
+	Question string `json:"question"`
+}
 
-	// Читаем и парсим JSON
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения ответа: %w", err)
-	}
+// Структура для аудио. url пустой, если запись недоступна вне VK
+type VKAudio struct {
+	ID      int    `json:"id"`
+	OwnerID int    `json:"owner_id"`
+	Artist  string `json:"artist"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+}
 
-	var vkResp VKWallResponse
-	if err := json.Unmarshal(body, &vkResp); err != nil {
-		return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
+// Media - вложения поста из ответа wall.get, в порядке вложений
+func (p VKPost) Media() []VKMedia {
+	var media []VKMedia
+	for _, att := range p.Attachments {
+		if m, ok := att.media(); ok {
+			media = append(media, m)
+		}
 	}
+	return media
+}
 
-	// Извлекаем медиа из attachments всех постов
-	var mediaList []VKMedia
-	for _, post := range vkResp.Response.Items {
-		for _, att := range post.Attachments {
-			mediaType, ok := att["type"].(string)
-			if !ok {
-				continue
-			}
-			var url string
-			switch mediaType {
-			case "photo":
-				if photoData, ok := att["photo"].(map[string]interface{}); ok {
-					photoBytes, _ := json.Marshal(photoData)
-					var photo VKPhoto
-					json.Unmarshal(photoBytes, &photo)
-					if len(photo.Sizes) > 0 {
-						url = photo.Sizes[len(photo.Sizes)-1].URL // Самый большой размер
-					}
-				}
-			case "video":
-				if videoData, ok := att["video"].(map[string]interface{}); ok {
-					videoBytes, _ := json.Marshal(videoData)
-					var video VKVideo
-					json.Unmarshal(videoBytes, &video)
-					url = video.Player // URL плеера
-				}
-			case "audio":
-				if audioData, ok := att["audio"].(map[string]interface{}); ok {
-					audioBytes, _ := json.Marshal(audioData)
-					var audio VKAudio
-					json.Unmarshal(audioBytes, &audio)
-					url = audio.URL
-				}
-			case "doc":
-				// Для документов можно взять URL, если есть
-				if docData, ok := att["doc"].(map[string]interface{}); ok {
-					if docURL, ok := docData["url"].(string); ok {
-						url = docURL
-					}
-				}
-			// Можно добавить другие типы: link, poll и т.д.
-			default:
-				continue
-			}
-			if url != "" {
-				mediaList = append(mediaList, VKMedia{Type: mediaType, URL: url})
+// media - вложение как VKMedia; false - тип не поддерживается или нет ссылки
+func (a VKAttachment) media() (VKMedia, bool) {
+	m := VKMedia{Type: a.Type}
+	switch {
+	case a.Type == "photo" && a.Photo != nil:
+		size, ok := largestSize(a.Photo.Sizes)
+		if !ok {
+			return m, false
+		}
+		m.URL, m.Width, m.Height, m.Title = size.link(), size.Width, size.Height, a.Photo.Text
+	case a.Type == "video" && a.Video != nil:
+		v := a.Video
+		m.URL = v.Player
+		if m.URL == "" {
+			m.URL = fmt.Sprintf("https://vk.com/video%d_%d", v.OwnerID, v.ID)
+		}
+		m.Width, m.Height, m.Title = v.Width, v.Height, v.Title
+		if preview, ok := largestSize(v.Image); ok {
+			m.Thumbnail = preview.link()
+		} else if frame, ok := largestSize(v.FirstFrame); ok {
+			m.Thumbnail = frame.link()
+		}
+	case a.Type == "doc" && a.Doc != nil:
+		m.URL, m.Title = a.Doc.URL, a.Doc.Title
+		if preview, ok := largestSize(a.Doc.Preview.Photo.Sizes); ok {
+			m.Thumbnail, m.Width, m.Height = preview.link(), preview.Width, preview.Height
+		}
+	case a.Type == "link" && a.Link != nil:
+		l := a.Link
+		m.URL, m.Title = l.URL, l.Title
+		if l.Description != "" {
+			m.Title = strings.TrimSpace(l.Title + ". " + l.Description)
+		}
+		if l.Photo != nil {
+			if preview, ok := largestSize(l.Photo.Sizes); ok {
+				m.Thumbnail = preview.link()
 			}
 		}
+	case a.Type == "poll" && a.Poll != nil:
+		m.URL = fmt.Sprintf("https://vk.com/poll%d_%d", a.Poll.OwnerID, a.Poll.ID)
+		m.Title = a.Poll.Question
+	case a.Type == "audio" && a.Audio != nil:
+		au := a.Audio
+		m.URL = au.URL
+		if m.URL == "" {
+			m.URL = fmt.Sprintf("https://vk.com/audio%d_%d", au.OwnerID, au.ID)
+		}
+		m.Title = strings.Trim(au.Artist+" - "+au.Title, " -")
+	default:
+		return m, false
 	}
-
-	return mediaList, nil
+	return m, m.URL != ""
 }
@@ -1,178 +1,249 @@
 package vkToken
 
-// получение vk токена
-// для первоначального получения токена необходима авторизация в браузере, затем скрипт будет автоматически обновлять токен
+// Токен VK API для researcher-а. Два режима:
+//   - сервисный ключ приложения: не истекает и не обновляется, его хватает для
+//     публичных данных (поиск открытых групп, их стены и комментарии);
+//   - пользовательский токен с refresh_token: хранится в файле и обновляется
+//     перед истечением и после ошибки 5 (авторизация не удалась).
+// Браузер researcher не открывает и код из консоли не ждёт: без действующего
+// токена возвращается ErrAuthRequired, а код авторизации передаётся флагом -auth-code.
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
-var refresh_token string
-var access_token string
-var auth_url string
+const (
+	tokenURL = "https://oauth.vk.com/access_token"
+	authURL  = "https://oauth.vk.com/authorize"
+	// refreshBefore - токен обновляется заранее, чтобы не истёк посреди обхода
+	refreshBefore = 5 * time.Minute
+	// DefaultRedirectURI - адрес возврата по умолчанию: код берётся из адресной строки
+	DefaultRedirectURI = "https://oauth.vk.com/blank.html"
+)
 
-var rw sync.RWMutex
-var expiresAt time.Time
+// ErrAuthRequired - действующего токена нет и получить его без человека нельзя
+var ErrAuthRequired = errors.New("VK authorization required")
 
 type TokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token,omitempty"`
-	ExpiresIn    int    `json:"expires_in"`
-	TokenType    string `json:"token_type"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error,omitempty"`
+	ErrorDescription string `json:"error_description,omitempty"`
 }
 
+// StoredToken - содержимое файла токена. Нулевой ExpiresAt - токен не истекает (scope offline)
 type StoredToken struct {
 	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
 }
 
-const (
-	clientID     = "YOUR_CLIENT_ID"     // Замени на свой из VK Developers
-	clientSecret = "YOUR_CLIENT_SECRET" // Замени на свой (секретно храни!)
-	redirectURI  = "http://localhost:8080/callback"
-)
-
-func InitTokenStrings() {
-	refresh_token = ""
-	access_token = ""
+// App - приложение VK, от имени которого выдаются токены
+type App struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
 }
 
-func generateState() string {
-	b := make([]byte, 32)
-	rand.Read(b)
-	return base64.URLEncoding.EncodeToString(b)
+// AuthURL - ссылка, по которой человек разрешает доступ и получает code для -auth-code
+func (a App) AuthURL() string {
+	params := url.Values{}
+	params.Set("client_id", a.ClientID)
+	params.Set("redirect_uri", a.redirectURI())
+	params.Set("scope", "wall,groups,offline")
+	params.Set("response_type", "code")
+	params.Set("v", "5.199")
+	return authURL + "?" + params.Encode()
 }
 
-func GetAccessToken() string {
-	rw.RLock()
-	defer rw.RUnlock()
-	return access_token
+func (a App) redirectURI() string {
+	if a.RedirectURI == "" {
+		return DefaultRedirectURI
+	}
+	return a.RedirectURI
 }
 
-func GetAuthUrl() string {
-	return auth_url
+// Provider выдаёт действующий токен; один Provider делится между всеми запросами
+type Provider struct {
+	mu    sync.Mutex
+	token StoredToken
+	// path - файл токена; пустой у сервисного ключа
+	path   string
+	app    App
+	client *http.Client
 }
 
-func setAccessToken(new_access_token string) {
-	rw.Lock()
-	defer rw.Unlock()
-	access_token = new_access_token
+// NewServiceKey - режим сервисного ключа
+func NewServiceKey(key string) (*Provider, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("%w: empty service key", ErrAuthRequired)
+	}
+	return &Provider{token: StoredToken{AccessToken: key}}, nil
 }
 
-func refreshAccessToken() error {
-	data := url.Values{}
-	data.Set("grant_type", "refresh_token")
-	data.Set("refresh_token", refresh_token)
-	data.Set("client_id", clientID)
-	data.Set("client_secret", clientSecret)
-
-	resp, err := http.PostForm("https://oauth.vk.com/access_token", data)
-	if err != nil {
-		return err
+// Load - режим обновляемого токена из файла path
+func Load(path string, app App) (*Provider, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no token file %s", ErrAuthRequired, path)
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var token StoredToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("token file %s: %w", path, err)
+	}
+	if token.AccessToken == "" && token.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: token file %s is empty", ErrAuthRequired, path)
 	}
+	return newProvider(path, app, token), nil
+}
 
-	var tokenResp TokenResponse
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return err
+// Exchange обменивает код авторизации на токен, сохраняет его в path и
+// возвращает Provider в режиме обновляемого токена
+func Exchange(ctx context.Context, path string, app App, code string) (*Provider, error) {
+	p := newProvider(path, app, StoredToken{})
+	data := url.Values{}
+	data.Set("redirect_uri", app.redirectURI())
+	data.Set("code", strings.TrimSpace(code))
+	if err := p.requestToken(ctx, data); err != nil {
+		return nil, err
 	}
+	return p, nil
+}
 
-	if tokenResp.AccessToken == "" {
-		return fmt.Errorf("failed to refresh token: %s", string(body))
+func newProvider(path string, app App, token StoredToken) *Provider {
+	return &Provider{
+		token:  token,
+		path:   path,
+		app:    app,
+		client: &http.Client{Timeout: 30 * time.Second},
 	}
+}
 
-	expiresAt = time.Now().Add(time.Hour)
-	setAccessToken(tokenResp.AccessToken)
-	refresh_token = tokenResp.RefreshToken
-	return nil
+// Refreshable - токен обновляется (не сервисный ключ)
+func (p *Provider) Refreshable() bool {
+	return p.path != ""
 }
 
-func getNewToken() error {
-	state := generateState()
-	authURL := fmt.Sprintf("https://oauth.vk.com/authorize?client_id=%s&redirect_uri=%s&scope=wall,groups&response_type=code&state=%s&v=5.131",
-		clientID, redirectURI, state)
+// Token возвращает действующий токен, при необходимости обновив его
+func (p *Provider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Refreshable() && p.expiring() {
+		if err := p.refresh(ctx); err != nil {
+			return "", err
+		}
+	}
+	return p.token.AccessToken, nil
+}
 
-	fmt.Printf("Открой браузер и перейди по ссылке: %s\n", authURL)
-	auth_url = authURL
-	fmt.Println("После авторизации скопируй code из URL и вставь сюда:")
+// Invalidate вызывается, когда VK отклонил токен failed (ошибка 5), и возвращает
+// новый. Если токен уже обновили в другом запросе, возвращает текущий.
+func (p *Provider) Invalidate(ctx context.Context, failed string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token.AccessToken != failed {
+		return p.token.AccessToken, nil
+	}
+	if !p.Refreshable() {
+		return "", fmt.Errorf("%w: service key rejected", ErrAuthRequired)
+	}
+	if err := p.refresh(ctx); err != nil {
+		return "", err
+	}
+	return p.token.AccessToken, nil
+}
 
-	var code string
-	fmt.Scanln(&code)
+func (p *Provider) expiring() bool {
+	if p.token.AccessToken == "" {
+		return true
+	}
+	return !p.token.ExpiresAt.IsZero() && time.Now().After(p.token.ExpiresAt.Add(-refreshBefore))
+}
 
+func (p *Provider) refresh(ctx context.Context) error {
+	if p.token.RefreshToken == "" {
+		return fmt.Errorf("%w: token expired and there is no refresh_token", ErrAuthRequired)
+	}
 	data := url.Values{}
-	data.Set("client_id", clientID)
-	data.Set("client_secret", clientSecret)
-	data.Set("redirect_uri", redirectURI)
-	data.Set("code", code)
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", p.token.RefreshToken)
+	return p.requestToken(ctx, data)
+}
+
+// requestToken запрашивает токен у oauth.vk.com и сохраняет его в файл.
+// Отказ VK (неверный код, отозванный refresh_token) - ErrAuthRequired,
+// сетевые ошибки возвращаются как есть и не требуют новой авторизации.
+func (p *Provider) requestToken(ctx context.Context, data url.Values) error {
+	data.Set("client_id", p.app.ClientID)
+	data.Set("client_secret", p.app.ClientSecret)
 
-	resp, err := http.PostForm("https://oauth.vk.com/access_token", data)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("VK token request: %w", err)
+	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("VK token request: %s", resp.Status)
+	}
 
 	var tokenResp TokenResponse
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return err
+		return fmt.Errorf("VK token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return fmt.Errorf("%w: %s %s", ErrAuthRequired, tokenResp.Error, tokenResp.ErrorDescription)
 	}
 
-	expiresAt = time.Now().Add(time.Hour)
-	setAccessToken(tokenResp.AccessToken)
-	refresh_token = tokenResp.RefreshToken
-	return nil
+	token := StoredToken{AccessToken: tokenResp.AccessToken, RefreshToken: tokenResp.RefreshToken}
+	if token.RefreshToken == "" {
+		// VK возвращает refresh_token не при каждом обновлении
+		token.RefreshToken = p.token.RefreshToken
+	}
+	if tokenResp.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+	p.token = token
+	return p.save()
 }
 
-func GetValidToken() error {
-	if len(access_token) == 0 && len(refresh_token) == 0 {
-		// первое получение токенов
-		fmt.Println("Токен не найден. Получаем новый...")
-		err := getNewToken()
-		if err != nil {
-			return err
-		}
-		return nil
-	}
-
-	// Проверяем, истёк ли токен
-	if time.Now().After(expiresAt) {
-		fmt.Println("Токен истёк. Обновляем...")
-		if refresh_token == "" {
-			// refresh_token нет — получаем новый через браузер
-			err := getNewToken()
-			if err != nil {
-				return err
-			}
-		} else {
-			// Обновляем через refresh_token
-			err := refreshAccessToken()
-			if err != nil {
-				fmt.Println("Не удалось обновить через refresh_token. Получаем новый...")
-				err = getNewToken()
-				if err != nil {
-					return err
-				}
-			}
-		}
+// save записывает токен атомарно: оборванная запись не портит прежний файл
+func (p *Provider) save() error {
+	data, err := json.MarshalIndent(p.token, "", "  ")
+	if err != nil {
+		return err
 	}
-
-	return nil
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return err
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
 }
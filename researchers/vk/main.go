@@ -1,244 +1,133 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"time"
-	"strings" // ДОБАВИТЬ ЭТУ СТРОКУ
 
-	"researcher-vk/internal/config"
+	"apiclient"
+	"researcher"
 	"researcher-vk/internal/sendRequests"
 	"researcher-vk/internal/vk"
-	_ "researcher-vk/internal/vkToken"
+	"researcher-vk/internal/vkToken"
 )
 
-var groupOffset int
+const (
+	serverURL       = "http://server:8080"
+	configFileName  = "/usr/local/etc/vk-researcher/test_conf.xml"
+	accessTokenFile = "/usr/local/etc/vk-researcher/access_token"
+	stateFileName   = "/var/lib/vk-researcher/state.json"
+	// tokenFileName - обновляемый токен пользователя (режим refresh_token)
+	tokenFileName = "/var/lib/vk-researcher/token.json"
+	// exitAuthRequired - код выхода без действующего токена: перезапуск не поможет
+	exitAuthRequired = 78
+)
 
-func MakeGroupsSlice(accessToken string, conf config.ResearcherConfig) ([]vk.VKGroup, error) {
-	if len(conf.Preferred_channels) == 0 {
-		return vk.GetTopPopularGroups(accessToken, conf.Channel_limit, groupOffset)
-	}
-	groupOffset += conf.Channel_limit
-	return vk.GetGroupsByFullNames(accessToken, conf.Preferred_channels, conf.Channel_limit)
-}
 func main() {
-    // Инициализируем логгер
-    if err := sendRequests.InitLogger(""); err != nil {
-        fmt.Printf("Failed to init logger: %v\n", err)
-    }
-    
-    defer func() {
-        if logger := sendRequests.GetLogger(); logger != nil {
-            logger.Close()
-        }
-    }()
-
-    currentDir, _ := os.Getwd()
-    fmt.Printf("Current directory: %s\n", currentDir)
-    
-    if debugLogger := sendRequests.GetDebugLogger(); debugLogger != nil {
-        debugLogger.DebugLog("MAIN", "Application started in directory: %s", currentDir)
-    }
-
-    // Небольшая задержка для запуска
-    time.Sleep(5 * time.Second)
-
-    accessTokenFileName := "/usr/local/etc/vk-researcher/access_token"
-    accessToken, err := os.ReadFile(accessTokenFileName)
-    if err != nil {
-        fmt.Println(err)
-        os.Exit(1)
-    }
-
-    // Добавить источник VK
-    sourceID, err := sendRequests.AddVKSource()
-    if err != nil {
-        fmt.Printf("Failed to add VK source: %v\n", err)
-        os.Exit(1)
-    } else {
-        fmt.Printf("sourceID = %d\n\n", sourceID)
-    }
-
-    groupOffset = 0
-    for {
-        fmt.Printf("\n=== New Scan Cycle ===\n")
-        
-        if logger := sendRequests.GetDebugLogger(); logger != nil {
-            logger.DebugLog("MAIN", "Starting new scan cycle")
-        }
-
-        ConfigFileName := "/usr/local/etc/vk-researcher/test_conf.xml"
-        xmlData, err := os.ReadFile(ConfigFileName)
-
-        if err != nil {
-            fmt.Println(err)
-            os.Exit(1)
-        }
-		
-        if logger := sendRequests.GetDebugLogger(); logger != nil {
-            logger.DebugLog("CONFIG", "Config file loaded, size: %d bytes", len(xmlData))
-        }
-
-        var conf config.ResearcherConfig
-        conf, err = config.ParseConfigFile(string(xmlData))
-        if err != nil {
-            fmt.Println(err)
-            os.Exit(1)
-        }
-        err = config.ValidateConfig(conf)
-        if err != nil {
-            fmt.Println(err)
-            os.Exit(1)
-        }
-
-        // Уменьшаем лимиты для избежания Flood Control
-        if conf.Channel_limit > 10 {
-            fmt.Printf("Reducing channel limit from %d to 10 to avoid flood control\n", conf.Channel_limit)
-            conf.Channel_limit = 10
-        }
-        
-        if conf.Post_limit > 50 {
-            fmt.Printf("Reducing post limit from %d to 50 to avoid flood control\n", conf.Post_limit)
-            conf.Post_limit = 50
-        }
-
-        // Получаем список групп
-        var groupsSlice []vk.VKGroup
-        groupsSlice, err = MakeGroupsSlice(string(accessToken), conf)
-        if err != nil {
-            fmt.Println(err)
-            os.Exit(1)
-        }
-
-        fmt.Printf("Found %d groups to process\n", len(groupsSlice))
-
-        // Обрабатываем группы с задержками
-        for i, group := range groupsSlice {
-            fmt.Printf("\n[%d/%d] Processing group: %s (ID: %d, Members: %d)\n", 
-                i+1, len(groupsSlice), group.Name, group.ID, group.MembersCount)
-            
-            // Задержка между группами
-            if i > 0 {
-                delay := 8 * time.Second
-                fmt.Printf("Waiting %v before next group...\n", delay)
-                time.Sleep(delay)
-            }
-
-            // Создаем автора для группы
-            authorName := fmt.Sprintf("VK Group: %s", group.Name)
-            authorID, err := sendRequests.AddVKAuthor(authorName)
-            if err != nil {
-                fmt.Printf("WARNING: Failed to create author for group %s: %v\n", group.Name, err)
-                // Пропускаем группу если не можем создать автора
-                continue
-            }
-
-            // Создаем канал
-            channelID, err := sendRequests.AddVKChannel(group, sourceID)
-            if err != nil {
-                fmt.Printf("ERROR: Failed to add channel for group %s: %v\n", group.Name, err)
-                continue
-            }
-
-            fmt.Printf("✓ Channel added with ID: %d, Author ID: %d\n", channelID, authorID)
-            fmt.Printf("Getting posts for group: %s (ID: %d)\n", group.Name, group.ID)
-            
-            // Получаем посты с задержкой
-            time.Sleep(1 * time.Second)
-            
-            postsSlice, err := vk.GetGroupPostsWithRetry(string(accessToken), group.ID, conf.Post_limit)
-            if err != nil {
-                fmt.Printf("ERROR in GetGroupPosts: %v\n", err)
-                fmt.Printf("Skipping group %s due to API error\n", group.Name)
-                
-                // При ошибке Flood control увеличиваем задержку
-                if err.Error() == "flood control" {
-                    fmt.Printf("Flood control detected, increasing delay...\n")
-                    time.Sleep(10 * time.Second)
-                }
-                continue
-            }
-            
-            fmt.Printf("Got %d posts\n", len(postsSlice))
-            
-            if len(postsSlice) == 0 {
-                fmt.Printf("No posts found for group %s\n", group.Name)
-                continue
-            }
+	authCode := flag.String("auth-code", "", "обменять код авторизации VK на токен, сохранить его и продолжить работу")
+	flag.Parse()
 
-            // Показываем информацию о первом посте
-            if len(postsSlice) > 0 {
-                fmt.Printf("First post preview: ID=%d, Date: %v, Likes: %d, Comments: %d\n", 
-                    postsSlice[0].ID, 
-                    time.Unix(postsSlice[0].Date, 0).Format("2006-01-02 15:04:05"),
-                    postsSlice[0].Likes, 
-                    postsSlice[0].Comments)
-            }
-
-            if logger := sendRequests.GetDebugLogger(); logger != nil {
-                logger.DebugLog("POSTS", "Retrieved %d posts for group %s", len(postsSlice), group.Name)
-            }
+	// Инициализируем логгер
+	if err := sendRequests.InitLogger(""); err != nil {
+		fmt.Printf("Failed to init logger: %v\n", err)
+	}
+	defer func() {
+		if logger := sendRequests.GetLogger(); logger != nil {
+			logger.Close()
+		}
+	}()
+
+	// Небольшая задержка для запуска
+	time.Sleep(5 * time.Second)
+
+	app := vkToken.App{
+		ClientID:     os.Getenv("VK_CLIENT_ID"),
+		ClientSecret: os.Getenv("VK_CLIENT_SECRET"),
+		RedirectURI:  os.Getenv("VK_REDIRECT_URI"),
+	}
+	tokens, err := tokenProvider(app, *authCode)
+	if err != nil {
+		authFailed(app, err)
+	}
 
-            // Ограничиваем количество постов для обработки
-            postsToProcess := len(postsSlice)
-            if postsToProcess > 20 {
-                postsToProcess = 20
-                fmt.Printf("Limiting to %d posts to avoid overloading\n", postsToProcess)
-            }
+	state, err := researcher.OpenState(stateFileName)
+	if err != nil {
+		fmt.Printf("Failed to open state: %v\n", err)
+		os.Exit(1)
+	}
 
-            // Отправляем посты
-            for j := 0; j < postsToProcess; j++ {
-                post := postsSlice[j]
-                fmt.Printf("Processing post %d/%d: ID=%d\n", 
-                    j+1, postsToProcess, post.ID)
-                
-                // Задержка между постами
-                if j > 0 {
-                    time.Sleep(500 * time.Millisecond)
-                }
-                
-                // Добавляем пост
-                postID, err := sendRequests.AddVKPost(post, channelID, authorID, group.Name)
-                if err != nil {
-                    fmt.Printf("ERROR: Failed to add post [%d]%d: %v\n", j, post.ID, err)
-                    
-                    // При ошибке сервера делаем паузу
-                    if strings.Contains(err.Error(), "server error") {
-                        fmt.Printf("Server error, waiting 5 seconds...\n")
-                        time.Sleep(5 * time.Second)
-                    }
-                    continue
-                }
+	platform := vk.NewPlatform(vk.NewClient(tokens))
+	// С сервисным ключом обход без preferred_channels упадёт на первом же поиске групп
+	if conf, err := researcher.LoadConfig(configFileName, platform.Name()); err == nil {
+		if err := platform.CheckConfig(conf); err != nil {
+			authFailed(app, err)
+		}
+	}
 
-                fmt.Printf("✓ Post %d added successfully with postID: %d\n", post.ID, postID)
+	ingest := researcher.NewIngest(apiclient.New(serverURL))
+	ingest.Log = sendRequests.LogResult
 
-                // Получаем и добавляем медиа (если есть лимит)
-                if conf.Media_limit > 0 && postID > 0 {
-                    MediaSlice, _ := vk.GetMediaFromPosts(string(accessToken), post.AuthorID, 5) // Ограничим 5 медиа
-                    
-                    if len(MediaSlice) > 0 {
-                        fmt.Printf("Found %d media items for post %d\n", len(MediaSlice), post.ID)
-                        
-                        for k, mediaInfo := range MediaSlice {
-                            fmt.Printf("Adding media %d/%d: Type=%s\n", 
-                                k+1, len(MediaSlice), mediaInfo.Type)
-                            
-                            err := sendRequests.AddVKMedia(mediaInfo, postID)
-                            if err != nil {
-                                fmt.Printf("ERROR: Failed to add media: %v\n", err)
-                            }
-                            time.Sleep(200 * time.Millisecond)
-                        }
-                    }
-                }
-            }
-            
-            fmt.Printf("✓ Group %s processed: %d posts added\n", group.Name, postsToProcess)
-        }
+	// Частоту запросов к VK API ограничивает vk.Client
+	runner := &researcher.Runner{
+		Platform:   platform,
+		Ingest:     ingest,
+		State:      state,
+		ConfigPath: configFileName,
+	}
+	if err := runner.Run(context.Background()); err != nil {
+		if errors.Is(err, vkToken.ErrAuthRequired) || errors.Is(err, vk.ErrUserTokenRequired) {
+			authFailed(app, err)
+		}
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
 
-        fmt.Printf("\n=== Scan cycle completed, waiting %d seconds ===\n", conf.Research_period)
+// tokenProvider выбирает режим токена: код из -auth-code, сохранённый токен
+// пользователя (tokenFileName), сервисный ключ из VK_SERVICE_KEY или accessTokenFile
+func tokenProvider(app vkToken.App, authCode string) (*vkToken.Provider, error) {
+	if authCode != "" {
+		return vkToken.Exchange(context.Background(), tokenFileName, app, authCode)
+	}
+	if _, err := os.Stat(tokenFileName); err == nil {
+		fmt.Printf("Using user token from %s\n", tokenFileName)
+		return vkToken.Load(tokenFileName, app)
+	}
+	if key := os.Getenv("VK_SERVICE_KEY"); key != "" {
+		fmt.Println("Using service key from VK_SERVICE_KEY")
+		return vkToken.NewServiceKey(key)
+	}
+	key, err := os.ReadFile(accessTokenFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no token file, VK_SERVICE_KEY or %s", vkToken.ErrAuthRequired, accessTokenFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Using service key from %s\n", accessTokenFile)
+	return vkToken.NewServiceKey(string(key))
+}
 
-        timer1 := time.NewTimer(time.Duration(conf.Research_period) * time.Second)
-        <-timer1.C
-    }
-}
\ No newline at end of file
+// authFailed завершает researcher без ожидания ввода: в контейнере его некому дать.
+// Нехватка токена пользователя для поиска групп тоже завершает его с exitAuthRequired.
+func authFailed(app vkToken.App, err error) {
+	fmt.Printf("ERROR: %v\n", err)
+	if errors.Is(err, vkToken.ErrAuthRequired) || errors.Is(err, vk.ErrUserTokenRequired) {
+		if errors.Is(err, vk.ErrUserTokenRequired) {
+			fmt.Printf("Service key mode: list group screen names in preferred_channels of %s, or authorize a user:\n", configFileName)
+		} else {
+			fmt.Println("Set VK_SERVICE_KEY (service key of the VK app) for public data, or authorize a user:")
+		}
+		if app.ClientID != "" {
+			fmt.Printf("  open %s\n", app.AuthURL())
+		} else {
+			fmt.Println("  set VK_CLIENT_ID and VK_CLIENT_SECRET, then open the authorization link printed here")
+		}
+		fmt.Println("  and run researcher-vk -auth-code <code from the redirect URL>")
+		os.Exit(exitAuthRequired)
+	}
+	os.Exit(1)
+}
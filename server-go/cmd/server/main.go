@@ -54,8 +54,27 @@ func main() {
 
 	// Настройка маршрутов
 	handler := handlers.NewHandlers(pool, cacheManager, mongoManager)
+	// API_LEGACY_ROUTES=false отключает устаревшие маршруты /api/{table}
+	handler.SetLegacyRoutes(getEnv("API_LEGACY_ROUTES", "true") != "false")
+	// POST_PREMODERATION=true - все новые посты ждут одобрения в /api/moderation/queue
+	handler.SetPremoderation(getEnv("POST_PREMODERATION", "false") == "true")
 	router := handler.SetupRoutes()
 
+	// Фоновая кластеризация сюжетов; STORY_CLUSTER_INTERVAL=0 отключает её
+	if interval, err := time.ParseDuration(getEnv("STORY_CLUSTER_INTERVAL", "10m")); err != nil {
+		log.Printf("Invalid STORY_CLUSTER_INTERVAL: %v", err)
+	} else if interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				if _, err := handler.ClusterStories(context.Background(), 72*time.Hour); err != nil {
+					log.Printf("Story clustering error: %v", err)
+				}
+			}
+		}()
+	}
+
 	// HTTP сервер
 	srv := &http.Server{
 		Addr:         ":8080",
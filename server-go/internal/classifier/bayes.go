@@ -0,0 +1,91 @@
+package classifier
+
+import "math"
+
+// naiveBayes - мультиномиальная модель со сглаживанием Лапласа.
+// Пост с несколькими категориями учитывается в каждой из них.
+type naiveBayes struct {
+	docs       int
+	classDocs  map[string]int
+	classWords map[string]int
+	wordCounts map[string]map[string]int
+	vocab      map[string]struct{}
+}
+
+func trainNaiveBayes(examples []Example) *naiveBayes {
+	nb := &naiveBayes{
+		classDocs:  map[string]int{},
+		classWords: map[string]int{},
+		wordCounts: map[string]map[string]int{},
+		vocab:      map[string]struct{}{},
+	}
+	for _, ex := range examples {
+		if len(ex.Categories) == 0 {
+			continue
+		}
+		tokens := Tokenize(ex.Text)
+		nb.docs++
+		for _, category := range ex.Categories {
+			nb.classDocs[category]++
+			counts := nb.wordCounts[category]
+			if counts == nil {
+				counts = map[string]int{}
+				nb.wordCounts[category] = counts
+			}
+			for _, t := range tokens {
+				counts[t]++
+				nb.classWords[category]++
+				nb.vocab[t] = struct{}{}
+			}
+		}
+	}
+	return nb
+}
+
+// active - модели хватает данных хотя бы для двух категорий
+func (nb *naiveBayes) active() bool {
+	return nb.docs >= MinTrainingDocs && len(nb.classDocs) >= 2
+}
+
+// predict - апостериорные вероятности категорий; пусто, пока модель не активна
+func (nb *naiveBayes) predict(tokens []string) map[string]float64 {
+	result := map[string]float64{}
+	if !nb.active() || len(tokens) == 0 {
+		return result
+	}
+
+	vocab := float64(len(nb.vocab))
+	total := 0
+	for _, n := range nb.classDocs {
+		total += n
+	}
+
+	logs := map[string]float64{}
+	best := math.Inf(-1)
+	for category, n := range nb.classDocs {
+		lp := math.Log(float64(n) / float64(total))
+		denom := float64(nb.classWords[category]) + vocab
+		counts := nb.wordCounts[category]
+		for _, t := range tokens {
+			if _, known := nb.vocab[t]; !known {
+				continue
+			}
+			lp += math.Log((float64(counts[t]) + 1) / denom)
+		}
+		logs[category] = lp
+		if lp > best {
+			best = lp
+		}
+	}
+
+	// softmax со сдвигом на максимум, чтобы не потерять точность
+	var sum float64
+	for category, lp := range logs {
+		result[category] = math.Exp(lp - best)
+		sum += result[category]
+	}
+	for category := range result {
+		result[category] /= sum
+	}
+	return result
+}
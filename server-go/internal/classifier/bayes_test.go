@@ -0,0 +1,106 @@
+package classifier
+
+import (
+	"math"
+	"testing"
+)
+
+// trainingSet - посты, размеченные редакторами: по 8 на спорт и экономику,
+// 3 на политику и один пост в экономике и политике
+var trainingSet = []Example{
+	{"Зенит обыграл ЦСКА в полуфинале кубка, победный гол забит на последней минуте", []string{"sport"}},
+	{"Спартак проиграл Локомотиву, тренер недоволен игрой защиты", []string{"sport"}},
+	{"Сборная вышла в финал чемпионата мира по хоккею", []string{"sport"}},
+	{"Форвард забил гол и помог команде выиграть матч", []string{"sport"}},
+	{"Теннисистка вышла в полуфинал турнира после трёх сетов", []string{"sport"}},
+	{"Тренер сборной объявил состав команды на матч чемпионата", []string{"sport"}},
+	{"Хоккеисты выиграли серию и сыграют в финале кубка", []string{"sport"}},
+	{"Вратарь отразил пенальти, матч завершился вничью", []string{"sport"}},
+	{"Центробанк сохранил ключевую ставку, инфляция замедляется", []string{"economy"}},
+	{"Курс рубля укрепился после решения центробанка по ставке", []string{"economy"}},
+	{"Инфляция в апреле составила полпроцента, цены на продукты выросли", []string{"economy"}},
+	{"Банки повысили ставки по вкладам вслед за ключевой ставкой", []string{"economy"}},
+	{"Нефть подорожала, курс доллара снизился на бирже", []string{"economy"}},
+	{"Аналитики ждут снижения ставки центробанка во втором полугодии", []string{"economy"}},
+	{"Цены на бензин выросли, инфляция ускорилась", []string{"economy"}},
+	{"Биржевые индексы выросли после публикации данных об инфляции", []string{"economy"}},
+	{"Госдума приняла закон о выборах в первом чтении", []string{"politics"}},
+	{"Президент подписал указ о назначении министра", []string{"politics"}},
+	{"Депутаты обсудили закон о бюджете и налогах", []string{"politics", "economy"}},
+	{"Министр выступил в Госдуме с отчётом правительства", []string{"politics"}},
+	// Пост без категорий в обучении не участвует
+	{"Погода на выходные: дожди и прохлада", nil},
+}
+
+func TestNaiveBayesTrain(t *testing.T) {
+	nb := trainNaiveBayes(trainingSet)
+	if nb.docs != 20 || !nb.active() {
+		t.Fatalf("docs = %d, active = %v", nb.docs, nb.active())
+	}
+	want := map[string]int{"sport": 8, "economy": 9, "politics": 4}
+	for category, n := range want {
+		if nb.classDocs[category] != n {
+			t.Errorf("%s docs = %d, want %d", category, nb.classDocs[category], n)
+		}
+	}
+	// Служебные и короткие слова в словарь не попадают
+	for _, w := range []string{"после", "на", "по", "погода"} {
+		if _, ok := nb.vocab[w]; ok {
+			t.Errorf("%q in vocabulary", w)
+		}
+	}
+	if nb.wordCounts["economy"]["инфляция"] != 3 {
+		t.Errorf("economy: инфляция = %d, want 3", nb.wordCounts["economy"]["инфляция"])
+	}
+}
+
+func TestNaiveBayesPredict(t *testing.T) {
+	nb := trainNaiveBayes(trainingSet)
+	tests := []struct {
+		text, want string
+	}{
+		{"Команда выиграла финал кубка, гол на последней минуте", "sport"},
+		{"Центробанк может снизить ключевую ставку, если инфляция замедлится", "economy"},
+		{"Госдума приняла закон, президент подписал указ", "politics"},
+	}
+	for _, tt := range tests {
+		probs := nb.predict(Tokenize(tt.text))
+		var sum float64
+		best, bestP := "", 0.0
+		for category, p := range probs {
+			sum += p
+			if p > bestP {
+				best, bestP = category, p
+			}
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("%q: probabilities sum to %v", tt.text, sum)
+		}
+		if best != tt.want || bestP < Threshold {
+			t.Errorf("%q: best %s (%.3f), want %s above %v; %v", tt.text, best, bestP, tt.want, Threshold, probs)
+		}
+	}
+
+	// Незнакомые слова не учитываются: остаются только априорные вероятности
+	probs := nb.predict(Tokenize("квантовый компьютер"))
+	if math.Abs(probs["economy"]-9.0/21) > 1e-9 {
+		t.Errorf("unknown words: %v, want priors", probs)
+	}
+}
+
+func TestNaiveBayesInactive(t *testing.T) {
+	// Меньше MinTrainingDocs примеров или одна категория - модель молчит
+	tests := [][]Example{
+		trainingSet[:MinTrainingDocs-1],
+		trainingSet[:8],
+	}
+	for i, examples := range tests {
+		nb := trainNaiveBayes(examples)
+		if nb.active() {
+			t.Errorf("set %d: active with %d docs, %d categories", i, nb.docs, len(nb.classDocs))
+		}
+		if probs := nb.predict(Tokenize("Зенит выиграл матч")); len(probs) != 0 {
+			t.Errorf("set %d: predict = %v", i, probs)
+		}
+	}
+}
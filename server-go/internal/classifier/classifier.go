@@ -0,0 +1,155 @@
+// Package classifier - автоматическая категоризация постов: словари ключевых слов
+// (настраиваются через admin API, таблица categories) и наивный байесовский классификатор,
+// обученный на категориях, подтверждённых редакторами (post_categories.method = 'editor').
+package classifier
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Способы назначения категории (post_categories.method)
+const (
+	MethodKeywords = "keywords"
+	MethodBayes    = "bayes"
+	MethodCombined = "keywords+bayes"
+	MethodEditor   = "editor"
+)
+
+const (
+	// Threshold - минимальная уверенность для назначения категории
+	Threshold = 0.5
+	// MaxCategories - не больше стольких категорий на пост
+	MaxCategories = 3
+	// MinTrainingDocs - байесовская модель включается, когда редакторы разметили столько постов
+	MinTrainingDocs = 20
+)
+
+// Dictionary - словарь категории. Ключевое слово - слово или фраза;
+// "*" на конце задаёт префикс ("полити*" совпадает с "политика", "политический").
+type Dictionary struct {
+	Category string
+	Keywords []string
+}
+
+// Example - пост, размеченный редактором
+type Example struct {
+	Text       string
+	Categories []string
+}
+
+// Assignment - назначенная категория
+type Assignment struct {
+	Category   string  `json:"category"`
+	Confidence float64 `json:"confidence"`
+	Method     string  `json:"method"`
+}
+
+// Stats - состояние модели
+type Stats struct {
+	TrainingDocs int            `json:"training_docs"`
+	Vocabulary   int            `json:"vocabulary"`
+	BayesActive  bool           `json:"bayes_active"`
+	Categories   map[string]int `json:"categories"` // постов в обучающей выборке по категориям
+}
+
+// Classifier - словари и обученная модель. После создания только читается,
+// поэтому безопасен для параллельного использования.
+type Classifier struct {
+	dicts []compiledDictionary
+	bayes *naiveBayes
+}
+
+// New строит классификатор по словарям и обучает модель на примерах редакторов
+func New(dicts []Dictionary, examples []Example) *Classifier {
+	c := &Classifier{bayes: trainNaiveBayes(examples)}
+	for _, d := range dicts {
+		c.dicts = append(c.dicts, compileDictionary(d))
+	}
+	return c
+}
+
+// Stats возвращает размер обучающей выборки и словаря модели
+func (c *Classifier) Stats() Stats {
+	s := Stats{
+		TrainingDocs: c.bayes.docs,
+		Vocabulary:   len(c.bayes.vocab),
+		BayesActive:  c.bayes.active(),
+		Categories:   map[string]int{},
+	}
+	for category, n := range c.bayes.classDocs {
+		s.Categories[category] = n
+	}
+	return s
+}
+
+// Classify назначает посту категории: уверенность словаря и модели объединяется
+// как вероятность хотя бы одного срабатывания. Категории упорядочены по уверенности.
+func (c *Classifier) Classify(text string) []Assignment {
+	all := words(text)
+	keywords := map[string]float64{}
+	for _, d := range c.dicts {
+		if hits := d.hits(all); hits > 0 {
+			// 1 совпадение - 0.5, 2 - 0.67, 3 - 0.75...
+			keywords[d.category] = 1 - 1/float64(hits+1)
+		}
+	}
+	bayes := c.bayes.predict(Tokenize(text))
+
+	var result []Assignment
+	for _, d := range c.dicts {
+		k, b := keywords[d.category], bayes[d.category]
+		a := Assignment{Category: d.category}
+		switch {
+		case k > 0 && b >= Threshold:
+			a.Confidence, a.Method = 1-(1-k)*(1-b), MethodCombined
+		case k >= b:
+			a.Confidence, a.Method = k, MethodKeywords
+		default:
+			a.Confidence, a.Method = b, MethodBayes
+		}
+		if a.Confidence >= Threshold {
+			a.Confidence = math.Round(a.Confidence*1000) / 1000
+			result = append(result, a)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Confidence > result[j].Confidence })
+	if len(result) > MaxCategories {
+		result = result[:MaxCategories]
+	}
+	return result
+}
+
+// stopWords - служебные слова, не несущие темы
+var stopWords = map[string]bool{
+	"and": true, "the": true, "for": true, "with": true, "that": true, "this": true, "from": true,
+	"are": true, "was": true, "were": true, "has": true, "have": true, "not": true, "but": true,
+	"you": true, "его": true, "она": true, "они": true, "что": true, "это": true, "как": true,
+	"так": true, "для": true, "при": true, "или": true, "уже": true, "ещё": true, "еще": true,
+	"все": true, "всё": true, "был": true, "была": true, "было": true, "были": true, "будет": true,
+	"если": true, "когда": true, "только": true, "также": true, "чем": true, "где": true,
+	"который": true, "которые": true, "после": true, "тоже": true, "нет": true, "над": true,
+	"под": true, "без": true, "через": true, "про": true, "там": true, "тут": true, "вот": true,
+}
+
+// words разбивает текст на слова в нижнем регистре
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Tokenize - слова текста для модели: без служебных и коротких слов
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, f := range words(text) {
+		if len([]rune(f)) < 3 || stopWords[f] {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
@@ -0,0 +1,54 @@
+package classifier
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testDicts = []Dictionary{
+	{Category: "sport", Keywords: []string{"матч", "гол", "чемпионат*"}},
+	{Category: "economy", Keywords: []string{"ключевая ставка", "инфляц*", "курс рубля"}},
+	{Category: "politics", Keywords: []string{"госдум*", "закон"}},
+}
+
+func TestClassifyKeywords(t *testing.T) {
+	c := New(testDicts, nil)
+	tests := []struct {
+		text string
+		want []Assignment
+	}{
+		// Префикс совпадает с формами слова, уверенность растёт с числом совпадений
+		{"Матч чемпионата завершился, гол на последней минуте", []Assignment{{"sport", 0.75, MethodKeywords}}},
+		{"Ключевая ставка и инфляция", []Assignment{{"economy", 0.667, MethodKeywords}}},
+		// Фраза ищется целиком: слова по отдельности не совпадают
+		{"Ставка сделана, ключевая роль у защиты", nil},
+		{"Госдума приняла закон о ключевой ставке", []Assignment{{"politics", 0.667, MethodKeywords}}},
+	}
+	for _, tt := range tests {
+		if got := c.Classify(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Classify(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyCombined(t *testing.T) {
+	c := New(testDicts, trainingSet)
+	if s := c.Stats(); !s.BayesActive || s.TrainingDocs != 20 || s.Categories["sport"] != 8 {
+		t.Fatalf("stats = %+v", s)
+	}
+
+	got := c.Classify("Центробанк сохранил ключевую ставку, инфляция замедляется")
+	if len(got) == 0 || got[0].Category != "economy" || got[0].Method != MethodCombined {
+		t.Fatalf("assignments = %+v, want economy by %s first", got, MethodCombined)
+	}
+	// Словарь (0.5) и модель вместе увереннее каждого по отдельности
+	if got[0].Confidence <= 0.5 || got[0].Confidence > 1 {
+		t.Errorf("confidence = %v", got[0].Confidence)
+	}
+
+	// Без ключевых слов категорию назначает модель
+	got = c.Classify("Хоккеисты выиграли финал кубка")
+	if len(got) == 0 || got[0].Category != "sport" || got[0].Method != MethodBayes {
+		t.Errorf("assignments = %+v, want sport by %s", got, MethodBayes)
+	}
+}
@@ -0,0 +1,61 @@
+package classifier
+
+import "strings"
+
+// keyword - слово или фраза словаря; prefix - последнее слово задано префиксом ("полити*")
+type keyword struct {
+	words  []string
+	prefix bool
+}
+
+type compiledDictionary struct {
+	category string
+	keywords []keyword
+}
+
+func compileDictionary(d Dictionary) compiledDictionary {
+	c := compiledDictionary{category: d.Category}
+	for _, raw := range d.Keywords {
+		raw = strings.TrimSpace(raw)
+		prefix := strings.HasSuffix(raw, "*")
+		w := words(strings.TrimSuffix(raw, "*"))
+		if len(w) == 0 {
+			continue
+		}
+		c.keywords = append(c.keywords, keyword{words: w, prefix: prefix})
+	}
+	return c
+}
+
+// hits - число разных ключевых слов словаря, встретившихся в тексте
+func (d compiledDictionary) hits(text []string) int {
+	n := 0
+	for _, k := range d.keywords {
+		if k.matches(text) {
+			n++
+		}
+	}
+	return n
+}
+
+func (k keyword) matches(text []string) bool {
+	last := len(k.words) - 1
+	for i := 0; i+last < len(text); i++ {
+		ok := true
+		for j, w := range k.words {
+			t := text[i+j]
+			if j == last && k.prefix {
+				ok = strings.HasPrefix(t, w)
+			} else {
+				ok = t == w
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
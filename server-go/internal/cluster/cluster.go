@@ -0,0 +1,229 @@
+// Package cluster - объединение сюжетов (stories) об одном событии.
+// Посты сравниваются по TF-IDF текста, общим тегам и близости по времени;
+// сюжеты связанных постов сливаются в сюжет с наименьшим ID (появившийся первым).
+package cluster
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"news-aggregator/internal/classifier"
+)
+
+const (
+	// topTerms - по стольким самым весомым словам поста ищутся пары для сравнения
+	topTerms = 8
+	// stemRunes - слова обрезаются до стольких букв: грубая замена стеммингу,
+	// чтобы "повысил" и "повысила", "укрепился" и "укрепляется" совпадали
+	stemRunes = 5
+)
+
+// Document - пост в окне кластеризации
+type Document struct {
+	PostID    int
+	StoryID   int
+	Text      string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+// Options - веса признаков и порог объединения
+type Options struct {
+	Window     time.Duration // посты дальше друг от друга по времени не связываются
+	Threshold  float64       // минимальная итоговая похожесть пары
+	TextWeight float64
+	TagWeight  float64
+	TimeWeight float64
+}
+
+// DefaultOptions - текст важнее всего, теги и время уточняют
+func DefaultOptions() Options {
+	return Options{
+		Window:     48 * time.Hour,
+		Threshold:  0.5,
+		TextWeight: 0.65,
+		TagWeight:  0.2,
+		TimeWeight: 0.15,
+	}
+}
+
+// Result - какие сюжеты в какие слить
+type Result struct {
+	Merges   map[int]int `json:"-"` // story_id -> story_id, в который он сливается
+	Compared int         `json:"compared_pairs"`
+	Linked   int         `json:"linked_pairs"`
+}
+
+type vector map[string]float64
+
+// Group находит связанные посты и возвращает слияния сюжетов
+func Group(docs []Document, opts Options) Result {
+	res := Result{Merges: map[int]int{}}
+	vectors := tfidf(docs)
+
+	// Кандидаты - пары постов с общим словом из топа
+	postings := map[string][]int{}
+	for i, v := range vectors {
+		for _, term := range top(v, topTerms) {
+			postings[term] = append(postings[term], i)
+		}
+	}
+
+	parent := map[int]int{}
+	var find func(int) int
+	find = func(s int) int {
+		p, ok := parent[s]
+		if !ok || p == s {
+			return s
+		}
+		root := find(p)
+		parent[s] = root
+		return root
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+		// корень - сюжет с наименьшим ID
+		if rb < ra {
+			ra, rb = rb, ra
+		}
+		parent[rb] = ra
+	}
+
+	seen := map[[2]int]bool{}
+	for _, list := range postings {
+		for x := 0; x < len(list); x++ {
+			for y := x + 1; y < len(list); y++ {
+				i, j := list[x], list[y]
+				if i > j {
+					i, j = j, i
+				}
+				if seen[[2]int{i, j}] || docs[i].StoryID == docs[j].StoryID {
+					continue
+				}
+				seen[[2]int{i, j}] = true
+				if find(docs[i].StoryID) == find(docs[j].StoryID) {
+					continue
+				}
+				res.Compared++
+				if score(docs[i], docs[j], vectors[i], vectors[j], opts) >= opts.Threshold {
+					res.Linked++
+					union(docs[i].StoryID, docs[j].StoryID)
+				}
+			}
+		}
+	}
+
+	for _, d := range docs {
+		if root := find(d.StoryID); root != d.StoryID {
+			res.Merges[d.StoryID] = root
+		}
+	}
+	return res
+}
+
+// score - взвешенная похожесть пары; 0, если посты дальше окна по времени
+func score(a, b Document, va, vb vector, opts Options) float64 {
+	gap := a.CreatedAt.Sub(b.CreatedAt)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > opts.Window {
+		return 0
+	}
+	proximity := 1 - float64(gap)/float64(opts.Window)
+	return opts.TextWeight*cosine(va, vb) + opts.TagWeight*jaccard(a.Tags, b.Tags) + opts.TimeWeight*proximity
+}
+
+// tfidf - нормированные TF-IDF векторы постов
+func tfidf(docs []Document) []vector {
+	counts := make([]map[string]int, len(docs))
+	df := map[string]int{}
+	for i, d := range docs {
+		counts[i] = map[string]int{}
+		for _, t := range classifier.Tokenize(d.Text) {
+			if r := []rune(t); len(r) > stemRunes {
+				t = string(r[:stemRunes])
+			}
+			if counts[i][t] == 0 {
+				df[t]++
+			}
+			counts[i][t]++
+		}
+	}
+
+	n := float64(len(docs))
+	vectors := make([]vector, len(docs))
+	for i, c := range counts {
+		v := vector{}
+		var norm float64
+		for t, tf := range c {
+			w := (1 + math.Log(float64(tf))) * math.Log(n/float64(df[t]))
+			if w > 0 {
+				v[t] = w
+				norm += w * w
+			}
+		}
+		norm = math.Sqrt(norm)
+		for t := range v {
+			v[t] /= norm
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
+
+func cosine(a, b vector) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for t, w := range a {
+		dot += w * b[t]
+	}
+	return dot
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := map[string]bool{}
+	for _, t := range a {
+		set[strings.ToLower(t)] = true
+	}
+	union := len(set)
+	common := 0
+	for _, t := range b {
+		t = strings.ToLower(t)
+		if set[t] {
+			common++
+			delete(set, t)
+		} else {
+			union++
+		}
+	}
+	return float64(common) / float64(union)
+}
+
+// top - самые весомые слова вектора
+func top(v vector, k int) []string {
+	terms := make([]string, 0, len(v))
+	for t := range v {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if v[terms[i]] != v[terms[j]] {
+			return v[terms[i]] > v[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > k {
+		terms = terms[:k]
+	}
+	return terms
+}
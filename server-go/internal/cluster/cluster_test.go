@@ -0,0 +1,84 @@
+package cluster
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGroupMergesStories(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	docs := []Document{
+		// Одно событие в трёх сюжетах: ставка ЦБ
+		{PostID: 1, StoryID: 30, Text: "Центробанк сохранил ключевую ставку на уровне 16 процентов годовых", Tags: []string{"экономика", "ЦБ"}, CreatedAt: base},
+		{PostID: 2, StoryID: 12, Text: "Банк России сохранил ключевую ставку на уровне 16 процентов годовых, инфляция замедляется", Tags: []string{"Экономика"}, CreatedAt: base.Add(time.Hour)},
+		{PostID: 3, StoryID: 45, Text: "Ключевая ставка сохранена: центробанк оставил 16 процентов", Tags: []string{"ЦБ"}, CreatedAt: base.Add(3 * time.Hour)},
+		// Тот же сюжет, что у поста 2: сравнивать незачем
+		{PostID: 4, StoryID: 12, Text: "Ставка центробанка осталась на уровне 16 процентов", CreatedAt: base.Add(2 * time.Hour)},
+		// Другое событие
+		{PostID: 5, StoryID: 7, Text: "Зенит обыграл ЦСКА в полуфинале кубка России по футболу", Tags: []string{"спорт"}, CreatedAt: base},
+		{PostID: 6, StoryID: 8, Text: "Футбол: Зенит вышел в финал кубка, обыграв ЦСКА", Tags: []string{"спорт"}, CreatedAt: base.Add(30 * time.Minute)},
+		// Похожий текст, но за пределами окна - отдельный сюжет
+		{PostID: 7, StoryID: 50, Text: "Центробанк сохранил ключевую ставку на уровне 16 процентов годовых", Tags: []string{"экономика"}, CreatedAt: base.Add(72 * time.Hour)},
+	}
+
+	// Фон окна: посты о других событиях, по одному в сюжете
+	background := []string{
+		"Новый телескоп снял туманность Ориона в инфракрасном диапазоне",
+		"В Москве открыли новую станцию метро на Большой кольцевой линии",
+		"Синоптики пообещали дожди и похолодание на выходных",
+		"Компания представила смартфон с гибким экраном",
+		"В Петербурге прошёл фестиваль уличной музыки",
+		"Учёные нашли в Антарктиде неизвестный вид бактерий",
+		"Авиакомпания запустила прямые рейсы в Калининград",
+		"Музей открыл выставку картин Айвазовского",
+	}
+	for i, text := range background {
+		docs = append(docs, Document{PostID: 100 + i, StoryID: 100 + i, Text: text, CreatedAt: base})
+	}
+
+	res := Group(docs, DefaultOptions())
+	// Сюжеты сливаются в сюжет с наименьшим ID
+	want := map[int]int{30: 12, 45: 12, 8: 7}
+	if !reflect.DeepEqual(res.Merges, want) {
+		t.Errorf("merges = %v, want %v", res.Merges, want)
+	}
+	if res.Linked < 3 || res.Compared < res.Linked {
+		t.Errorf("compared %d, linked %d", res.Compared, res.Linked)
+	}
+}
+
+func TestGroupKeepsUnrelated(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	docs := []Document{
+		{PostID: 1, StoryID: 1, Text: "Центробанк сохранил ключевую ставку", CreatedAt: base},
+		{PostID: 2, StoryID: 2, Text: "Зенит обыграл ЦСКА в полуфинале кубка", CreatedAt: base},
+		{PostID: 3, StoryID: 3, Text: "Новый телескоп снял туманность Ориона", CreatedAt: base},
+	}
+	if res := Group(docs, DefaultOptions()); len(res.Merges) != 0 {
+		t.Errorf("merges = %v, want none", res.Merges)
+	}
+}
+
+func TestScore(t *testing.T) {
+	opts := DefaultOptions()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	v := vector{"ставк": 1}
+	a := Document{Tags: []string{"ЦБ"}, CreatedAt: base}
+
+	// Одинаковый текст, теги и время - сумма весов
+	if got := score(a, a, v, v, opts); math.Abs(got-1) > 1e-9 {
+		t.Errorf("identical: score = %v, want 1", got)
+	}
+	// Половина окна: близость по времени 0.5, теги без учёта регистра
+	b := Document{Tags: []string{"цб", "экономика"}, CreatedAt: base.Add(opts.Window / 2)}
+	want := opts.TextWeight + opts.TagWeight*0.5 + opts.TimeWeight*0.5
+	if got := score(a, b, v, v, opts); math.Abs(got-want) > 1e-9 {
+		t.Errorf("half window: score = %v, want %v", got, want)
+	}
+	b.CreatedAt = base.Add(-opts.Window - time.Minute)
+	if got := score(a, b, v, v, opts); got != 0 {
+		t.Errorf("outside window: score = %v, want 0", got)
+	}
+}
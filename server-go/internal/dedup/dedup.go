@@ -0,0 +1,119 @@
+// Package dedup - поиск дубликатов постов между платформами.
+// Точный отпечаток - SHA-256 нормализованного текста, нечёткий - 64-битный SimHash
+// по словам. Близкие SimHash ищутся через LSH: хэш делится на Bands полос,
+// и посты с расстоянием Хэмминга <= MaxDistance совпадают хотя бы в одной полосе.
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Bands - число полос LSH по 16 бит
+	Bands = 4
+	// MaxDistance - посты с расстоянием Хэмминга не больше этого считаются одной новостью
+	MaxDistance = 3
+	// MinWords - у более коротких текстов SimHash ненадёжен, для них ищутся только точные копии
+	MinWords = 8
+)
+
+// Fingerprint - отпечатки поста (таблица post_fingerprints)
+type Fingerprint struct {
+	ContentHash string
+	SimHash     uint64
+	Words       int
+}
+
+var (
+	urlPattern     = regexp.MustCompile(`(?i)\bhttps?://\S+|\bwww\.\S+`)
+	mentionPattern = regexp.MustCompile(`[#@][\p{L}\p{N}_]+`)
+)
+
+// Normalize приводит текст к виду, не зависящему от платформы: без ссылок,
+// хэштегов и упоминаний, пунктуации и регистра, "ё" заменяется на "е"
+func Normalize(text string) string {
+	text = urlPattern.ReplaceAllString(text, " ")
+	text = mentionPattern.ReplaceAllString(text, " ")
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// Compute строит отпечатки заголовка и текста поста
+func Compute(title, content string) Fingerprint {
+	normalized := Normalize(title + " " + content)
+	return Fingerprint{
+		ContentHash: ContentHash(normalized),
+		SimHash:     SimHash(normalized),
+		Words:       len(strings.Fields(normalized)),
+	}
+}
+
+// ContentHash - SHA-256 нормализованного текста в hex
+func ContentHash(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// SimHash - 64-битный SimHash по словам текста. Для коротких постов слова
+// надёжнее шинглов: правка одного слова меняет меньше признаков.
+func SimHash(normalized string) uint64 {
+	var weights [64]int
+	for _, word := range strings.Fields(normalized) {
+		h := fnv.New64a()
+		h.Write([]byte(word))
+		sum := h.Sum64()
+		for b := 0; b < 64; b++ {
+			if sum&(1<<uint(b)) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var result uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			result |= 1 << uint(b)
+		}
+	}
+	return result
+}
+
+// Distance - расстояние Хэмминга между двумя SimHash
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similarity - доля совпадающих бит, 1 - идентичные отпечатки
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(Distance(a, b))/64
+}
+
+// BandValues - полосы LSH: значения 16-битных частей SimHash
+func (f Fingerprint) BandValues() [Bands]int32 {
+	var bands [Bands]int32
+	for i := 0; i < Bands; i++ {
+		bands[i] = int32((f.SimHash >> (16 * uint(i))) & 0xFFFF)
+	}
+	return bands
+}
+
+// Fuzzy - текст достаточно длинный для поиска нечётких дубликатов
+func (f Fingerprint) Fuzzy() bool {
+	return f.Words >= MinWords
+}
+
+// Signed - SimHash для колонки BIGINT
+func (f Fingerprint) Signed() int64 {
+	return int64(f.SimHash)
+}
@@ -0,0 +1,92 @@
+package dedup
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Ёлка в Москве! https://t.me/news/1 #новости @channel", "елка в москве"},
+		{"  ЦБ сохранил ставку: 16%…  www.cbr.ru ", "цб сохранил ставку 16"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+const (
+	rateText = "Совет директоров Банка России на заседании в пятницу принял решение сохранить ключевую ставку " +
+		"на уровне 16% годовых. Регулятор допустил её снижение во втором полугодии, если инфляция продолжит замедляться."
+	matchText = "Матч завершился со счётом 2:1, победный гол забили на последней минуте. " +
+		"Финал турнира пройдёт в июне в Москве на стадионе Лужники."
+)
+
+func TestNearDuplicate(t *testing.T) {
+	// Один текст на двух платформах: другой заголовок, ссылки и хэштеги
+	telegram := Compute("Центробанк сохранил ключевую ставку на уровне 16 процентов", rateText+" https://t.me/news/123 #экономика")
+	site := Compute("ЦБ сохранил ключевую ставку на уровне 16 процентов", rateText+" Подробнее: https://lenta.ru/news/2024/04/26/stavka/")
+	other := Compute("Зенит обыграл ЦСКА в полуфинале Кубка России", matchText)
+
+	if !telegram.Fuzzy() || !site.Fuzzy() {
+		t.Fatalf("words = %d, %d, want at least %d", telegram.Words, site.Words, MinWords)
+	}
+	if telegram.ContentHash == site.ContentHash {
+		t.Error("different texts have the same content hash")
+	}
+	if d := Distance(telegram.SimHash, site.SimHash); d > MaxDistance {
+		t.Errorf("near duplicates: distance = %d, want <= %d", d, MaxDistance)
+	}
+	if d := Distance(telegram.SimHash, other.SimHash); d <= 2*MaxDistance {
+		t.Errorf("different news: distance = %d, want > %d", d, 2*MaxDistance)
+	}
+	if !shareBand(telegram, site) {
+		t.Errorf("near duplicates share no LSH band: %v, %v", telegram.BandValues(), site.BandValues())
+	}
+}
+
+func TestExactCopy(t *testing.T) {
+	// Разметка платформы не меняет точный отпечаток
+	a := Compute("Ставка ЦБ", rateText)
+	b := Compute("СТАВКА ЦБ!", rateText+" #экономика https://vk.com/wall-1_2")
+	if a.ContentHash != b.ContentHash || a.SimHash != b.SimHash {
+		t.Errorf("copies differ: %+v, %+v", a, b)
+	}
+}
+
+func TestBandsFindCloseHashes(t *testing.T) {
+	// При расстоянии не больше MaxDistance хотя бы одна из Bands полос совпадает
+	base := Fingerprint{SimHash: 0x0123456789abcdef}
+	for _, flips := range [][]uint{{0, 16, 32}, {15, 31, 47}, {63, 62, 61}, {5}} {
+		near := base
+		for _, b := range flips {
+			near.SimHash ^= 1 << b
+		}
+		if !shareBand(base, near) {
+			t.Errorf("bits %v flipped: no common band", flips)
+		}
+	}
+	if bands := base.BandValues(); bands != [Bands]int32{0xcdef, 0x89ab, 0x4567, 0x0123} {
+		t.Errorf("bands = %x", bands)
+	}
+}
+
+func shareBand(a, b Fingerprint) bool {
+	ab, bb := a.BandValues(), b.BandValues()
+	for i := range ab {
+		if ab[i] == bb[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSignedRoundTrip(t *testing.T) {
+	f := Fingerprint{SimHash: 1<<63 | 42}
+	if f.Signed() >= 0 || uint64(f.Signed()) != f.SimHash {
+		t.Errorf("Signed() = %d", f.Signed())
+	}
+	if s := Similarity(0, 0xF); s != 1-4.0/64 {
+		t.Errorf("Similarity = %v", s)
+	}
+}
@@ -0,0 +1,220 @@
+// Package filter - цепочка фильтров контента для входящих постов:
+// блок-листы по ключевым словам и регулярным выражениям (общие или для темы),
+// плотность ссылок, рекламные метки и минимальная репутация автора.
+// Правила хранятся в таблице filter_rules и редактируются через admin API.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Action - решение по посту
+type Action string
+
+const (
+	Accept     Action = "accept"
+	Quarantine Action = "quarantine"
+	Reject     Action = "reject"
+)
+
+// severity - чем больше, тем строже решение
+func (a Action) severity() int {
+	switch a {
+	case Quarantine:
+		return 1
+	case Reject:
+		return 2
+	}
+	return 0
+}
+
+// Виды правил
+const (
+	KindKeyword       = "keyword"
+	KindRegex         = "regex"
+	KindLinkDensity   = "link_density"
+	KindAdMarker      = "ad_marker"
+	KindMinReputation = "min_reputation"
+	KindNSFW          = "nsfw"
+)
+
+// Kinds - все поддерживаемые виды правил
+var Kinds = []string{KindKeyword, KindRegex, KindLinkDensity, KindAdMarker, KindMinReputation, KindNSFW}
+
+// Rule - правило из таблицы filter_rules.
+// Topic == nil - правило действует для всех тем.
+// Pattern: keyword - слова через запятую или с новой строки, regex - выражение,
+// ad_marker - своё выражение для рекламных меток вместо встроенного (необязательно).
+// Threshold: link_density - максимум ссылок на 100 слов,
+// min_reputation - минимальная внутренняя репутация автора (0..100, новый автор - 50).
+type Rule struct {
+	RuleID      int       `json:"rule_id"`
+	Kind        string    `json:"kind"`
+	Topic       *string   `json:"topic"`
+	Pattern     string    `json:"pattern"`
+	Threshold   *float64  `json:"threshold"`
+	Action      Action    `json:"action"`
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Post - данные поста, по которым принимается решение
+type Post struct {
+	Title   string
+	Content string
+	Topic   string // тема канала или источника
+	// AdMarked - платформа сама пометила пост как рекламу (VK marked_as_ads)
+	AdMarked bool
+	// NSFW - платформа пометила пост как 18+ (Reddit over_18)
+	NSFW bool
+	// AuthorReputation - внутренняя репутация автора (0..100); nil - неизвестна, правило репутации не применяется
+	AuthorReputation *float64
+}
+
+func (p *Post) text() string {
+	return p.Title + "\n" + p.Content
+}
+
+// Reason - сработавшее правило
+type Reason struct {
+	RuleID  int    `json:"rule_id"`
+	Kind    string `json:"kind"`
+	Action  Action `json:"action"`
+	Message string `json:"message"`
+}
+
+// Decision - итог цепочки: самое строгое действие среди сработавших правил
+type Decision struct {
+	Action  Action   `json:"decision"`
+	Reasons []Reason `json:"reasons"`
+}
+
+// Summary - причины одной строкой для логов и сообщений об ошибке
+func (d Decision) Summary() string {
+	parts := make([]string, 0, len(d.Reasons))
+	for _, r := range d.Reasons {
+		parts = append(parts, r.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Filter - проверка одного правила; возвращает описание срабатывания
+type Filter interface {
+	Check(p *Post) (string, bool)
+}
+
+type step struct {
+	rule   Rule
+	filter Filter
+}
+
+// Chain - скомпилированная цепочка включённых правил
+type Chain struct {
+	steps []step
+}
+
+// NewChain компилирует включённые правила. Некорректные правила пропускаются,
+// их ошибки возвращаются, чтобы вызывающий мог их залогировать.
+func NewChain(rules []Rule) (*Chain, []error) {
+	c := &Chain{}
+	var errs []error
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		f, err := Compile(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %d: %w", rule.RuleID, err))
+			continue
+		}
+		c.steps = append(c.steps, step{rule: rule, filter: f})
+	}
+	return c, errs
+}
+
+// Run прогоняет пост через все правила его темы
+func (c *Chain) Run(p Post) Decision {
+	d := Decision{Action: Accept, Reasons: []Reason{}}
+	for _, s := range c.steps {
+		if s.rule.Topic != nil && !strings.EqualFold(*s.rule.Topic, p.Topic) {
+			continue
+		}
+		msg, matched := s.filter.Check(&p)
+		if !matched {
+			continue
+		}
+		d.Reasons = append(d.Reasons, Reason{
+			RuleID:  s.rule.RuleID,
+			Kind:    s.rule.Kind,
+			Action:  s.rule.Action,
+			Message: msg,
+		})
+		if s.rule.Action.severity() > d.Action.severity() {
+			d.Action = s.rule.Action
+		}
+	}
+	return d
+}
+
+// RuleError - ошибка в поле правила
+type RuleError struct {
+	Field   string
+	Message string
+}
+
+func (e *RuleError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Validate проверяет правило перед сохранением
+func Validate(rule Rule) error {
+	if rule.Action != Quarantine && rule.Action != Reject {
+		return &RuleError{"action", "must be one of: quarantine, reject"}
+	}
+	_, err := Compile(rule)
+	return err
+}
+
+// Compile строит фильтр по правилу
+func Compile(rule Rule) (Filter, error) {
+	switch rule.Kind {
+	case KindKeyword:
+		return newKeywordFilter(rule.Pattern)
+	case KindRegex:
+		if rule.Pattern == "" {
+			return nil, &RuleError{"pattern", "is required"}
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, &RuleError{"pattern", "is not a valid regex: " + err.Error()}
+		}
+		return regexFilter{re: re}, nil
+	case KindLinkDensity:
+		if rule.Threshold == nil || *rule.Threshold < 0 {
+			return nil, &RuleError{"threshold", "is required: maximum links per 100 words"}
+		}
+		return linkDensityFilter{max: *rule.Threshold}, nil
+	case KindAdMarker:
+		re := defaultAdMarkers
+		if rule.Pattern != "" {
+			var err error
+			if re, err = regexp.Compile(rule.Pattern); err != nil {
+				return nil, &RuleError{"pattern", "is not a valid regex: " + err.Error()}
+			}
+		}
+		return adMarkerFilter{re: re}, nil
+	case KindMinReputation:
+		if rule.Threshold == nil {
+			return nil, &RuleError{"threshold", "is required: minimal author reputation"}
+		}
+		return minReputationFilter{min: *rule.Threshold}, nil
+	case KindNSFW:
+		return nsfwFilter{}, nil
+	}
+	return nil, &RuleError{"kind", "must be one of: " + strings.Join(Kinds, ", ")}
+}
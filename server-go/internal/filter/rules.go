@@ -0,0 +1,129 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// linkPattern - ссылки в тексте поста
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+|\bwww\.[^\s<>"]+`)
+
+// defaultAdMarkers - встроенные признаки рекламы: партнёрские и промо-параметры ссылок,
+// токен маркировки рекламы erid и явные пометки в тексте. utm_* и ref стоят и на
+// обычных ссылках новостей и RSS, поэтому в набор не входят: их ловят правила
+// ad_marker со своим pattern.
+var defaultAdMarkers = regexp.MustCompile(`(?i)[?&](referral|aff|aff_id|affiliate|partner|promo|promocode|clickid|subid)=` +
+	`|\berid[:=\s]|#реклама|на правах рекламы|рекламная интеграция`)
+
+// keywordFilter - блок-лист слов и фраз без учёта регистра, совпадение по границам слов
+type keywordFilter struct {
+	re *regexp.Regexp
+}
+
+func newKeywordFilter(pattern string) (Filter, error) {
+	var words []string
+	for _, w := range strings.FieldsFunc(pattern, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, regexp.QuoteMeta(w))
+		}
+	}
+	if len(words) == 0 {
+		return nil, &RuleError{"pattern", "must list at least one keyword"}
+	}
+	// \b в RE2 не работает с кириллицей, поэтому границы слов задаются явно
+	re, err := regexp.Compile(`(?i)(?:^|[^\p{L}\p{N}])(` + strings.Join(words, "|") + `)(?:[^\p{L}\p{N}]|$)`)
+	if err != nil {
+		return nil, &RuleError{"pattern", err.Error()}
+	}
+	return keywordFilter{re: re}, nil
+}
+
+func (f keywordFilter) Check(p *Post) (string, bool) {
+	m := f.re.FindStringSubmatch(p.text())
+	if m == nil {
+		return "", false
+	}
+	return fmt.Sprintf("blocked keyword %q", m[1]), true
+}
+
+// regexFilter - произвольное регулярное выражение по заголовку и тексту
+type regexFilter struct {
+	re *regexp.Regexp
+}
+
+func (f regexFilter) Check(p *Post) (string, bool) {
+	text := p.text()
+	loc := f.re.FindStringIndex(text)
+	if loc == nil {
+		return "", false
+	}
+	return fmt.Sprintf("matched pattern %s (%q)", f.re.String(), truncate(text[loc[0]:loc[1]], 50)), true
+}
+
+// linkDensityFilter - слишком много ссылок на 100 слов текста
+type linkDensityFilter struct {
+	max float64
+}
+
+func (f linkDensityFilter) Check(p *Post) (string, bool) {
+	text := p.text()
+	links := len(linkPattern.FindAllStringIndex(text, -1))
+	if links == 0 {
+		return "", false
+	}
+	words := len(strings.Fields(text))
+	if words == 0 {
+		words = 1
+	}
+	density := float64(links) * 100 / float64(words)
+	if density <= f.max {
+		return "", false
+	}
+	return fmt.Sprintf("link density %.1f per 100 words exceeds %.1f (%d links)", density, f.max, links), true
+}
+
+// adMarkerFilter - пост помечен платформой как реклама или содержит рекламные метки
+type adMarkerFilter struct {
+	re *regexp.Regexp
+}
+
+func (f adMarkerFilter) Check(p *Post) (string, bool) {
+	if p.AdMarked {
+		return "marked as advertisement by platform", true
+	}
+	if m := f.re.FindString(p.text()); m != "" {
+		return fmt.Sprintf("advertisement marker %q", strings.TrimSpace(m)), true
+	}
+	return "", false
+}
+
+// minReputationFilter - репутация автора ниже порога
+type minReputationFilter struct {
+	min float64
+}
+
+func (f minReputationFilter) Check(p *Post) (string, bool) {
+	if p.AuthorReputation == nil || *p.AuthorReputation >= f.min {
+		return "", false
+	}
+	return fmt.Sprintf("author reputation %.2f is below %.2f", *p.AuthorReputation, f.min), true
+}
+
+// nsfwFilter - платформа пометила пост как 18+
+type nsfwFilter struct{}
+
+func (nsfwFilter) Check(p *Post) (string, bool) {
+	if !p.NSFW {
+		return "", false
+	}
+	return "marked as NSFW by platform", true
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"news-aggregator/internal/pgpool"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// authorReputation - внутренняя репутация автора (SQL-функция author_reputation_stats, db/tmp.sql)
+type authorReputation struct {
+	Submissions     int     `json:"submissions"`
+	ApprovedPosts   int     `json:"approved_posts"`
+	RejectedPosts   int     `json:"rejected_posts"`
+	FilteredPosts   int     `json:"filtered_posts"`
+	EngagementRatio float64 `json:"engagement_ratio"`
+	FilterRate      float64 `json:"filter_rate"`
+	Score           float64 `json:"score"`
+}
+
+// authorRecord - автор и его профиль на платформе
+type authorRecord struct {
+	AuthorID           int        `json:"author_id"`
+	Name               string     `json:"name"`
+	Platform           *string    `json:"platform"`
+	ExternalID         *string    `json:"external_id"`
+	ProfileURL         *string    `json:"profile_url"`
+	PlatformReputation *int64     `json:"platform_reputation"`
+	UpdatedAt          *time.Time `json:"updated_at"`
+}
+
+// authorProfile - профиль автора: данные платформы и внутренняя репутация
+type authorProfile struct {
+	authorRecord
+	Reputation authorReputation `json:"reputation"`
+}
+
+const authorProfileQuery = `
+    SELECT author_id, name, platform, external_id, profile_url, platform_reputation, updated_at
+    FROM authors
+    WHERE author_id = $1`
+
+const authorRecordColumns = "author_id, name, platform, external_id, profile_url, platform_reputation, updated_at"
+
+// Автор с профилем платформы определяется парой (platform, external_id): одно имя
+// на разных платформах - разные авторы. Автор без external_id определяется по имени.
+// У существующего автора обновляется профиль, имя не меняется.
+const (
+	authorUpsertByExternalIDQuery = `
+    INSERT INTO authors (name, platform, external_id, profile_url, platform_reputation)
+    VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT (platform, external_id) WHERE external_id IS NOT NULL DO UPDATE SET
+        profile_url = COALESCE(EXCLUDED.profile_url, authors.profile_url),
+        platform_reputation = COALESCE(EXCLUDED.platform_reputation, authors.platform_reputation),
+        updated_at = NOW()
+    RETURNING ` + authorRecordColumns
+
+	authorUpsertByNameQuery = `
+    INSERT INTO authors (name, platform, external_id, profile_url, platform_reputation)
+    VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT (name) WHERE external_id IS NULL DO UPDATE SET
+        platform = COALESCE(EXCLUDED.platform, authors.platform),
+        profile_url = COALESCE(EXCLUDED.profile_url, authors.profile_url),
+        platform_reputation = COALESCE(EXCLUDED.platform_reputation, authors.platform_reputation),
+        updated_at = NOW()
+    RETURNING ` + authorRecordColumns
+)
+
+// authorReputationQuery - репутация одного автора по SQL-функции author_reputation_stats
+// (db/tmp.sql), её же вызывает представление author_reputation
+const authorReputationQuery = `
+    SELECT submissions, approved_posts, rejected_posts, filtered_posts,
+           engagement_ratio::float8, filter_rate::float8, reputation_score::float8
+    FROM author_reputation_stats($1)`
+
+// loadAuthorReputation - внутренняя репутация автора; pgx.ErrNoRows - автора нет
+func loadAuthorReputation(ctx context.Context, conn *pgpool.PConn, authorID int) (authorReputation, error) {
+	var rep authorReputation
+	err := conn.QueryRow(ctx, authorReputationQuery, authorID).Scan(&rep.Submissions, &rep.ApprovedPosts,
+		&rep.RejectedPosts, &rep.FilteredPosts, &rep.EngagementRatio, &rep.FilterRate, &rep.Score)
+	return rep, err
+}
+
+// authorProfileHandler - GET /api/authors/{id}/profile
+func (h *Handlers) authorProfileHandler(w http.ResponseWriter, r *http.Request) {
+	authorID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || authorID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid author ID")
+		return
+	}
+
+	ctx := r.Context()
+	cacheKey := fmt.Sprintf("cache:author_profile:%d", authorID)
+	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(cached))
+		return
+	}
+
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var p authorProfile
+	err = conn.QueryRow(ctx, authorProfileQuery, authorID).Scan(&p.AuthorID, &p.Name, &p.Platform,
+		&p.ExternalID, &p.ProfileURL, &p.PlatformReputation, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Author not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to read author profile", err)
+		return
+	}
+	if p.Reputation, err = loadAuthorReputation(ctx, conn, authorID); err != nil {
+		writeDBError(w, r, "Failed to compute author reputation", err)
+		return
+	}
+
+	data, _ := json.Marshal(p)
+	h.cache.SetEX(ctx, cacheKey, string(data), 60)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// ensureAuthorHandler - POST /api/v1/authors/ensure: создаёт автора или обновляет
+// профиль существующего и возвращает его
+func (h *Handlers) ensureAuthorHandler(w http.ResponseWriter, r *http.Request) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	h.ensureAuthor(w, r, data)
+}
+
+// ensureAuthor сохраняет автора по ключу (platform, external_id) или по имени
+func (h *Handlers) ensureAuthor(w http.ResponseWriter, r *http.Request, data map[string]interface{}) {
+	if fieldErrors := validateBody(tableSchemas["authors"], data, false); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+	platform, _ := data["platform"].(string)
+	externalID, _ := data["external_id"].(string)
+	if externalID != "" && platform == "" {
+		writeValidationError(w, r, []FieldError{{Field: "platform", Message: "is required with external_id"}})
+		return
+	}
+
+	query := authorUpsertByNameQuery
+	if externalID != "" {
+		query = authorUpsertByExternalIDQuery
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var a authorRecord
+	err = conn.QueryRow(ctx, query, data["name"], nullIfEmpty(platform), nullIfEmpty(externalID),
+		data["profile_url"], data["platform_reputation"]).Scan(&a.AuthorID, &a.Name, &a.Platform,
+		&a.ExternalID, &a.ProfileURL, &a.PlatformReputation, &a.UpdatedAt)
+	if err != nil {
+		writeDBError(w, r, "Failed to save author", err)
+		return
+	}
+
+	h.cache.Del(ctx, "cache:authors", fmt.Sprintf("cache:author_profile:%d", a.AuthorID))
+	writeJSON(w, http.StatusOK, a)
+}
+
+// nullIfEmpty - пустая строка сохраняется как NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,651 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"news-aggregator/internal/classifier"
+	"news-aggregator/internal/pgpool"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// Модель переобучается не реже раза в classifierTTL, а также сразу после
+// правок словарей и разметки редакторов
+const classifierTTL = 10 * time.Minute
+
+// maxTrainingDocs - обучающая выборка ограничена последними размеченными постами
+const maxTrainingDocs = 5000
+
+// classifierState - обученный классификатор, общий для всех запросов
+type classifierState struct {
+	mu       sync.Mutex
+	model    *classifier.Classifier
+	loadedAt time.Time
+}
+
+func newClassifierState() *classifierState {
+	return &classifierState{}
+}
+
+// invalidate - следующий запрос переобучит модель
+func (s *classifierState) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.model = nil
+}
+
+// Тело POST/PUT /api/admin/categories
+var categorySchema = bodySchema{
+	"name":        reqString(50),
+	"description": optString(255),
+	"keywords":    stringList(100),
+}
+
+// Тело PUT /api/posts/{id}/categories - разметка редактора
+var postCategoriesSchema = bodySchema{
+	"editor":     reqString(255),
+	"categories": fieldRule{Kind: kindStringList, MaxLen: 50, Required: true},
+}
+
+// Тело POST /api/admin/classifier/classify
+var classifyTextSchema = bodySchema{
+	"title":   optString(255),
+	"content": fieldRule{Kind: kindString},
+}
+
+// category - категория со словарём классификатора
+type category struct {
+	CategoryID  int       `json:"category_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Keywords    []string  `json:"keywords"`
+	PostsCount  int       `json:"posts_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// postCategory - категория поста
+type postCategory struct {
+	CategoryID int       `json:"category_id"`
+	Name       string    `json:"name"`
+	Confidence float64   `json:"confidence"`
+	Method     string    `json:"method"`
+	AssignedBy *string   `json:"assigned_by"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+const categoryColumns = `c.category_id, c.name, c.description, c.keywords,
+    (SELECT COUNT(*) FROM post_categories pc WHERE pc.category_id = c.category_id), c.created_at, c.updated_at`
+
+// setupCategoryRoutes регистрирует словари категорий и управление классификатором
+// на роутере с префиксом /admin
+func (h *Handlers) setupCategoryRoutes(r *mux.Router) {
+	r.HandleFunc("/categories", h.listCategoriesHandler).Methods("GET")
+	r.HandleFunc("/categories", h.createCategoryHandler).Methods("POST")
+	r.HandleFunc("/categories/{id:[0-9]+}", h.updateCategoryHandler).Methods("PUT")
+	r.HandleFunc("/categories/{id:[0-9]+}", h.deleteCategoryHandler).Methods("DELETE")
+	r.HandleFunc("/classifier/classify", h.classifyTextHandler).Methods("POST")
+	r.HandleFunc("/classifier/stats", h.classifierStatsHandler).Methods("GET")
+	r.HandleFunc("/classifier/retrain", h.retrainClassifierHandler).Methods("POST")
+	r.HandleFunc("/classifier/reclassify", h.reclassifyPostsHandler).Methods("POST")
+}
+
+func scanCategory(row pgx.Row) (category, error) {
+	var c category
+	err := row.Scan(&c.CategoryID, &c.Name, &c.Description, &c.Keywords, &c.PostsCount, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+// loadClassifier возвращает классификатор, обучая его заново, если модель устарела
+func (h *Handlers) loadClassifier(ctx context.Context, conn *pgpool.PConn) (*classifier.Classifier, error) {
+	s := h.classifier
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.model != nil && time.Since(s.loadedAt) < classifierTTL {
+		return s.model, nil
+	}
+
+	var dicts []classifier.Dictionary
+	rows, err := conn.Query(ctx, "SELECT name, keywords FROM categories ORDER BY category_id")
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var d classifier.Dictionary
+		if err := rows.Scan(&d.Category, &d.Keywords); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		dicts = append(dicts, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Обучающая выборка - посты, категории которых проставил редактор
+	var examples []classifier.Example
+	rows, err = conn.Query(ctx, `
+        SELECT p.title || E'\n' || COALESCE(nt.text, ''), ARRAY_AGG(c.name ORDER BY c.name)
+        FROM post_categories pc
+        JOIN categories c ON pc.category_id = c.category_id
+        JOIN posts p ON pc.post_id = p.post_id
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE pc.method = 'editor'
+        GROUP BY p.post_id, nt.text
+        ORDER BY MAX(pc.assigned_at) DESC
+        LIMIT $1`, maxTrainingDocs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ex classifier.Example
+		if err := rows.Scan(&ex.Text, &ex.Categories); err != nil {
+			return nil, err
+		}
+		examples = append(examples, ex)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	s.model = classifier.New(dicts, examples)
+	s.loadedAt = time.Now()
+	return s.model, nil
+}
+
+// categorizePost сохраняет категории, назначенные классификатором.
+// Разметку редактора не трогает: у таких постов автоматические категории не пересчитываются.
+func categorizePost(ctx context.Context, tx pgx.Tx, postID int, assignments []classifier.Assignment) error {
+	var reviewed bool
+	err := tx.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM post_categories WHERE post_id = $1 AND method = 'editor')", postID).Scan(&reviewed)
+	if err != nil || reviewed {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM post_categories WHERE post_id = $1", postID); err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO post_categories (post_id, category_id, confidence, method)
+            SELECT $1, category_id, $3, $4 FROM categories WHERE name = $2`,
+			postID, a.Category, a.Confidence, a.Method)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listCategoriesHandler - GET /api/categories
+func (h *Handlers) listCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, "SELECT "+categoryColumns+" FROM categories c ORDER BY c.category_id")
+	if err != nil {
+		writeDBError(w, r, "Failed to read categories", err)
+		return
+	}
+	defer rows.Close()
+
+	categories := []category{}
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			writeDBError(w, r, "Failed to read categories", err)
+			return
+		}
+		categories = append(categories, c)
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// decodeCategory читает и проверяет тело запроса категории
+func decodeCategory(w http.ResponseWriter, r *http.Request, partial bool) (map[string]interface{}, bool) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return nil, false
+	}
+	if fieldErrors := validateBody(categorySchema, data, partial); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return nil, false
+	}
+	return data, true
+}
+
+// keywordList - ключевые слова из тела запроса без пустых и повторов
+func keywordList(value interface{}) []string {
+	keywords := []string{}
+	seen := map[string]bool{}
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		k := strings.ToLower(strings.TrimSpace(item.(string)))
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keywords = append(keywords, k)
+		}
+	}
+	return keywords
+}
+
+// createCategoryHandler - POST /api/admin/categories
+func (h *Handlers) createCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := decodeCategory(w, r, false)
+	if !ok {
+		return
+	}
+	description, _ := data["description"].(string)
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	created, err := scanCategory(conn.QueryRow(ctx, `
+        INSERT INTO categories (name, description, keywords) VALUES ($1, $2, $3)
+        RETURNING category_id, name, description, keywords, 0, created_at, updated_at`,
+		data["name"], description, keywordList(data["keywords"])))
+	if err != nil {
+		writeDBError(w, r, "Failed to create category", err)
+		return
+	}
+
+	h.classifier.invalidate()
+	log.Printf("[%s] Category %q created with %d keywords", requestID(r), created.Name, len(created.Keywords))
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// updateCategoryHandler - PUT /api/admin/categories/{id}: частичное обновление словаря
+func (h *Handlers) updateCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := decodeCategory(w, r, true)
+	if !ok {
+		return
+	}
+
+	var name, description *string
+	if v, ok := data["name"].(string); ok {
+		name = &v
+	}
+	if v, ok := data["description"].(string); ok {
+		description = &v
+	}
+	var keywords []string
+	if _, present := data["keywords"]; present {
+		keywords = keywordList(data["keywords"])
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	id := mux.Vars(r)["id"]
+	var updatedID int
+	err = conn.QueryRow(ctx, `
+        UPDATE categories
+        SET name = COALESCE($1, name), description = COALESCE($2, description),
+            keywords = COALESCE($3, keywords), updated_at = NOW()
+        WHERE category_id = $4
+        RETURNING category_id`, name, description, keywords, id).Scan(&updatedID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Category not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to update category", err)
+		return
+	}
+
+	updated, err := scanCategory(conn.QueryRow(ctx,
+		"SELECT "+categoryColumns+" FROM categories c WHERE c.category_id = $1", updatedID))
+	if err != nil {
+		writeDBError(w, r, "Failed to read category", err)
+		return
+	}
+
+	h.classifier.invalidate()
+	log.Printf("[%s] Category %d updated", requestID(r), updated.CategoryID)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// deleteCategoryHandler - DELETE /api/admin/categories/{id}; назначения категории удаляются каскадно
+func (h *Handlers) deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var deleted string
+	err = conn.QueryRow(ctx, "DELETE FROM categories WHERE category_id = $1 RETURNING name", mux.Vars(r)["id"]).Scan(&deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Category not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to delete category", err)
+		return
+	}
+
+	h.classifier.invalidate()
+	log.Printf("[%s] Category %q deleted", requestID(r), deleted)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Category deleted"})
+}
+
+// readPostCategories - категории поста; nil, если поста нет
+func readPostCategories(ctx context.Context, conn *pgpool.PConn, postID int) ([]postCategory, error) {
+	var exists bool
+	if err := conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM posts WHERE post_id = $1)", postID).Scan(&exists); err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := conn.Query(ctx, `
+        SELECT c.category_id, c.name, pc.confidence::float8, pc.method, pc.assigned_by, pc.assigned_at
+        FROM post_categories pc
+        JOIN categories c ON pc.category_id = c.category_id
+        WHERE pc.post_id = $1
+        ORDER BY pc.confidence DESC, c.name`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []postCategory{}
+	for rows.Next() {
+		var c postCategory
+		if err := rows.Scan(&c.CategoryID, &c.Name, &c.Confidence, &c.Method, &c.AssignedBy, &c.AssignedAt); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// postCategoriesHandler - GET /api/posts/{id}/categories
+func (h *Handlers) postCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || postID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	categories, err := readPostCategories(ctx, conn, postID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read post categories", err)
+		return
+	}
+	if categories == nil {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// setPostCategoriesHandler - PUT /api/posts/{id}/categories: редактор заменяет категории поста.
+// Разметка редактора попадает в обучающую выборку классификатора.
+func (h *Handlers) setPostCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || postID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+		return
+	}
+
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	if fieldErrors := validateBody(postCategoriesSchema, data, false); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+	editor := data["editor"].(string)
+	var names []string
+	for _, item := range data["categories"].([]interface{}) {
+		names = append(names, item.(string))
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		writeDBError(w, r, "Failed to begin transaction", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM posts WHERE post_id = $1)", postID).Scan(&exists); err != nil {
+		writeDBError(w, r, "Failed to read post", err)
+		return
+	}
+	if !exists {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+
+	var unknown []string
+	err = tx.QueryRow(ctx, `
+        SELECT COALESCE(ARRAY_AGG(n), '{}') FROM UNNEST($1::text[]) n
+        WHERE NOT EXISTS (SELECT 1 FROM categories c WHERE c.name = n)`, names).Scan(&unknown)
+	if err != nil {
+		writeDBError(w, r, "Failed to read categories", err)
+		return
+	}
+	if len(unknown) > 0 {
+		writeValidationError(w, r, []FieldError{{Field: "categories", Message: "unknown categories: " + strings.Join(unknown, ", ")}})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM post_categories WHERE post_id = $1", postID); err != nil {
+		writeDBError(w, r, "Failed to update post categories", err)
+		return
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO post_categories (post_id, category_id, confidence, method, assigned_by)
+        SELECT $1, category_id, 1, 'editor', $3 FROM categories WHERE name = ANY($2)`,
+		postID, names, editor)
+	if err != nil {
+		writeDBError(w, r, "Failed to update post categories", err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		writeDBError(w, r, "Failed to commit transaction", err)
+		return
+	}
+
+	h.classifier.invalidate()
+	log.Printf("[%s] Post %d categorized by %q: %s", requestID(r), postID, editor, strings.Join(names, ", "))
+
+	categories, err := readPostCategories(ctx, conn, postID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read post categories", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, categories)
+}
+
+// classifyTextHandler - POST /api/admin/classifier/classify: пробная классификация без сохранения
+func (h *Handlers) classifyTextHandler(w http.ResponseWriter, r *http.Request) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	if fieldErrors := validateBody(classifyTextSchema, data, false); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+	title, _ := data["title"].(string)
+	content, _ := data["content"].(string)
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	model, err := h.loadClassifier(ctx, conn)
+	if err != nil {
+		writeDBError(w, r, "Failed to load classifier", err)
+		return
+	}
+	assignments := model.Classify(title + "\n" + content)
+	if assignments == nil {
+		assignments = []classifier.Assignment{}
+	}
+	writeJSON(w, http.StatusOK, assignments)
+}
+
+// classifierStatsHandler - GET /api/admin/classifier/stats
+func (h *Handlers) classifierStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	model, err := h.loadClassifier(ctx, conn)
+	if err != nil {
+		writeDBError(w, r, "Failed to load classifier", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, model.Stats())
+}
+
+// retrainClassifierHandler - POST /api/admin/classifier/retrain: переобучение на текущей разметке
+func (h *Handlers) retrainClassifierHandler(w http.ResponseWriter, r *http.Request) {
+	h.classifier.invalidate()
+	h.classifierStatsHandler(w, r)
+}
+
+// reclassifyPostsHandler - POST /api/admin/classifier/reclassify?limit=500&all=false.
+// Без all классифицируются посты без категорий, с all=true - все посты, кроме размеченных редактором.
+func (h *Handlers) reclassifyPostsHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	limit := queryInt(r, "limit", 500, 1, 5000, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+	all := r.URL.Query().Get("all") == "true"
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	model, err := h.loadClassifier(ctx, conn)
+	if err != nil {
+		writeDBError(w, r, "Failed to load classifier", err)
+		return
+	}
+
+	condition := "NOT EXISTS (SELECT 1 FROM post_categories pc WHERE pc.post_id = p.post_id)"
+	if all {
+		condition = "NOT EXISTS (SELECT 1 FROM post_categories pc WHERE pc.post_id = p.post_id AND pc.method = 'editor')"
+	}
+	rows, err := conn.Query(ctx, `
+        SELECT p.post_id, p.title || E'\n' || COALESCE(nt.text, '')
+        FROM posts p
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE `+condition+`
+        ORDER BY p.post_id DESC
+        LIMIT $1`, limit)
+	if err != nil {
+		writeDBError(w, r, "Failed to read posts", err)
+		return
+	}
+	type pending struct {
+		id   int
+		text string
+	}
+	var posts []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.text); err != nil {
+			rows.Close()
+			writeDBError(w, r, "Failed to read posts", err)
+			return
+		}
+		posts = append(posts, p)
+	}
+	rows.Close()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		writeDBError(w, r, "Failed to begin transaction", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	categorized := 0
+	for _, p := range posts {
+		assignments := model.Classify(p.text)
+		if err := categorizePost(ctx, tx, p.id, assignments); err != nil {
+			writeDBError(w, r, "Failed to save post categories", err)
+			return
+		}
+		if len(assignments) > 0 {
+			categorized++
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		writeDBError(w, r, "Failed to commit transaction", err)
+		return
+	}
+
+	log.Printf("[%s] Reclassified %d posts, %d categorized", requestID(r), len(posts), categorized)
+	writeJSON(w, http.StatusOK, map[string]int{
+		"processed":   len(posts),
+		"categorized": categorized,
+	})
+}
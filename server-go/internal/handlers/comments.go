@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Параметры дерева комментариев по умолчанию и их пределы
+const (
+	defaultCommentDepth     = 3
+	maxCommentDepth         = 10
+	defaultCommentLimit     = 20
+	maxCommentLimit         = 100
+	defaultChildrenLimit    = 5
+	maxCommentChildrenLimit = 50
+)
+
+// Порядок комментариев одного уровня
+var commentSortOrders = map[string]string{
+	"new":   "created_at DESC NULLS LAST, comment_id DESC",
+	"likes": "likes_count DESC NULLS LAST, created_at DESC NULLS LAST, comment_id DESC",
+}
+
+// commentNode - комментарий с вложенными ответами
+type commentNode struct {
+	CommentID          int            `json:"comment_id"`
+	ParentCommentID    *int           `json:"parent_comment_id"`
+	Nickname           string         `json:"nickname"`
+	Text               string         `json:"text"`
+	CreatedAt          *time.Time     `json:"created_at"`
+	LikesCount         int            `json:"likes_count"`
+	Depth              int            `json:"depth"`
+	RepliesCount       int            `json:"replies_count"`
+	Children           []*commentNode `json:"children"`
+	NextChildrenCursor string         `json:"next_children_cursor,omitempty"`
+}
+
+// commentCursor - позиция "загрузить ещё" внутри одного уровня дерева.
+// Parent == nil - корневые комментарии поста.
+type commentCursor struct {
+	Parent *int   `json:"p,omitempty"`
+	Offset int    `json:"o"`
+	Sort   string `json:"s"`
+	Depth  int    `json:"d"` // глубина комментариев уровня
+}
+
+func (c commentCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCommentCursor(s string) (commentCursor, bool) {
+	var c commentCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return c, false
+	}
+	if _, ok := commentSortOrders[c.Sort]; !ok || c.Offset < 0 || c.Depth < 0 {
+		return c, false
+	}
+	return c, true
+}
+
+// queryInt читает целый query-параметр в пределах [min, max]
+func queryInt(r *http.Request, name string, def, min, max int, errs *[]FieldError) int {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < min || n > max {
+		*errs = append(*errs, FieldError{Field: name, Message: "must be an integer between " + strconv.Itoa(min) + " and " + strconv.Itoa(max)})
+		return def
+	}
+	return n
+}
+
+// postCommentsHandler - GET /api/posts/{id}/comments: дерево комментариев поста.
+// Параметры: sort=new|likes, depth - число уровней, limit - комментариев первого уровня,
+// children_limit - ответов на каждый комментарий, cursor - продолжение уровня.
+func (h *Handlers) postCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || postID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+		return
+	}
+
+	var fieldErrors []FieldError
+	levels := queryInt(r, "depth", defaultCommentDepth, 1, maxCommentDepth, &fieldErrors)
+	limit := queryInt(r, "limit", defaultCommentLimit, 1, maxCommentLimit, &fieldErrors)
+	childrenLimit := queryInt(r, "children_limit", defaultChildrenLimit, 0, maxCommentChildrenLimit, &fieldErrors)
+
+	cursor := commentCursor{Sort: "new"}
+	if s := r.URL.Query().Get("sort"); s != "" {
+		cursor.Sort = s
+		if _, ok := commentSortOrders[s]; !ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: "sort", Message: "must be one of: new, likes"})
+		}
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		c, ok := decodeCommentCursor(raw)
+		if !ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: "cursor", Message: "is invalid"})
+		}
+		cursor = c
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var postExists bool
+	var totalComments, totalInLevel int
+	err = conn.QueryRow(ctx, `
+        SELECT
+            EXISTS(SELECT 1 FROM posts WHERE post_id = $1),
+            (SELECT COUNT(*) FROM comments WHERE post_id = $1),
+            (SELECT COUNT(*) FROM comments WHERE post_id = $1 AND parent_comment_id IS NOT DISTINCT FROM $2::int)`,
+		postID, cursor.Parent).Scan(&postExists, &totalComments, &totalInLevel)
+	if err != nil {
+		writeDBError(w, r, "Failed to read comments", err)
+		return
+	}
+	if !postExists {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+
+	// Поддерево строится рекурсивно от уровня курсора; внутри каждого уровня
+	// комментарии нумеруются в выбранном порядке, лишние отсекаются по rn
+	query := `
+        WITH RECURSIVE tree AS (
+            SELECT comment_id, parent_comment_id, nickname, text, created_at, likes_count, 0 AS level
+            FROM comments
+            WHERE post_id = $1 AND parent_comment_id IS NOT DISTINCT FROM $2::int
+            UNION ALL
+            SELECT c.comment_id, c.parent_comment_id, c.nickname, c.text, c.created_at, c.likes_count, t.level + 1
+            FROM comments c
+            JOIN tree t ON c.parent_comment_id = t.comment_id
+            WHERE t.level + 1 < $3
+        ),
+        ranked AS (
+            SELECT tree.*,
+                   ROW_NUMBER() OVER (PARTITION BY parent_comment_id ORDER BY ` + commentSortOrders[cursor.Sort] + `) AS rn
+            FROM tree
+        )
+        SELECT r.comment_id, r.parent_comment_id, r.nickname, r.text, r.created_at,
+               COALESCE(r.likes_count, 0), r.level,
+               (SELECT COUNT(*) FROM comments ch WHERE ch.parent_comment_id = r.comment_id) AS replies_count
+        FROM ranked r
+        WHERE (r.level = 0 AND r.rn > $4 AND r.rn <= $4 + $5)
+           OR (r.level > 0 AND r.rn <= $6)
+        ORDER BY r.level, r.rn`
+
+	rows, err := conn.Query(ctx, query, postID, cursor.Parent, levels, cursor.Offset, limit, childrenLimit)
+	if err != nil {
+		writeDBError(w, r, "Failed to read comments", err)
+		return
+	}
+	defer rows.Close()
+
+	var roots []*commentNode
+	nodes := map[int]*commentNode{}
+	for rows.Next() {
+		var (
+			n     commentNode
+			level int
+		)
+		if err := rows.Scan(&n.CommentID, &n.ParentCommentID, &n.Nickname, &n.Text, &n.CreatedAt,
+			&n.LikesCount, &level, &n.RepliesCount); err != nil {
+			writeDBError(w, r, "Failed to read comments", err)
+			return
+		}
+		n.Depth = cursor.Depth + level
+		n.Children = []*commentNode{}
+		node := &n
+
+		// Строки упорядочены по уровню, поэтому родитель уже разобран;
+		// ответы на комментарии за пределами страницы отбрасываются
+		if level == 0 {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[*n.ParentCommentID]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			continue
+		}
+		nodes[n.CommentID] = node
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, "Failed to read comments", err)
+		return
+	}
+
+	// Курсоры "загрузить ещё ответы" для узлов, у которых показаны не все ответы
+	for _, node := range nodes {
+		if node.RepliesCount > len(node.Children) {
+			parentID := node.CommentID
+			node.NextChildrenCursor = commentCursor{
+				Parent: &parentID,
+				Offset: len(node.Children),
+				Sort:   cursor.Sort,
+				Depth:  node.Depth + 1,
+			}.encode()
+		}
+	}
+
+	if roots == nil {
+		roots = []*commentNode{}
+	}
+	response := map[string]interface{}{
+		"post_id":        postID,
+		"sort":           cursor.Sort,
+		"depth":          levels,
+		"total_comments": totalComments,
+		"total_in_level": totalInLevel,
+		"comments":       roots,
+	}
+	if cursor.Parent != nil {
+		response["parent_comment_id"] = *cursor.Parent
+	}
+	if next := cursor.Offset + len(roots); next < totalInLevel {
+		nextCursor := cursor
+		nextCursor.Offset = next
+		response["next_cursor"] = nextCursor.encode()
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
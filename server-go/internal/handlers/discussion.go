@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// discussionStats - активность обсуждения поста. Формулы - в SQL-функции
+// discussion_stats (db/tmp.sql), её же вызывает представление post_discussion_stats.
+type discussionStats struct {
+	PostID                    int        `json:"post_id"`
+	PostCreatedAt             *time.Time `json:"post_created_at"`
+	CommentsTotal             int        `json:"comments_total"`
+	UniqueCommenters          int        `json:"unique_commenters"`
+	RepliesTotal              int        `json:"replies_total"`
+	MaxDepth                  int        `json:"max_depth"`
+	ReplyRatio                float64    `json:"reply_ratio"`
+	CommentsPerHour           float64    `json:"comments_per_hour"`
+	TimeToFirstCommentSeconds *int64     `json:"time_to_first_comment_seconds"`
+	FirstCommentAt            *time.Time `json:"first_comment_at"`
+	LastCommentAt             *time.Time `json:"last_comment_at"`
+}
+
+// discussionStatsQuery - статистика одобренного поста
+const discussionStatsQuery = `
+    SELECT d.post_id, d.post_created_at, d.comments_total, d.unique_commenters,
+           d.replies_total, d.max_depth, d.reply_ratio::float8, d.comments_per_hour::float8,
+           d.time_to_first_comment_seconds, d.first_comment_at, d.last_comment_at
+    FROM posts p
+    CROSS JOIN LATERAL discussion_stats(p.post_id) d
+    WHERE p.post_id = $1 AND p.post_status = 'approved'`
+
+// postDiscussionStatsHandler - GET /api/posts/{id}/discussion-stats
+func (h *Handlers) postDiscussionStatsHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || postID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+		return
+	}
+
+	ctx := r.Context()
+	cacheKey := fmt.Sprintf("cache:discussion_stats:%d", postID)
+	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(cached))
+		return
+	}
+
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var s discussionStats
+	err = conn.QueryRow(ctx, discussionStatsQuery, postID).Scan(&s.PostID, &s.PostCreatedAt,
+		&s.CommentsTotal, &s.UniqueCommenters, &s.RepliesTotal, &s.MaxDepth, &s.ReplyRatio,
+		&s.CommentsPerHour, &s.TimeToFirstCommentSeconds, &s.FirstCommentAt, &s.LastCommentAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to compute discussion stats", err)
+		return
+	}
+
+	data, _ := json.Marshal(s)
+	h.cache.SetEX(ctx, cacheKey, string(data), 60)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
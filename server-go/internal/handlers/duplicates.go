@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"news-aggregator/internal/dedup"
+	"news-aggregator/internal/pgpool"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxLSHCandidates - сколько кандидатов из совпавших полос LSH сравнивается с постом
+const maxLSHCandidates = 200
+
+// storyMatch - сюжет, к которому отнесён пост, и ближайший к нему дубликат
+type storyMatch struct {
+	StoryID     int  `json:"story_id"`
+	DuplicateOf *int `json:"duplicate_of"`
+	Distance    *int `json:"distance"`
+}
+
+// duplicatePost - пост того же сюжета
+type duplicatePost struct {
+	PostID      int       `json:"post_id"`
+	Title       string    `json:"title"`
+	ChannelID   *int      `json:"channel_id"`
+	ChannelName *string   `json:"channel_name"`
+	SourceName  *string   `json:"source_name"`
+	PostStatus  string    `json:"post_status"`
+	CreatedAt   time.Time `json:"created_at"`
+	Exact       bool      `json:"exact"`
+	Distance    int       `json:"distance"`
+	Similarity  float64   `json:"similarity"`
+}
+
+// setupDuplicateRoutes регистрирует обслуживание отпечатков на роутере с префиксом /admin
+func (h *Handlers) setupDuplicateRoutes(r *mux.Router) {
+	r.HandleFunc("/duplicates/backfill", h.backfillFingerprintsHandler).Methods("POST")
+}
+
+// findExactDuplicate - пост с тем же нормализованным текстом; 0, если такого нет
+func findExactDuplicate(ctx context.Context, conn *pgpool.PConn, fp dedup.Fingerprint) (int, error) {
+	var postID int
+	err := conn.QueryRow(ctx,
+		"SELECT post_id FROM post_fingerprints WHERE content_hash = $1 ORDER BY post_id LIMIT 1",
+		fp.ContentHash).Scan(&postID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return postID, err
+}
+
+// assignStory сохраняет отпечатки поста и относит его к сюжету ближайшего дубликата:
+// сначала ищется точная копия, затем близкий SimHash среди кандидатов LSH.
+// Если дубликата нет, пост открывает новый сюжет.
+func assignStory(ctx context.Context, tx pgx.Tx, postID int, title string, fp dedup.Fingerprint) (storyMatch, error) {
+	var match storyMatch
+	var duplicateOf int
+	err := tx.QueryRow(ctx, `
+        SELECT post_id, story_id FROM post_fingerprints
+        WHERE content_hash = $1 AND post_id <> $2
+        ORDER BY post_id LIMIT 1`, fp.ContentHash, postID).Scan(&duplicateOf, &match.StoryID)
+	switch {
+	case err == nil:
+		distance := 0
+		match.DuplicateOf, match.Distance = &duplicateOf, &distance
+	case !errors.Is(err, pgx.ErrNoRows):
+		return match, err
+	case fp.Fuzzy():
+		if match, err = nearestDuplicate(ctx, tx, postID, fp); err != nil {
+			return match, err
+		}
+	}
+
+	if match.StoryID == 0 {
+		err := tx.QueryRow(ctx,
+			"INSERT INTO stories (title, first_post_id) VALUES ($1, $2) RETURNING story_id",
+			title, postID).Scan(&match.StoryID)
+		if err != nil {
+			return match, err
+		}
+	} else if _, err := tx.Exec(ctx, "UPDATE stories SET updated_at = NOW() WHERE story_id = $1", match.StoryID); err != nil {
+		return match, err
+	}
+
+	bands := fp.BandValues()
+	_, err = tx.Exec(ctx, `
+        INSERT INTO post_fingerprints (post_id, content_hash, simhash, band0, band1, band2, band3, words, story_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        ON CONFLICT (post_id) DO UPDATE SET
+            content_hash = EXCLUDED.content_hash, simhash = EXCLUDED.simhash,
+            band0 = EXCLUDED.band0, band1 = EXCLUDED.band1, band2 = EXCLUDED.band2, band3 = EXCLUDED.band3,
+            words = EXCLUDED.words, story_id = EXCLUDED.story_id`,
+		postID, fp.ContentHash, fp.Signed(), bands[0], bands[1], bands[2], bands[3], fp.Words, match.StoryID)
+	return match, err
+}
+
+// nearestDuplicate ищет ближайший SimHash среди постов, совпавших хотя бы в одной полосе LSH
+func nearestDuplicate(ctx context.Context, tx pgx.Tx, postID int, fp dedup.Fingerprint) (storyMatch, error) {
+	var match storyMatch
+	bands := fp.BandValues()
+	rows, err := tx.Query(ctx, `
+        SELECT post_id, story_id, simhash FROM post_fingerprints
+        WHERE (band0 = $1 OR band1 = $2 OR band2 = $3 OR band3 = $4)
+          AND words >= $5 AND post_id <> $6
+        ORDER BY post_id DESC
+        LIMIT $7`, bands[0], bands[1], bands[2], bands[3], dedup.MinWords, postID, maxLSHCandidates)
+	if err != nil {
+		return match, err
+	}
+	defer rows.Close()
+
+	best := dedup.MaxDistance + 1
+	for rows.Next() {
+		var candidateID, storyID int
+		var simhash int64
+		if err := rows.Scan(&candidateID, &storyID, &simhash); err != nil {
+			return match, err
+		}
+		if d := dedup.Distance(fp.SimHash, uint64(simhash)); d < best {
+			best = d
+			id, distance := candidateID, d
+			match = storyMatch{StoryID: storyID, DuplicateOf: &id, Distance: &distance}
+		}
+	}
+	return match, rows.Err()
+}
+
+// refreshFingerprint пересчитывает отпечатки после правки заголовка или текста.
+// Сюжет поста не меняется.
+func refreshFingerprint(ctx context.Context, tx pgx.Tx, postID int) error {
+	var title, content string
+	err := tx.QueryRow(ctx, `
+        SELECT p.title, COALESCE(nt.text, '')
+        FROM posts p LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE p.post_id = $1`, postID).Scan(&title, &content)
+	if err != nil {
+		return err
+	}
+
+	fp := dedup.Compute(title, content)
+	bands := fp.BandValues()
+	_, err = tx.Exec(ctx, `
+        UPDATE post_fingerprints
+        SET content_hash = $2, simhash = $3, band0 = $4, band1 = $5, band2 = $6, band3 = $7, words = $8
+        WHERE post_id = $1`,
+		postID, fp.ContentHash, fp.Signed(), bands[0], bands[1], bands[2], bands[3], fp.Words)
+	return err
+}
+
+// postDuplicatesHandler - GET /api/posts/{id}/duplicates: посты того же сюжета
+// с расстоянием Хэмминга между отпечатками, ближайшие первыми
+func (h *Handlers) postDuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || postID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var storyID *int
+	var contentHash *string
+	var simhash *int64
+	err = conn.QueryRow(ctx, `
+        SELECT f.story_id, f.content_hash, f.simhash
+        FROM posts p LEFT JOIN post_fingerprints f ON p.post_id = f.post_id
+        WHERE p.post_id = $1`, postID).Scan(&storyID, &contentHash, &simhash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to read post fingerprint", err)
+		return
+	}
+
+	duplicates := []duplicatePost{}
+	if storyID != nil {
+		rows, err := conn.Query(ctx, `
+            SELECT p.post_id, p.title, p.channel_id, c.name, s.name, p.post_status, p.created_at,
+                   f.content_hash = $2, f.simhash
+            FROM post_fingerprints f
+            JOIN posts p ON f.post_id = p.post_id
+            LEFT JOIN channels c ON p.channel_id = c.channel_id
+            LEFT JOIN sources s ON c.source_id = s.source_id
+            WHERE f.story_id = $1 AND f.post_id <> $3`, *storyID, *contentHash, postID)
+		if err != nil {
+			writeDBError(w, r, "Failed to read duplicates", err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var d duplicatePost
+			var other int64
+			if err := rows.Scan(&d.PostID, &d.Title, &d.ChannelID, &d.ChannelName, &d.SourceName,
+				&d.PostStatus, &d.CreatedAt, &d.Exact, &other); err != nil {
+				writeDBError(w, r, "Failed to read duplicates", err)
+				return
+			}
+			d.Distance = dedup.Distance(uint64(*simhash), uint64(other))
+			d.Similarity = dedup.Similarity(uint64(*simhash), uint64(other))
+			duplicates = append(duplicates, d)
+		}
+		sortDuplicates(duplicates)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"post_id":    postID,
+		"story_id":   storyID,
+		"duplicates": duplicates,
+	})
+}
+
+// sortDuplicates - точные копии и ближайшие отпечатки первыми, затем по времени публикации
+func sortDuplicates(duplicates []duplicatePost) {
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		if duplicates[i].Distance != duplicates[j].Distance {
+			return duplicates[i].Distance < duplicates[j].Distance
+		}
+		return duplicates[i].CreatedAt.Before(duplicates[j].CreatedAt)
+	})
+}
+
+// backfillFingerprintsHandler - POST /api/admin/duplicates/backfill?limit=1000:
+// отпечатки и сюжеты для постов, созданных до появления поиска дубликатов.
+// Посты обрабатываются от старых к новым, чтобы сюжет открывал первоисточник.
+func (h *Handlers) backfillFingerprintsHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	limit := queryInt(r, "limit", 1000, 1, 10000, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+        SELECT p.post_id, p.title, COALESCE(nt.text, '')
+        FROM posts p
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE NOT EXISTS (SELECT 1 FROM post_fingerprints f WHERE f.post_id = p.post_id)
+        ORDER BY p.created_at, p.post_id
+        LIMIT $1`, limit)
+	if err != nil {
+		writeDBError(w, r, "Failed to read posts", err)
+		return
+	}
+	type pending struct {
+		id             int
+		title, content string
+	}
+	var posts []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.title, &p.content); err != nil {
+			rows.Close()
+			writeDBError(w, r, "Failed to read posts", err)
+			return
+		}
+		posts = append(posts, p)
+	}
+	rows.Close()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		writeDBError(w, r, "Failed to begin transaction", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	duplicates := 0
+	for _, p := range posts {
+		match, err := assignStory(ctx, tx, p.id, p.title, dedup.Compute(p.title, p.content))
+		if err != nil {
+			writeDBError(w, r, "Failed to save fingerprint", err)
+			return
+		}
+		if match.DuplicateOf != nil {
+			duplicates++
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		writeDBError(w, r, "Failed to commit transaction", err)
+		return
+	}
+
+	log.Printf("[%s] Fingerprinted %d posts, %d duplicates", requestID(r), len(posts), duplicates)
+	writeJSON(w, http.StatusOK, map[string]int{
+		"processed":  len(posts),
+		"duplicates": duplicates,
+	})
+}
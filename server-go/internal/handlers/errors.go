@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Машиночитаемые коды ошибок API
+const (
+	errCodeBadRequest    = "bad_request"
+	errCodeInvalidJSON   = "invalid_json"
+	errCodeValidation    = "validation_failed"
+	errCodeNotFound      = "not_found"
+	errCodeReadOnly      = "read_only"
+	errCodeConflict      = "conflict"
+	errCodeDuplicatePost = "duplicate_post"
+	errCodePostRejected  = "post_rejected"
+	errCodeUnprocessable = "unprocessable_entity"
+	errCodeUnavailable   = "service_unavailable"
+	errCodeInternal      = "internal_error"
+)
+
+// Коды ошибок PostgreSQL, которые отдаются клиенту как ошибки запроса
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+	pgCheckViolation      = "23514"
+	pgStringTooLong       = "22001"
+	pgInvalidText         = "22P02"
+	pgNumericOutOfRange   = "22003"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+type ctxKey int
+
+const requestIDKey ctxKey = iota
+
+// FieldError - ошибка валидации конкретного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError - единый формат ответа об ошибке
+type APIError struct {
+	Code        string       `json:"code"`
+	Message     string       `json:"message"`
+	FieldErrors []FieldError `json:"field_errors,omitempty"`
+	RequestID   string       `json:"request_id"`
+}
+
+// requestIDMiddleware присваивает каждому запросу идентификатор
+// (берёт X-Request-ID клиента, если он передан) и возвращает его в ответе
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" || len(id) > 64 {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+func requestID(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// writeJSON отдаёт успешный ответ в JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError отдаёт ошибку в едином JSON-формате
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeAPIError(w, status, APIError{
+		Code:      code,
+		Message:   message,
+		RequestID: requestID(r),
+	})
+}
+
+// writeValidationError отдаёт 400 со списком ошибок по полям
+func writeValidationError(w http.ResponseWriter, r *http.Request, fieldErrors []FieldError) {
+	writeAPIError(w, http.StatusBadRequest, APIError{
+		Code:        errCodeValidation,
+		Message:     "Request validation failed",
+		FieldErrors: fieldErrors,
+		RequestID:   requestID(r),
+	})
+}
+
+// writeDBError преобразует ошибку PostgreSQL в HTTP-ответ.
+// Текст ошибки БД пишется только в лог, клиент получает message и код.
+func writeDBError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	rid := requestID(r)
+	log.Printf("[%s] %s %s: %s: %v", rid, r.Method, r.URL.Path, message, err)
+
+	apiErr := APIError{
+		Code:      errCodeInternal,
+		Message:   message,
+		RequestID: rid,
+	}
+	status := http.StatusInternalServerError
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		var fieldErrors []FieldError
+		if pgErr.ColumnName != "" {
+			fieldErrors = append(fieldErrors, FieldError{Field: pgErr.ColumnName})
+		}
+
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			status = http.StatusConflict
+			apiErr.Code = errCodeConflict
+			apiErr.Message = "Resource already exists"
+		case pgForeignKeyViolation:
+			status = http.StatusUnprocessableEntity
+			apiErr.Code = errCodeUnprocessable
+			apiErr.Message = "Referenced resource does not exist"
+		case pgNotNullViolation:
+			status = http.StatusUnprocessableEntity
+			apiErr.Code = errCodeUnprocessable
+			apiErr.Message = "Required field is missing"
+		case pgCheckViolation, pgStringTooLong, pgInvalidText, pgNumericOutOfRange:
+			status = http.StatusUnprocessableEntity
+			apiErr.Code = errCodeUnprocessable
+			apiErr.Message = "Field value is not acceptable"
+		default:
+			fieldErrors = nil
+		}
+
+		for i := range fieldErrors {
+			fieldErrors[i].Message = apiErr.Message
+		}
+		apiErr.FieldErrors = fieldErrors
+	}
+
+	writeAPIError(w, status, apiErr)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, apiErr APIError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(apiErr)
+}
@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"news-aggregator/internal/filter"
+	"news-aggregator/internal/pgpool"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// Цепочка пересобирается не реже раза в filterChainTTL (правила могли изменить
+// через другой экземпляр сервера), а также сразу после правок через admin API
+const filterChainTTL = time.Minute
+
+// filterChainState - собранная цепочка фильтров, общая для всех запросов
+type filterChainState struct {
+	mu       sync.Mutex
+	chain    *filter.Chain
+	loadedAt time.Time
+}
+
+func newFilterChainState() *filterChainState {
+	return &filterChainState{}
+}
+
+// invalidate - следующий пост пересоберёт цепочку
+func (s *filterChainState) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chain = nil
+}
+
+const filterRuleColumns = "rule_id, kind, topic, pattern, threshold, action, description, enabled, created_at, updated_at"
+
+// Тело POST/PUT /api/admin/filters
+var filterRuleSchema = bodySchema{
+	"kind":        reqString(30),
+	"topic":       optString(255),
+	"pattern":     fieldRule{Kind: kindString},
+	"threshold":   fieldRule{Kind: kindNumber},
+	"action":      reqString(20),
+	"description": optString(255),
+	"enabled":     fieldRule{Kind: kindBool},
+}
+
+// Тело POST /api/admin/filters/test - пост для пробного прогона цепочки
+var filterTestSchema = bodySchema{
+	"title":             optString(255),
+	"content":           fieldRule{Kind: kindString},
+	"topic":             optString(255),
+	"marked_as_ads":     fieldRule{Kind: kindBool},
+	"nsfw":              fieldRule{Kind: kindBool},
+	"author_reputation": fieldRule{Kind: kindNumber},
+}
+
+// setupFilterRoutes регистрирует admin API правил фильтрации на роутере с префиксом /admin
+func (h *Handlers) setupFilterRoutes(r *mux.Router) {
+	r.HandleFunc("/filters", h.listFilterRulesHandler).Methods("GET")
+	r.HandleFunc("/filters", h.createFilterRuleHandler).Methods("POST")
+	r.HandleFunc("/filters/test", h.testFilterHandler).Methods("POST")
+	r.HandleFunc("/filters/decisions", h.listFilterDecisionsHandler).Methods("GET")
+	r.HandleFunc("/filters/{id:[0-9]+}", h.getFilterRuleHandler).Methods("GET")
+	r.HandleFunc("/filters/{id:[0-9]+}", h.updateFilterRuleHandler).Methods("PUT")
+	r.HandleFunc("/filters/{id:[0-9]+}", h.deleteFilterRuleHandler).Methods("DELETE")
+}
+
+func scanFilterRule(row pgx.Row) (filter.Rule, error) {
+	var rule filter.Rule
+	var action string
+	err := row.Scan(&rule.RuleID, &rule.Kind, &rule.Topic, &rule.Pattern, &rule.Threshold,
+		&action, &rule.Description, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt)
+	rule.Action = filter.Action(action)
+	return rule, err
+}
+
+func loadFilterRules(ctx context.Context, conn *pgpool.PConn, enabledOnly bool) ([]filter.Rule, error) {
+	query := "SELECT " + filterRuleColumns + " FROM filter_rules"
+	if enabledOnly {
+		query += " WHERE enabled"
+	}
+	rows, err := conn.Query(ctx, query+" ORDER BY rule_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []filter.Rule{}
+	for rows.Next() {
+		rule, err := scanFilterRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// loadFilterChain возвращает цепочку из включённых правил; битые правила логируются и пропускаются
+func (h *Handlers) loadFilterChain(ctx context.Context, conn *pgpool.PConn) (*filter.Chain, error) {
+	s := h.filters
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chain != nil && time.Since(s.loadedAt) < filterChainTTL {
+		return s.chain, nil
+	}
+
+	rules, err := loadFilterRules(ctx, conn, true)
+	if err != nil {
+		return nil, err
+	}
+	chain, errs := filter.NewChain(rules)
+	for _, err := range errs {
+		log.Printf("Content filter: skipping %v", err)
+	}
+	s.chain, s.loadedAt = chain, time.Now()
+	return chain, nil
+}
+
+// filterPost прогоняет создаваемый пост через цепочку фильтров.
+// Тема берётся из канала, а если её нет - из источника канала;
+// репутация автора - по SQL-функции author_reputation_stats.
+func (h *Handlers) filterPost(ctx context.Context, conn *pgpool.PConn, data map[string]interface{}) (filter.Decision, error) {
+	chain, err := h.loadFilterChain(ctx, conn)
+	if err != nil {
+		return filter.Decision{}, err
+	}
+
+	channelID, _ := toFloat(data["channel_id"])
+	var topic string
+	err = conn.QueryRow(ctx, `
+        SELECT COALESCE(NULLIF(c.topic, ''), s.topic, '')
+        FROM channels c
+        LEFT JOIN sources s ON s.source_id = c.source_id
+        WHERE c.channel_id = $1`, int(channelID)).Scan(&topic)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return filter.Decision{}, err
+	}
+
+	post := filter.Post{Topic: topic}
+	post.Title, _ = data["title"].(string)
+	post.Content, _ = data["content"].(string)
+	post.AdMarked, _ = data["marked_as_ads"].(bool)
+	post.NSFW, _ = data["nsfw"].(bool)
+
+	// Внутренняя репутация автора (0..100) по истории его постов
+	authorID, _ := toFloat(data["author_id"])
+	reputation, err := loadAuthorReputation(ctx, conn, int(authorID))
+	switch {
+	case err == nil:
+		post.AuthorReputation = &reputation.Score
+	case !errors.Is(err, pgx.ErrNoRows):
+		return filter.Decision{}, err
+	}
+	return chain.Run(post), nil
+}
+
+// recordFilterDecision сохраняет решение фильтра; postID == 0 - пост не создан (отклонён)
+func recordFilterDecision(ctx context.Context, tx pgx.Tx, postID int32, data map[string]interface{}, decision filter.Decision) error {
+	authorID, _ := toFloat(data["author_id"])
+	channelID, _ := toFloat(data["channel_id"])
+	title, _ := data["title"].(string)
+	reasons, _ := json.Marshal(decision.Reasons)
+
+	var post interface{}
+	if postID > 0 {
+		post = postID
+	}
+	_, err := tx.Exec(ctx, `
+        INSERT INTO post_filter_decisions (post_id, channel_id, author_id, title, decision, reasons)
+        VALUES ($1, (SELECT channel_id FROM channels WHERE channel_id = $2),
+                (SELECT author_id FROM authors WHERE author_id = $3), $4, $5, $6)`,
+		post, int(channelID), int(authorID), title, string(decision.Action), reasons)
+	return err
+}
+
+// ruleFromBody заполняет правило из тела запроса поверх текущих значений
+func ruleFromBody(rule filter.Rule, data map[string]interface{}) filter.Rule {
+	if v, ok := data["kind"].(string); ok {
+		rule.Kind = v
+	}
+	if v, present := data["topic"]; present {
+		rule.Topic = nil
+		if s, ok := v.(string); ok && s != "" {
+			rule.Topic = &s
+		}
+	}
+	if v, ok := data["pattern"].(string); ok {
+		rule.Pattern = v
+	}
+	if v, present := data["threshold"]; present {
+		rule.Threshold = nil
+		if n, ok := toFloat(v); ok {
+			rule.Threshold = &n
+		}
+	}
+	if v, ok := data["action"].(string); ok {
+		rule.Action = filter.Action(v)
+	}
+	if v, ok := data["description"].(string); ok {
+		rule.Description = v
+	}
+	if v, ok := data["enabled"].(bool); ok {
+		rule.Enabled = v
+	}
+	return rule
+}
+
+// decodeFilterRule читает и проверяет тело запроса правила
+func decodeFilterRule(w http.ResponseWriter, r *http.Request, base filter.Rule, partial bool) (filter.Rule, bool) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return base, false
+	}
+	if fieldErrors := validateBody(filterRuleSchema, data, partial); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return base, false
+	}
+
+	rule := ruleFromBody(base, data)
+	if err := filter.Validate(rule); err != nil {
+		var ruleErr *filter.RuleError
+		if errors.As(err, &ruleErr) {
+			writeValidationError(w, r, []FieldError{{Field: ruleErr.Field, Message: ruleErr.Message}})
+		} else {
+			writeError(w, r, http.StatusBadRequest, errCodeBadRequest, err.Error())
+		}
+		return base, false
+	}
+	return rule, true
+}
+
+// listFilterRulesHandler - GET /api/admin/filters
+func (h *Handlers) listFilterRulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rules, err := loadFilterRules(ctx, conn, false)
+	if err != nil {
+		writeDBError(w, r, "Failed to read filter rules", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// createFilterRuleHandler - POST /api/admin/filters
+func (h *Handlers) createFilterRuleHandler(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeFilterRule(w, r, filter.Rule{Enabled: true}, false)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	created, err := scanFilterRule(conn.QueryRow(ctx, `
+        INSERT INTO filter_rules (kind, topic, pattern, threshold, action, description, enabled)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING `+filterRuleColumns,
+		rule.Kind, rule.Topic, rule.Pattern, rule.Threshold, string(rule.Action), rule.Description, rule.Enabled))
+	if err != nil {
+		writeDBError(w, r, "Failed to create filter rule", err)
+		return
+	}
+
+	h.filters.invalidate()
+	log.Printf("[%s] Content filter rule %d created: %s %s", requestID(r), created.RuleID, created.Kind, created.Action)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// getFilterRuleHandler - GET /api/admin/filters/{id}
+func (h *Handlers) getFilterRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rule, err := scanFilterRule(conn.QueryRow(ctx,
+		"SELECT "+filterRuleColumns+" FROM filter_rules WHERE rule_id = $1", mux.Vars(r)["id"]))
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Filter rule not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to read filter rule", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// updateFilterRuleHandler - PUT /api/admin/filters/{id}: частичное обновление правила
+func (h *Handlers) updateFilterRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	id := mux.Vars(r)["id"]
+	current, err := scanFilterRule(conn.QueryRow(ctx,
+		"SELECT "+filterRuleColumns+" FROM filter_rules WHERE rule_id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Filter rule not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to read filter rule", err)
+		return
+	}
+
+	rule, ok := decodeFilterRule(w, r, current, true)
+	if !ok {
+		return
+	}
+
+	updated, err := scanFilterRule(conn.QueryRow(ctx, `
+        UPDATE filter_rules
+        SET kind = $1, topic = $2, pattern = $3, threshold = $4, action = $5,
+            description = $6, enabled = $7, updated_at = NOW()
+        WHERE rule_id = $8
+        RETURNING `+filterRuleColumns,
+		rule.Kind, rule.Topic, rule.Pattern, rule.Threshold, string(rule.Action), rule.Description, rule.Enabled, id))
+	if err != nil {
+		writeDBError(w, r, "Failed to update filter rule", err)
+		return
+	}
+
+	h.filters.invalidate()
+	log.Printf("[%s] Content filter rule %d updated", requestID(r), updated.RuleID)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// deleteFilterRuleHandler - DELETE /api/admin/filters/{id}
+func (h *Handlers) deleteFilterRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var deleted int
+	err = conn.QueryRow(ctx, "DELETE FROM filter_rules WHERE rule_id = $1 RETURNING rule_id", mux.Vars(r)["id"]).Scan(&deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Filter rule not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to delete filter rule", err)
+		return
+	}
+
+	h.filters.invalidate()
+	log.Printf("[%s] Content filter rule %d deleted", requestID(r), deleted)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Filter rule deleted"})
+}
+
+// testFilterHandler - POST /api/admin/filters/test: пробный прогон без сохранения решения
+func (h *Handlers) testFilterHandler(w http.ResponseWriter, r *http.Request) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	if fieldErrors := validateBody(filterTestSchema, data, false); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	chain, err := h.loadFilterChain(ctx, conn)
+	if err != nil {
+		writeDBError(w, r, "Failed to read filter rules", err)
+		return
+	}
+
+	post := filter.Post{}
+	post.Title, _ = data["title"].(string)
+	post.Content, _ = data["content"].(string)
+	post.Topic, _ = data["topic"].(string)
+	post.AdMarked, _ = data["marked_as_ads"].(bool)
+	post.NSFW, _ = data["nsfw"].(bool)
+	if rep, ok := toFloat(data["author_reputation"]); ok {
+		post.AuthorReputation = &rep
+	}
+	writeJSON(w, http.StatusOK, chain.Run(post))
+}
+
+// listFilterDecisionsHandler - GET /api/admin/filters/decisions?decision=reject&limit=50
+func (h *Handlers) listFilterDecisionsHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	limit := queryInt(r, "limit", 50, 1, 500, &fieldErrors)
+	decision := r.URL.Query().Get("decision")
+	switch filter.Action(decision) {
+	case "", filter.Accept, filter.Quarantine, filter.Reject:
+	default:
+		fieldErrors = append(fieldErrors, FieldError{Field: "decision", Message: "must be one of: accept, quarantine, reject"})
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+        SELECT decision_id, post_id, channel_id, author_id, title, decision, reasons, created_at
+        FROM post_filter_decisions
+        WHERE $1 = '' OR decision = $1
+        ORDER BY decision_id DESC
+        LIMIT $2`, decision, limit)
+	if err != nil {
+		writeDBError(w, r, "Failed to read filter decisions", err)
+		return
+	}
+	defer rows.Close()
+
+	writeJSON(w, http.StatusOK, h.rowsToJSON(rows))
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -12,7 +11,13 @@ import (
 	"time"
 
 	"news-aggregator/internal/cache"
+	"news-aggregator/internal/classifier"
+	"news-aggregator/internal/dedup"
+	"news-aggregator/internal/filter"
+	"news-aggregator/internal/keywords"
+	"news-aggregator/internal/langdetect"
 	"news-aggregator/internal/mongo"
+	"news-aggregator/internal/openapi"
 	"news-aggregator/internal/pgpool"
 
 	"github.com/gorilla/mux"
@@ -23,6 +28,15 @@ type Handlers struct {
 	pool  *pgpool.PgPool
 	cache *cache.CacheManager
 	mongo *mongo.MongoManager
+
+	legacyRoutes bool // старый роутер /api/{table} работает параллельно с /api/v1
+	legacyUsage  *legacyUsage
+
+	premoderation bool // новые посты ждут одобрения редактора (pending)
+
+	classifier *classifierState // категоризация постов, переобучается по разметке редакторов
+
+	filters *filterChainState // цепочка фильтров контента, пересобирается после правок правил
 }
 
 var validTables = map[string]bool{
@@ -54,6 +68,9 @@ var validTables = map[string]bool{
 	"media_with_context":           true,
 	"comprehensive_post_info":      true,
 	"extended_post_analytics":      true,
+	"post_discussion_stats":        true,
+	"author_reputation":            true,
+	"story_stats":                  true,
 }
 
 var pkMap = map[string]string{
@@ -70,18 +87,37 @@ var pkMap = map[string]string{
 
 func NewHandlers(pool *pgpool.PgPool, cache *cache.CacheManager, mongo *mongo.MongoManager) *Handlers {
 	return &Handlers{
-		pool:  pool,
-		cache: cache,
-		mongo: mongo,
+		pool:         pool,
+		cache:        cache,
+		mongo:        mongo,
+		legacyRoutes: true,
+		legacyUsage:  newLegacyUsage(),
+		classifier:   newClassifierState(),
+		filters:      newFilterChainState(),
 	}
 }
 
+// SetLegacyRoutes включает или отключает устаревшие маршруты /api/{table}.
+// Вызывается до SetupRoutes.
+func (h *Handlers) SetLegacyRoutes(enabled bool) {
+	h.legacyRoutes = enabled
+}
+
+// SetPremoderation включает премодерацию: посты, прошедшие фильтр контента,
+// получают статус pending и попадают в ленту только после одобрения
+func (h *Handlers) SetPremoderation(enabled bool) {
+	h.premoderation = enabled
+}
+
 func (h *Handlers) SetupRoutes() http.Handler {
     r := mux.NewRouter()
 
     // Health check endpoint
     r.HandleFunc("/health", h.healthHandler).Methods("GET")
 
+    // Описание API (должно быть ПЕРЕД табличными маршрутами)
+    r.HandleFunc("/api/openapi.json", openapi.Handler).Methods("GET")
+
     // Специальные endpoint для VK ресерчера (должны быть ПЕРЕД табличными маршрутами)
     r.HandleFunc("/api/vk/posts", h.createVKPostHandler).Methods("POST")
     r.HandleFunc("/api/vk/sources", h.createVKSourceHandler).Methods("POST")
@@ -100,22 +136,51 @@ func (h *Handlers) SetupRoutes() http.Handler {
     r.HandleFunc("/api/mongo/analytics/channels", h.channelPerformanceHandler).Methods("GET")
     r.HandleFunc("/api/mongo/materialize", h.materializeViewHandler).Methods("POST")
 
-    // CRUD операции для PostgreSQL
-    r.HandleFunc("/api/{table}", h.createHandler).Methods("POST")
-    r.HandleFunc("/api/{table}", h.readAllHandler).Methods("GET")
-    r.HandleFunc("/api/{table}/{id}", h.readOneHandler).Methods("GET")
-    r.HandleFunc("/api/{table}/{id}", h.updateHandler).Methods("PUT")
-    r.HandleFunc("/api/{table}/{id}", h.deleteHandler).Methods("DELETE")
-
-    // Обработка post_tags с двумя ID
-    r.HandleFunc("/api/{table}/{id}/{id2}", h.readOneHandler).Methods("GET")
-    r.HandleFunc("/api/{table}/{id}/{id2}", h.updateHandler).Methods("PUT")
-    r.HandleFunc("/api/{table}/{id}/{id2}", h.deleteHandler).Methods("DELETE")
-
-        r.HandleFunc("/api/authors", h.createHandler).Methods("POST")
-    r.HandleFunc("/api/authors", h.readAllHandler).Methods("GET")
-
-    return r
+    // Дерево комментариев, статистика обсуждения, дубликаты, ключевые слова и профиль автора (должны быть ПЕРЕД табличными маршрутами)
+    r.HandleFunc("/api/posts/{id:[0-9]+}/comments", h.postCommentsHandler).Methods("GET")
+    r.HandleFunc("/api/posts/{id:[0-9]+}/discussion-stats", h.postDiscussionStatsHandler).Methods("GET")
+    r.HandleFunc("/api/posts/{id:[0-9]+}/duplicates", h.postDuplicatesHandler).Methods("GET")
+    r.HandleFunc("/api/posts/{id:[0-9]+}/keywords", h.postKeywordsHandler).Methods("GET")
+    r.HandleFunc("/api/authors/{id:[0-9]+}/profile", h.authorProfileHandler).Methods("GET")
+
+    // Категории постов (должны быть ПЕРЕД табличными маршрутами)
+    r.HandleFunc("/api/categories", h.listCategoriesHandler).Methods("GET")
+    r.HandleFunc("/api/posts/{id:[0-9]+}/categories", h.postCategoriesHandler).Methods("GET")
+    r.HandleFunc("/api/posts/{id:[0-9]+}/categories", h.setPostCategoriesHandler).Methods("PUT")
+
+    // Сюжеты, растущие теги и иерархия тегов (должны быть ПЕРЕД табличными маршрутами)
+    r.HandleFunc("/api/stories", h.listStoriesHandler).Methods("GET")
+    r.HandleFunc("/api/stories/{id:[0-9]+}", h.storyHandler).Methods("GET")
+    r.HandleFunc("/api/trends/tags", h.trendsTagsHandler).Methods("GET")
+    r.HandleFunc("/api/tags/{id:[0-9]+}/hierarchy", h.tagHierarchyHandler).Methods("GET")
+
+    // Правила фильтра контента, классификатор и очередь модерации (должны быть ПЕРЕД табличными маршрутами)
+    admin := r.PathPrefix("/api/admin").Subrouter()
+    h.setupFilterRoutes(admin)
+    h.setupCategoryRoutes(admin)
+    h.setupDuplicateRoutes(admin)
+    h.setupStoryRoutes(admin)
+    h.setupTagRoutes(admin)
+    h.setupLanguageRoutes(admin)
+    h.setupKeywordRoutes(admin)
+    h.setupModerationRoutes(r.PathPrefix("/api/moderation").Subrouter())
+
+    // Версионированный API (должен быть ПЕРЕД табличными маршрутами)
+    h.setupV1Routes(r)
+
+    // Устаревший табличный роутер, работает параллельно с /api/v1
+    if h.legacyRoutes {
+        h.setupLegacyRoutes(r)
+    }
+
+    r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        writeError(w, r, http.StatusNotFound, errCodeNotFound, "Route not found")
+    })
+    r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        writeError(w, r, http.StatusMethodNotAllowed, errCodeBadRequest, "Method not allowed")
+    })
+
+    return requestIDMiddleware(r)
 }
 
 // ============ VK RESEARCHER SPECIAL HANDLERS ============
@@ -123,7 +188,7 @@ func (h *Handlers) SetupRoutes() http.Handler {
 func (h *Handlers) createVKSourceHandler(w http.ResponseWriter, r *http.Request) {
     var data map[string]interface{}
     if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-        http.Error(w, "Invalid JSON", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
         return
     }
     
@@ -143,7 +208,7 @@ func (h *Handlers) createVKSourceHandler(w http.ResponseWriter, r *http.Request)
 func (h *Handlers) createVKChannelHandler(w http.ResponseWriter, r *http.Request) {
     var data map[string]interface{}
     if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-        http.Error(w, "Invalid JSON", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
         return
     }
     
@@ -165,7 +230,7 @@ func (h *Handlers) createVKChannelHandler(w http.ResponseWriter, r *http.Request
 func (h *Handlers) createVKPostHandler(w http.ResponseWriter, r *http.Request) {
     var data map[string]interface{}
     if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-        http.Error(w, "Invalid JSON", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
         return
     }
     
@@ -181,7 +246,7 @@ func (h *Handlers) createVKPostHandler(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) createVKMediaHandler(w http.ResponseWriter, r *http.Request) {
     var data map[string]interface{}
     if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-        http.Error(w, "Invalid JSON", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
         return
     }
     
@@ -193,6 +258,12 @@ func (h *Handlers) createVKMediaHandler(w http.ResponseWriter, r *http.Request)
         "media_content": data["media_content"],
         "media_type":    data["media_type"],
     }
+    // Размеры, превью и подпись передаются, только если researcher их прислал
+    for _, field := range []string{"width", "height", "thumbnail_url", "title"} {
+        if v, ok := data[field]; ok {
+            transformedData[field] = v
+        }
+    }
     
     // Используем обычный обработчик
     h.createHandlerInternal(w, r, "media", transformedData)
@@ -201,25 +272,32 @@ func (h *Handlers) createVKMediaHandler(w http.ResponseWriter, r *http.Request)
 func (h *Handlers) createVKAuthorHandler(w http.ResponseWriter, r *http.Request) {
     var data map[string]interface{}
     if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-        http.Error(w, "Invalid JSON", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
         return
     }
     
     log.Printf("[VK Researcher] Creating author: %+v", data)
     
-    // Преобразуем данные от VK
+    // Преобразуем данные от VK: автор с ID страницы ищется по нему, а не по имени
     transformedData := map[string]interface{}{
         "name": data["name"],
     }
+    for _, key := range []string{"external_id", "profile_url", "platform_reputation"} {
+        if value, ok := data[key]; ok {
+            transformedData[key] = value
+        }
+    }
+    if id, ok := data["external_id"]; ok && id != nil {
+        transformedData["platform"] = "vk"
+    }
     
-    // Используем обычный обработчик
-    h.createHandlerInternal(w, r, "authors", transformedData)
+    h.ensureAuthor(w, r, transformedData)
 }
 
 func (h *Handlers) createVKCommentHandler(w http.ResponseWriter, r *http.Request) {
     var data map[string]interface{}
     if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-        http.Error(w, "Invalid JSON", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
         return
     }
     
@@ -320,6 +398,13 @@ authorID := 1 // Дефолтный автор ID
         transformed["tags"] = interfaceTags
     }
     
+    // Сигналы для фильтра контента
+    if ads, ok := vkData["marked_as_ads"].(bool); ok {
+        transformed["marked_as_ads"] = ads
+    } else if ads, ok := vkData["marked_as_ads"].(float64); ok {
+        transformed["marked_as_ads"] = ads != 0
+    }
+    
     // Created at (VK использует timestamp, а сервер - datetime string)
     if createdAt, ok := vkData["created_at"].(string); ok {
         transformed["created_at"] = createdAt
@@ -338,12 +423,17 @@ authorID := 1 // Дефолтный автор ID
 // Внутренний обработчик создания (без проверки дубликатов для VK)
 func (h *Handlers) createHandlerInternal(w http.ResponseWriter, r *http.Request, table string, data map[string]interface{}) {
     if !validTables[table] {
-        http.Error(w, "Table not found", http.StatusNotFound)
+        writeError(w, r, http.StatusNotFound, errCodeNotFound, "Table not found")
         return
     }
 
     if len(data) == 0 {
-        http.Error(w, "No fields provided", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "No fields provided")
+        return
+    }
+
+    if fieldErrors := validateBody(tableSchemas[table], data, false); len(fieldErrors) > 0 {
+        writeValidationError(w, r, fieldErrors)
         return
     }
 
@@ -366,14 +456,14 @@ func (h *Handlers) createHandlerInternal(w http.ResponseWriter, r *http.Request,
     ctx := r.Context()
     conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
     if err != nil {
-        http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+        writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
         return
     }
     defer conn.Release()
 
     tx, err := conn.Begin(ctx)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to begin transaction", err)
         return
     }
     defer tx.Rollback(ctx)
@@ -382,7 +472,7 @@ func (h *Handlers) createHandlerInternal(w http.ResponseWriter, r *http.Request,
     rows, err := tx.Query(ctx, query, values...)
     if err != nil {
         log.Printf("[VK Researcher] Error executing query for table %s: %v", table, err)
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to create item", err)
         return
     }
     defer rows.Close()
@@ -398,7 +488,7 @@ func (h *Handlers) createHandlerInternal(w http.ResponseWriter, r *http.Request,
         }
 
         if err := rows.Scan(valuePtrs...); err != nil {
-            http.Error(w, err.Error(), http.StatusInternalServerError)
+            writeDBError(w, r, "Failed to read created item", err)
             return
         }
 
@@ -410,7 +500,7 @@ func (h *Handlers) createHandlerInternal(w http.ResponseWriter, r *http.Request,
     rows.Close()
 
     if err := tx.Commit(ctx); err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to commit transaction", err)
         return
     }
 
@@ -462,13 +552,19 @@ func (h *Handlers) createHandler(w http.ResponseWriter, r *http.Request) {
 	table := vars["table"]
 
 	if !validTables[table] {
-		http.Error(w, "Table not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Table not found")
+		return
+	}
+
+	schema, writable := tableSchemas[table]
+	if !writable {
+		writeError(w, r, http.StatusMethodNotAllowed, errCodeReadOnly, "Table is read-only")
 		return
 	}
 
 	var data map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
 		return
 	}
 
@@ -478,23 +574,13 @@ func (h *Handlers) createHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Проверка дубликатов для posts (для обычных таблиц)
-	if table == "posts" && data["content"] != nil {
-		if title, ok := data["title"].(string); ok {
-			if content, ok := data["content"].(string); ok {
-				hash := fmt.Sprintf("%x", sha256.Sum256([]byte(title+content)))
-				ctx := r.Context()
-				isDup, _ := h.mongo.IsDuplicateContent(ctx, hash)
-				if isDup {
-					http.Error(w, "Duplicate post detected", http.StatusConflict)
-					return
-				}
-			}
-		}
+	if len(data) == 0 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "No fields provided")
+		return
 	}
 
-	if len(data) == 0 {
-		http.Error(w, "No fields provided", http.StatusBadRequest)
+	if fieldErrors := validateBody(schema, data, false); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
 		return
 	}
 
@@ -517,14 +603,14 @@ func (h *Handlers) createHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
 	if err != nil {
-		http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
 		return
 	}
 	defer conn.Release()
 
 	tx, err := conn.Begin(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to begin transaction", err)
 		return
 	}
 	defer tx.Rollback(ctx)
@@ -532,7 +618,7 @@ func (h *Handlers) createHandler(w http.ResponseWriter, r *http.Request) {
 	// Используем Query вместо QueryRow для получения FieldDescriptions
 	rows, err := tx.Query(ctx, query, values...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to create item", err)
 		return
 	}
 	defer rows.Close()
@@ -548,7 +634,7 @@ func (h *Handlers) createHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if err := rows.Scan(valuePtrs...); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeDBError(w, r, "Failed to read created item", err)
 			return
 		}
 
@@ -560,7 +646,7 @@ func (h *Handlers) createHandler(w http.ResponseWriter, r *http.Request) {
 	rows.Close()
 
 	if err := tx.Commit(ctx); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to commit transaction", err)
 		return
 	}
 
@@ -590,73 +676,94 @@ func (h *Handlers) createHandler(w http.ResponseWriter, r *http.Request) {
 func (h *Handlers) createPostHandler(w http.ResponseWriter, r *http.Request, data map[string]interface{}) {
     ctx := r.Context()
     
-    // Проверка обязательных полей
-    title, hasTitle := data["title"].(string)
-    content, hasContent := data["content"].(string)
-    if !hasTitle || !hasContent {
-        http.Error(w, "Title and content are required", http.StatusBadRequest)
+    // Проверка тела запроса по схеме posts (с сигналами для фильтра контента)
+    if fieldErrors := validateBody(postCreateSchema, data, false); len(fieldErrors) > 0 {
+        writeValidationError(w, r, fieldErrors)
         return
     }
+    title := data["title"].(string)
+    content := data["content"].(string)
     
-    // Проверка дубликатов (только для обычных запросов, не для VK)
+    // author_id и channel_id уже проверены схемой (float64 из JSON или int от VK)
+    authorID, _ := toFloat(data["author_id"])
+    channelID, _ := toFloat(data["channel_id"])
+
+    conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+    if err != nil {
+        writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+        return
+    }
+    defer conn.Release()
+
+    // Проверка точных копий (только для обычных запросов, не для VK);
+    // нечёткие дубликаты не отклоняются, а попадают в сюжет оригинала
+    fingerprint := dedup.Compute(title, content)
     if !strings.Contains(r.URL.Path, "/vk/") {
-        hash := fmt.Sprintf("%x", sha256.Sum256([]byte(title+content)))
-        isDup, _ := h.mongo.IsDuplicateContent(ctx, hash)
-        if isDup {
-            http.Error(w, "Duplicate post detected", http.StatusConflict)
+        dupID, err := findExactDuplicate(ctx, conn, fingerprint)
+        if err != nil {
+            writeDBError(w, r, "Failed to check duplicates", err)
             return
         }
-    }
-    
-    authorID, hasAuthor := data["author_id"].(float64)
-    if !hasAuthor {
-        // Пробуем получить как int
-        if authorInt, ok := data["author_id"].(int); ok {
-            authorID = float64(authorInt)
-            hasAuthor = true
-        }
-    }
-    
-    channelID, hasChannel := data["channel_id"].(float64)
-    if !hasChannel {
-        // Пробуем получить как int
-        if channelInt, ok := data["channel_id"].(int); ok {
-            channelID = float64(channelInt)
-            hasChannel = true
+        if dupID > 0 {
+            writeError(w, r, http.StatusConflict, errCodeDuplicatePost, fmt.Sprintf("Duplicate of post %d", dupID))
+            return
         }
     }
-    
-    if !hasAuthor || !hasChannel {
-        http.Error(w, "Author ID and Channel ID are required", http.StatusBadRequest)
+
+    // Фильтр контента: спам, реклама, блок-листы темы, репутация автора
+    decision, err := h.filterPost(ctx, conn, data)
+    if err != nil {
+        writeDBError(w, r, "Failed to run content filter", err)
         return
     }
 
-    conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+    // Классификатор категорий; его ошибка не мешает сохранить пост
+    model, err := h.loadClassifier(ctx, conn)
     if err != nil {
-        http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
-        return
+        log.Printf("[%s] Failed to load classifier: %v", requestID(r), err)
     }
-    defer conn.Release()
 
     tx, err := conn.Begin(ctx)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to begin transaction", err)
         return
     }
     defer tx.Rollback(ctx)
 
+    if decision.Action == filter.Reject {
+        if err := recordFilterDecision(ctx, tx, 0, data, decision); err != nil {
+            writeDBError(w, r, "Failed to record filter decision", err)
+            return
+        }
+        if err := tx.Commit(ctx); err != nil {
+            writeDBError(w, r, "Failed to commit transaction", err)
+            return
+        }
+        log.Printf("[%s] Post %q rejected by content filter: %s", requestID(r), title, decision.Summary())
+        writeError(w, r, http.StatusUnprocessableEntity, errCodePostRejected, "Post rejected by content filter: "+decision.Summary())
+        return
+    }
+
     // 1. Вставляем контент в news_texts - ИСПРАВЛЕНО: используем колонку "text" вместо "content"
     textQuery := "INSERT INTO news_texts (text) VALUES ($1) RETURNING text_id"
     var textID int32
     err = tx.QueryRow(ctx, textQuery, content).Scan(&textID)
     if err != nil {
-        http.Error(w, "Failed to insert content: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to insert content", err)
         return
     }
 
     // 2. Вставляем пост в posts
-    postQuery := `INSERT INTO posts (title, author_id, text_id, channel_id, comments_count, likes_count, created_at) 
-                  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING post_id, title, author_id, text_id, channel_id, comments_count, likes_count, created_at`
+    postQuery := `INSERT INTO posts (title, author_id, text_id, channel_id, comments_count, likes_count, created_at, post_status, language, nsfw, metadata) 
+                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING post_id, title, author_id, text_id, channel_id, comments_count, likes_count, created_at`
+    
+    // Статус: карантин по решению фильтра, pending при премодерации
+    status := postStatusApproved
+    if decision.Action == filter.Quarantine {
+        status = postStatusQuarantined
+    } else if h.premoderation {
+        status = postStatusPending
+    }
     
     commentsCount := 0
     likesCount := 0
@@ -670,16 +777,28 @@ func (h *Handlers) createPostHandler(w http.ResponseWriter, r *http.Request, dat
     } else if lc, ok := data["likes_count"].(int); ok {
         likesCount = lc
     }
+    // Просмотры на платформе (Telegram) - только в статистике MongoDB
+    viewsCount, _ := toFloat(data["views_count"])
+
+    // Метка 18+ и поля платформы (флейр, доля голосов "за", награды Reddit)
+    nsfw, _ := data["nsfw"].(bool)
+    metadata, _ := data["metadata"].(map[string]interface{})
+    if metadata == nil {
+        metadata = map[string]interface{}{}
+    }
     
     // Определяем дату создания
     createdAt := time.Now()
     if ca, ok := data["created_at"].(string); ok {
-        // Пробуем распарсить строку даты
-        if parsedTime, err := time.Parse("2006-01-02 15:04:05", ca); err == nil {
+        // Пробуем распарсить строку даты (формат researcher-ов или RFC3339)
+        if parsedTime, err := parseTimestamp(ca); err == nil {
             createdAt = parsedTime
         }
     }
     
+    // Язык текста: стемминг в MongoDB и фильтр lang=
+    language := langdetect.Detect(title + "\n" + content).Lang
+
     var postID int32
     var resultTitle string
     var resultAuthorID int32
@@ -697,6 +816,10 @@ func (h *Handlers) createPostHandler(w http.ResponseWriter, r *http.Request, dat
         commentsCount, 
         likesCount, 
         createdAt,
+        status,
+        language,
+        nsfw,
+        metadata,
     ).Scan(
         &postID,
         &resultTitle,
@@ -709,55 +832,71 @@ func (h *Handlers) createPostHandler(w http.ResponseWriter, r *http.Request, dat
     )
     
     if err != nil {
-        http.Error(w, "Failed to insert post: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to insert post", err)
         return
     }
 
-    // 3. Обработка тегов
-    if tags, ok := data["tags"].([]interface{}); ok && len(tags) > 0 {
-        for _, tagValue := range tags {
-            if tagName, ok := tagValue.(string); ok && tagName != "" {
-                // Проверяем существует ли тег, если нет - создаем
-                var tagID int32
-                tagCheckQuery := "SELECT tag_id FROM tags WHERE name = $1"
-                err := tx.QueryRow(ctx, tagCheckQuery, tagName).Scan(&tagID)
-                
-                if err != nil {
-                    // Тег не существует, создаем новый
-                    createTagQuery := "INSERT INTO tags (name) VALUES ($1) RETURNING tag_id"
-                    err = tx.QueryRow(ctx, createTagQuery, tagName).Scan(&tagID)
-                    if err != nil {
-                        log.Printf("Failed to create tag %s: %v", tagName, err)
-                        continue
-                    }
-                }
-                
-                // Связываем тег с постом
-                linkTagQuery := "INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING"
-                _, err = tx.Exec(ctx, linkTagQuery, postID, tagID)
-                if err != nil {
-                    log.Printf("Failed to link tag %s to post %d: %v", tagName, postID, err)
-                }
-            }
+    // 3. Обработка тегов: варианты ("#Новости", "новостей") сводятся к каноническому тегу
+    tags := []string{}
+    if t, ok := data["tags"].([]interface{}); ok && len(t) > 0 {
+        tags = linkPostTags(ctx, tx, int(postID), t)
+    }
+
+    // Ключевые фразы и сущности: дополняют теги для поиска, трендов и сюжетов
+    terms := keywords.Extract(title, content)
+    if err := savePostKeywords(ctx, tx, int(postID), terms); err != nil {
+        writeDBError(w, r, "Failed to save post keywords", err)
+        return
+    }
+
+    if err := recordFilterDecision(ctx, tx, postID, data, decision); err != nil {
+        writeDBError(w, r, "Failed to record filter decision", err)
+        return
+    }
+
+    // Сюжет: пост присоединяется к ближайшему дубликату с любой платформы
+    story, err := assignStory(ctx, tx, int(postID), title, fingerprint)
+    if err != nil {
+        writeDBError(w, r, "Failed to save post fingerprint", err)
+        return
+    }
+
+    // Категории поста по словарям и обученной модели
+    categories := []classifier.Assignment{}
+    if model != nil {
+        if assigned := model.Classify(title + "\n" + content); assigned != nil {
+            categories = assigned
+        }
+        if err := categorizePost(ctx, tx, int(postID), categories); err != nil {
+            writeDBError(w, r, "Failed to save post categories", err)
+            return
         }
     }
 
     if err := tx.Commit(ctx); err != nil {
-        http.Error(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to commit transaction", err)
         return
     }
 
-    // 4. Индексация в MongoDB
-    tags := []string{}
-    if t, ok := data["tags"].([]interface{}); ok {
-        for _, tag := range t {
-            if tagStr, ok := tag.(string); ok {
-                tags = append(tags, tagStr)
+    // 4. Индексация в MongoDB (только одобренные посты)
+    if decision.Action == filter.Quarantine {
+        log.Printf("[%s] Post %d quarantined by content filter: %s", requestID(r), postID, decision.Summary())
+    }
+    if status == postStatusApproved {
+        go func() {
+            ctx := context.Background()
+            if err := h.mongo.IndexPost(ctx, int(postID), title, content, tags); err != nil {
+                return
             }
-        }
+            h.mongo.SetEngagementStats(ctx, int(postID), likesCount, commentsCount)
+            if viewsCount > 0 {
+                h.mongo.SetViewCount(ctx, int(postID), int(viewsCount))
+            }
+            if ratio, awards, ok := platformVotes(metadata); ok {
+                h.mongo.SetVoteStats(ctx, int(postID), ratio, awards)
+            }
+        }()
     }
-    
-    go h.mongo.IndexPost(context.Background(), int(postID), title, content, tags)
 
     // 5. Подготовка ответа
     result := map[string]interface{}{
@@ -771,6 +910,15 @@ func (h *Handlers) createPostHandler(w http.ResponseWriter, r *http.Request, dat
         "created_at":     resultCreatedAt.Format("2006-01-02 15:04:05"),
         "content":        content,
         "tags":           tags,
+        "post_status":    status,
+        "language":       language,
+        "nsfw":           nsfw,
+        "metadata":       metadata,
+        "keywords":       terms,
+        "filter":         decision,
+        "categories":     categories,
+        "story_id":       story.StoryID,
+        "duplicate_of":   story.DuplicateOf,
     }
 
     // 6. Инвалидация кеша
@@ -780,12 +928,23 @@ func (h *Handlers) createPostHandler(w http.ResponseWriter, r *http.Request, dat
     json.NewEncoder(w).Encode(result)
 }
 
+// platformVotes - доля голосов "за" и награды из metadata поста (Reddit);
+// ok - доля передана
+func platformVotes(metadata map[string]interface{}) (float64, int, bool) {
+	ratio, ok := toFloat(metadata["upvote_ratio"])
+	if !ok || ratio < 0 || ratio > 1 {
+		return 0, 0, false
+	}
+	awards, _ := toFloat(metadata["awards"])
+	return ratio, int(max(awards, 0)), true
+}
+
 func (h *Handlers) readAllHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	table := vars["table"]
 
 	if !validTables[table] {
-		http.Error(w, "Table not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Table not found")
 		return
 	}
 
@@ -808,7 +967,7 @@ func (h *Handlers) readAllHandler(w http.ResponseWriter, r *http.Request) {
 	// Чтение из реплики
 	conn, err := h.pool.Acquire(ctx, true)
 	if err != nil {
-		http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
 		return
 	}
 	defer conn.Release()
@@ -816,7 +975,7 @@ func (h *Handlers) readAllHandler(w http.ResponseWriter, r *http.Request) {
 	query := fmt.Sprintf("SELECT * FROM %s", table)
 	rows, err := conn.Query(ctx, query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to read data", err)
 		return
 	}
 	defer rows.Close()
@@ -830,10 +989,21 @@ func (h *Handlers) readAllHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-// readAllPostsHandler - специальный обработчик для чтения всех постов
+// readAllPostsHandler - специальный обработчик для чтения всех постов.
+// lang= оставляет посты одного языка.
 func (h *Handlers) readAllPostsHandler(w http.ResponseWriter, r *http.Request) {
+    var fieldErrors []FieldError
+    lang := queryLang(r, &fieldErrors)
+    if len(fieldErrors) > 0 {
+        writeValidationError(w, r, fieldErrors)
+        return
+    }
+
     ctx := r.Context()
     cacheKey := "cache:posts:full"
+    if lang != "" {
+        cacheKey += ":lang:" + lang
+    }
 
     // Проверка кеша
     if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
@@ -844,7 +1014,7 @@ func (h *Handlers) readAllPostsHandler(w http.ResponseWriter, r *http.Request) {
 
     conn, err := h.pool.Acquire(ctx, true)
     if err != nil {
-        http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+        writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
         return
     }
     defer conn.Release()
@@ -863,6 +1033,9 @@ func (h *Handlers) readAllPostsHandler(w http.ResponseWriter, r *http.Request) {
             p.comments_count,
             p.likes_count,
             p.created_at,
+            p.language,
+            p.nsfw,
+            p.metadata,
             COALESCE(
                 ARRAY_AGG(t.name) FILTER (WHERE t.name IS NOT NULL), 
                 '{}'::text[]
@@ -873,13 +1046,14 @@ func (h *Handlers) readAllPostsHandler(w http.ResponseWriter, r *http.Request) {
         LEFT JOIN channels c ON p.channel_id = c.channel_id
         LEFT JOIN post_tags pt ON p.post_id = pt.post_id
         LEFT JOIN tags t ON pt.tag_id = t.tag_id
+        WHERE p.post_status = 'approved' AND ($1 = '' OR p.language = $1)
         GROUP BY p.post_id, a.name, nt.text, c.name
         ORDER BY p.created_at DESC
     `
 
-    rows, err := conn.Query(ctx, query)
+    rows, err := conn.Query(ctx, query, lang)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to read posts", err)
         return
     }
     defer rows.Close()
@@ -887,7 +1061,12 @@ func (h *Handlers) readAllPostsHandler(w http.ResponseWriter, r *http.Request) {
     results := h.rowsToJSON(rows)
 
     data, _ := json.Marshal(results)
-    h.cache.SetEX(ctx, cacheKey, string(data), 300) // TTL 5 минут
+    // Ключи с lang= не сбрасываются при записи постов, поэтому живут меньше
+    ttl := 300
+    if lang != "" {
+        ttl = 60
+    }
+    h.cache.SetEX(ctx, cacheKey, string(data), ttl)
 
     w.Header().Set("Content-Type", "application/json")
     w.Write(data)
@@ -900,7 +1079,7 @@ func (h *Handlers) readOneHandler(w http.ResponseWriter, r *http.Request) {
 	id2 := vars["id2"]
 
 	if !validTables[table] {
-		http.Error(w, "Table not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Table not found")
 		return
 	}
 
@@ -917,7 +1096,7 @@ func (h *Handlers) readOneHandler(w http.ResponseWriter, r *http.Request) {
 
 		conn, err := h.pool.Acquire(ctx, true)
 		if err != nil {
-			http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+			writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
 			return
 		}
 	defer conn.Release()
@@ -925,7 +1104,7 @@ func (h *Handlers) readOneHandler(w http.ResponseWriter, r *http.Request) {
 		query := fmt.Sprintf("SELECT * FROM %s WHERE post_id=$1 AND tag_id=$2", table)
 		rows, err := conn.Query(ctx, query, id, id2)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeDBError(w, r, "Failed to read data", err)
 			return
 		}
 		defer rows.Close()
@@ -948,7 +1127,7 @@ func (h *Handlers) readOneHandler(w http.ResponseWriter, r *http.Request) {
 	// Обычное чтение по PK
 	pk, ok := pkMap[table]
 	if !ok {
-		http.Error(w, "Table has no simple PK", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Table has no simple PK")
 		return
 	}
 
@@ -961,7 +1140,7 @@ func (h *Handlers) readOneHandler(w http.ResponseWriter, r *http.Request) {
 
 	conn, err := h.pool.Acquire(ctx, true)
 	if err != nil {
-		http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
 		return
 	}
 	defer conn.Release()
@@ -969,7 +1148,7 @@ func (h *Handlers) readOneHandler(w http.ResponseWriter, r *http.Request) {
 	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = $1", table, pk)
 	rows, err := conn.Query(ctx, query, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to read data", err)
 		return
 	}
 	defer rows.Close()
@@ -996,7 +1175,7 @@ func (h *Handlers) readOnePostHandler(w http.ResponseWriter, r *http.Request, id
 
     conn, err := h.pool.Acquire(ctx, true)
     if err != nil {
-        http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+        writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
         return
     }
     defer conn.Release()
@@ -1015,6 +1194,9 @@ func (h *Handlers) readOnePostHandler(w http.ResponseWriter, r *http.Request, id
             p.comments_count,
             p.likes_count,
             p.created_at,
+            p.language,
+            p.nsfw,
+            p.metadata,
             COALESCE(
                 ARRAY_AGG(t.name) FILTER (WHERE t.name IS NOT NULL), 
                 '{}'::text[]
@@ -1025,13 +1207,13 @@ func (h *Handlers) readOnePostHandler(w http.ResponseWriter, r *http.Request, id
         LEFT JOIN channels c ON p.channel_id = c.channel_id
         LEFT JOIN post_tags pt ON p.post_id = pt.post_id
         LEFT JOIN tags t ON pt.tag_id = t.tag_id
-        WHERE p.post_id = $1
+        WHERE p.post_id = $1 AND p.post_status = 'approved'
         GROUP BY p.post_id, a.name, nt.text, c.name
     `
 
     rows, err := conn.Query(ctx, query, id)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to read post", err)
         return
     }
     defer rows.Close()
@@ -1039,7 +1221,7 @@ func (h *Handlers) readOnePostHandler(w http.ResponseWriter, r *http.Request, id
     results := h.rowsToJSON(rows)
     
     if len(results) == 0 {
-        http.Error(w, "Post not found", http.StatusNotFound)
+        writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
         return
     }
 
@@ -1056,7 +1238,7 @@ func (h *Handlers) updateHandler(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	if !validTables[table] {
-		http.Error(w, "Table not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Table not found")
 		return
 	}
 
@@ -1068,18 +1250,29 @@ func (h *Handlers) updateHandler(w http.ResponseWriter, r *http.Request) {
 
 	pk, ok := pkMap[table]
 	if !ok {
-		http.Error(w, "Table has no simple PK", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Table has no simple PK")
+		return
+	}
+
+	schema, writable := tableSchemas[table]
+	if !writable {
+		writeError(w, r, http.StatusMethodNotAllowed, errCodeReadOnly, "Table is read-only")
 		return
 	}
 
 	var data map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
 		return
 	}
 
 	if len(data) == 0 {
-		http.Error(w, "No fields provided", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "No fields provided")
+		return
+	}
+
+	if fieldErrors := validateBody(schema, data, true); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
 		return
 	}
 
@@ -1093,6 +1286,10 @@ func (h *Handlers) updateHandler(w http.ResponseWriter, r *http.Request) {
 		values = append(values, value)
 		i++
 	}
+	// Профиль автора обновляется ресерчерами - фиксируем время обновления
+	if table == "authors" {
+		sets = append(sets, "updated_at = NOW()")
+	}
 	values = append(values, id)
 
 	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d",
@@ -1101,44 +1298,23 @@ func (h *Handlers) updateHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
 	if err != nil {
-		http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
 		return
 	}
 	defer conn.Release()
 
 	if err := conn.Exec(ctx, query, values...); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to update item", err)
 		return
 	}
 
-	// Обновление в MongoDB для posts
-	if table == "posts" {
-		postID, _ := strconv.Atoi(id)
-		title := ""
-		content := ""
-		tags := []string{}
-
-		if t, ok := data["title"].(string); ok {
-			title = t
-		}
-		if c, ok := data["content"].(string); ok {
-			content = c
-		}
-		if t, ok := data["tags"].([]interface{}); ok {
-			for _, tag := range t {
-				tags = append(tags, tag.(string))
-			}
-		}
-
-		if title != "" || content != "" || len(tags) > 0 {
-			go h.mongo.UpdatePostIndex(context.Background(), postID, title, content, tags)
-		}
-	}
-
 	// Инвалидация кеша
 	h.cache.Del(ctx, "cache:"+table, "cache:"+table+":"+id)
 
-	w.Write([]byte("Item updated\n"))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Item updated",
+		"id":      id,
+	})
 }
 
 // updatePostHandler - специальный обработчик для обновления постов
@@ -1146,31 +1322,36 @@ func (h *Handlers) updatePostHandler(w http.ResponseWriter, r *http.Request, id
     ctx := r.Context()
     postID, err := strconv.Atoi(id)
     if err != nil {
-        http.Error(w, "Invalid post ID", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
         return
     }
 
     var data map[string]interface{}
     if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-        http.Error(w, "Invalid JSON", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
         return
     }
 
     if len(data) == 0 {
-        http.Error(w, "No fields provided", http.StatusBadRequest)
+        writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "No fields provided")
+        return
+    }
+
+    if fieldErrors := validateBody(tableSchemas["posts"], data, true); len(fieldErrors) > 0 {
+        writeValidationError(w, r, fieldErrors)
         return
     }
 
     conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
     if err != nil {
-        http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+        writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
         return
     }
     defer conn.Release()
 
     tx, err := conn.Begin(ctx)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to begin transaction", err)
         return
     }
     defer tx.Rollback(ctx)
@@ -1179,7 +1360,7 @@ func (h *Handlers) updatePostHandler(w http.ResponseWriter, r *http.Request, id
     var currentTextID int32
     err = tx.QueryRow(ctx, "SELECT text_id FROM posts WHERE post_id = $1", postID).Scan(&currentTextID)
     if err != nil {
-        http.Error(w, "Post not found", http.StatusNotFound)
+        writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
         return
     }
 
@@ -1193,7 +1374,7 @@ func (h *Handlers) updatePostHandler(w http.ResponseWriter, r *http.Request, id
         updateContentQuery := "UPDATE news_texts SET text = $1 WHERE text_id = $2"
         _, err = tx.Exec(ctx, updateContentQuery, content, currentTextID)
         if err != nil {
-            http.Error(w, "Failed to update content: "+err.Error(), http.StatusInternalServerError)
+            writeDBError(w, r, "Failed to update content", err)
             return
         }
         contentUpdated = true
@@ -1221,16 +1402,22 @@ func (h *Handlers) updatePostHandler(w http.ResponseWriter, r *http.Request, id
         }
     }
 
+    // Счётчики после обновления - для статистики в MongoDB
+    statsUpdated := false
+    likesCount, commentsCount := 0, 0
     if len(updates) > 0 {
         values = append(values, postID)
-        updatePostQuery := fmt.Sprintf("UPDATE posts SET %s WHERE post_id = $%d", 
+        updatePostQuery := fmt.Sprintf("UPDATE posts SET %s WHERE post_id = $%d RETURNING COALESCE(likes_count, 0), COALESCE(comments_count, 0)", 
             strings.Join(updates, ", "), paramCount)
         
-        _, err = tx.Exec(ctx, updatePostQuery, values...)
+        err = tx.QueryRow(ctx, updatePostQuery, values...).Scan(&likesCount, &commentsCount)
         if err != nil {
-            http.Error(w, "Failed to update post: "+err.Error(), http.StatusInternalServerError)
+            writeDBError(w, r, "Failed to update post", err)
             return
         }
+        _, likesUpdated := data["likes_count"]
+        _, commentsUpdated := data["comments_count"]
+        statsUpdated = likesUpdated || commentsUpdated
     }
 
     // 4. Обновляем теги если есть
@@ -1240,42 +1427,32 @@ func (h *Handlers) updatePostHandler(w http.ResponseWriter, r *http.Request, id
         deleteTagsQuery := "DELETE FROM post_tags WHERE post_id = $1"
         _, err = tx.Exec(ctx, deleteTagsQuery, postID)
         if err != nil {
-            http.Error(w, "Failed to clear old tags: "+err.Error(), http.StatusInternalServerError)
+            writeDBError(w, r, "Failed to clear old tags", err)
             return
         }
 
-        // Добавляем новые теги
-        for _, tagValue := range tags {
-            if tagName, ok := tagValue.(string); ok && tagName != "" {
-                newTags = append(newTags, tagName)
-                
-                // Проверяем существует ли тег
-                var tagID int32
-                tagCheckQuery := "SELECT tag_id FROM tags WHERE name = $1"
-                err := tx.QueryRow(ctx, tagCheckQuery, tagName).Scan(&tagID)
-                
-                if err != nil {
-                    // Тег не существует, создаем новый
-                    createTagQuery := "INSERT INTO tags (name) VALUES ($1) RETURNING tag_id"
-                    err = tx.QueryRow(ctx, createTagQuery, tagName).Scan(&tagID)
-                    if err != nil {
-                        log.Printf("Failed to create tag %s: %v", tagName, err)
-                        continue
-                    }
-                }
-                
-                // Связываем тег с постом
-                linkTagQuery := "INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2)"
-                _, err = tx.Exec(ctx, linkTagQuery, postID, tagID)
-                if err != nil {
-                    log.Printf("Failed to link tag %s to post %d: %v", tagName, postID, err)
-                }
-            }
+        // Добавляем новые теги (канонические)
+        newTags = linkPostTags(ctx, tx, postID, tags)
+    }
+
+    // Отпечатки для поиска дубликатов, язык и ключевые слова по новому тексту
+    if contentUpdated || newTitle != "" {
+        if err := refreshFingerprint(ctx, tx, postID); err != nil {
+            writeDBError(w, r, "Failed to update post fingerprint", err)
+            return
+        }
+        if _, err := refreshLanguage(ctx, tx, postID); err != nil {
+            writeDBError(w, r, "Failed to update post language", err)
+            return
+        }
+        if _, err := refreshKeywords(ctx, tx, postID); err != nil {
+            writeDBError(w, r, "Failed to update post keywords", err)
+            return
         }
     }
 
     if err := tx.Commit(ctx); err != nil {
-        http.Error(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to commit transaction", err)
         return
     }
 
@@ -1283,6 +1460,9 @@ func (h *Handlers) updatePostHandler(w http.ResponseWriter, r *http.Request, id
     if contentUpdated || newTitle != "" || len(newTags) > 0 {
         go h.mongo.UpdatePostIndex(context.Background(), postID, newTitle, newContent, newTags)
     }
+    if statsUpdated {
+        go h.mongo.SetEngagementStats(context.Background(), postID, likesCount, commentsCount)
+    }
 
     // 6. Инвалидация кеша
     h.cache.Del(ctx, 
@@ -1295,7 +1475,10 @@ func (h *Handlers) updatePostHandler(w http.ResponseWriter, r *http.Request, id
         "cache:post_tags",
     )
 
-    w.Write([]byte("Post updated successfully\n"))
+    writeJSON(w, http.StatusOK, map[string]interface{}{
+        "message": "Post updated successfully",
+        "post_id": postID,
+    })
 }
 
 func (h *Handlers) deleteHandler(w http.ResponseWriter, r *http.Request) {
@@ -1304,7 +1487,7 @@ func (h *Handlers) deleteHandler(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	if !validTables[table] {
-		http.Error(w, "Table not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Table not found")
 		return
 	}
 
@@ -1322,7 +1505,7 @@ func (h *Handlers) deleteHandler(w http.ResponseWriter, r *http.Request) {
 
 	pk, ok := pkMap[table]
 	if !ok {
-		http.Error(w, "Table has no simple PK", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Table has no simple PK")
 		return
 	}
 
@@ -1331,20 +1514,23 @@ func (h *Handlers) deleteHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
 	if err != nil {
-		http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
 		return
 	}
 	defer conn.Release()
 
 	if err := conn.Exec(ctx, query, id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to delete item", err)
 		return
 	}
 
 	// Инвалидация кеша
 	h.cache.Del(ctx, "cache:"+table, "cache:"+table+":"+id)
 
-	w.Write([]byte("Item deleted\n"))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Item deleted",
+		"id":      id,
+	})
 }
 
 // deletePostHandler - специальный обработчик для удаления постов
@@ -1352,20 +1538,20 @@ func (h *Handlers) deletePostHandler(w http.ResponseWriter, r *http.Request, id
 	ctx := r.Context()
 	postID, err := strconv.Atoi(id)
 	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
 		return
 	}
 
 	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
 	if err != nil {
-		http.Error(w, "Database temporarily unavailable", http.StatusServiceUnavailable)
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
 		return
 	}
 	defer conn.Release()
 
 	tx, err := conn.Begin(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to begin transaction", err)
 		return
 	}
 	defer tx.Rollback(ctx)
@@ -1374,7 +1560,7 @@ func (h *Handlers) deletePostHandler(w http.ResponseWriter, r *http.Request, id
 	var textID int32
 	err = tx.QueryRow(ctx, "SELECT text_id FROM posts WHERE post_id = $1", postID).Scan(&textID)
 	if err != nil {
-		http.Error(w, "Post not found", http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
 		return
 	}
 
@@ -1382,7 +1568,7 @@ func (h *Handlers) deletePostHandler(w http.ResponseWriter, r *http.Request, id
 	deleteTagsQuery := "DELETE FROM post_tags WHERE post_id = $1"
 	_, err = tx.Exec(ctx, deleteTagsQuery, postID)
 	if err != nil {
-		http.Error(w, "Failed to delete tag associations: "+err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to delete tag associations", err)
 		return
 	}
 
@@ -1390,7 +1576,7 @@ func (h *Handlers) deletePostHandler(w http.ResponseWriter, r *http.Request, id
 	deletePostQuery := "DELETE FROM posts WHERE post_id = $1"
 	_, err = tx.Exec(ctx, deletePostQuery, postID)
 	if err != nil {
-		http.Error(w, "Failed to delete post: "+err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to delete post", err)
 		return
 	}
 
@@ -1398,12 +1584,12 @@ func (h *Handlers) deletePostHandler(w http.ResponseWriter, r *http.Request, id
 	// deleteContentQuery := "DELETE FROM news_texts WHERE text_id = $1"
 	// _, err = tx.Exec(ctx, deleteContentQuery, textID)
 	// if err != nil {
-	//     http.Error(w, "Failed to delete content: "+err.Error(), http.StatusInternalServerError)
+	//     writeDBError(w, r, "Failed to delete content", err)
 	//     return
 	// }
 
 	if err := tx.Commit(ctx); err != nil {
-		http.Error(w, "Failed to commit transaction: "+err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to commit transaction", err)
 		return
 	}
 
@@ -1421,7 +1607,10 @@ func (h *Handlers) deletePostHandler(w http.ResponseWriter, r *http.Request, id
 		"cache:post_tags",
 	)
 
-	w.Write([]byte("Post deleted successfully\n"))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Post deleted successfully",
+		"post_id": postID,
+	})
 }
 
 // ============ MONGODB HANDLERS ============
@@ -1429,7 +1618,20 @@ func (h *Handlers) deletePostHandler(w http.ResponseWriter, r *http.Request, id
 func (h *Handlers) advancedSearchHandler(w http.ResponseWriter, r *http.Request) {
 	var filters map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	fieldErrors := validateBody(advancedSearchSchema, filters, true)
+	// Язык - в теле или в query, как у ленты
+	if lang := queryLang(r, &fieldErrors); lang != "" && filters["lang"] == nil {
+		filters["lang"] = lang
+	}
+	if lang, ok := filters["lang"].(string); ok && !langdetect.Supported(lang) {
+		fieldErrors = append(fieldErrors, FieldError{Field: "lang", Message: "must be one of: " + strings.Join(langdetect.Languages, ", ")})
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
 		return
 	}
 
@@ -1444,7 +1646,7 @@ func (h *Handlers) advancedSearchHandler(w http.ResponseWriter, r *http.Request)
 
 	results, err := h.mongo.AdvancedSearch(ctx, filters, 20)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Search failed", err)
 		return
 	}
 
@@ -1472,7 +1674,7 @@ func (h *Handlers) topTagsHandler(w http.ResponseWriter, r *http.Request) {
 
 	results, err := h.mongo.GetTopTags(ctx, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to get top tags", err)
 		return
 	}
 
@@ -1500,7 +1702,7 @@ func (h *Handlers) engagementAnalysisHandler(w http.ResponseWriter, r *http.Requ
 
 	results, err := h.mongo.GetPostEngagementAnalysis(ctx, days)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to get engagement analysis", err)
 		return
 	}
 
@@ -1532,7 +1734,7 @@ func (h *Handlers) userHistoryHandler(w http.ResponseWriter, r *http.Request) {
 
     results, err := h.mongo.GetUserHistory(ctx, userID, limit)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeDBError(w, r, "Failed to get user history", err)
         return
     }
 
@@ -1567,7 +1769,7 @@ func (h *Handlers) topPostsViewHandler(w http.ResponseWriter, r *http.Request) {
 
 	results, err := h.mongo.GetTopPostsFromView(ctx, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to get top posts", err)
 		return
 	}
 
@@ -1580,48 +1782,95 @@ func (h *Handlers) topPostsViewHandler(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handlers) postOperationsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	postID, _ := strconv.Atoi(vars["post_id"])
+	postID, err := strconv.Atoi(vars["post_id"])
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+		return
+	}
 
 	var operations map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&operations); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
 		return
 	}
 
 	ctx := r.Context()
 	operationType, _ := operations["operation"].(string)
 
+	schema, ok := postOperationSchemas[operationType]
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Unknown operation type")
+		return
+	}
+	params := make(map[string]interface{}, len(operations))
+	for key, value := range operations {
+		if key != "operation" {
+			params[key] = value
+		}
+	}
+	if fieldErrors := validateBody(schema, params, false); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	// Теги в MongoDB совпадают с каноническими тегами PostgreSQL
+	if tag, ok := params["tag"].(string); ok {
+		conn, err := h.pool.Acquire(ctx, true)
+		if err != nil {
+			writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+			return
+		}
+		params["tag"], err = canonicalTagName(ctx, conn, tag)
+		conn.Release()
+		if err != nil {
+			writeDBError(w, r, "Failed to resolve tag", err)
+			return
+		}
+	}
+
 	switch operationType {
 	case "increment_views":
-		h.mongo.IncrementViewCount(ctx, postID)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Views incremented"})
+		err = h.mongo.IncrementViewCount(ctx, postID)
+		if err == nil {
+			writeJSON(w, http.StatusOK, map[string]string{"message": "Views incremented"})
+		}
 
 	case "add_tag":
-		tag, _ := operations["tag"].(string)
-		h.mongo.AddTagToPost(ctx, postID, tag)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Tag added"})
+		tag := params["tag"].(string)
+		err = h.mongo.AddTagToPost(ctx, postID, tag)
+		if err == nil {
+			writeJSON(w, http.StatusOK, map[string]string{"message": "Tag added"})
+		}
 
 	case "remove_tag":
-		tag, _ := operations["tag"].(string)
-		h.mongo.RemoveTagFromPost(ctx, postID, tag)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Tag removed"})
+		tag := params["tag"].(string)
+		err = h.mongo.RemoveTagFromPost(ctx, postID, tag)
+		if err == nil {
+			writeJSON(w, http.StatusOK, map[string]string{"message": "Tag removed"})
+		}
 
 	case "update_stats":
-		likesDelta := int(operations["likes_delta"].(float64))
-		commentsDelta := int(operations["comments_delta"].(float64))
-		h.mongo.UpdatePostStats(ctx, postID, likesDelta, commentsDelta)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Stats updated"})
+		likesDelta, _ := toFloat(params["likes_delta"])
+		commentsDelta, _ := toFloat(params["comments_delta"])
+		err = h.mongo.UpdatePostStats(ctx, postID, int(likesDelta), int(commentsDelta))
+		if err == nil {
+			writeJSON(w, http.StatusOK, map[string]string{"message": "Stats updated"})
+		}
 
 	case "upsert":
-		data := operations["data"].(map[string]interface{})
-		wasInserted, _ := h.mongo.UpsertPost(ctx, postID, data)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message":      map[bool]string{true: "Post created", false: "Post updated"}[wasInserted],
-			"was_inserted": wasInserted,
-		})
-
-	default:
-		http.Error(w, "Unknown operation type", http.StatusBadRequest)
+		data := params["data"].(map[string]interface{})
+		var wasInserted bool
+		wasInserted, err = h.mongo.UpsertPost(ctx, postID, data)
+		if err == nil {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"message":      map[bool]string{true: "Post created", false: "Post updated"}[wasInserted],
+				"was_inserted": wasInserted,
+			})
+		}
+	}
+
+	if err != nil {
+		writeDBError(w, r, "Operation failed", err)
 		return
 	}
 
@@ -1641,7 +1890,7 @@ func (h *Handlers) channelPerformanceHandler(w http.ResponseWriter, r *http.Requ
 
 	results, err := h.mongo.GetChannelPerformance(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to get channel performance", err)
 		return
 	}
 
@@ -1656,7 +1905,7 @@ func (h *Handlers) materializeViewHandler(w http.ResponseWriter, r *http.Request
 	ctx := r.Context()
 
 	if err := h.mongo.MaterializeTopPostsView(ctx); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeDBError(w, r, "Failed to materialize view", err)
 		return
 	}
 
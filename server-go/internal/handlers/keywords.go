@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"news-aggregator/internal/keywords"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// setupKeywordRoutes регистрирует извлечение ключевых слов старых постов на роутере с префиксом /admin
+func (h *Handlers) setupKeywordRoutes(r *mux.Router) {
+	r.HandleFunc("/keywords/backfill", h.backfillKeywordsHandler).Methods("POST")
+}
+
+// savePostKeywords заменяет ключевые фразы и сущности поста
+func savePostKeywords(ctx context.Context, tx pgx.Tx, postID int, terms []keywords.Term) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM post_keywords WHERE post_id = $1", postID); err != nil {
+		return err
+	}
+	for _, t := range terms {
+		term := t.Term
+		if r := []rune(term); len(r) > 100 {
+			term = string(r[:100])
+		}
+		_, err := tx.Exec(ctx, `
+            INSERT INTO post_keywords (post_id, term, kind, score) VALUES ($1, $2, $3, $4)
+            ON CONFLICT (post_id, kind, term) DO NOTHING`,
+			postID, term, t.Kind, t.Score)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshKeywords извлекает ключевые слова поста заново после правки заголовка или текста
+func refreshKeywords(ctx context.Context, tx pgx.Tx, postID int) ([]keywords.Term, error) {
+	var title, content string
+	err := tx.QueryRow(ctx, `
+        SELECT p.title, COALESCE(nt.text, '')
+        FROM posts p LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE p.post_id = $1`, postID).Scan(&title, &content)
+	if err != nil {
+		return nil, err
+	}
+	terms := keywords.Extract(title, content)
+	return terms, savePostKeywords(ctx, tx, postID, terms)
+}
+
+// postKeywordsHandler - GET /api/posts/{id}/keywords?kind=: ключевые фразы и сущности поста
+func (h *Handlers) postKeywordsHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || postID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	if kind != "" && !keywords.Supported(kind) {
+		writeValidationError(w, r, []FieldError{{Field: "kind", Message: "must be one of: " + strings.Join(keywords.Kinds, ", ")}})
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM posts WHERE post_id = $1)", postID).Scan(&exists); err != nil {
+		writeDBError(w, r, "Failed to read post", err)
+		return
+	}
+	if !exists {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+
+	// Сущности перед ключевыми фразами, как в ответе на создание поста
+	rows, err := conn.Query(ctx, `
+        SELECT term, kind, score::float8 FROM post_keywords
+        WHERE post_id = $1 AND ($2 = '' OR kind = $2)
+        ORDER BY kind = 'keyword', score DESC, term`, postID, kind)
+	if err != nil {
+		writeDBError(w, r, "Failed to read post keywords", err)
+		return
+	}
+	defer rows.Close()
+
+	terms := []keywords.Term{}
+	for rows.Next() {
+		var t keywords.Term
+		if err := rows.Scan(&t.Term, &t.Kind, &t.Score); err != nil {
+			writeDBError(w, r, "Failed to read post keywords", err)
+			return
+		}
+		terms = append(terms, t)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, "Failed to read post keywords", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, terms)
+}
+
+// backfillKeywordsHandler - POST /api/admin/keywords/backfill?after_id=0&limit=500:
+// ключевые слова постов, созданных до появления извлечения, в PostgreSQL и MongoDB.
+// Посты обходятся по возрастанию post_id; next_after_id передаётся в следующий вызов,
+// null - все посты обработаны.
+func (h *Handlers) backfillKeywordsHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	afterID := queryInt(r, "after_id", 0, 0, 1<<31-1, &fieldErrors)
+	limit := queryInt(r, "limit", 500, 1, 5000, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+        SELECT p.post_id, p.title, COALESCE(nt.text, '')
+        FROM posts p
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE p.post_id > $1
+        ORDER BY p.post_id
+        LIMIT $2`, afterID, limit)
+	if err != nil {
+		writeDBError(w, r, "Failed to read posts", err)
+		return
+	}
+	var postIDs []int
+	extracted := map[int][]keywords.Term{}
+	for rows.Next() {
+		var postID int
+		var title, content string
+		if err := rows.Scan(&postID, &title, &content); err != nil {
+			rows.Close()
+			writeDBError(w, r, "Failed to read posts", err)
+			return
+		}
+		postIDs = append(postIDs, postID)
+		extracted[postID] = keywords.Extract(title, content)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, "Failed to read posts", err)
+		return
+	}
+
+	counts := map[string]int{}
+	for _, postID := range postIDs {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			writeDBError(w, r, "Failed to begin transaction", err)
+			return
+		}
+		if err := savePostKeywords(ctx, tx, postID, extracted[postID]); err != nil {
+			tx.Rollback(ctx)
+			writeDBError(w, r, "Failed to save post keywords", err)
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			writeDBError(w, r, "Failed to commit transaction", err)
+			return
+		}
+		if err := h.mongo.SetPostKeywords(ctx, postID, extracted[postID]); err != nil {
+			log.Printf("[%s] Failed to set keywords of post %d in MongoDB: %v", requestID(r), postID, err)
+		}
+		for _, t := range extracted[postID] {
+			counts[t.Kind]++
+		}
+	}
+
+	var next *int
+	if len(postIDs) == limit {
+		next = &postIDs[len(postIDs)-1]
+	}
+	log.Printf("[%s] Extracted keywords of %d posts after %d: %v", requestID(r), len(postIDs), afterID, counts)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"processed":     len(postIDs),
+		"terms":         counts,
+		"next_after_id": next,
+	})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"news-aggregator/internal/langdetect"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// setupLanguageRoutes регистрирует определение языка старых постов на роутере с префиксом /admin
+func (h *Handlers) setupLanguageRoutes(r *mux.Router) {
+	r.HandleFunc("/languages/backfill", h.backfillLanguagesHandler).Methods("POST")
+}
+
+// queryLang читает фильтр lang= ленты и поиска; пустая строка - без фильтра
+func queryLang(r *http.Request, errs *[]FieldError) string {
+	lang := strings.ToLower(r.URL.Query().Get("lang"))
+	if lang != "" && !langdetect.Supported(lang) {
+		*errs = append(*errs, FieldError{Field: "lang", Message: "must be one of: " + strings.Join(langdetect.Languages, ", ")})
+		return ""
+	}
+	return lang
+}
+
+// refreshLanguage определяет язык поста по заголовку и тексту после их правки
+func refreshLanguage(ctx context.Context, tx pgx.Tx, postID int) (string, error) {
+	var title, content string
+	err := tx.QueryRow(ctx, `
+        SELECT p.title, COALESCE(nt.text, '')
+        FROM posts p LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE p.post_id = $1`, postID).Scan(&title, &content)
+	if err != nil {
+		return "", err
+	}
+	lang := langdetect.Detect(title + "\n" + content).Lang
+	_, err = tx.Exec(ctx, "UPDATE posts SET language = $2 WHERE post_id = $1", postID, lang)
+	return lang, err
+}
+
+// backfillLanguagesHandler - POST /api/admin/languages/backfill?limit=1000:
+// язык постов, созданных до появления определения языка, в PostgreSQL и MongoDB
+func (h *Handlers) backfillLanguagesHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	limit := queryInt(r, "limit", 1000, 1, 10000, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+        SELECT p.post_id, p.title, COALESCE(nt.text, '')
+        FROM posts p
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        WHERE p.language IS NULL
+        ORDER BY p.post_id
+        LIMIT $1`, limit)
+	if err != nil {
+		writeDBError(w, r, "Failed to read posts", err)
+		return
+	}
+	detected := map[int]string{}
+	for rows.Next() {
+		var postID int
+		var title, content string
+		if err := rows.Scan(&postID, &title, &content); err != nil {
+			rows.Close()
+			writeDBError(w, r, "Failed to read posts", err)
+			return
+		}
+		detected[postID] = langdetect.Detect(title + "\n" + content).Lang
+	}
+	rows.Close()
+
+	counts := map[string]int{}
+	for postID, lang := range detected {
+		if err := conn.Exec(ctx, "UPDATE posts SET language = $2 WHERE post_id = $1", postID, lang); err != nil {
+			writeDBError(w, r, "Failed to update post", err)
+			return
+		}
+		if err := h.mongo.SetPostLanguage(ctx, postID, lang); err != nil {
+			log.Printf("[%s] Failed to set language of post %d in MongoDB: %v", requestID(r), postID, err)
+		}
+		counts[lang]++
+	}
+
+	h.cache.Del(ctx, "cache:posts:full")
+	log.Printf("[%s] Detected language of %d posts: %v", requestID(r), len(detected), counts)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"processed": len(detected),
+		"languages": counts,
+	})
+}
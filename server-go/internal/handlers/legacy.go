@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// legacyUsage считает обращения к устаревшим маршрутам /api/{table},
+// чтобы было видно, какие клиенты ещё не перешли на /api/v1
+type legacyUsage struct {
+	mu       sync.Mutex
+	counts   map[string]int64
+	lastSeen map[string]time.Time
+}
+
+func newLegacyUsage() *legacyUsage {
+	return &legacyUsage{
+		counts:   make(map[string]int64),
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+func (u *legacyUsage) record(key string) int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.counts[key]++
+	u.lastSeen[key] = time.Now()
+	return u.counts[key]
+}
+
+type legacyUsageEntry struct {
+	Route    string `json:"route"`
+	Count    int64  `json:"count"`
+	LastSeen string `json:"last_seen"`
+}
+
+func (u *legacyUsage) snapshot() []legacyUsageEntry {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	entries := make([]legacyUsageEntry, 0, len(u.counts))
+	for key, count := range u.counts {
+		entries = append(entries, legacyUsageEntry{
+			Route:    key,
+			Count:    count,
+			LastSeen: u.lastSeen[key].Format(time.RFC3339),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Count > entries[j].Count })
+	return entries
+}
+
+// setupLegacyRoutes регистрирует старый табличный роутер /api/{table}
+func (h *Handlers) setupLegacyRoutes(r *mux.Router) {
+	// CRUD операции для PostgreSQL
+	r.HandleFunc("/api/{table}", h.legacy(h.createHandler)).Methods("POST")
+	r.HandleFunc("/api/{table}", h.legacy(h.readAllHandler)).Methods("GET")
+	r.HandleFunc("/api/{table}/{id}", h.legacy(h.readOneHandler)).Methods("GET")
+	r.HandleFunc("/api/{table}/{id}", h.legacy(h.updateHandler)).Methods("PUT")
+	r.HandleFunc("/api/{table}/{id}", h.legacy(h.deleteHandler)).Methods("DELETE")
+
+	// Обработка post_tags с двумя ID
+	r.HandleFunc("/api/{table}/{id}/{id2}", h.legacy(h.readOneHandler)).Methods("GET")
+	r.HandleFunc("/api/{table}/{id}/{id2}", h.legacy(h.updateHandler)).Methods("PUT")
+	r.HandleFunc("/api/{table}/{id}/{id2}", h.legacy(h.deleteHandler)).Methods("DELETE")
+}
+
+// legacy помечает ответ устаревшего маршрута заголовками Deprecation/Link
+// и логирует обращение
+func (h *Handlers) legacy(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		table := mux.Vars(r)["table"]
+		if table == "v1" {
+			// Неизвестный путь внутри /api/v1 не должен попадать в табличный роутер
+			writeError(w, r, http.StatusNotFound, errCodeNotFound, "Route not found")
+			return
+		}
+		route, _ := mux.CurrentRoute(r).GetPathTemplate()
+		key := fmt.Sprintf("%s %s (%s)", r.Method, route, table)
+		count := h.legacyUsage.record(key)
+
+		log.Printf("[%s] deprecated route %s %s used by %q (%d calls)",
+			requestID(r), r.Method, r.URL.Path, r.UserAgent(), count)
+
+		w.Header().Set("Deprecation", "true")
+		if successor := legacySuccessor(table); successor != "" {
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		}
+		next(w, r)
+	}
+}
+
+// legacySuccessor - адрес ресурса v1, заменяющего таблицу или представление
+func legacySuccessor(table string) string {
+	if _, ok := v1Resources[table]; ok {
+		return "/api/v1/" + table
+	}
+	if table == "post_tags" {
+		return "/api/v1/posts/{id}/tags"
+	}
+	for name, view := range v1Analytics {
+		if view == table {
+			return "/api/v1/analytics/" + name
+		}
+	}
+	return ""
+}
+
+// legacyUsageHandler - статистика обращений к устаревшим маршрутам
+func (h *Handlers) legacyUsageHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"legacy_routes_enabled": h.legacyRoutes,
+		"usage":                 h.legacyUsage.snapshot(),
+	})
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"news-aggregator/internal/pgpool"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// Статусы поста (posts.post_status). В ленту и индекс MongoDB попадают только approved.
+const (
+	postStatusPending     = "pending"
+	postStatusApproved    = "approved"
+	postStatusRejected    = "rejected"
+	postStatusQuarantined = "quarantined"
+)
+
+// Тело POST /api/moderation/{post_id}/approve|reject
+var moderationSchema = bodySchema{
+	"moderator": reqString(255),
+	"note":      optString(500),
+}
+
+// setupModerationRoutes регистрирует очередь модерации на роутере с префиксом /moderation
+func (h *Handlers) setupModerationRoutes(r *mux.Router) {
+	r.HandleFunc("/queue", h.moderationQueueHandler).Methods("GET")
+	r.HandleFunc("/{post_id:[0-9]+}/approve", h.moderatePostHandler(postStatusApproved)).Methods("POST")
+	r.HandleFunc("/{post_id:[0-9]+}/reject", h.moderatePostHandler(postStatusRejected)).Methods("POST")
+}
+
+// moderationQueueHandler - GET /api/moderation/queue?status=pending|quarantined&limit=&offset=
+// Посты, ожидающие решения редактора, старые первыми, с причинами срабатывания фильтра.
+func (h *Handlers) moderationQueueHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	limit := queryInt(r, "limit", 50, 1, 200, &fieldErrors)
+	offset := queryInt(r, "offset", 0, 0, maxInt4, &fieldErrors)
+
+	statuses := []string{postStatusPending, postStatusQuarantined}
+	switch s := r.URL.Query().Get("status"); s {
+	case "":
+	case postStatusPending, postStatusQuarantined:
+		statuses = []string{s}
+	default:
+		fieldErrors = append(fieldErrors, FieldError{Field: "status", Message: "must be one of: pending, quarantined"})
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var total int
+	if err := conn.QueryRow(ctx, "SELECT COUNT(*) FROM posts WHERE post_status = ANY($1)", statuses).Scan(&total); err != nil {
+		writeDBError(w, r, "Failed to read moderation queue", err)
+		return
+	}
+
+	rows, err := conn.Query(ctx, `
+        SELECT
+            p.post_id,
+            p.title,
+            nt.text AS content,
+            p.author_id,
+            a.name AS author_name,
+            p.channel_id,
+            c.name AS channel_name,
+            p.post_status,
+            p.created_at,
+            COALESCE(fd.reasons, '[]'::jsonb) AS filter_reasons
+        FROM posts p
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        LEFT JOIN authors a ON p.author_id = a.author_id
+        LEFT JOIN channels c ON p.channel_id = c.channel_id
+        LEFT JOIN LATERAL (
+            SELECT reasons FROM post_filter_decisions d
+            WHERE d.post_id = p.post_id
+            ORDER BY d.decision_id DESC
+            LIMIT 1
+        ) fd ON TRUE
+        WHERE p.post_status = ANY($1)
+        ORDER BY p.created_at, p.post_id
+        LIMIT $2 OFFSET $3`, statuses, limit, offset)
+	if err != nil {
+		writeDBError(w, r, "Failed to read moderation queue", err)
+		return
+	}
+	defer rows.Close()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total": total,
+		"posts": h.rowsToJSON(rows),
+	})
+}
+
+// moderatePostHandler - POST /api/moderation/{post_id}/approve|reject.
+// Одобренный пост попадает в ленту и индекс MongoDB, отклонённый убирается из них.
+func (h *Handlers) moderatePostHandler(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		postID, err := strconv.Atoi(mux.Vars(r)["post_id"])
+		if err != nil || postID < 1 {
+			writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid post ID")
+			return
+		}
+
+		var data map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+			return
+		}
+		if fieldErrors := validateBody(moderationSchema, data, false); len(fieldErrors) > 0 {
+			writeValidationError(w, r, fieldErrors)
+			return
+		}
+		moderator := data["moderator"].(string)
+		note, _ := data["note"].(string)
+
+		ctx := r.Context()
+		conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+		if err != nil {
+			writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+			return
+		}
+		defer conn.Release()
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			writeDBError(w, r, "Failed to begin transaction", err)
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		var previous string
+		err = tx.QueryRow(ctx, "SELECT post_status FROM posts WHERE post_id = $1 FOR UPDATE", postID).Scan(&previous)
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+			return
+		}
+		if err != nil {
+			writeDBError(w, r, "Failed to read post", err)
+			return
+		}
+
+		var moderatedAt time.Time
+		err = tx.QueryRow(ctx, `
+            UPDATE posts
+            SET post_status = $2, moderated_by = $3, moderated_at = NOW(), moderation_note = NULLIF($4, '')
+            WHERE post_id = $1
+            RETURNING moderated_at`, postID, status, moderator, note).Scan(&moderatedAt)
+		if err != nil {
+			writeDBError(w, r, "Failed to moderate post", err)
+			return
+		}
+		if err := tx.Commit(ctx); err != nil {
+			writeDBError(w, r, "Failed to commit transaction", err)
+			return
+		}
+
+		if status == postStatusApproved && previous != postStatusApproved {
+			if err := h.indexApprovedPost(ctx, conn, postID); err != nil {
+				log.Printf("[%s] Failed to index approved post %d: %v", requestID(r), postID, err)
+			}
+		} else if status != postStatusApproved {
+			go h.mongo.RemovePostIndex(context.Background(), postID)
+		}
+
+		h.cache.Del(ctx, "cache:posts", "cache:posts:full", fmt.Sprintf("cache:posts:full:%d", postID))
+		log.Printf("[%s] Post %d moderated by %q: %s -> %s", requestID(r), postID, moderator, previous, status)
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"post_id":         postID,
+			"previous_status": previous,
+			"post_status":     status,
+			"moderated_by":    moderator,
+			"moderated_at":    moderatedAt.Format(time.RFC3339),
+			"moderation_note": note,
+		})
+	}
+}
+
+// indexApprovedPost добавляет одобренный пост в индекс MongoDB
+func (h *Handlers) indexApprovedPost(ctx context.Context, conn *pgpool.PConn, postID int) error {
+	var title, content string
+	var tags []string
+	var likes, comments int
+	err := conn.QueryRow(ctx, `
+        SELECT p.title, COALESCE(nt.text, ''),
+               COALESCE(ARRAY_AGG(t.name) FILTER (WHERE t.name IS NOT NULL), '{}'::text[]),
+               COALESCE(p.likes_count, 0), COALESCE(p.comments_count, 0)
+        FROM posts p
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        LEFT JOIN post_tags pt ON p.post_id = pt.post_id
+        LEFT JOIN tags t ON pt.tag_id = t.tag_id
+        WHERE p.post_id = $1
+        GROUP BY p.post_id, nt.text`, postID).Scan(&title, &content, &tags, &likes, &comments)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx := context.Background()
+		if err := h.mongo.IndexPost(ctx, postID, title, content, tags); err != nil {
+			return
+		}
+		h.mongo.SetEngagementStats(ctx, postID, likes, comments)
+	}()
+	return nil
+}
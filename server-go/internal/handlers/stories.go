@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"news-aggregator/internal/cluster"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// defaultClusterHours - за сколько часов посты сравниваются фоновой кластеризацией
+	defaultClusterHours = 72
+	// maxClusterPosts - не больше стольких постов за один проход
+	maxClusterPosts = 5000
+)
+
+// storySortOrders - допустимые значения sort для GET /api/stories
+var storySortOrders = map[string]string{
+	"last_seen":  "last_seen_at DESC, story_id DESC",
+	"engagement": "engagement DESC, story_id DESC",
+	"posts":      "posts_count DESC, last_seen_at DESC",
+}
+
+// story - строка представления story_stats
+type story struct {
+	StoryID       int       `json:"story_id"`
+	Title         string    `json:"title"`
+	FirstPostID   *int      `json:"first_post_id"`
+	PostsCount    int       `json:"posts_count"`
+	ChannelsCount int       `json:"channels_count"`
+	SourcesCount  int       `json:"sources_count"`
+	LikesTotal    int64     `json:"likes_total"`
+	CommentsTotal int64     `json:"comments_total"`
+	Engagement    int64     `json:"engagement"`
+	FirstSeenAt   time.Time `json:"first_seen_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+const storyColumns = `story_id, title, first_post_id, posts_count, channels_count, sources_count,
+    likes_total, comments_total, engagement, first_seen_at, last_seen_at, updated_at`
+
+func scanStory(row pgx.Row, s *story) error {
+	return row.Scan(&s.StoryID, &s.Title, &s.FirstPostID, &s.PostsCount, &s.ChannelsCount, &s.SourcesCount,
+		&s.LikesTotal, &s.CommentsTotal, &s.Engagement, &s.FirstSeenAt, &s.LastSeenAt, &s.UpdatedAt)
+}
+
+// storyPost - пост сюжета
+type storyPost struct {
+	PostID        int       `json:"post_id"`
+	Title         string    `json:"title"`
+	ChannelID     *int      `json:"channel_id"`
+	ChannelName   *string   `json:"channel_name"`
+	SourceName    *string   `json:"source_name"`
+	LikesCount    int       `json:"likes_count"`
+	CommentsCount int       `json:"comments_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// setupStoryRoutes регистрирует ручной запуск кластеризации на роутере с префиксом /admin
+func (h *Handlers) setupStoryRoutes(r *mux.Router) {
+	r.HandleFunc("/stories/cluster", h.clusterStoriesHandler).Methods("POST")
+}
+
+// ClusterStories объединяет сюжеты постов за последние lookback: пары постов сравниваются
+// по TF-IDF текста, общим тегам и сущностям (люди, организации, места) и времени публикации, сюжеты связанных постов
+// сливаются в более ранний. Вызывается фоновой задачей из main и через admin API.
+func (h *Handlers) ClusterStories(ctx context.Context, lookback time.Duration) (cluster.Result, error) {
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		return cluster.Result{}, err
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+        SELECT f.post_id, f.story_id, p.title || ' ' || COALESCE(nt.text, ''), p.created_at,
+               COALESCE(array_agg(DISTINCT t.name) FILTER (WHERE t.name IS NOT NULL), '{}')
+        FROM post_fingerprints f
+        JOIN posts p ON f.post_id = p.post_id
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        LEFT JOIN (
+            SELECT pt.post_id, tg.name FROM post_tags pt JOIN tags tg ON pt.tag_id = tg.tag_id
+            UNION
+            SELECT pk.post_id, LOWER(pk.term) FROM post_keywords pk WHERE pk.kind <> 'keyword'
+        ) t ON p.post_id = t.post_id
+        WHERE p.created_at >= NOW() - make_interval(secs => $1) AND p.post_status <> 'rejected'
+        GROUP BY f.post_id, f.story_id, p.title, nt.text, p.created_at
+        ORDER BY p.created_at DESC
+        LIMIT $2`, lookback.Seconds(), maxClusterPosts)
+	if err != nil {
+		return cluster.Result{}, err
+	}
+	var docs []cluster.Document
+	for rows.Next() {
+		var d cluster.Document
+		if err := rows.Scan(&d.PostID, &d.StoryID, &d.Text, &d.CreatedAt, &d.Tags); err != nil {
+			rows.Close()
+			return cluster.Result{}, err
+		}
+		docs = append(docs, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return cluster.Result{}, err
+	}
+
+	result := cluster.Group(docs, cluster.DefaultOptions())
+	if len(result.Merges) == 0 {
+		return result, nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	targets := map[int]bool{}
+	for from, to := range result.Merges {
+		if _, err := tx.Exec(ctx, "UPDATE post_fingerprints SET story_id = $2 WHERE story_id = $1", from, to); err != nil {
+			return result, err
+		}
+		if _, err := tx.Exec(ctx, "DELETE FROM stories WHERE story_id = $1", from); err != nil {
+			return result, err
+		}
+		targets[to] = true
+	}
+	// Заголовок и первоисточник сюжета - самый ранний пост после слияния
+	for storyID := range targets {
+		_, err := tx.Exec(ctx, `
+            UPDATE stories s SET title = first.title, first_post_id = first.post_id, updated_at = NOW()
+            FROM (
+                SELECT p.post_id, p.title FROM post_fingerprints f JOIN posts p ON f.post_id = p.post_id
+                WHERE f.story_id = $1
+                ORDER BY p.created_at, p.post_id LIMIT 1
+            ) first
+            WHERE s.story_id = $1`, storyID)
+		if err != nil {
+			return result, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return result, err
+	}
+
+	log.Printf("Story clustering: %d posts, %d pairs compared, %d stories merged",
+		len(docs), result.Compared, len(result.Merges))
+	return result, nil
+}
+
+// clusterStoriesHandler - POST /api/admin/stories/cluster?hours=72: внеочередной проход кластеризации
+func (h *Handlers) clusterStoriesHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	hours := queryInt(r, "hours", defaultClusterHours, 1, 24*30, &fieldErrors)
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	result, err := h.ClusterStories(r.Context(), time.Duration(hours)*time.Hour)
+	if err != nil {
+		writeDBError(w, r, "Failed to cluster stories", err)
+		return
+	}
+
+	log.Printf("[%s] Clustered stories for %dh: %d merged", requestID(r), hours, len(result.Merges))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"hours":          hours,
+		"compared_pairs": result.Compared,
+		"linked_pairs":   result.Linked,
+		"merged_stories": len(result.Merges),
+	})
+}
+
+// listStoriesHandler - GET /api/stories: сюжеты с суммарной вовлечённостью.
+// Параметры: sort=last_seen|engagement|posts, min_posts, hours - только сюжеты
+// с постами за последние N часов, limit, offset.
+func (h *Handlers) listStoriesHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	limit := queryInt(r, "limit", 20, 1, 100, &fieldErrors)
+	offset := queryInt(r, "offset", 0, 0, 1000000, &fieldErrors)
+	minPosts := queryInt(r, "min_posts", 2, 1, 1000, &fieldErrors)
+	hours := queryInt(r, "hours", 0, 0, 24*365, &fieldErrors)
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy == "" {
+		sortBy = "last_seen"
+	}
+	orderBy, ok := storySortOrders[sortBy]
+	if !ok {
+		fieldErrors = append(fieldErrors, FieldError{Field: "sort", Message: "must be one of: last_seen, engagement, posts"})
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	cacheKey := fmt.Sprintf("cache:stories:%s:%d:%d:%d:%d", sortBy, minPosts, hours, limit, offset)
+	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(cached))
+		return
+	}
+
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+        SELECT `+storyColumns+` FROM story_stats
+        WHERE posts_count >= $1 AND ($2 = 0 OR last_seen_at >= NOW() - make_interval(hours => $2))
+        ORDER BY `+orderBy+`
+        LIMIT $3 OFFSET $4`, minPosts, hours, limit, offset)
+	if err != nil {
+		writeDBError(w, r, "Failed to read stories", err)
+		return
+	}
+	defer rows.Close()
+
+	stories := []story{}
+	for rows.Next() {
+		var s story
+		if err := scanStory(rows, &s); err != nil {
+			writeDBError(w, r, "Failed to read stories", err)
+			return
+		}
+		stories = append(stories, s)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, "Failed to read stories", err)
+		return
+	}
+
+	data := mustMarshal(map[string]interface{}{
+		"stories": stories,
+		"limit":   limit,
+		"offset":  offset,
+	})
+	h.cache.SetEX(ctx, cacheKey, string(data), 60)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// storyHandler - GET /api/stories/{id}: сюжет и его посты в порядке публикации
+func (h *Handlers) storyHandler(w http.ResponseWriter, r *http.Request) {
+	storyID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || storyID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid story ID")
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var s story
+	err = scanStory(conn.QueryRow(ctx, "SELECT "+storyColumns+" FROM story_stats WHERE story_id = $1", storyID), &s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Story not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to read story", err)
+		return
+	}
+
+	rows, err := conn.Query(ctx, `
+        SELECT p.post_id, p.title, p.channel_id, c.name, src.name, p.likes_count, p.comments_count, p.created_at
+        FROM post_fingerprints f
+        JOIN posts p ON f.post_id = p.post_id
+        LEFT JOIN channels c ON p.channel_id = c.channel_id
+        LEFT JOIN sources src ON c.source_id = src.source_id
+        WHERE f.story_id = $1 AND p.post_status = 'approved'
+        ORDER BY p.created_at, p.post_id`, storyID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read story posts", err)
+		return
+	}
+	defer rows.Close()
+
+	posts := []storyPost{}
+	for rows.Next() {
+		var p storyPost
+		if err := rows.Scan(&p.PostID, &p.Title, &p.ChannelID, &p.ChannelName, &p.SourceName,
+			&p.LikesCount, &p.CommentsCount, &p.CreatedAt); err != nil {
+			writeDBError(w, r, "Failed to read story posts", err)
+			return
+		}
+		posts = append(posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, "Failed to read story posts", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"story": s,
+		"posts": posts,
+	})
+}
@@ -0,0 +1,668 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"news-aggregator/internal/pgpool"
+	"news-aggregator/internal/tagnorm"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// Тело POST /api/admin/tags/merge
+var tagMergeSchema = bodySchema{
+	"target_id":  reqRefField(),
+	"source_ids": refList(),
+}
+
+// Тело POST /api/admin/tags/aliases
+var tagAliasSchema = bodySchema{
+	"alias":  reqString(100),
+	"tag_id": reqRefField(),
+}
+
+// Тело PUT /api/admin/tags/{id}/parent; null убирает тег из иерархии
+var tagParentSchema = bodySchema{
+	"parent_id": refField(),
+}
+
+// tagAlias - синоним тега
+type tagAlias struct {
+	Alias     string    `json:"alias"`
+	TagID     int       `json:"tag_id"`
+	TagName   string    `json:"tag_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// tagRef - тег в иерархии
+type tagRef struct {
+	TagID int    `json:"tag_id"`
+	Name  string `json:"name"`
+}
+
+// tagMergeResult - итог слияния тегов
+type tagMergeResult struct {
+	TargetID      int      `json:"target_id"`
+	TargetName    string   `json:"target_name"`
+	Merged        []string `json:"merged"`
+	PostsRelinked int64    `json:"posts_relinked"`
+	MongoUpdated  int64    `json:"mongo_posts_updated"`
+	MongoSynced   bool     `json:"mongo_synced"`
+}
+
+// rowQuerier - общее у pgx.Tx и *pgpool.PConn
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// setupTagRoutes регистрирует синонимы, иерархию и слияние тегов на роутере с префиксом /admin
+func (h *Handlers) setupTagRoutes(r *mux.Router) {
+	r.HandleFunc("/tags/merge", h.mergeTagsHandler).Methods("POST")
+	r.HandleFunc("/tags/normalize", h.normalizeTagsHandler).Methods("POST")
+	r.HandleFunc("/tags/aliases", h.listTagAliasesHandler).Methods("GET")
+	r.HandleFunc("/tags/aliases", h.createTagAliasHandler).Methods("POST")
+	r.HandleFunc("/tags/aliases/{alias}", h.deleteTagAliasHandler).Methods("DELETE")
+	r.HandleFunc("/tags/{id:[0-9]+}/parent", h.setTagParentHandler).Methods("PUT")
+}
+
+// lookupTag ищет канонический тег для варианта: сначала по синонимам, затем по ключу
+// нормализации. Возвращает 0, если такого тега ещё нет.
+func lookupTag(ctx context.Context, q rowQuerier, key string) (int32, string, error) {
+	var tagID int32
+	var name string
+	err := q.QueryRow(ctx, `
+        SELECT t.tag_id, t.name FROM tag_aliases a JOIN tags t ON a.tag_id = t.tag_id
+        WHERE a.alias = $1`, key).Scan(&tagID, &name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = q.QueryRow(ctx,
+			"SELECT tag_id, name FROM tags WHERE normalized = $1 ORDER BY tag_id LIMIT 1",
+			key).Scan(&tagID, &name)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", nil
+	}
+	return tagID, name, err
+}
+
+// resolveTag возвращает канонический тег для варианта из тела запроса,
+// создавая новый тег, если совпадений нет. Для тега без букв и цифр возвращает 0.
+func resolveTag(ctx context.Context, tx pgx.Tx, raw string) (int32, string, error) {
+	key := tagnorm.Key(raw)
+	if key == "" {
+		return 0, "", nil
+	}
+	tagID, name, err := lookupTag(ctx, tx, key)
+	if err != nil || tagID != 0 {
+		return tagID, name, err
+	}
+	err = tx.QueryRow(ctx, `
+        INSERT INTO tags (name, normalized) VALUES ($1, $2)
+        ON CONFLICT (name) DO UPDATE SET normalized = COALESCE(tags.normalized, EXCLUDED.normalized)
+        RETURNING tag_id, name`, tagnorm.Display(raw), key).Scan(&tagID, &name)
+	return tagID, name, err
+}
+
+// linkPostTags связывает пост с каноническими тегами и возвращает их имена без повторов
+func linkPostTags(ctx context.Context, tx pgx.Tx, postID int, raw []interface{}) []string {
+	names := []string{}
+	seen := map[int32]bool{}
+	for _, value := range raw {
+		tagName, ok := value.(string)
+		if !ok || tagName == "" {
+			continue
+		}
+		tagID, name, err := resolveTag(ctx, tx, tagName)
+		if err != nil {
+			log.Printf("Failed to resolve tag %s: %v", tagName, err)
+			continue
+		}
+		if tagID == 0 || seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+
+		if _, err := tx.Exec(ctx, "INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", postID, tagID); err != nil {
+			log.Printf("Failed to link tag %s to post %d: %v", name, postID, err)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// canonicalTagName - имя канонического тега для операций MongoDB без записи в PostgreSQL
+func canonicalTagName(ctx context.Context, q rowQuerier, raw string) (string, error) {
+	_, name, err := lookupTag(ctx, q, tagnorm.Key(raw))
+	if err != nil || name != "" {
+		return name, err
+	}
+	return tagnorm.Display(raw), nil
+}
+
+// decodeTagBody читает и проверяет тело admin-запроса по тегам
+func decodeTagBody(w http.ResponseWriter, r *http.Request, schema bodySchema) (map[string]interface{}, bool) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return nil, false
+	}
+	if fieldErrors := validateBody(schema, data, false); len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return nil, false
+	}
+	return data, true
+}
+
+// mergeTags переносит посты, синонимы и дочерние теги sources в target и удаляет sources.
+// Ключи нормализации удалённых тегов становятся синонимами target.
+func mergeTags(ctx context.Context, tx pgx.Tx, targetID int, sourceIDs []int) (tagMergeResult, error) {
+	result := tagMergeResult{TargetID: targetID, Merged: []string{}}
+	if err := tx.QueryRow(ctx, "SELECT name FROM tags WHERE tag_id = $1", targetID).Scan(&result.TargetName); err != nil {
+		return result, err
+	}
+
+	rows, err := tx.Query(ctx, "SELECT name FROM tags WHERE tag_id = ANY($1) AND tag_id <> $2", sourceIDs, targetID)
+	if err != nil {
+		return result, err
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return result, err
+		}
+		result.Merged = append(result.Merged, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	res, err := tx.Exec(ctx, `
+        INSERT INTO post_tags (post_id, tag_id)
+        SELECT DISTINCT post_id, $2 FROM post_tags WHERE tag_id = ANY($1)
+        ON CONFLICT DO NOTHING`, sourceIDs, targetID)
+	if err != nil {
+		return result, err
+	}
+	result.PostsRelinked = res.RowsAffected()
+
+	statements := []string{
+		"UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = ANY($1)",
+		"UPDATE tags SET parent_id = NULL WHERE tag_id = $2 AND parent_id = ANY($1)",
+		"UPDATE tags SET parent_id = $2 WHERE parent_id = ANY($1) AND tag_id <> $2",
+	}
+	for _, sql := range statements {
+		if _, err := tx.Exec(ctx, sql, sourceIDs, targetID); err != nil {
+			return result, err
+		}
+	}
+	for _, name := range result.Merged {
+		if key := tagnorm.Key(name); key != "" {
+			_, err := tx.Exec(ctx, `
+                INSERT INTO tag_aliases (alias, tag_id) VALUES ($1, $2)
+                ON CONFLICT (alias) DO UPDATE SET tag_id = EXCLUDED.tag_id`, key, targetID)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+	_, err = tx.Exec(ctx, "DELETE FROM tags WHERE tag_id = ANY($1) AND tag_id <> $2", sourceIDs, targetID)
+	return result, err
+}
+
+// syncMergedTags переписывает массивы tags в MongoDB после слияния в PostgreSQL
+func (h *Handlers) syncMergedTags(ctx context.Context, rid string, result *tagMergeResult) {
+	if len(result.Merged) == 0 {
+		result.MongoSynced = true
+		return
+	}
+	updated, err := h.mongo.MergeTags(ctx, result.Merged, result.TargetName)
+	if err != nil {
+		log.Printf("[%s] Failed to merge tags %v into %q in MongoDB: %v", rid, result.Merged, result.TargetName, err)
+		return
+	}
+	result.MongoUpdated, result.MongoSynced = updated, true
+}
+
+// invalidateTagCaches - после слияния меняются теги многих постов
+func (h *Handlers) invalidateTagCaches(ctx context.Context) {
+	h.cache.Del(ctx, "cache:tags", "cache:post_tags", "cache:posts", "cache:posts:full")
+}
+
+// mergeTagsHandler - POST /api/admin/tags/merge: теги source_ids сливаются в target_id
+// в post_tags и в массивах tags документов MongoDB
+func (h *Handlers) mergeTagsHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := decodeTagBody(w, r, tagMergeSchema)
+	if !ok {
+		return
+	}
+	target, _ := toFloat(data["target_id"])
+	targetID := int(target)
+	var sourceIDs []int
+	for _, v := range data["source_ids"].([]interface{}) {
+		id, _ := toFloat(v)
+		sourceIDs = append(sourceIDs, int(id))
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		writeDBError(w, r, "Failed to begin transaction", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := mergeTags(ctx, tx, targetID, sourceIDs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Target tag not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to merge tags", err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		writeDBError(w, r, "Failed to commit transaction", err)
+		return
+	}
+
+	h.syncMergedTags(ctx, requestID(r), &result)
+	h.invalidateTagCaches(ctx)
+	log.Printf("[%s] Merged tags %v into %q", requestID(r), result.Merged, result.TargetName)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// normalizeTagsHandler - POST /api/admin/tags/normalize?merge=true: пересчитывает ключи
+// нормализации всех тегов. С merge=true теги с одинаковым ключом сливаются в самый
+// используемый из них, иначе возвращаются только группы-кандидаты.
+func (h *Handlers) normalizeTagsHandler(w http.ResponseWriter, r *http.Request) {
+	merge := r.URL.Query().Get("merge") == "true"
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	// Теги самого частого варианта идут первыми в группе
+	rows, err := conn.Query(ctx, `
+        SELECT t.tag_id, t.name, COALESCE(t.normalized, ''), COUNT(pt.post_id) AS posts
+        FROM tags t LEFT JOIN post_tags pt ON t.tag_id = pt.tag_id
+        GROUP BY t.tag_id
+        ORDER BY posts DESC, t.tag_id`)
+	if err != nil {
+		writeDBError(w, r, "Failed to read tags", err)
+		return
+	}
+	type tagKey struct {
+		id        int
+		name, old string
+	}
+	var all []tagKey
+	for rows.Next() {
+		var t tagKey
+		var posts int
+		if err := rows.Scan(&t.id, &t.name, &t.old, &posts); err != nil {
+			rows.Close()
+			writeDBError(w, r, "Failed to read tags", err)
+			return
+		}
+		all = append(all, t)
+	}
+	rows.Close()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		writeDBError(w, r, "Failed to begin transaction", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	updated := 0
+	groups := map[string][]tagRef{}
+	var order []string
+	for _, t := range all {
+		key := tagnorm.Key(t.name)
+		if key != t.old {
+			if _, err := tx.Exec(ctx, "UPDATE tags SET normalized = NULLIF($2, '') WHERE tag_id = $1", t.id, key); err != nil {
+				writeDBError(w, r, "Failed to update tag", err)
+				return
+			}
+			updated++
+		}
+		if key == "" {
+			continue
+		}
+		if groups[key] == nil {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], tagRef{TagID: t.id, Name: t.name})
+	}
+
+	candidates := [][]tagRef{}
+	var merges []tagMergeResult
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		candidates = append(candidates, group)
+		if !merge {
+			continue
+		}
+		sourceIDs := make([]int, 0, len(group)-1)
+		for _, t := range group[1:] {
+			sourceIDs = append(sourceIDs, t.TagID)
+		}
+		result, err := mergeTags(ctx, tx, group[0].TagID, sourceIDs)
+		if err != nil {
+			writeDBError(w, r, "Failed to merge tags", err)
+			return
+		}
+		merges = append(merges, result)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		writeDBError(w, r, "Failed to commit transaction", err)
+		return
+	}
+
+	for i := range merges {
+		h.syncMergedTags(ctx, requestID(r), &merges[i])
+	}
+	if merge {
+		h.invalidateTagCaches(ctx)
+	}
+
+	log.Printf("[%s] Normalized %d tags, %d duplicate groups, merge=%v", requestID(r), updated, len(candidates), merge)
+	response := map[string]interface{}{
+		"updated": updated,
+		"groups":  candidates,
+	}
+	if merge {
+		if merges == nil {
+			merges = []tagMergeResult{}
+		}
+		response["merges"] = merges
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listTagAliasesHandler - GET /api/admin/tags/aliases
+func (h *Handlers) listTagAliasesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, `
+        SELECT a.alias, a.tag_id, t.name, a.created_at
+        FROM tag_aliases a JOIN tags t ON a.tag_id = t.tag_id
+        ORDER BY t.name, a.alias`)
+	if err != nil {
+		writeDBError(w, r, "Failed to read tag aliases", err)
+		return
+	}
+	defer rows.Close()
+
+	aliases := []tagAlias{}
+	for rows.Next() {
+		var a tagAlias
+		if err := rows.Scan(&a.Alias, &a.TagID, &a.TagName, &a.CreatedAt); err != nil {
+			writeDBError(w, r, "Failed to read tag aliases", err)
+			return
+		}
+		aliases = append(aliases, a)
+	}
+	writeJSON(w, http.StatusOK, aliases)
+}
+
+// createTagAliasHandler - POST /api/admin/tags/aliases: вариант ("news") становится
+// синонимом тега. Сохраняется ключ нормализации варианта, поэтому "#News" и "news"
+// тоже попадут в тег. Синоним переопределяет совпадение по ключу.
+func (h *Handlers) createTagAliasHandler(w http.ResponseWriter, r *http.Request) {
+	data, ok := decodeTagBody(w, r, tagAliasSchema)
+	if !ok {
+		return
+	}
+	key := tagnorm.Key(data["alias"].(string))
+	if key == "" {
+		writeValidationError(w, r, []FieldError{{Field: "alias", Message: "must contain letters or digits"}})
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	tagID, _ := toFloat(data["tag_id"])
+	var a tagAlias
+	err = conn.QueryRow(ctx, `
+        WITH saved AS (
+            INSERT INTO tag_aliases (alias, tag_id) VALUES ($1, $2)
+            ON CONFLICT (alias) DO UPDATE SET tag_id = EXCLUDED.tag_id
+            RETURNING alias, tag_id, created_at
+        )
+        SELECT s.alias, s.tag_id, t.name, s.created_at FROM saved s JOIN tags t ON s.tag_id = t.tag_id`,
+		key, int(tagID)).Scan(&a.Alias, &a.TagID, &a.TagName, &a.CreatedAt)
+	if err != nil {
+		writeDBError(w, r, "Failed to save tag alias", err)
+		return
+	}
+
+	log.Printf("[%s] Tag alias %q -> %q", requestID(r), a.Alias, a.TagName)
+	writeJSON(w, http.StatusCreated, a)
+}
+
+// deleteTagAliasHandler - DELETE /api/admin/tags/aliases/{alias}
+func (h *Handlers) deleteTagAliasHandler(w http.ResponseWriter, r *http.Request) {
+	key := tagnorm.Key(mux.Vars(r)["alias"])
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var alias string
+	err = conn.QueryRow(ctx, "DELETE FROM tag_aliases WHERE alias = $1 RETURNING alias", key).Scan(&alias)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Tag alias not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to delete tag alias", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Tag alias deleted"})
+}
+
+// setTagParentHandler - PUT /api/admin/tags/{id}/parent: место тега в иерархии.
+// Тег не может стать потомком самого себя.
+func (h *Handlers) setTagParentHandler(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || tagID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid tag ID")
+		return
+	}
+	data, ok := decodeTagBody(w, r, tagParentSchema)
+	if !ok {
+		return
+	}
+	if _, present := data["parent_id"]; !present {
+		writeValidationError(w, r, []FieldError{{Field: "parent_id", Message: "is required"}})
+		return
+	}
+	var parentID *int
+	if v, ok := toFloat(data["parent_id"]); ok {
+		id := int(v)
+		parentID = &id
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	if parentID != nil {
+		var cycle bool
+		err := conn.QueryRow(ctx, `
+            WITH RECURSIVE ancestors AS (
+                SELECT tag_id, parent_id FROM tags WHERE tag_id = $1
+                UNION
+                SELECT t.tag_id, t.parent_id FROM tags t JOIN ancestors a ON t.tag_id = a.parent_id
+            )
+            SELECT EXISTS (SELECT 1 FROM ancestors WHERE tag_id = $2)`, *parentID, tagID).Scan(&cycle)
+		if err != nil {
+			writeDBError(w, r, "Failed to check tag hierarchy", err)
+			return
+		}
+		if cycle {
+			writeValidationError(w, r, []FieldError{{Field: "parent_id", Message: "must not be the tag itself or its descendant"}})
+			return
+		}
+	}
+
+	var tag tagRef
+	var parentName *string
+	err = conn.QueryRow(ctx, `
+        UPDATE tags t SET parent_id = $2 WHERE t.tag_id = $1
+        RETURNING t.tag_id, t.name, (SELECT name FROM tags WHERE tag_id = $2)`,
+		tagID, parentID).Scan(&tag.TagID, &tag.Name, &parentName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Tag not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to update tag", err)
+		return
+	}
+
+	h.cache.Del(ctx, "cache:tags")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tag_id":      tag.TagID,
+		"name":        tag.Name,
+		"parent_id":   parentID,
+		"parent_name": parentName,
+	})
+}
+
+// tagHierarchyHandler - GET /api/tags/{id}/hierarchy: предки тега от корня,
+// дочерние теги и синонимы
+func (h *Handlers) tagHierarchyHandler(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || tagID < 1 {
+		writeError(w, r, http.StatusBadRequest, errCodeBadRequest, "Invalid tag ID")
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var tag tagRef
+	var normalized *string
+	err = conn.QueryRow(ctx, "SELECT tag_id, name, normalized FROM tags WHERE tag_id = $1", tagID).
+		Scan(&tag.TagID, &tag.Name, &normalized)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Tag not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to read tag", err)
+		return
+	}
+
+	ancestors, err := readTagRefs(ctx, conn, `
+        WITH RECURSIVE ancestors AS (
+            SELECT parent_id, 1 AS depth FROM tags WHERE tag_id = $1
+            UNION ALL
+            SELECT t.parent_id, a.depth + 1 FROM tags t JOIN ancestors a ON t.tag_id = a.parent_id
+            WHERE a.depth < 32
+        )
+        SELECT t.tag_id, t.name FROM ancestors a JOIN tags t ON t.tag_id = a.parent_id
+        ORDER BY a.depth DESC`, tagID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read tag hierarchy", err)
+		return
+	}
+	children, err := readTagRefs(ctx, conn, "SELECT tag_id, name FROM tags WHERE parent_id = $1 ORDER BY name", tagID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read tag hierarchy", err)
+		return
+	}
+	aliases := []string{}
+	rows, err := conn.Query(ctx, "SELECT alias FROM tag_aliases WHERE tag_id = $1 ORDER BY alias", tagID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read tag aliases", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			writeDBError(w, r, "Failed to read tag aliases", err)
+			return
+		}
+		aliases = append(aliases, alias)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tag_id":     tag.TagID,
+		"name":       tag.Name,
+		"normalized": normalized,
+		"ancestors":  ancestors,
+		"children":   children,
+		"aliases":    aliases,
+	})
+}
+
+func readTagRefs(ctx context.Context, conn *pgpool.PConn, sql string, args ...interface{}) ([]tagRef, error) {
+	rows, err := conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refs := []tagRef{}
+	for rows.Next() {
+		var t tagRef
+		if err := rows.Scan(&t.TagID, &t.Name); err != nil {
+			return nil, err
+		}
+		refs = append(refs, t)
+	}
+	return refs, rows.Err()
+}
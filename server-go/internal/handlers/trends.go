@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"news-aggregator/internal/classifier"
+	"news-aggregator/internal/trends"
+)
+
+const (
+	defaultTrendsLimit = 20
+	maxTrendsLimit     = 100
+	// maxTrendTexts - не больше стольких постов разбирается на слова за запрос
+	maxTrendTexts = 20000
+)
+
+// trendGroup - растущие теги одного источника или канала
+type trendGroup struct {
+	ID   int            `json:"id"`
+	Name string         `json:"name"`
+	Tags []trends.Trend `json:"tags"`
+}
+
+// trendsTagsHandler - GET /api/trends/tags?window=1h|24h: теги (вместе с извлечёнными
+// ключевыми словами) и слова из текста постов,
+// частота которых в последнем окне выросла относительно базового периода.
+// source_id и channel_id ограничивают выборку, group_by=source|channel
+// дополнительно возвращает растущие теги по каждому источнику или каналу.
+func (h *Handlers) trendsTagsHandler(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []FieldError
+	limit := queryInt(r, "limit", defaultTrendsLimit, 1, maxTrendsLimit, &fieldErrors)
+	sourceID := queryInt(r, "source_id", 0, 1, 1<<31-1, &fieldErrors)
+	channelID := queryInt(r, "channel_id", 0, 1, 1<<31-1, &fieldErrors)
+	name := r.URL.Query().Get("window")
+	if name == "" {
+		name = "1h"
+	}
+	window, ok := trends.Windows[name]
+	if !ok {
+		fieldErrors = append(fieldErrors, FieldError{Field: "window", Message: "must be one of: 1h, 24h"})
+	}
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy != "" && groupBy != "source" && groupBy != "channel" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "group_by", Message: "must be one of: source, channel"})
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	cacheKey := fmt.Sprintf("cache:trends:tags:%s:%d:%d:%s:%d", name, sourceID, channelID, groupBy, limit)
+	if cached, err := h.cache.Get(ctx, cacheKey); err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(cached))
+		return
+	}
+
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	// Границы окон считаются от времени базы, чтобы не зависеть от часового пояса сервера
+	var now time.Time
+	if err := conn.QueryRow(ctx, "SELECT NOW()::timestamp").Scan(&now); err != nil {
+		writeDBError(w, r, "Failed to read time", err)
+		return
+	}
+	windowStart := now.Add(-time.Duration(window.Hours) * time.Hour)
+	baselineStart := windowStart.Add(-time.Duration(window.Hours*window.Baselines) * time.Hour)
+
+	// Упоминания тегов в окне и в базовом периоде по каналам. Ключевые слова и сущности
+	// поста считаются наравне с тегами; совпавшие с тегом учитываются один раз.
+	windowHours, totalHours := window.Hours, window.Hours*(window.Baselines+1)
+	tagRows, err := conn.Query(ctx, `
+        SELECT t.name, COALESCE(c.source_id, 0), COALESCE(src.name, ''), COALESCE(p.channel_id, 0), COALESCE(c.name, ''),
+               COUNT(*) FILTER (WHERE p.created_at >= NOW() - make_interval(hours => $2)),
+               COUNT(*) FILTER (WHERE p.created_at < NOW() - make_interval(hours => $2))
+        FROM posts p
+        JOIN (
+            SELECT pt.post_id, tg.name FROM post_tags pt JOIN tags tg ON pt.tag_id = tg.tag_id
+            UNION
+            SELECT pk.post_id, LOWER(pk.term) FROM post_keywords pk
+        ) t ON p.post_id = t.post_id
+        LEFT JOIN channels c ON p.channel_id = c.channel_id
+        LEFT JOIN sources src ON c.source_id = src.source_id
+        WHERE p.created_at >= NOW() - make_interval(hours => $1) AND p.post_status = 'approved'
+          AND ($3 = 0 OR c.source_id = $3) AND ($4 = 0 OR p.channel_id = $4)
+        GROUP BY t.name, c.source_id, src.name, p.channel_id, c.name`,
+		totalHours, windowHours, sourceID, channelID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read tag counts", err)
+		return
+	}
+
+	total := map[string]trends.Counts{}
+	groups := map[int]map[string]trends.Counts{}
+	groupNames := map[int]string{}
+	for tagRows.Next() {
+		var tag, srcName, chName string
+		var srcID, chID int
+		var c trends.Counts
+		if err := tagRows.Scan(&tag, &srcID, &srcName, &chID, &chName, &c.Recent, &c.Baseline); err != nil {
+			tagRows.Close()
+			writeDBError(w, r, "Failed to read tag counts", err)
+			return
+		}
+		addCounts(total, tag, c)
+
+		id, groupName := srcID, srcName
+		if groupBy == "channel" {
+			id, groupName = chID, chName
+		}
+		if groupBy != "" && id != 0 {
+			if groups[id] == nil {
+				groups[id] = map[string]trends.Counts{}
+			}
+			groupNames[id] = groupName
+			addCounts(groups[id], tag, c)
+		}
+	}
+	tagRows.Close()
+	if err := tagRows.Err(); err != nil {
+		writeDBError(w, r, "Failed to read tag counts", err)
+		return
+	}
+
+	// Слова из заголовков и текстов: считается число постов со словом
+	terms := map[string]trends.Counts{}
+	rows, err := conn.Query(ctx, `
+        SELECT p.title || ' ' || COALESCE(nt.text, ''), p.created_at >= NOW() - make_interval(hours => $2)
+        FROM posts p
+        LEFT JOIN news_texts nt ON p.text_id = nt.text_id
+        LEFT JOIN channels c ON p.channel_id = c.channel_id
+        WHERE p.created_at >= NOW() - make_interval(hours => $1) AND p.post_status = 'approved'
+          AND ($3 = 0 OR c.source_id = $3) AND ($4 = 0 OR p.channel_id = $4)
+        ORDER BY p.created_at DESC
+        LIMIT $5`, totalHours, windowHours, sourceID, channelID, maxTrendTexts)
+	if err != nil {
+		writeDBError(w, r, "Failed to read posts", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var text string
+		var recent bool
+		if err := rows.Scan(&text, &recent); err != nil {
+			writeDBError(w, r, "Failed to read posts", err)
+			return
+		}
+		seen := map[string]bool{}
+		for _, term := range classifier.Tokenize(text) {
+			if seen[term] {
+				continue
+			}
+			seen[term] = true
+			c := trends.Counts{Baseline: 1}
+			if recent {
+				c = trends.Counts{Recent: 1}
+			}
+			addCounts(terms, term, c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		writeDBError(w, r, "Failed to read posts", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"window":           window.Name,
+		"window_start":     windowStart,
+		"baseline_start":   baselineStart,
+		"baseline_windows": window.Baselines,
+		"tags":             trends.Detect(total, window, limit),
+		"terms":            trends.Detect(terms, window, limit),
+	}
+	if groupBy != "" {
+		list := []trendGroup{}
+		for id, counts := range groups {
+			if tags := trends.Detect(counts, window, limit); len(tags) > 0 {
+				list = append(list, trendGroup{ID: id, Name: groupNames[id], Tags: tags})
+			}
+		}
+		sortTrendGroups(list)
+		response["groups"] = list
+	}
+
+	data := mustMarshal(response)
+	// Часовое окно обновляется чаще суточного
+	h.cache.SetEX(ctx, cacheKey, string(data), window.Hours*60)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func addCounts(m map[string]trends.Counts, name string, c trends.Counts) {
+	sum := m[name]
+	sum.Recent += c.Recent
+	sum.Baseline += c.Baseline
+	m[name] = sum
+}
+
+// sortTrendGroups - сначала источники и каналы с самым сильным всплеском
+func sortTrendGroups(list []trendGroup) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Tags[0].ZScore != list[j].Tags[0].ZScore {
+			return list[i].Tags[0].ZScore > list[j].Tags[0].ZScore
+		}
+		return list[i].ID < list[j].ID
+	})
+}
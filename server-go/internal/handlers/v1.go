@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5"
+)
+
+// v1Resources - ресурсы /api/v1 и таблицы, на которые они отображаются.
+// Имена ресурсов - часть контракта API и не меняются вместе со схемой БД.
+var v1Resources = map[string]string{
+	"posts":    "posts",
+	"channels": "channels",
+	"sources":  "sources",
+	"authors":  "authors",
+	"comments": "comments",
+	"media":    "media",
+	"tags":     "tags",
+}
+
+// v1Analytics - публичные имена аналитических представлений из db/tmp.sql
+var v1Analytics = map[string]string{
+	"channel-activity":    "channel_activity_stats",
+	"author-performance":  "author_performance",
+	"tag-popularity":      "tag_popularity_detailed",
+	"source-stats":        "source_post_stats",
+	"commenter-activity":  "user_comment_activity",
+	"posts-ranked":        "posts_ranked_by_popularity",
+	"author-likes-trend":  "author_likes_trend",
+	"cumulative-posts":    "cumulative_posts_analysis",
+	"tag-rank-by-channel": "tag_rank_by_channel",
+	"commenters":          "commenter_analysis",
+	"discussion-stats":    "post_discussion_stats",
+	"author-reputation":   "author_reputation",
+	"story-stats":         "story_stats",
+}
+
+// Тело POST /api/v1/posts/{id}/tags: существующий тег по tag_id или тег по имени
+var v1PostTagSchema = bodySchema{
+	"tag_id": refField(),
+	"name":   optString(100),
+}
+
+// setupV1Routes регистрирует версионированный API /api/v1
+func (h *Handlers) setupV1Routes(r *mux.Router) {
+	v1 := r.PathPrefix("/api/v1").Subrouter()
+
+	v1.HandleFunc("/meta/legacy-usage", h.legacyUsageHandler).Methods("GET")
+
+	// Аналитика MongoDB (должна быть ПЕРЕД /analytics/{name})
+	v1.HandleFunc("/analytics/top-tags", h.topTagsHandler).Methods("GET")
+	v1.HandleFunc("/analytics/engagement", h.engagementAnalysisHandler).Methods("GET")
+	v1.HandleFunc("/analytics/channels", h.channelPerformanceHandler).Methods("GET")
+	v1.HandleFunc("/analytics/top-posts", h.topPostsViewHandler).Methods("GET")
+	v1.HandleFunc("/analytics", h.v1AnalyticsListHandler).Methods("GET")
+	v1.HandleFunc("/analytics/{name}", h.v1AnalyticsHandler).Methods("GET")
+
+	v1.HandleFunc("/search", h.advancedSearchHandler).Methods("POST")
+
+	// Правила фильтра контента, классификатор и очередь модерации
+	admin := v1.PathPrefix("/admin").Subrouter()
+	h.setupFilterRoutes(admin)
+	h.setupCategoryRoutes(admin)
+	h.setupDuplicateRoutes(admin)
+	h.setupStoryRoutes(admin)
+	h.setupTagRoutes(admin)
+	h.setupLanguageRoutes(admin)
+	h.setupKeywordRoutes(admin)
+	h.setupModerationRoutes(v1.PathPrefix("/moderation").Subrouter())
+
+	// Дерево комментариев поста, активность обсуждения, дубликаты и ключевые слова
+	v1.HandleFunc("/posts/{id:[0-9]+}/comments", h.postCommentsHandler).Methods("GET")
+	v1.HandleFunc("/posts/{id:[0-9]+}/discussion-stats", h.postDiscussionStatsHandler).Methods("GET")
+	v1.HandleFunc("/posts/{id:[0-9]+}/duplicates", h.postDuplicatesHandler).Methods("GET")
+	v1.HandleFunc("/posts/{id:[0-9]+}/keywords", h.postKeywordsHandler).Methods("GET")
+	v1.HandleFunc("/authors/{id:[0-9]+}/profile", h.authorProfileHandler).Methods("GET")
+	v1.HandleFunc("/authors/ensure", h.ensureAuthorHandler).Methods("POST")
+
+	// Категории постов
+	v1.HandleFunc("/categories", h.listCategoriesHandler).Methods("GET")
+	v1.HandleFunc("/posts/{id:[0-9]+}/categories", h.postCategoriesHandler).Methods("GET")
+	v1.HandleFunc("/posts/{id:[0-9]+}/categories", h.setPostCategoriesHandler).Methods("PUT")
+
+	// Сюжеты, растущие теги и иерархия тегов
+	v1.HandleFunc("/stories", h.listStoriesHandler).Methods("GET")
+	v1.HandleFunc("/stories/{id:[0-9]+}", h.storyHandler).Methods("GET")
+	v1.HandleFunc("/trends/tags", h.trendsTagsHandler).Methods("GET")
+	v1.HandleFunc("/tags/{id:[0-9]+}/hierarchy", h.tagHierarchyHandler).Methods("GET")
+
+	// Теги поста
+	v1.HandleFunc("/posts/{id:[0-9]+}/tags", h.v1PostTagsHandler).Methods("GET")
+	v1.HandleFunc("/posts/{id:[0-9]+}/tags", h.v1AddPostTagHandler).Methods("POST")
+	v1.HandleFunc("/posts/{id:[0-9]+}/tags/{tag_id:[0-9]+}", h.v1RemovePostTagHandler).Methods("DELETE")
+
+	// CRUD ресурсов
+	for resource, table := range v1Resources {
+		v1.HandleFunc("/"+resource, withTable(table, h.readAllHandler)).Methods("GET")
+		v1.HandleFunc("/"+resource, withTable(table, h.createHandler)).Methods("POST")
+		v1.HandleFunc("/"+resource+"/{id:[0-9]+}", withTable(table, h.v1ReadOneHandler)).Methods("GET")
+		v1.HandleFunc("/"+resource+"/{id:[0-9]+}", withTable(table, h.updateHandler)).Methods("PUT")
+		v1.HandleFunc("/"+resource+"/{id:[0-9]+}", withTable(table, h.deleteHandler)).Methods("DELETE")
+	}
+}
+
+// withTable подставляет имя таблицы в переменные маршрута,
+// чтобы общие CRUD-обработчики работали с фиксированными ресурсами v1
+func withTable(table string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := map[string]string{"table": table}
+		for key, value := range mux.Vars(r) {
+			vars[key] = value
+		}
+		next(w, mux.SetURLVars(r, vars))
+	}
+}
+
+// v1ReadOneHandler возвращает одну запись объектом (а не массивом, как /api/{table}/{id}) или 404
+func (h *Handlers) v1ReadOneHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	table := vars["table"]
+	id := vars["id"]
+
+	if table == "posts" {
+		h.readOnePostHandler(w, r, id)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s = $1", table, pkMap[table])
+	rows, err := conn.Query(ctx, query, id)
+	if err != nil {
+		writeDBError(w, r, "Failed to read data", err)
+		return
+	}
+	defer rows.Close()
+
+	results := h.rowsToJSON(rows)
+	if len(results) == 0 {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Item not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results[0])
+}
+
+// v1AnalyticsListHandler - список доступных аналитических отчётов
+func (h *Handlers) v1AnalyticsListHandler(w http.ResponseWriter, r *http.Request) {
+	names := []string{"top-tags", "engagement", "channels", "top-posts"}
+	for name := range v1Analytics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"reports": names})
+}
+
+// v1AnalyticsHandler отдаёт аналитическое представление по публичному имени
+func (h *Handlers) v1AnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	view, ok := v1Analytics[mux.Vars(r)["name"]]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Analytics report not found")
+		return
+	}
+	h.readAllHandler(w, mux.SetURLVars(r, map[string]string{"table": view}))
+}
+
+// v1PostTagsHandler - теги поста
+func (h *Handlers) v1PostTagsHandler(w http.ResponseWriter, r *http.Request) {
+	postID := mux.Vars(r)["id"]
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, true)
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var exists bool
+	if err := conn.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM posts WHERE post_id = $1)", postID).Scan(&exists); err != nil {
+		writeDBError(w, r, "Failed to read post", err)
+		return
+	}
+	if !exists {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+
+	rows, err := conn.Query(ctx, `
+        SELECT t.tag_id, t.name
+        FROM post_tags pt
+        JOIN tags t ON t.tag_id = pt.tag_id
+        WHERE pt.post_id = $1
+        ORDER BY t.name`, postID)
+	if err != nil {
+		writeDBError(w, r, "Failed to read post tags", err)
+		return
+	}
+	defer rows.Close()
+
+	writeJSON(w, http.StatusOK, h.rowsToJSON(rows))
+}
+
+// v1AddPostTagHandler привязывает тег к посту (тег по имени создаётся при необходимости)
+func (h *Handlers) v1AddPostTagHandler(w http.ResponseWriter, r *http.Request) {
+	postID, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var data map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, r, http.StatusBadRequest, errCodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	fieldErrors := validateBody(v1PostTagSchema, data, false)
+	_, hasID := data["tag_id"]
+	name, _ := data["name"].(string)
+	if len(fieldErrors) == 0 && hasID == (name != "") {
+		fieldErrors = append(fieldErrors, FieldError{Field: "tag_id", Message: "exactly one of tag_id or name is required"})
+	}
+	if len(fieldErrors) > 0 {
+		writeValidationError(w, r, fieldErrors)
+		return
+	}
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		writeDBError(w, r, "Failed to begin transaction", err)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// В индекс MongoDB попадают только одобренные посты
+	var postStatus string
+	err = tx.QueryRow(ctx, "SELECT post_status FROM posts WHERE post_id = $1", postID).Scan(&postStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to read post", err)
+		return
+	}
+
+	var tagID int32
+	if hasID {
+		id, _ := toFloat(data["tag_id"])
+		err = tx.QueryRow(ctx, "SELECT tag_id, name FROM tags WHERE tag_id = $1", int(id)).Scan(&tagID, &name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, r, http.StatusUnprocessableEntity, errCodeUnprocessable, "Referenced tag does not exist")
+			return
+		}
+		if err != nil {
+			writeDBError(w, r, "Failed to read tag", err)
+			return
+		}
+	} else {
+		// Вариант имени сводится к каноническому тегу
+		tagID, name, err = resolveTag(ctx, tx, name)
+		if err != nil {
+			writeDBError(w, r, "Failed to create tag", err)
+			return
+		}
+		if tagID == 0 {
+			writeValidationError(w, r, []FieldError{{Field: "name", Message: "must contain letters or digits"}})
+			return
+		}
+	}
+
+	res, err := tx.Exec(ctx, "INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", postID, tagID)
+	if err != nil {
+		writeDBError(w, r, "Failed to link tag", err)
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		writeDBError(w, r, "Failed to commit transaction", err)
+		return
+	}
+
+	if res.RowsAffected() > 0 && postStatus == postStatusApproved {
+		go h.mongo.AddTagToPost(context.Background(), postID, name)
+	}
+	h.invalidatePostTagCache(ctx, postID)
+
+	status := http.StatusCreated
+	if res.RowsAffected() == 0 {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"post_id": postID,
+		"tag_id":  tagID,
+		"name":    name,
+	})
+}
+
+// v1RemovePostTagHandler отвязывает тег от поста
+func (h *Handlers) v1RemovePostTagHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, _ := strconv.Atoi(vars["id"])
+	tagID, _ := strconv.Atoi(vars["tag_id"])
+
+	ctx := r.Context()
+	conn, err := h.pool.Acquire(ctx, false) // Запись - только мастер
+	if err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, errCodeUnavailable, "Database temporarily unavailable")
+		return
+	}
+	defer conn.Release()
+
+	var name string
+	err = conn.QueryRow(ctx, `
+        DELETE FROM post_tags pt USING tags t
+        WHERE pt.post_id = $1 AND pt.tag_id = $2 AND t.tag_id = pt.tag_id
+        RETURNING t.name`, postID, tagID).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, errCodeNotFound, "Post tag not found")
+		return
+	}
+	if err != nil {
+		writeDBError(w, r, "Failed to remove tag", err)
+		return
+	}
+
+	go h.mongo.RemoveTagFromPost(context.Background(), postID, name)
+	h.invalidatePostTagCache(ctx, postID)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Tag removed",
+		"post_id": postID,
+		"tag_id":  tagID,
+	})
+}
+
+func (h *Handlers) invalidatePostTagCache(ctx context.Context, postID int) {
+	h.cache.Del(ctx, "cache:posts", "cache:posts:full", fmt.Sprintf("cache:posts:full:%d", postID),
+		"cache:tags", "cache:post_tags")
+}
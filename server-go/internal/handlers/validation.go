@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindNumber
+	kindTimestamp
+	kindStringList
+	kindObject
+	kindBool
+	kindIntList
+)
+
+// fieldRule описывает допустимое значение одного поля тела запроса
+type fieldRule struct {
+	Kind     fieldKind
+	Required bool
+	MaxLen   int // для строк и элементов списка, в символах
+	HasMin   bool
+	Min      float64
+	HasMax   bool
+	Max      float64
+}
+
+type bodySchema map[string]fieldRule
+
+const maxInt4 = math.MaxInt32
+
+// Короткие конструкторы правил
+func optString(maxLen int) fieldRule {
+	return fieldRule{Kind: kindString, MaxLen: maxLen}
+}
+
+func reqString(maxLen int) fieldRule {
+	return fieldRule{Kind: kindString, MaxLen: maxLen, Required: true}
+}
+
+func reqText() fieldRule {
+	return fieldRule{Kind: kindString, Required: true}
+}
+
+func optTimestamp() fieldRule {
+	return fieldRule{Kind: kindTimestamp}
+}
+
+func stringList(itemLen int) fieldRule {
+	return fieldRule{Kind: kindStringList, MaxLen: itemLen}
+}
+
+func intRange(min, max float64) fieldRule {
+	return fieldRule{Kind: kindInt, HasMin: true, Min: min, HasMax: true, Max: max}
+}
+
+// counterField - неотрицательный счётчик (INT)
+func counterField() fieldRule {
+	return intRange(0, maxInt4)
+}
+
+// refField - ссылка на SERIAL-ключ другой таблицы
+func refField() fieldRule {
+	return intRange(1, maxInt4)
+}
+
+func reqRefField() fieldRule {
+	r := refField()
+	r.Required = true
+	return r
+}
+
+// refList - непустой список ссылок на SERIAL-ключи
+func refList() fieldRule {
+	r := refField()
+	r.Kind, r.Required = kindIntList, true
+	return r
+}
+
+// Схемы тел POST/PUT для таблиц. Длины строк совпадают с VARCHAR в db/init.sql.
+// Таблицы и представления без схемы доступны только на чтение.
+var tableSchemas = map[string]bodySchema{
+	"users": {
+		"username":     optString(255),
+		"access_level": reqString(20),
+		"created_at":   optTimestamp(),
+	},
+	"authors": {
+		"name":                reqString(255),
+		"platform":            optString(50),
+		"external_id":         optString(255),
+		"profile_url":         optString(500),
+		"platform_reputation": fieldRule{Kind: kindInt, HasMin: true, Min: 0},
+	},
+	"news_texts": {
+		"text": reqText(),
+	},
+	"sources": {
+		"name":    reqString(255),
+		"address": reqString(255),
+		"topic":   optString(255),
+	},
+	"channels": {
+		"name":              reqString(255),
+		"link":              optString(255),
+		"subscribers_count": counterField(),
+		"source_id":         refField(),
+		"topic":             optString(255),
+	},
+	"posts": {
+		"title":          reqString(255),
+		"content":        reqText(),
+		"author_id":      reqRefField(),
+		"channel_id":     reqRefField(),
+		"text_id":        refField(),
+		"comments_count": counterField(),
+		"likes_count":    counterField(),
+		"created_at":     optTimestamp(),
+		"tags":           stringList(100),
+		"nsfw":           fieldRule{Kind: kindBool},
+		"metadata":       fieldRule{Kind: kindObject},
+	},
+	"media": {
+		"post_id":       reqRefField(),
+		"media_content": optString(1000),
+		"media_type":    optString(50),
+		"width":         counterField(),
+		"height":        counterField(),
+		"thumbnail_url": optString(1000),
+		"title":         optString(500),
+	},
+	"tags": {
+		"name": reqString(100),
+	},
+	"post_tags": {
+		"post_id": reqRefField(),
+		"tag_id":  reqRefField(),
+	},
+	"comments": {
+		"post_id":           reqRefField(),
+		"nickname":          reqString(255),
+		"parent_comment_id": refField(),
+		"text":              reqText(),
+		"created_at":        optTimestamp(),
+		"likes_count":       counterField(),
+	},
+}
+
+// postCreateSchema - тело создания поста: колонки posts, сигнал фильтра контента,
+// который не сохраняется в posts (пометка рекламы платформой), и просмотры
+// на платформе (хранятся только в статистике MongoDB)
+var postCreateSchema = func() bodySchema {
+	schema := bodySchema{
+		"marked_as_ads": {Kind: kindBool},
+		"views_count":   counterField(),
+	}
+	for key, rule := range tableSchemas["posts"] {
+		schema[key] = rule
+	}
+	return schema
+}()
+
+// Схема фильтров расширенного поиска
+var advancedSearchSchema = bodySchema{
+	"tags":         stringList(100),
+	"exclude_tags": stringList(100),
+	"lang":         optString(8),
+	"keywords":     stringList(100),
+	"min_likes":    fieldRule{Kind: kindNumber, HasMin: true, Min: 0},
+}
+
+// Схемы операций над постом в MongoDB
+var postOperationSchemas = map[string]bodySchema{
+	"increment_views": {},
+	"add_tag":         {"tag": reqString(100)},
+	"remove_tag":      {"tag": reqString(100)},
+	"update_stats": {
+		"likes_delta":    fieldRule{Kind: kindInt, Required: true, HasMin: true, Min: -maxInt4, HasMax: true, Max: maxInt4},
+		"comments_delta": fieldRule{Kind: kindInt, Required: true, HasMin: true, Min: -maxInt4, HasMax: true, Max: maxInt4},
+	},
+	"upsert": {
+		"data": fieldRule{Kind: kindObject, Required: true},
+	},
+}
+
+// validateBody проверяет тело запроса по схеме.
+// partial=true - режим PUT: обязательность полей не проверяется.
+func validateBody(schema bodySchema, data map[string]interface{}, partial bool) []FieldError {
+	var errs []FieldError
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		rule, ok := schema[key]
+		if !ok {
+			errs = append(errs, FieldError{Field: key, Message: "unknown field"})
+			continue
+		}
+		value := data[key]
+		if value == nil {
+			if rule.Required {
+				errs = append(errs, FieldError{Field: key, Message: "must not be null"})
+			}
+			continue
+		}
+		if msg := checkValue(rule, value); msg != "" {
+			errs = append(errs, FieldError{Field: key, Message: msg})
+		}
+	}
+
+	if !partial {
+		required := []string{}
+		for key, rule := range schema {
+			if _, present := data[key]; rule.Required && !present {
+				required = append(required, key)
+			}
+		}
+		sort.Strings(required)
+		for _, key := range required {
+			errs = append(errs, FieldError{Field: key, Message: "is required"})
+		}
+	}
+
+	return errs
+}
+
+func checkValue(rule fieldRule, value interface{}) string {
+	switch rule.Kind {
+	case kindString:
+		s, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if rule.Required && s == "" {
+			return "must not be empty"
+		}
+		if rule.MaxLen > 0 && utf8.RuneCountInString(s) > rule.MaxLen {
+			return fmt.Sprintf("must be at most %d characters", rule.MaxLen)
+		}
+
+	case kindInt, kindNumber:
+		n, ok := toFloat(value)
+		if !ok {
+			return "must be a number"
+		}
+		if rule.Kind == kindInt && n != math.Trunc(n) {
+			return "must be an integer"
+		}
+		if rule.HasMin && n < rule.Min {
+			return fmt.Sprintf("must be >= %v", rule.Min)
+		}
+		if rule.HasMax && n > rule.Max {
+			return fmt.Sprintf("must be <= %v", rule.Max)
+		}
+
+	case kindTimestamp:
+		s, ok := value.(string)
+		if !ok {
+			return "must be a timestamp string"
+		}
+		if _, err := parseTimestamp(s); err != nil {
+			return "must be in format 2006-01-02 15:04:05 or RFC3339"
+		}
+
+	case kindStringList:
+		var items []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			items = v
+		case []string:
+			for _, s := range v {
+				items = append(items, s)
+			}
+		default:
+			return "must be an array of strings"
+		}
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return "must be an array of strings"
+			}
+			if rule.MaxLen > 0 && utf8.RuneCountInString(s) > rule.MaxLen {
+				return fmt.Sprintf("items must be at most %d characters", rule.MaxLen)
+			}
+		}
+
+	case kindIntList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return "must be an array of integers"
+		}
+		if rule.Required && len(items) == 0 {
+			return "must not be empty"
+		}
+		item := rule
+		item.Kind = kindInt
+		for _, v := range items {
+			if msg := checkValue(item, v); msg != "" {
+				return "items " + msg
+			}
+		}
+
+	case kindObject:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return "must be an object"
+		}
+
+	case kindBool:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	}
+	return ""
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// parseTimestamp понимает формат, который шлют researcher-ы, и RFC3339
+func parseTimestamp(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02 15:04:05", s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,371 @@
+package keywords
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"news-aggregator/internal/tagnorm"
+)
+
+// Entry - сущность словаря: каноническое имя и варианты написания.
+// Варианты сравниваются по tagnorm.Key, поэтому падежные формы ("Москве",
+// "Путина") и латиница находят ту же запись. Вариант из заглавных букв
+// ("ЕС", "WHO") совпадает только с написанием заглавными.
+type Entry struct {
+	Name     string
+	Kind     string
+	Variants []string
+}
+
+// token - слово или отдельный знак препинания
+type token struct {
+	text string
+	word bool
+}
+
+type dictMatch struct {
+	entry *Entry
+	upper bool
+}
+
+type dictionary struct {
+	byKey  map[string][]dictMatch
+	maxLen int
+}
+
+var builtin = compile(Dictionary)
+
+func compile(entries []Entry) *dictionary {
+	d := &dictionary{byKey: map[string][]dictMatch{}}
+	for i := range entries {
+		e := &entries[i]
+		for _, v := range append([]string{e.Name}, e.Variants...) {
+			key := tagnorm.Key(v)
+			if key == "" {
+				continue
+			}
+			d.byKey[key] = append(d.byKey[key], dictMatch{entry: e, upper: isUpper(v)})
+			if n := len(strings.Fields(key)); n > d.maxLen {
+				d.maxLen = n
+			}
+		}
+	}
+	return d
+}
+
+// Entities - люди, организации и места в тексте: записи словаря, а также
+// "Имя Фамилия" с известным именем, названия после ООО/ПАО/АО и перед Inc/Ltd.
+func Entities(text string) []Term {
+	tokens := tokenize(text)
+
+	type found struct {
+		name  string
+		kind  string
+		count int
+	}
+	byKey := map[string]*found{}
+	var order []string
+	add := func(name, kind string) {
+		key := kind + ":" + tagnorm.Key(name)
+		if f, ok := byKey[key]; ok {
+			f.count++
+			return
+		}
+		byKey[key] = &found{name: name, kind: kind, count: 1}
+		order = append(order, key)
+	}
+
+	for i := 0; i < len(tokens); {
+		if !tokens[i].word {
+			i++
+			continue
+		}
+		if e, n := builtin.match(tokens, i); e != nil {
+			add(e.Name, e.Kind)
+			i += n
+			continue
+		}
+		if name, n := orgByMarker(tokens, i); n > 0 {
+			add(name, KindOrganization)
+			i += n
+			continue
+		}
+		if name, n := personByFirstName(tokens, i); n > 0 {
+			add(name, KindPerson)
+			i += n
+			continue
+		}
+		i++
+	}
+
+	list := make([]*found, 0, len(order))
+	total := 0
+	for _, key := range order {
+		list = append(list, byKey[key])
+		total += byKey[key].count
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].count > list[j].count })
+	if len(list) > MaxEntities {
+		list = list[:MaxEntities]
+	}
+
+	result := make([]Term, 0, len(list))
+	for _, f := range list {
+		result = append(result, Term{Term: f.name, Kind: f.kind, Score: round(float64(f.count) / float64(total))})
+	}
+	return result
+}
+
+// match - самая длинная запись словаря, начинающаяся с tokens[i], и число её слов
+func (d *dictionary) match(tokens []token, i int) (*Entry, int) {
+	for n := d.maxLen; n >= 1; n-- {
+		words := wordRun(tokens, i, n)
+		if words == nil {
+			continue
+		}
+		// Имена собственные пишутся с заглавной: "мир" - не "Мир"
+		if !isCapitalized(words[0]) {
+			continue
+		}
+		for _, m := range d.byKey[tagnorm.Key(strings.Join(words, " "))] {
+			if m.upper && !isUpper(strings.Join(words, "")) {
+				continue
+			}
+			return m.entry, n
+		}
+	}
+	return nil, 0
+}
+
+// orgByMarker - "ООО «Ромашка»", "ПАО Газпромнефть", "Acme Inc": название без формы собственности
+func orgByMarker(tokens []token, i int) (string, int) {
+	if orgPrefixes[tokens[i].text] {
+		j := i + 1
+		if j < len(tokens) && !tokens[j].word && isQuote(tokens[j].text) {
+			var words []string
+			for k := j + 1; k < len(tokens) && k <= j+5; k++ {
+				if !tokens[k].word {
+					if isQuote(tokens[k].text) && len(words) > 0 {
+						return strings.Join(words, " "), k - i + 1
+					}
+					break
+				}
+				words = append(words, tokens[k].text)
+			}
+			return "", 0
+		}
+		if j < len(tokens) && tokens[j].word && isCapitalized(tokens[j].text) {
+			return tokens[j].text, 2
+		}
+		return "", 0
+	}
+
+	// Капитализированные слова перед Inc, Ltd и т. п.
+	var words []string
+	for k := i; k < len(tokens) && k < i+4; k++ {
+		if !tokens[k].word {
+			break
+		}
+		if orgSuffixes[strings.ToLower(tokens[k].text)] && len(words) > 0 {
+			return strings.Join(words, " "), k - i + 1
+		}
+		if !isCapitalized(tokens[k].text) {
+			break
+		}
+		words = append(words, tokens[k].text)
+	}
+	return "", 0
+}
+
+// personByFirstName - известное имя и следующее за ним слово с заглавной буквы
+func personByFirstName(tokens []token, i int) (string, int) {
+	words := wordRun(tokens, i, 2)
+	if words == nil || !isCapitalized(words[0]) || !isCapitalized(words[1]) || isUpper(words[1]) {
+		return "", 0
+	}
+	if !firstNames[nameKey(words[0])] || stopWords[strings.ToLower(words[1])] {
+		return "", 0
+	}
+	return words[0] + " " + words[1], 2
+}
+
+// wordRun - n слов подряд начиная с tokens[i], nil если между ними есть знаки
+func wordRun(tokens []token, i, n int) []string {
+	if i+n > len(tokens) {
+		return nil
+	}
+	words := make([]string, 0, n)
+	for _, t := range tokens[i : i+n] {
+		if !t.word {
+			return nil
+		}
+		words = append(words, t.text)
+	}
+	return words
+}
+
+// tokenize разбивает текст на слова (с дефисами внутри) и знаки препинания, пробелы отбрасываются
+func tokenize(text string) []token {
+	var tokens []token
+	var word []rune
+	endWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, token{text: strings.TrimRight(string(word), "-"), word: true})
+			word = word[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		case r == '-' && len(word) > 0:
+			word = append(word, r)
+		case unicode.IsSpace(r):
+			endWord()
+		default:
+			endWord()
+			tokens = append(tokens, token{text: string(r)})
+		}
+	}
+	endWord()
+	return tokens
+}
+
+func isCapitalized(w string) bool {
+	for _, r := range w {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+func isUpper(w string) bool {
+	letters := 0
+	for _, r := range w {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters > 0
+}
+
+func isQuote(s string) bool {
+	return strings.ContainsAny(s, "«»\"“”„'")
+}
+
+// orgPrefixes - формы собственности перед названием организации
+var orgPrefixes = toSet("ООО", "ОАО", "ПАО", "ЗАО", "АО", "НКО", "АНО", "ГК", "ФГУП", "МУП")
+
+// orgSuffixes - формы собственности после названия
+var orgSuffixes = toSet("inc", "ltd", "llc", "corp", "corporation", "gmbh", "plc", "ag")
+
+// nameKey - ключ имени: основа tagnorm.Key, отрезанная повторно, чтобы "Сергей"
+// и "Сергея" совпали
+func nameKey(w string) string {
+	return tagnorm.Key(tagnorm.Key(w))
+}
+
+// firstNames - ключи (nameKey) распространённых имён: "Сергея Иванова" - тоже человек
+var firstNames = func() map[string]bool {
+	names := []string{
+		"александр", "алексей", "анатолий", "андрей", "анна", "анастасия", "антон", "артём",
+		"борис", "вадим", "валентина", "валерий", "василий", "вера", "виктор", "виктория",
+		"виталий", "владимир", "владислав", "галина", "геннадий", "георгий", "григорий",
+		"дарья", "денис", "дмитрий", "евгений", "евгения", "екатерина", "елена", "иван",
+		"игорь", "илья", "ирина", "кирилл", "константин", "ксения", "леонид", "людмила",
+		"максим", "марина", "мария", "михаил", "надежда", "наталья", "никита", "николай",
+		"олег", "ольга", "павел", "пётр", "роман", "руслан", "светлана", "сергей", "станислав",
+		"татьяна", "тимур", "фёдор", "юлия", "юрий", "яна", "ярослав",
+		"alexander", "andrew", "anna", "bill", "charles", "daniel", "david", "donald", "elizabeth",
+		"elon", "emma", "george", "jack", "james", "jeff", "joe", "john", "joseph", "kamala",
+		"mark", "mary", "michael", "paul", "peter", "richard", "robert", "sam", "sarah", "thomas",
+		"tim", "william",
+	}
+	set := map[string]bool{}
+	for _, n := range names {
+		set[nameKey(n)] = true
+	}
+	return set
+}()
+
+// Dictionary - встроенный словарь сущностей
+var Dictionary = []Entry{
+	// Места
+	{Name: "Россия", Kind: KindLocation, Variants: []string{"РФ", "Российская Федерация", "Russia"}},
+	{Name: "Москва", Kind: KindLocation, Variants: []string{"Moscow"}},
+	{Name: "Санкт-Петербург", Kind: KindLocation, Variants: []string{"Петербург", "Питер", "Saint Petersburg", "St Petersburg"}},
+	{Name: "Новосибирск", Kind: KindLocation, Variants: []string{"Novosibirsk"}},
+	{Name: "Екатеринбург", Kind: KindLocation, Variants: []string{"Yekaterinburg"}},
+	{Name: "Казань", Kind: KindLocation, Variants: []string{"Kazan"}},
+	{Name: "Нижний Новгород", Kind: KindLocation, Variants: []string{"Nizhny Novgorod"}},
+	{Name: "Сибирь", Kind: KindLocation, Variants: []string{"Siberia"}},
+	{Name: "Крым", Kind: KindLocation, Variants: []string{"Crimea"}},
+	{Name: "Украина", Kind: KindLocation, Variants: []string{"Україна", "Ukraine"}},
+	{Name: "Киев", Kind: KindLocation, Variants: []string{"Київ", "Kyiv", "Kiev"}},
+	{Name: "Беларусь", Kind: KindLocation, Variants: []string{"Белоруссия", "Belarus"}},
+	{Name: "Минск", Kind: KindLocation, Variants: []string{"Minsk"}},
+	{Name: "Казахстан", Kind: KindLocation, Variants: []string{"Kazakhstan"}},
+	{Name: "США", Kind: KindLocation, Variants: []string{"Соединённые Штаты", "United States", "USA"}},
+	{Name: "Нью-Йорк", Kind: KindLocation, Variants: []string{"New York"}},
+	{Name: "Китай", Kind: KindLocation, Variants: []string{"КНР", "China"}},
+	{Name: "Пекин", Kind: KindLocation, Variants: []string{"Beijing"}},
+	{Name: "Япония", Kind: KindLocation, Variants: []string{"Japan"}},
+	{Name: "Индия", Kind: KindLocation, Variants: []string{"India"}},
+	{Name: "Германия", Kind: KindLocation, Variants: []string{"ФРГ", "Germany"}},
+	{Name: "Берлин", Kind: KindLocation, Variants: []string{"Berlin"}},
+	{Name: "Франция", Kind: KindLocation, Variants: []string{"France"}},
+	{Name: "Париж", Kind: KindLocation, Variants: []string{"Paris"}},
+	{Name: "Великобритания", Kind: KindLocation, Variants: []string{"Британия", "United Kingdom", "Britain", "UK"}},
+	{Name: "Лондон", Kind: KindLocation, Variants: []string{"London"}},
+	{Name: "Турция", Kind: KindLocation, Variants: []string{"Turkey", "Türkiye"}},
+	{Name: "Израиль", Kind: KindLocation, Variants: []string{"Israel"}},
+	{Name: "Европа", Kind: KindLocation, Variants: []string{"Europe"}},
+
+	// Организации
+	{Name: "ООН", Kind: KindOrganization, Variants: []string{"United Nations", "UN"}},
+	{Name: "НАТО", Kind: KindOrganization, Variants: []string{"NATO"}},
+	{Name: "Евросоюз", Kind: KindOrganization, Variants: []string{"ЕС", "Европейский союз", "European Union", "EU"}},
+	{Name: "ВОЗ", Kind: KindOrganization, Variants: []string{"World Health Organization", "WHO"}},
+	{Name: "Госдума", Kind: KindOrganization, Variants: []string{"Государственная дума", "State Duma"}},
+	{Name: "Кремль", Kind: KindOrganization, Variants: []string{"Kremlin"}},
+	{Name: "Банк России", Kind: KindOrganization, Variants: []string{"Центробанк", "ЦБ", "Central Bank of Russia"}},
+	{Name: "МИД", Kind: KindOrganization},
+	{Name: "МВД", Kind: KindOrganization},
+	{Name: "ФСБ", Kind: KindOrganization},
+	{Name: "МЧС", Kind: KindOrganization},
+	{Name: "Минздрав", Kind: KindOrganization},
+	{Name: "Минобороны", Kind: KindOrganization},
+	{Name: "Сбербанк", Kind: KindOrganization, Variants: []string{"Сбер", "Sberbank"}},
+	{Name: "Газпром", Kind: KindOrganization, Variants: []string{"Gazprom"}},
+	{Name: "Роснефть", Kind: KindOrganization, Variants: []string{"Rosneft"}},
+	{Name: "Яндекс", Kind: KindOrganization, Variants: []string{"Yandex"}},
+	{Name: "ВКонтакте", Kind: KindOrganization, Variants: []string{"VK"}},
+	{Name: "Telegram", Kind: KindOrganization, Variants: []string{"Телеграм", "Телеграмм"}},
+	{Name: "Google", Kind: KindOrganization, Variants: []string{"Гугл"}},
+	{Name: "Apple", Kind: KindOrganization},
+	{Name: "Microsoft", Kind: KindOrganization, Variants: []string{"Майкрософт"}},
+	{Name: "Amazon", Kind: KindOrganization},
+	{Name: "Meta", Kind: KindOrganization},
+	{Name: "OpenAI", Kind: KindOrganization},
+	{Name: "Tesla", Kind: KindOrganization, Variants: []string{"Тесла"}},
+	{Name: "SpaceX", Kind: KindOrganization},
+
+	// Люди
+	{Name: "Владимир Путин", Kind: KindPerson, Variants: []string{"Путин", "Vladimir Putin", "Putin"}},
+	{Name: "Михаил Мишустин", Kind: KindPerson, Variants: []string{"Мишустин", "Mishustin"}},
+	{Name: "Сергей Лавров", Kind: KindPerson, Variants: []string{"Лавров", "Lavrov"}},
+	{Name: "Сергей Собянин", Kind: KindPerson, Variants: []string{"Собянин", "Sobyanin"}},
+	{Name: "Александр Лукашенко", Kind: KindPerson, Variants: []string{"Лукашенко", "Lukashenko"}},
+	{Name: "Владимир Зеленский", Kind: KindPerson, Variants: []string{"Зеленский", "Volodymyr Zelensky", "Zelensky", "Zelenskyy"}},
+	{Name: "Дональд Трамп", Kind: KindPerson, Variants: []string{"Трамп", "Donald Trump", "Trump"}},
+	{Name: "Джо Байден", Kind: KindPerson, Variants: []string{"Байден", "Joe Biden", "Biden"}},
+	{Name: "Си Цзиньпин", Kind: KindPerson, Variants: []string{"Xi Jinping"}},
+	{Name: "Эммануэль Макрон", Kind: KindPerson, Variants: []string{"Макрон", "Emmanuel Macron", "Macron"}},
+	{Name: "Илон Маск", Kind: KindPerson, Variants: []string{"Маск", "Elon Musk", "Musk"}},
+	{Name: "Павел Дуров", Kind: KindPerson, Variants: []string{"Дуров", "Pavel Durov", "Durov"}},
+	{Name: "Марк Цукерберг", Kind: KindPerson, Variants: []string{"Цукерберг", "Mark Zuckerberg", "Zuckerberg"}},
+	{Name: "Сэм Альтман", Kind: KindPerson, Variants: []string{"Альтман", "Sam Altman", "Altman"}},
+}
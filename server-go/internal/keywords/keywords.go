@@ -0,0 +1,245 @@
+// Package keywords - извлечение ключевых фраз (RAKE) и именованных сущностей
+// (люди, организации, места) из текста поста без внешних сервисов. Результат
+// хранится рядом с тегами и дополняет их для постов без хэштегов.
+package keywords
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"news-aggregator/internal/tagnorm"
+)
+
+// Виды извлечённых терминов (post_keywords.kind)
+const (
+	KindKeyword      = "keyword"
+	KindPerson       = "person"
+	KindOrganization = "organization"
+	KindLocation     = "location"
+)
+
+// Kinds - все виды терминов
+var Kinds = []string{KindKeyword, KindPerson, KindOrganization, KindLocation}
+
+const (
+	// MaxKeywords - не больше стольких ключевых фраз на пост
+	MaxKeywords = 10
+	// MaxEntities - не больше стольких сущностей на пост
+	MaxEntities = 15
+	// maxPhraseWords - более длинные фразы-кандидаты отбрасываются
+	maxPhraseWords = 3
+	// minWordRunes - более короткие слова не становятся ключевыми
+	minWordRunes = 3
+	// titleBoost - множитель веса фраз, встречающихся в заголовке
+	titleBoost = 1.5
+)
+
+// Term - ключевая фраза или сущность. Score нормирован: у лучшей фразы поста 1,
+// у сущности - доля её упоминаний среди всех найденных сущностей.
+type Term struct {
+	Term  string  `json:"term"`
+	Kind  string  `json:"kind"`
+	Score float64 `json:"score"`
+}
+
+// Extract - ключевые фразы и сущности поста. Сущности идут первыми.
+func Extract(title, content string) []Term {
+	terms := Entities(title + "\n" + content)
+	seen := map[string]bool{}
+	for _, t := range terms {
+		seen[tagnorm.Key(t.Term)] = true
+	}
+	for _, t := range Keywords(title, content) {
+		// Фраза, совпавшая с сущностью или её вариантом ("elon musk"), уже есть в списке
+		key := tagnorm.Key(t.Term)
+		if !seen[key] && builtin.byKey[key] == nil {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// Supported - допустимое значение kind
+func Supported(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Keywords - ключевые фразы по алгоритму RAKE: текст режется на фразы-кандидаты
+// по знакам препинания и служебным словам, вес слова - отношение его степени
+// (сумма длин фраз со словом) к частоте, вес фразы - сумма весов её слов.
+// Варианты одной фразы ("выборы мэра", "выборах мэра") сливаются по tagnorm.Key.
+func Keywords(title, content string) []Term {
+	titlePhrases := map[string]bool{}
+	for _, p := range candidates(title) {
+		titlePhrases[phraseKey(p)] = true
+	}
+	phrases := append(candidates(title), candidates(content)...)
+	if len(phrases) == 0 {
+		return nil
+	}
+
+	freq := map[string]int{}
+	degree := map[string]int{}
+	for _, p := range phrases {
+		for _, w := range p {
+			freq[w]++
+			degree[w] += len(p)
+		}
+	}
+
+	type scored struct {
+		display string
+		score   float64
+		count   int
+	}
+	byKey := map[string]*scored{}
+	var order []string
+	for _, p := range phrases {
+		key := phraseKey(p)
+		if s, ok := byKey[key]; ok {
+			s.count++
+			continue
+		}
+		score := 0.0
+		for _, w := range p {
+			score += float64(degree[w]) / float64(freq[w])
+		}
+		if titlePhrases[key] {
+			score *= titleBoost
+		}
+		byKey[key] = &scored{display: strings.Join(p, " "), score: score, count: 1}
+		order = append(order, key)
+	}
+
+	var list []*scored
+	for _, key := range order {
+		s := byKey[key]
+		// Одиночное слово, встреченное один раз, - шум, а не тема поста
+		if !strings.Contains(s.display, " ") && s.count < 2 && !titlePhrases[key] {
+			continue
+		}
+		// Повторы фразы усиливают её, но слабее, чем в чистой частотной модели
+		s.score *= 1 + 0.5*float64(s.count-1)
+		list = append(list, s)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].score > list[j].score })
+	if len(list) > MaxKeywords {
+		list = list[:MaxKeywords]
+	}
+
+	result := make([]Term, 0, len(list))
+	for _, s := range list {
+		result = append(result, Term{Term: s.display, Kind: KindKeyword, Score: round(s.score / list[0].score)})
+	}
+	return result
+}
+
+// candidates - фразы-кандидаты RAKE: слова в нижнем регистре между разделителями
+func candidates(text string) [][]string {
+	var phrases [][]string
+	var current []string
+	flush := func() {
+		if len(current) > 0 && len(current) <= maxPhraseWords {
+			phrases = append(phrases, current)
+		}
+		current = nil
+	}
+
+	var word []rune
+	endWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		w := strings.ToLower(string(word))
+		word = word[:0]
+		if stopWords[w] || len([]rune(w)) < minWordRunes || isNumber(w) {
+			flush()
+			return
+		}
+		current = append(current, w)
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		case r == '-' && len(word) > 0:
+			// "северо-запад", "covid-19" - одно слово
+			word = append(word, r)
+		case unicode.IsSpace(r):
+			endWord()
+		default:
+			endWord()
+			flush()
+		}
+	}
+	endWord()
+	flush()
+	return phrases
+}
+
+func phraseKey(words []string) string {
+	return tagnorm.Key(strings.Join(words, " "))
+}
+
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func round(v float64) float64 {
+	return float64(int(v*1000+0.5)) / 1000
+}
+
+// stopWords - служебные и общие слова, разделяющие фразы-кандидаты
+var stopWords = toSet(
+	// русские
+	"и", "в", "во", "не", "что", "он", "на", "я", "с", "со", "как", "а", "то", "все", "всё", "она",
+	"так", "его", "но", "да", "ты", "к", "у", "же", "вы", "за", "бы", "по", "только", "ее", "её",
+	"мне", "было", "вот", "от", "меня", "еще", "ещё", "нет", "о", "об", "из", "ему", "теперь", "когда",
+	"даже", "ну", "ли", "если", "уже", "или", "ни", "быть", "был", "была", "были", "будет", "будут",
+	"него", "до", "вас", "нибудь", "опять", "уж", "вам", "ведь", "там", "потом", "себя", "ничего",
+	"ей", "может", "они", "тут", "где", "есть", "надо", "ней", "для", "мы", "тебя", "их", "чем",
+	"сам", "чтоб", "чтобы", "без", "будто", "чего", "раз", "тоже", "себе", "под", "ж", "тогда",
+	"кто", "этот", "этого", "этой", "этом", "этому", "эти", "этих", "это", "того", "потому",
+	"какой", "какая", "какие", "совсем", "ним", "здесь", "один", "почти", "мой", "тем", "сейчас",
+	"куда", "зачем", "всех", "никогда", "можно", "при", "наконец", "два", "другой", "хоть",
+	"после", "над", "больше", "тот", "через", "эта", "нас", "про", "всего", "них", "много",
+	"разве", "три", "эту", "моя", "впрочем", "хорошо", "свою", "своей", "свои", "своих", "своего",
+	"перед", "иногда", "лучше", "чуть", "том", "нельзя", "такой", "им", "более", "всегда",
+	"конечно", "всю", "между", "который", "которая", "которое", "которые", "которых", "которым",
+	"также", "однако", "пока", "ранее", "около", "сегодня", "вчера", "завтра",
+	"заявил", "заявила", "сообщил", "сообщила", "сообщает", "сообщили", "отметил", "отметила",
+	"рассказал", "рассказала", "году", "года", "год", "лет", "время", "очень", "весь", "вся",
+	"новости", "новость",
+	// украинские
+	"і", "й", "та", "що", "це", "як", "від", "які", "який", "яка", "але", "вже", "також", "бути",
+	// английские
+	"a", "an", "the", "and", "or", "but", "if", "of", "at", "by", "for", "with", "about", "against",
+	"between", "into", "through", "during", "before", "after", "above", "below", "to", "from", "up",
+	"down", "in", "out", "on", "off", "over", "under", "again", "then", "once", "here", "there",
+	"when", "where", "why", "how", "all", "any", "both", "each", "few", "more", "most", "other",
+	"some", "such", "no", "nor", "not", "only", "own", "same", "so", "than", "too", "very", "can",
+	"will", "just", "should", "now", "is", "are", "was", "were", "be", "been", "being", "have",
+	"has", "had", "do", "does", "did", "it", "its", "this", "that", "these", "those", "he", "she",
+	"they", "them", "his", "her", "their", "we", "you", "your", "our", "who", "whom", "which",
+	"what", "said", "says", "also", "would", "could", "new", "news", "year", "years", "today",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,110 @@
+package keywords
+
+import (
+	"reflect"
+	"testing"
+)
+
+const electionText = "В сентябре - выборы мэра Москвы. Комиссия назначила дату. " +
+	"О выборах мэра Москвы, как и о дате, горожан известят письмом."
+
+func TestKeywords(t *testing.T) {
+	tests := []struct {
+		name, title string
+		want        []Term
+	}{
+		// "выборах мэра Москвы" - вариант той же фразы; "сентябре" встретилось один раз
+		{"no title", "", []Term{
+			{"выборы мэра москвы", KindKeyword, 1},
+			{"комиссия назначила дату", KindKeyword, 0.667},
+			{"горожан известят письмом", KindKeyword, 0.667},
+		}},
+		// Фразы заголовка весят в titleBoost раз больше, одиночное слово из заголовка остаётся
+		{"title", "Выборы мэра Москвы: дата", []Term{
+			{"выборы мэра москвы", KindKeyword, 1},
+			{"комиссия назначила дату", KindKeyword, 0.333},
+			{"горожан известят письмом", KindKeyword, 0.333},
+			{"дата", KindKeyword, 0.083},
+		}},
+	}
+	for _, tt := range tests {
+		if got := Keywords(tt.title, electionText); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: keywords =\n%v\nwant\n%v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestKeywordsCandidates(t *testing.T) {
+	// Служебные слова, числа и короткие слова режут фразы; фразы длиннее
+	// maxPhraseWords отбрасываются
+	got := candidates("Курс доллара на бирже вырос до 92 рублей, аналитики ждут дальнейшего роста курса валюты")
+	want := [][]string{{"курс", "доллара"}, {"бирже", "вырос"}, {"рублей"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want %v", got, want)
+	}
+	if got := Keywords("", "и в на, 2024"); len(got) != 0 {
+		t.Errorf("keywords of stop words = %v", got)
+	}
+}
+
+func TestEntities(t *testing.T) {
+	text := "Путин провёл встречу с Си Цзиньпином в Пекине. Владимир Путин и председатель КНР обсудили торговлю. " +
+		"ООО «Ромашка» и Acme Inc подписали контракт, рассказал Сергей Иванов."
+	want := []Term{
+		// Варианты и падежные формы - одна сущность с каноническим именем
+		{"Владимир Путин", KindPerson, 0.25},
+		{"Си Цзиньпин", KindPerson, 0.125},
+		{"Пекин", KindLocation, 0.125},
+		{"Китай", KindLocation, 0.125},
+		{"Ромашка", KindOrganization, 0.125},
+		{"Acme", KindOrganization, 0.125},
+		// Не из словаря: известное имя и фамилия
+		{"Сергей Иванов", KindPerson, 0.125},
+	}
+	if got := Entities(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("entities =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestEntitiesCase(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		// Аббревиатура из словаря совпадает только заглавными
+		{"ЕС ввёл пошлины", []string{"Евросоюз"}},
+		{"это ес или нет", nil},
+		// Имя собственное - с заглавной буквы
+		{"в москве и в Москве", []string{"Москва"}},
+		{"WHO warns, who knows", []string{"ВОЗ"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range Entities(tt.text) {
+			got = append(got, e.Term)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Entities(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	got := Extract("Илон Маск представил ракету SpaceX", "Elon Musk показал ракету Starship. Испытания ракеты пройдут летом.")
+	// Сущности первыми; "Elon Musk" - вариант сущности, а не ключевая фраза
+	want := []Term{
+		{"Илон Маск", KindPerson, 0.667},
+		{"SpaceX", KindOrganization, 0.333},
+	}
+	if len(got) < len(want) || !reflect.DeepEqual(got[:len(want)], want) {
+		t.Fatalf("terms = %v, want %v first", got, want)
+	}
+	for _, term := range got[len(want):] {
+		if term.Kind != KindKeyword || term.Term == "elon musk" || term.Term == "илон маск" {
+			t.Errorf("unexpected term %v", term)
+		}
+	}
+	if !Supported(KindLocation) || Supported("topic") {
+		t.Error("Supported mismatch")
+	}
+}
@@ -0,0 +1,193 @@
+// Package langdetect - определение языка поста без сетевых сервисов: по алфавиту
+// выбираются языки-кандидаты, затем триграммы текста сверяются с профилями самых
+// частых триграмм языков. Чем выше триграмма в профиле, тем вероятнее язык; при
+// малом отрыве лучшего языка от второго язык не определяется.
+package langdetect
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Коды языков ISO 639-1
+const (
+	Russian   = "ru"
+	Ukrainian = "uk"
+	English   = "en"
+	German    = "de"
+	French    = "fr"
+	Spanish   = "es"
+	// Unknown - текст слишком короткий или алфавит не поддерживается
+	Unknown = "und"
+)
+
+const (
+	// minLetters - в более коротких текстах язык не определяется
+	minLetters = 12
+	// minConfidence - при меньшей уверенности язык считается неопределённым
+	minConfidence = 0.2
+	// confidenceScale переводит отрыв в средней логарифмической вероятности в уверенность
+	confidenceScale = 8.0
+	// rankOffset сглаживает разницу между первыми триграммами профиля
+	rankOffset = 5
+	// missPenalty - ранг, добавляемый к длине профиля для отсутствующей триграммы
+	missPenalty = 100
+)
+
+// Languages - поддерживаемые коды для фильтров lang=
+var Languages = []string{Russian, Ukrainian, English, German, French, Spanish, Unknown}
+
+// mongoLanguages - значения поля language для текстового индекса MongoDB.
+// Украинского стемминга в MongoDB нет, как и для неопределённого языка.
+var mongoLanguages = map[string]string{
+	Russian: "russian",
+	English: "english",
+	German:  "german",
+	French:  "french",
+	Spanish: "spanish",
+}
+
+// ranks - ранги триграмм профилей
+var ranks = map[string]map[string]int{}
+
+func init() {
+	for lang, grams := range profiles {
+		ranks[lang] = make(map[string]int, len(grams))
+		for i, g := range grams {
+			ranks[lang][g] = i
+		}
+	}
+}
+
+// Буквы, которые есть только в одном из кириллических языков
+const (
+	ukrainianOnly = "іїєґ"
+	russianOnly   = "ыэъё"
+)
+
+// Result - определённый язык и уверенность 0..1
+type Result struct {
+	Lang       string  `json:"lang"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Detect определяет язык текста
+func Detect(text string) Result {
+	text = strings.ToLower(text)
+	var cyrillic, latin, ukLetters, ruLetters int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			if strings.ContainsRune(ukrainianOnly, r) {
+				ukLetters++
+			} else if strings.ContainsRune(russianOnly, r) {
+				ruLetters++
+			}
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	if cyrillic+latin < minLetters {
+		return Result{Lang: Unknown}
+	}
+
+	var candidates []string
+	if cyrillic >= latin {
+		// Буквы одного алфавита решают, если других нет
+		switch {
+		case ukLetters > 0 && ruLetters == 0:
+			return Result{Lang: Ukrainian, Confidence: 1}
+		case ruLetters > 0 && ukLetters == 0:
+			return Result{Lang: Russian, Confidence: 1}
+		}
+		candidates = []string{Russian, Ukrainian}
+	} else {
+		candidates = []string{English, German, French, Spanish}
+	}
+
+	counts := trigrams(text)
+	best, second := candidates[0], ""
+	scores := map[string]float64{}
+	for _, lang := range candidates {
+		scores[lang] = score(counts, lang)
+		switch {
+		case scores[lang] > scores[best]:
+			best, second = lang, best
+		case lang != best && (second == "" || scores[lang] > scores[second]):
+			second = lang
+		}
+	}
+
+	// Уверенность - вероятность лучшего языка против второго по отрыву в средней
+	// логарифмической вероятности триграмм
+	confidence := 2/(1+math.Exp(-confidenceScale*(scores[best]-scores[second]))) - 1
+	if confidence < minConfidence {
+		return Result{Lang: Unknown, Confidence: math.Round(confidence*1000) / 1000}
+	}
+	return Result{Lang: best, Confidence: math.Round(confidence*1000) / 1000}
+}
+
+// MongoLanguage - значение поля language документа MongoDB для кода языка
+func MongoLanguage(lang string) string {
+	if l, ok := mongoLanguages[lang]; ok {
+		return l
+	}
+	return "none"
+}
+
+// Supported сообщает, можно ли фильтровать по коду
+func Supported(lang string) bool {
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// trigrams - число триграмм текста
+func trigrams(text string) map[string]int {
+	counts := map[string]int{}
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, w := range words {
+		runes := []rune("_" + w + "_")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+	return counts
+}
+
+// score - средняя логарифмическая вероятность триграмм текста в языке: вероятность
+// падает с рангом триграммы в профиле, отсутствующая триграмма дополнительно штрафуется
+func score(counts map[string]int, lang string) float64 {
+	var sum float64
+	total := 0
+	for g, n := range counts {
+		rank, ok := ranks[lang][g]
+		if !ok {
+			rank = len(ranks[lang]) + missPenalty
+		}
+		sum -= float64(n) * math.Log(float64(rank+rankOffset))
+		total += n
+	}
+	return sum / float64(total)
+}
+
+// split разбирает профиль; повторы отбрасываются, важен первый
+func split(s string) []string {
+	var grams []string
+	seen := map[string]bool{}
+	for _, g := range strings.Fields(s) {
+		if r := []rune(g); len(r) != 3 {
+			continue
+		}
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+	return grams
+}
@@ -0,0 +1,56 @@
+package langdetect
+
+import "testing"
+
+// Заголовки постов Reddit и VK
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Apple announces new iPad at event next week", English},
+		{"Elon Musk says Tesla will release new model", English},
+		{"Ukraine war: Russia launches missile strikes on Kyiv", English},
+		{"Scientists discover water on Mars", English},
+		{"Judge blocks federal ban on abortion pill", English},
+		{"Study finds microplastics in human brain tissue", English},
+		{"Japan earthquake: tsunami warning issued after strong quake", English},
+		{"TIL that the Eiffel Tower can grow by more than 6 inches in summer", English},
+		{"My cat refuses to sleep anywhere except on my keyboard", English},
+		{"Deutsche Bahn streicht Tausende Verbindungen wegen Streik", German},
+		{"Polizei sucht Zeugen nach Unfall in Berlin", German},
+		{"Le gouvernement annonce une hausse du prix de l'électricité", French},
+		{"Incendie dans un immeuble à Marseille : trois blessés", French},
+		{"El Gobierno aprueba la subida del salario mínimo", Spanish},
+		{"Detenido un hombre por el incendio de un edificio en Valencia", Spanish},
+		{"В Москве открылась новая станция метро", Russian},
+		{"Сборная России проиграла в финале", Russian},
+		{"Новости дня: главное к этому часу", Russian},
+		{"Київ накрила сильна злива", Ukrainian},
+		{"Потужна гроза на заході країни", Ukrainian},
+	}
+	for _, tt := range tests {
+		got := Detect(tt.text)
+		if got.Lang != tt.want {
+			t.Errorf("Detect(%q) = %+v, want %s", tt.text, got, tt.want)
+			continue
+		}
+		if got.Confidence < minConfidence {
+			t.Errorf("Detect(%q) confidence = %v, below %v", tt.text, got.Confidence, minConfidence)
+		}
+	}
+}
+
+func TestDetectUnknown(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"ok lol",
+		"12345 67890 !!!",
+		// Ни один профиль не выделяется
+		"zzzz qqqq xxxx kkkk",
+	} {
+		if got := Detect(text); got.Lang != Unknown {
+			t.Errorf("Detect(%q) = %+v, want %s", text, got, Unknown)
+		}
+	}
+}
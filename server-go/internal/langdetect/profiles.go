@@ -0,0 +1,90 @@
+package langdetect
+
+// profiles - по 300 самых частых триграмм языка в выборке новостных заголовков,
+// текстов и частотных слов, по убыванию частоты; "_" - граница слова
+var profiles = map[string][]string{
+	Russian: split(`ли_ _пр _по _в_ _на на_ ть_ _за про то_ _вы нов ся_ _и_ _но сле _ст али ия_ ова ой_ что
+		_го _чт ать го_ ом_ ые_ _мо _не ово ста тел _до _ко _ме _со ани ей_ ии_ ите лед но_ ого
+		оль ост пре тся _во _от _то _че ель ени или ки_ ове ода под рос ств стр тор ты_ ый_ _бо
+		_од _сл _эт ави ако бол во_ год да_ еду ели ие_ ко_ ла_ ле_ осл пос пра сти тре _из _ка
+		_лю _ра _ре ам_ бы_ вит гов едо ело ест за_ из_ ить лиц лов люд не_ ние ния ные ов_ овы
+		од_ одн оро от_ ото пла пол рав сто сь_ тво ти_ тра тьс ься это _ак _да _де _жи _ис _пл
+		_се _сп _су _та аза ак_ акц ала ают ая_ ва_ ван вод вые вый дал дан дел ди_ до_ дов его
+		ент ет_ же_ ка_ каз как кой кот кци льк льс нал нес нь_ обы оли ра_ ред рот сли ссл та_
+		тат тол том ции ция шли ько ьст ют_ _бы _ве _ещ _к_ _ма _ми _о_ _об _он _ро _ру _тр _уч
+		_це _ча ада ает анн аты ах_ вал ват век вор вос выр выс гор даю ден дер дет дит дол дую
+		ды_ ень ерж еск етс ещё зак зал ив_ изн ий_ ики ил_ ило иль исс ит_ итс ици кол кон лей
+		ло_ льн льш мен мес мет мос нак наш нед ном оби оди око оле он_ она оне ори оры оск оти
+		при пус рад раз рез рит род сил ска скв ско тав тив тит тоб убл уст ующ ход цен час чел
+		чен щё_ ьно юди _а_ _бе _вр _вс _гл _дв _же _ил _ин _ли`),
+	Ukrainian: split(`_по ли_ _на на_ _за _до _пр ого _пі го_ ть_ ів_ _ро _та _в_ _ви али ся_ ти_ _мі _що ати
+		ере ла_ ни_ ня_ про ста іль _у_ _як ди_ ий_ или ки_ ові рок стр та_ ува що_ _бу _ві _лю
+		_но _че аст від до_ люд лід ння ні_ ост пер слі ють _ки _ко _ра _ст _і_ бул дин дос ку_
+		льн ля_ міс нов ног оди пов пос під сти ії_ _во _кі _ма _ме _пе _ре _ін ала ают аїн вал
+		ві_ ез_ ене енн ень ив_ ин_ ини ися ка_ кра ків льк му_ нас ном нь_ олі ому осл ої_ піс
+		раї рез рос сля тер три трі тьс уст ься юди ід_ ісл іст ія_ _гр _да _де _зб _зр _мо _не
+		_св _си _сп _ти _то _тр _ук _ур _це _ці _ча _чо _ще ажд анн анц аш_ ван ває вці гра да_
+		дал дан ден дже ей_ ер_ жда же_ жен за_ зна зро ики ити иєв киє кіл лов ліц ми_ мож нал
+		не_ нер но_ ну_ нці ньо ова ове ода оді оже ок_ оку окі оло оро очі пла пол пус раж ред
+		рив ро_ ру_ ряд сту так тан тим тис тит тра туп укр ула ули упн уря ход ця_ ці_ ція ції
+		час чер чі_ ше_ ще_ ька ьно яви яд_ ять ідо ій_ іці _бе _вн _го _ен _з_ _зн _кр _оз _пл
+		_рі _се _ск _су _те _ту ави аза аке ако аль ано ані асу ахо аяв ає_ аєт бо_ був біл бір
+		ва_ вад ват вел вен вер ви_ вил вин вип вих вно вод вон вши вій віт год гри дей доб док
+		дом дів ент ерг ест ети жаю зав зал зап зах зая збі зон`),
+	English: split(`_th the es_ er_ he_ nd_ ng_ ing ed_ _an and st_ _co _st _of _re _in on_ re_ _to at_ in_
+		ter ver _fo ce_ ear of_ _wh le_ ts_ _la ve_ _a_ _ca _fi _ne oun to_ al_ ll_ res se_ _fa
+		_mo _pr _wa ain an_ ar_ en_ ent ove _be est for me_ ns_ _do _ma _sa _se her ion nt_ rs_
+		_ha _he ew_ hou _le _on _sh ate ave igh ld_ ne_ new und _go _ho _mi _we all as_ ay_ che
+		ere eve ght hat is_ nce str ch_ com eas ect lan or_ pla ree ry_ tes tio ure ut_ wer _de
+		_li _pa _pl _po _so _wi _wo ace hea ht_ ow_ sta ste tho unc _af _no _su _ta _te aft ces
+		ers fte it_ lea my_ ons out ple pro rd_ sho ss_ te_ ted th_ thi _bu _ch _di _ex _is _it
+		_my ad_ ck_ cou ct_ ds_ eat ee_ fir gh_ hin ice ill ine men omp ot_ our rou rt_ tha tur
+		ugh war _as _ea _ev _fr _pe _ru _si art ast el_ ern fac ge_ ide int nou one oug ous rea
+		sea ssi _ar _at _bo _fe _gr _lo _me _ov _sp _ti ach are arg ase cro din ead et_ fin hav
+		hed id_ ire ist ke_ ly_ mar mon nch od_ off ome ong ood ort rai rec red rge rn_ san sto
+		sur tin _ab _ac _ai _ba _cr _dr _en _hi _ra _ri _ro _un _ye _yo aid any app ark ati car
+		chi con day de_ dy_ eop ep_ his ial ike ild ile ime ind ive lau les lin ls_ min nds nte
+		ook opl ord oul owe peo pri ron ros sai ses tal ten thr`),
+	German: split(`en_ er_ ie_ _di die ein _de ine sch ten _ei nd_ der gen den _ha _un che nde _da _me ch_
+		ei_ hen men ste und ung _au _se _st in_ ner _ge _wi abe sei _in _we cht eit es_ ich it_
+		nge te_ _an _be _ma _vo _zu ach aus ben end ens ht_ ne_ nen ng_ nsc rde sen _na _sc and
+		ent ere ers eue hre ist nac rei ter zei _en _er _ne _so _wa am_ as_ ber das de_ eis em_
+		hab hat ier neu nte on_ ren sse um_ vor wie _ab _mi _um at_ auf des ehr ert ese ete etz
+		fen gie hr_ lan lle mit och str ue_ us_ ver wei zu_ _al _am _fr _ih _sa _si _ve _wu ang
+		ann ass bei ft_ he_ her iel ies ihr len mme nn_ nne or_ reg rte run sta tra uf_ unt urd
+		wur zen _do _dr _du _es _fa _fe _fü _he _hi _im _ja _ko _po _pr _ra _re _wo _ze _zw aft
+		ag_ ahr al_ an_ anz be_ cha chl dem ege egi eri erl eru est fan ffe fra für geg gel ges
+		haf ige ill ind ite ize jah le_ liz ll_ mal man meh mei nem oli oll omm org pol pro rer
+		rst ser sol ss_ tag tet tzt von war was wer wir zt_ ßen ür_ _bi _em _ex _fo _gr _je _ka
+		_ki _kö _la _le _ni _ob _pl _rü _ta _te _tr adt age agt ain ake all als ami ar_ aue aße
+		bis chi cho chu ckt dec doc dre dt_ dur ebe eck eic eig eil el_ ele emo emp ene erg ern
+		eut fes for ge_ geh gri gro gte gun hau hie hil hla hne`),
+	French: split(`es_ _de nt_ de_ _la _le les ent la_ ne_ ont _un ns_ des le_ que re_ on_ rs_ _pr _se ouv
+		_on eur ur_ uve _en ans et_ ue_ une _co _et _pa _pl _po _qu _so ce_ lle men te_ _ce _no
+		is_ it_ son tre _a_ _au _d_ _da _dé ain dan est iqu nou our plu té_ urs _fa _l_ _tr _vi
+		com ell eme er_ ers ill ion nes par qui ts_ ues un_ us_ ver _ma _mi _su ant elo en_ ie_
+		ien ine lon lus mai ntr omm onn res rès se_ sel ssi sur ter vel ès_ ée_ _av _do _du _es
+		_in _lo _mo _pe _sa _ét ais and aut ble con cou du_ ern fai ier ins iss me_ mme nde nne
+		oin oli ort ous per pol pou pre pro prè ran rem sie sse tio usi ère ées _ai _an _ap _ca
+		_di _go _gr _ne _nu _si _to ace ait anc apr ard art ass au_ ava ces cet emi ert ett fra
+		gou gra heu ice ide ieu ire iti ièr lan lem lic loi mil mis moi nem nts nui ois oul pas
+		por pré rne rso rt_ rte sem ses si_ sil sou ssé st_ ten tes tiq tou tra tro tte ui_ uit
+		ure uss éco és_ _ac _al _bl _ch _ex _fe _fi _fo _fr _he _il _jo _ra _re _ru _ré _s_ _vo
+		_éc _él _én _éq aid aie air ale ar_ as_ ati aus car cha che cla den dep der di_ don déc
+		déf eau ect ema emp enc end ens epu erc erg err ess evé fac gue ies il_ ile ili ime in_
+		inf ist ite ité ix_ jou lad lat leu lev lli lor lup man`),
+	Spanish: split(`as_ os_ _de _la de_ es_ la_ est _co _un el_ _el _es _lo ra_ _po _qu _se on_ que sta tra
+		_en _pr con los nte ue_ ant ron ía_ _al _ha _y_ ent ier te_ un_ da_ do_ en_ ida las na_
+		ntr ta_ tas _in _ma _mu ado an_ aro les or_ per por ras res tar uev una _mi _no _nu _pa
+		ar_ des eva ien nas nue ona otr ran seg tes _di _ot _pe _re _su _tr al_ bie dad egú gún
+		ica no_ nos ont son tos uda ón_ ún_ _an _du _le _me aci ada alg cio com das del dos ene
+		era ero ers ert fic go_ gun ici ile ime io_ ión lan lic mer mil nta ora par pol pre qui
+		re_ rid ros rso sti to_ tro ura _a_ _añ _ca _ce _ci _go _ho _pl _si _so _ve ade all ara
+		ari ayo año baj bre ca_ che ció cor cos cía dur egu er_ erc eri ern err ese gob he_ ia_
+		icí inf ios isi jo_ lgu lle llo lo_ lta man may men mis nad nes noc obi och oli ote pla
+		pri pro rec rie rim rno ro_ rot rta rá_ ría se_ so_ sto tic tig tre tud uer ues uno var
+		ves vo_ yor ños _au _ay _cu _e_ _eq _ex _he _ll _ni _or _sa _ta _te _ti _to _va _vi aba
+		ad_ ajo ali alt ami ana ani art ará ast ata ato ayu cad cen cer cho cia cie ciu cta cua
+		dan der dio dir dor ea_ eco ect eda ell ema ena enf eo_ equ erg erm eso esp evo ey_ ez_
+		gad gra gue gul hab hac has her hor ias ico ide ido iga`),
+}
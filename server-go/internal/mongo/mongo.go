@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"news-aggregator/internal/dedup"
+	"news-aggregator/internal/keywords"
+	"news-aggregator/internal/langdetect"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -61,7 +66,8 @@ func NewMongoManager(uri string) (*MongoManager, error) {
 func (m *MongoManager) createCollectionsAndIndexes(ctx context.Context) error {
 	posts := m.db.Collection("posts")
 
-	// Текстовый индекс для полнотекстового поиска
+	// Текстовый индекс для полнотекстового поиска. Язык стемминга документа берётся
+	// из поля language (langdetect.MongoLanguage), russian - для документов без него
 	_, err := posts.Indexes().CreateOne(ctx, mongo.IndexModel{
 		Keys: bson.D{
 			{Key: "title", Value: "text"},
@@ -110,6 +116,14 @@ func (m *MongoManager) createCollectionsAndIndexes(ctx context.Context) error {
 		return err
 	}
 
+	// Фильтр поиска по ключевым словам
+	_, err = posts.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "keywords", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
 	// TTL индекс для автоудаления старых постов (1 год)
 	_, err = posts.Indexes().CreateOne(ctx, mongo.IndexModel{
 		Keys:    bson.D{{Key: "created_at", Value: 1}},
@@ -134,7 +148,7 @@ func (m *MongoManager) createCollectionsAndIndexes(ctx context.Context) error {
 func (m *MongoManager) IndexPost(ctx context.Context, postID int, title, content string, tags []string) error {
 	posts := m.db.Collection("posts")
 
-	contentHash := fmt.Sprintf("%d", hashString(title+content))
+	contentHash := dedup.Compute(title, content).ContentHash
 
 	doc := bson.M{
 		"post_id":      postID,
@@ -150,15 +164,61 @@ func (m *MongoManager) IndexPost(ctx context.Context, postID int, title, content
 		"created_at": time.Now(),
 		"updated_at": time.Now(),
 	}
+	for key, value := range languageFields(title, content) {
+		doc[key] = value
+	}
+	for key, value := range keywordFields(keywords.Extract(title, content)) {
+		doc[key] = value
+	}
 
 	_, err := posts.InsertOne(ctx, doc)
 	return err
 }
 
+// languageFields - код языка поста (lang) и язык стемминга текстового индекса (language)
+func languageFields(title, content string) bson.M {
+	lang := langdetect.Detect(title + "\n" + content).Lang
+	return bson.M{"lang": lang, "language": langdetect.MongoLanguage(lang)}
+}
+
+// keywordFields - ключевые фразы и сущности поста: keywords - все термины в нижнем
+// регистре для фильтра поиска, entities - люди, организации и места с видом
+func keywordFields(terms []keywords.Term) bson.M {
+	list := []string{}
+	entities := []bson.M{}
+	for _, t := range terms {
+		list = append(list, strings.ToLower(t.Term))
+		if t.Kind != keywords.KindKeyword {
+			entities = append(entities, bson.M{"name": t.Term, "kind": t.Kind})
+		}
+	}
+	return bson.M{"keywords": list, "entities": entities}
+}
+
+// SetPostKeywords сохраняет ключевые слова, извлечённые при пересчёте в PostgreSQL
+func (m *MongoManager) SetPostKeywords(ctx context.Context, postID int, terms []keywords.Term) error {
+	posts := m.db.Collection("posts")
+	_, err := posts.UpdateOne(ctx,
+		bson.M{"post_id": postID},
+		bson.M{"$set": keywordFields(terms)},
+	)
+	return err
+}
+
+// SetPostLanguage сохраняет язык, определённый при пересчёте в PostgreSQL
+func (m *MongoManager) SetPostLanguage(ctx context.Context, postID int, lang string) error {
+	posts := m.db.Collection("posts")
+	_, err := posts.UpdateOne(ctx,
+		bson.M{"post_id": postID},
+		bson.M{"$set": bson.M{"lang": lang, "language": langdetect.MongoLanguage(lang)}},
+	)
+	return err
+}
+
 func (m *MongoManager) UpdatePostIndex(ctx context.Context, postID int, title, content string, tags []string) error {
 	posts := m.db.Collection("posts")
 
-	contentHash := fmt.Sprintf("%d", hashString(title+content))
+	contentHash := dedup.Compute(title, content).ContentHash
 
 	update := bson.M{
 		"$set": bson.M{
@@ -169,6 +229,15 @@ func (m *MongoManager) UpdatePostIndex(ctx context.Context, postID int, title, c
 			"updated_at":   time.Now(),
 		},
 	}
+	// Язык и ключевые слова пересчитываются только по полному тексту
+	if content != "" {
+		for key, value := range languageFields(title, content) {
+			update["$set"].(bson.M)[key] = value
+		}
+		for key, value := range keywordFields(keywords.Extract(title, content)) {
+			update["$set"].(bson.M)[key] = value
+		}
+	}
 
 	_, err := posts.UpdateOne(ctx, bson.M{"post_id": postID}, update)
 	return err
@@ -180,6 +249,7 @@ func (m *MongoManager) RemovePostIndex(ctx context.Context, postID int) error {
 	return err
 }
 
+// IsDuplicateContent ищет пост по отпечатку dedup.Compute(title, content).ContentHash
 func (m *MongoManager) IsDuplicateContent(ctx context.Context, contentHash string) (bool, error) {
 	posts := m.db.Collection("posts")
 	count, err := posts.CountDocuments(ctx, bson.M{"content_hash": contentHash})
@@ -197,6 +267,38 @@ func (m *MongoManager) IncrementViewCount(ctx context.Context, postID int) error
 	return err
 }
 
+// SetViewCount - просмотры поста на платформе; счётчик только растёт
+func (m *MongoManager) SetViewCount(ctx context.Context, postID int, views int) error {
+	posts := m.db.Collection("posts")
+	_, err := posts.UpdateOne(ctx,
+		bson.M{"post_id": postID},
+		bson.M{"$max": bson.M{"stats.views": views}},
+	)
+	return err
+}
+
+// SetEngagementStats - лайки и комментарии поста из PostgreSQL (likes_count,
+// comments_count); учитываются в рейтинге top_posts_view
+func (m *MongoManager) SetEngagementStats(ctx context.Context, postID int, likes, comments int) error {
+	posts := m.db.Collection("posts")
+	_, err := posts.UpdateOne(ctx,
+		bson.M{"post_id": postID},
+		bson.M{"$set": bson.M{"stats.likes": likes, "stats.comments": comments}},
+	)
+	return err
+}
+
+// SetVoteStats - голоса на платформе (Reddit): доля голосов "за" и награды;
+// учитываются в рейтинге top_posts_view
+func (m *MongoManager) SetVoteStats(ctx context.Context, postID int, upvoteRatio float64, awards int) error {
+	posts := m.db.Collection("posts")
+	_, err := posts.UpdateOne(ctx,
+		bson.M{"post_id": postID},
+		bson.M{"$set": bson.M{"stats.upvote_ratio": upvoteRatio, "stats.awards": awards}},
+	)
+	return err
+}
+
 func (m *MongoManager) AddTagToPost(ctx context.Context, postID int, tag string) error {
 	posts := m.db.Collection("posts")
 	_, err := posts.UpdateOne(ctx,
@@ -215,6 +317,22 @@ func (m *MongoManager) RemoveTagFromPost(ctx context.Context, postID int, tag st
 	return err
 }
 
+// MergeTags заменяет в массивах tags постов теги from на тег to.
+// Возвращает число изменённых постов.
+func (m *MongoManager) MergeTags(ctx context.Context, from []string, to string) (int64, error) {
+	posts := m.db.Collection("posts")
+	filter := bson.M{"tags": bson.M{"$in": from}}
+	// $addToSet и $pull по одному полю нельзя совместить в одном обновлении
+	res, err := posts.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"tags": to}})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := posts.UpdateMany(ctx, filter, bson.M{"$pull": bson.M{"tags": bson.M{"$in": from}}}); err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
 func (m *MongoManager) UpdatePostStats(ctx context.Context, postID, likesDelta, commentsDelta int) error {
 	posts := m.db.Collection("posts")
 	_, err := posts.UpdateOne(ctx,
@@ -271,6 +389,21 @@ func (m *MongoManager) AdvancedSearch(ctx context.Context, filters map[string]in
         filter["tags"] = bson.M{"$nin": excludeTags}
     }
 
+    if lang, ok := filters["lang"].(string); ok && lang != "" {
+        filter["lang"] = lang
+    }
+
+    // Ключевые фразы и сущности (keywordFields хранит их в нижнем регистре)
+    if terms, ok := filters["keywords"].([]interface{}); ok && len(terms) > 0 {
+        all := make([]interface{}, 0, len(terms))
+        for _, t := range terms {
+            if s, ok := t.(string); ok {
+                all = append(all, strings.ToLower(strings.TrimSpace(s)))
+            }
+        }
+        filter["keywords"] = bson.M{"$all": all}
+    }
+
     // Оптимизация: используем более эффективный набор полей
     opts := options.Find().
         SetLimit(int64(limit)).
@@ -281,6 +414,8 @@ func (m *MongoManager) AdvancedSearch(ctx context.Context, filters map[string]in
             "title":     1,
             "tags":      1,
             "stats":     1,
+            "lang":      1,
+            "keywords":  1,
             "_id":       0,
         })
 
@@ -574,14 +709,26 @@ func (m *MongoManager) MaterializeTopPostsView(ctx context.Context) error {
     pipeline := mongo.Pipeline{
         {{Key: "$match", Value: bson.M{
             "created_at": bson.M{"$gte": cutoffDate},
-            "stats.views": bson.M{"$gt": 0}, // Только посты с просмотрами
+            // Только посты с какой-либо активностью (Reddit просмотры не сообщает)
+            "$or": bson.A{
+                bson.M{"stats.views": bson.M{"$gt": 0}},
+                bson.M{"stats.likes": bson.M{"$gt": 0}},
+                bson.M{"stats.comments": bson.M{"$gt": 0}},
+                bson.M{"stats.awards": bson.M{"$gt": 0}},
+            },
         }}},
+        // Награды платформы весят больше лайка; спорные посты (доля голосов
+        // "за" ниже 1) теряют рейтинг пропорционально, без доли - множитель 1
         {{Key: "$addFields", Value: bson.M{
             "total_score": bson.M{
-                "$add": bson.A{
-                    bson.M{"$multiply": bson.A{"$stats.likes", 3}},
-                    bson.M{"$multiply": bson.A{"$stats.comments", 2}},
-                    bson.M{"$multiply": bson.A{"$stats.views", 0.5}},
+                "$multiply": bson.A{
+                    bson.M{"$add": bson.A{
+                        bson.M{"$multiply": bson.A{"$stats.likes", 3}},
+                        bson.M{"$multiply": bson.A{"$stats.comments", 2}},
+                        bson.M{"$multiply": bson.A{"$stats.views", 0.5}},
+                        bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$stats.awards", 0}}, 5}},
+                    }},
+                    bson.M{"$ifNull": bson.A{"$stats.upvote_ratio", 1}},
                 },
             },
         }}},
@@ -638,12 +785,3 @@ func (m *MongoManager) Close() error {
 	defer cancel()
 	return m.client.Disconnect(ctx)
 }
-
-// Вспомогательная функция для хэширования
-func hashString(s string) uint64 {
-	var hash uint64 = 5381
-	for _, c := range s {
-		hash = ((hash << 5) + hash) + uint64(c)
-	}
-	return hash
-}
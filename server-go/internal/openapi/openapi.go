@@ -0,0 +1,18 @@
+// Package openapi отдаёт OpenAPI-описание REST API сервера.
+// При изменении маршрутов в handlers.SetupRoutes нужно обновлять openapi.json.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Handler обслуживает GET /api/openapi.json
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(spec)
+}
@@ -0,0 +1,95 @@
+// Package tagnorm - нормализация тегов: "#Новости", "новости" и "новостей" получают
+// один ключ. Ключ - транслитерированная латиницей основа каждого слова, поэтому
+// "Москва" и "moskva" тоже совпадают. Переводы ("news" - "новости") ключ не связывает:
+// для них есть таблица синонимов tag_aliases.
+package tagnorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength - длина тега в таблице tags
+const MaxLength = 100
+
+// minStem - окончание не отрезается, если от слова останется меньше букв
+const minStem = 3
+
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye",
+}
+
+// endings - окончания русских (после транслитерации) и английских слов,
+// от длинных к коротким; отрезается первое подошедшее
+var endings = []string{
+	"iyami", "yami", "iyakh",
+	"ami", "ogo", "ego", "omu", "emu", "ymi", "imi", "aya", "yaya", "iye", "iya", "iyu",
+	"akh", "yakh", "ykh", "ikh", "ing", "ies",
+	"ov", "ev", "ey", "oy", "om", "em", "am", "ym", "im", "ie", "ii", "ye", "yy", "iy", "ed", "es",
+	"a", "e", "i", "o", "u", "y", "s",
+}
+
+// invariant - английские слова на "s", у которых это не окончание множественного
+// числа: "news" не должно совпасть с "new"
+var invariant = map[string]bool{
+	"news": true, "series": true, "species": true, "means": true,
+	"physics": true, "politics": true, "economics": true, "mathematics": true,
+	"ethics": true, "analytics": true, "electronics": true, "logistics": true,
+}
+
+// Display - имя нового тега: без "#", в нижнем регистре, с одиночными пробелами
+func Display(name string) string {
+	name = strings.Join(strings.Fields(strings.TrimLeft(strings.TrimSpace(name), "#")), " ")
+	name = strings.ToLower(name)
+	if r := []rune(name); len(r) > MaxLength {
+		name = strings.TrimSpace(string(r[:MaxLength]))
+	}
+	return name
+}
+
+// Key - ключ нормализации: основы слов через пробел. Пустой, если в теге нет букв и цифр.
+func Key(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		fields[i] = stem(transliterate(f))
+	}
+	key := strings.Join(fields, " ")
+	if r := []rune(key); len(r) > MaxLength {
+		key = strings.TrimSpace(string(r[:MaxLength]))
+	}
+	return key
+}
+
+func transliterate(word string) string {
+	var b strings.Builder
+	for _, r := range strings.ReplaceAll(word, "ё", "е") {
+		if t, ok := translit[r]; ok {
+			b.WriteString(t)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func stem(word string) string {
+	if invariant[word] {
+		return word
+	}
+	// "s" после s, u, i - часть основы: "class", "status", "crisis", "avtobus"
+	if strings.HasSuffix(word, "ss") || strings.HasSuffix(word, "us") || strings.HasSuffix(word, "is") {
+		return word
+	}
+	for _, e := range endings {
+		if strings.HasSuffix(word, e) && len([]rune(word))-len(e) >= minStem {
+			return strings.TrimSuffix(word, e)
+		}
+	}
+	return word
+}
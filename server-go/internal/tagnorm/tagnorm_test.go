@@ -0,0 +1,84 @@
+package tagnorm
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestKeyMerges(t *testing.T) {
+	// Варианты одного тега получают один ключ
+	groups := [][]string{
+		{"#Новости", "новости", "Новостей", "НОВОСТИ", "новость"},
+		{"news", "#News", "NEWS"},
+		{"Москва", "moskva", "Москвы"},
+		{"игры", "игра", "#Игр"},
+		{"games", "game", "Games"},
+		{"stories", "story"},
+		{"status", "Status"},
+		{"кризис", "кризиса", "кризисы"},
+		{"Искусственный интеллект", "искусственного интеллекта"},
+	}
+	for _, group := range groups {
+		want := Key(group[0])
+		if want == "" {
+			t.Errorf("Key(%q) is empty", group[0])
+		}
+		for _, name := range group[1:] {
+			if got := Key(name); got != want {
+				t.Errorf("Key(%q) = %q, want %q as for %q", name, got, want, group[0])
+			}
+		}
+	}
+}
+
+func TestKeyKeepsApart(t *testing.T) {
+	// Переводы и похожие слова не сливаются: переводы связывает tag_aliases
+	pairs := [][2]string{
+		{"news", "новости"},
+		{"news", "new"},
+		{"series", "serie"},
+		{"игры", "игрок"},
+		{"python", "pyth"},
+	}
+	for _, p := range pairs {
+		if a, b := Key(p[0]), Key(p[1]); a == b {
+			t.Errorf("Key(%q) = Key(%q) = %q, want different keys", p[0], p[1], a)
+		}
+	}
+}
+
+func TestKeyWithoutLetters(t *testing.T) {
+	for _, name := range []string{"", "#", "  ", "#!?"} {
+		if got := Key(name); got != "" {
+			t.Errorf("Key(%q) = %q, want empty", name, got)
+		}
+	}
+}
+
+func TestLongTagsCutByRunes(t *testing.T) {
+	for _, word := range []string{"東京", "🔥", "ß"} {
+		long := strings.Repeat(word, MaxLength)
+		for name, got := range map[string]string{"Key": Key(long), "Display": Display(long)} {
+			if !utf8.ValidString(got) {
+				t.Errorf("%s(%q...) is not valid UTF-8", name, word)
+			}
+			if n := utf8.RuneCountInString(got); n > MaxLength {
+				t.Errorf("%s(%q...) has %d runes, want at most %d", name, word, n, MaxLength)
+			}
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"#Новости", "новости"},
+		{"  ##Big   Data ", "big data"},
+		{"news", "news"},
+	}
+	for _, tt := range tests {
+		if got := Display(tt.in); got != tt.want {
+			t.Errorf("Display(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
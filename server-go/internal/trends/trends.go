@@ -0,0 +1,90 @@
+// Package trends - поиск растущих тегов и слов: частота в последнем окне сравнивается
+// со средней частотой в таких же окнах базового периода перед ним. Число упоминаний
+// в окне считается пуассоновским, поэтому z-оценка = (n - среднее) / sqrt(среднее).
+package trends
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// MinCount - тег должен встретиться в окне хотя бы столько раз
+	MinCount = 2
+	// MinZScore - порог всплеска относительно базового периода
+	MinZScore = 2.0
+)
+
+// Window - окно сравнения и длина базового периода в таких окнах
+type Window struct {
+	Name      string
+	Hours     int
+	Baselines int
+}
+
+// Windows - допустимые значения window: час сравнивается с прошедшими сутками,
+// сутки - с прошедшей неделей
+var Windows = map[string]Window{
+	"1h":  {Name: "1h", Hours: 1, Baselines: 24},
+	"24h": {Name: "24h", Hours: 24, Baselines: 7},
+}
+
+// Counts - упоминания в окне и за весь базовый период
+type Counts struct {
+	Recent   int
+	Baseline int
+}
+
+// Trend - растущий тег или слово
+type Trend struct {
+	Name         string  `json:"name"`
+	Count        int     `json:"count"`
+	BaselineMean float64 `json:"baseline_mean"`
+	GrowthRatio  float64 `json:"growth_ratio"`
+	ZScore       float64 `json:"z_score"`
+	Emerging     bool    `json:"emerging"` // в базовом периоде не встречался
+}
+
+// Detect отбирает всплески: не меньше MinCount упоминаний в окне и z-оценка не ниже
+// MinZScore либо первое появление. Результат упорядочен по z-оценке.
+func Detect(counts map[string]Counts, w Window, limit int) []Trend {
+	trends := []Trend{}
+	for name, c := range counts {
+		if c.Recent < MinCount {
+			continue
+		}
+		mean := float64(c.Baseline) / float64(w.Baselines)
+		// Дисперсия не меньше одного упоминания, чтобы редкие теги не давали бесконечных оценок
+		z := (float64(c.Recent) - mean) / math.Sqrt(math.Max(mean, 1))
+		emerging := c.Baseline == 0
+		if z < MinZScore && !emerging {
+			continue
+		}
+		trends = append(trends, Trend{
+			Name:         name,
+			Count:        c.Recent,
+			BaselineMean: round(mean),
+			GrowthRatio:  round((float64(c.Recent) + 1) / (mean + 1)),
+			ZScore:       round(z),
+			Emerging:     emerging,
+		})
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].ZScore != trends[j].ZScore {
+			return trends[i].ZScore > trends[j].ZScore
+		}
+		if trends[i].Count != trends[j].Count {
+			return trends[i].Count > trends[j].Count
+		}
+		return trends[i].Name < trends[j].Name
+	})
+	if len(trends) > limit {
+		trends = trends[:limit]
+	}
+	return trends
+}
+
+func round(x float64) float64 {
+	return math.Round(x*1000) / 1000
+}
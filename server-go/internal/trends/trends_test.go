@@ -0,0 +1,54 @@
+package trends
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	counts := map[string]Counts{
+		// 30 за сутки при 10 в среднем за сутки недели: z = 20 / sqrt(10)
+		"выборы": {Recent: 30, Baseline: 70},
+		// Обычный уровень
+		"погода": {Recent: 11, Baseline: 70},
+		"курс":   {Recent: 3, Baseline: 14},
+		// Впервые за неделю: дисперсия не меньше 1, z = n
+		"затмение": {Recent: 5},
+		"бета":     {Recent: 3},
+		"альфа":    {Recent: 3},
+		"новинка":  {Recent: 2},
+		// Меньше MinCount упоминаний
+		"редкий": {Recent: 1},
+	}
+	want := []Trend{
+		{Name: "выборы", Count: 30, BaselineMean: 10, GrowthRatio: 2.818, ZScore: 6.325},
+		{Name: "затмение", Count: 5, GrowthRatio: 6, ZScore: 5, Emerging: true},
+		// Равные z-оценки и счётчики - по имени
+		{Name: "альфа", Count: 3, GrowthRatio: 4, ZScore: 3, Emerging: true},
+		{Name: "бета", Count: 3, GrowthRatio: 4, ZScore: 3, Emerging: true},
+		{Name: "новинка", Count: 2, GrowthRatio: 3, ZScore: 2, Emerging: true},
+	}
+	got := Detect(counts, Windows["24h"], 10)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("trends =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := Detect(counts, Windows["24h"], 2); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("limit 2: trends = %+v", got)
+	}
+}
+
+func TestDetectRareBaseline(t *testing.T) {
+	// Среднее 0.5 в час за сутки: знаменатель z-оценки не меньше 1
+	got := Detect(map[string]Counts{"тег": {Recent: 4, Baseline: 12}}, Windows["1h"], 10)
+	want := []Trend{{Name: "тег", Count: 4, BaselineMean: 0.5, GrowthRatio: 3.333, ZScore: 3.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("trends = %+v, want %+v", got, want)
+	}
+}
+
+func TestDetectEmpty(t *testing.T) {
+	// Пустой результат - пустой срез, а не nil: в JSON это [], а не null
+	if got := Detect(nil, Windows["1h"], 10); got == nil || len(got) != 0 {
+		t.Errorf("trends = %#v, want empty slice", got)
+	}
+}